@@ -4,31 +4,45 @@ const functionMapping: { [key: string]: string } = {
 	"location-wkt": "location/wkt",
 	"location-address": "location/address",
 	"specialty-all": "specialty/all",
+	"specialty-triage": "specialty/triage",
 	"specialist-find": "specialist/find",
+	"specialist-search": "specialist/search",
+	"specialist-get": "specialist/{id}",
+	"emergency-nearest": "emergency/nearest",
+};
+
+// endpoints not called with POST, path parameters in braces are filled from the arguments
+const functionMethod: { [key: string]: string } = {
+	"specialist-get": "GET",
 };
 
 const functionDescription = [
 	{
 		name: "time-current",
-		description: "Gets the current time",
+		description:
+			"Gets the current time with the UTC offset, daylight saving time flag and weekday. Prefer passing the user's location; the timezone is then resolved automatically",
 		parameters: {
 			type: "object",
 			properties: {
+				location: {
+					type: "string",
+					description:
+						"WKT point of the location, longitude first, e.g. POINT(21.2496774 48.7172272), as returned by location-wkt",
+				},
 				timezone: {
 					type: "string",
 					description:
-						"Timezone as a string representation in format Shanghai/China",
+						"IANA timezone in format Area/City, e.g. Europe/Bratislava; only used when no location is given",
 				},
 			},
-			required: ["timezone"],
 			description:
-				"Payload containing timezone as a string representation in format Shanghai/China",
+				"Payload containing either the location as a WKT point or an IANA timezone",
 		},
 	},
 	{
 		name: "location-wkt",
 		description:
-			"Generates WKT representation of a location from a string representation of the location",
+			"Generates WKT representation of a location from a string representation of the location. The response lists the matching candidates; when 'ambiguous' is true, ask the user which candidate they meant instead of picking one",
 		parameters: {
 			type: "object",
 			properties: {
@@ -37,6 +51,11 @@ const functionDescription = [
 					description:
 						"A string representation of the user's location, e.g. 'London, UK'",
 				},
+				limit: {
+					type: "number",
+					description:
+						"Optional maximum number of candidates, between 1 and 10. Default value is 5.",
+				},
 			},
 			required: ["user_location"],
 			description:
@@ -53,7 +72,7 @@ const functionDescription = [
 				wkt_location: {
 					type: "string",
 					description:
-						"A WKT representation of the user's location, e.g. 'POINT(21.2496774 48.7172272)'",
+						"A WKT representation of the user's location with the longitude first, e.g. 'POINT(21.2496774 48.7172272)'",
 				},
 			},
 			required: ["wkt_location"],
@@ -65,6 +84,23 @@ const functionDescription = [
 		name: "specialty-all",
 		description: "Gets list of all specialties in the database",
 	},
+	{
+		name: "specialty-triage",
+		description:
+			"Suggests specialties for the user's complaint using a curated symptom mapping. Prefer its candidates over guessing the specialty",
+		parameters: {
+			type: "object",
+			properties: {
+				text: {
+					type: "string",
+					description:
+						"The user's complaint in their own words, e.g. 'bolí ma zub'",
+				},
+			},
+			required: ["text"],
+			description: "Payload containing the user's complaint",
+		},
+	},
 	{
 		name: "specialist-find",
 		description:
@@ -85,7 +121,7 @@ const functionDescription = [
 				user_location: {
 					type: "string",
 					description:
-						"WKT representation representation of the user's location, e.g. 'POINT(21.2496774 48.7172272)'",
+						"WKT representation of the user's location with the longitude first, e.g. 'POINT(21.2496774 48.7172272)'",
 				},
 			},
 			required: ["specialty_id", "radius", "user_location"],
@@ -93,6 +129,82 @@ const functionDescription = [
 				"Payload containing user preferences when searching for a specialist",
 		},
 	},
+	{
+		name: "specialist-search",
+		description:
+			"Searches specialists by free text across clinic name, doctor names, address and specialty. Diacritics and small typos are tolerated",
+		parameters: {
+			type: "object",
+			properties: {
+				query: {
+					type: "string",
+					description:
+						"Free text query exactly as typed by the user, e.g. 'ocny lekar michalovce'",
+				},
+				limit: {
+					type: "number",
+					description: "Maximum number of results, default is 20",
+				},
+				origin: {
+					type: "string",
+					description:
+						"Optional WKT representation of the user's location the navigation directions start at, e.g. 'POINT(21.2496774 48.7172272)'",
+				},
+			},
+			required: ["query"],
+			description: "Payload containing the search query",
+		},
+	},
+	{
+		name: "specialist-get",
+		description:
+			"Gets the full profile of a single specialist: specialty, opening hours, staff, insurers, reviews and navigation links. Use it to follow up on a search or find result",
+		parameters: {
+			type: "object",
+			properties: {
+				id: {
+					type: "number",
+					description: "Id of the specialist from a previous search or find result",
+				},
+			},
+			required: ["id"],
+			description: "Payload containing the specialist id",
+		},
+	},
+	{
+		name: "emergency-nearest",
+		description:
+			"Finds emergency and on-call services open right now near the user, the nearest first: out-of-hours practices (ambulantná pohotovostná služba), hospital emergency departments and pharmacies on duty. Use it whenever the user needs help outside regular hours or urgently; for life-threatening situations always tell the user to call 112 or 155 first",
+		parameters: {
+			type: "object",
+			properties: {
+				user_location: {
+					type: "string",
+					description:
+						"A WKT representation of the user's location with the longitude first, e.g. 'POINT(21.2496774 48.7172272)'",
+				},
+				kind: {
+					type: "string",
+					enum: ["aps", "er", "pharmacy"],
+					description:
+						"Optional kind of service: aps for the out-of-hours practice, er for a hospital emergency department, pharmacy for a pharmacy on duty",
+				},
+				radius: {
+					type: "number",
+					description:
+						"Optional search radius in meters, at most 100000. Default value is 20000.",
+				},
+				limit: {
+					type: "number",
+					description:
+						"Optional maximum number of services, between 1 and 20. Default value is 5.",
+				},
+			},
+			required: ["user_location"],
+			description:
+				"Payload containing the user location and optional filters",
+		},
+	},
 ];
 
-export { functionDescription, functionMapping };
+export { functionDescription, functionMapping, functionMethod };
@@ -1,4 +1,8 @@
-import { functionDescription, functionMapping } from "./functions";
+import {
+	functionDescription,
+	functionMapping,
+	functionMethod,
+} from "./functions";
 
 async function postOpenAI(
 	apiKey: string,
@@ -25,18 +29,43 @@ async function postOpenAI(
 	});
 }
 
+// Instructions for the model when the server rejects a function call, keyed by the error code
+const errorHints: Record<string, string> = {
+	invalid_payload:
+		"The function arguments were invalid, fix the listed fields and call the function again.",
+	not_found:
+		"Nothing was found, tell the user and suggest refining the request.",
+	conflict: "The change conflicts with existing data, tell the user.",
+	rate_limited:
+		"The service is busy, tell the user to try again in a few minutes.",
+	timeout: "The service did not respond in time, tell the user to try again later.",
+	upstream_error:
+		"An external service failed, tell the user to try again later.",
+	unavailable: "The service is unavailable, tell the user to try again later.",
+	internal_error:
+		"The service failed, apologise to the user and quote the request_id if they want to report it.",
+};
+
 async function postToServer(endpoint: string, body: string): Promise<Response> {
 	const serverURL = process.env.SERVER_URL;
-	const endpointMap = functionMapping[endpoint] || endpoint;
+	const method = functionMethod[endpoint] || "POST";
+	let endpointMap = functionMapping[endpoint] || endpoint;
+
+	if (endpointMap.includes("{")) {
+		const args = JSON.parse(body);
+		endpointMap = endpointMap.replace(/\{(\w+)\}/g, (_, key) =>
+			encodeURIComponent(String(args[key] ?? "")),
+		);
+	}
 
 	const headers = {
 		"Content-Type": "application/json",
 	};
 
 	return fetch(`${serverURL}/${endpointMap}`, {
-		method: "POST",
+		method,
 		headers,
-		body: body,
+		body: method === "GET" ? undefined : body,
 	});
 }
 
@@ -104,6 +133,9 @@ export async function POST(req: Request): Promise<Response> {
 			// Send request to the determined server endpoint
 			const serverResponse = await postToServer(endpoint, bodyToServer);
 			const serverResult = await serverResponse.json();
+			if (!serverResponse.ok && errorHints[serverResult.code]) {
+				serverResult.hint = errorHints[serverResult.code];
+			}
 
 			conversations.push({
 				role: "function",
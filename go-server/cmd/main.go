@@ -1,16 +1,21 @@
 package main
 
 import (
+	"context"
 	"database/sql"
 	"errors"
 	"flag"
 	"fmt"
+	"net/http"
 	"os"
 	"time"
 
+	"github.com/acornak/healthcare-poc/geocoding"
 	"github.com/acornak/healthcare-poc/handlers"
+	"github.com/acornak/healthcare-poc/migrations"
 	"github.com/acornak/healthcare-poc/models"
 	"github.com/acornak/healthcare-poc/scrapers"
+	"github.com/acornak/healthcare-poc/timezone"
 	"github.com/joho/godotenv"
 	swaggerFiles "github.com/swaggo/files"
 	ginSwagger "github.com/swaggo/gin-swagger"
@@ -35,22 +40,39 @@ type server struct {
 }
 
 type config struct {
-	port   string
-	env    string
-	dbConn dbConfig
+	port       string
+	env        string
+	adminToken string
+	dbConn     dbConfig
+	geocoding  geocodingConfig
+}
+
+type geocodingConfig struct {
+	providers    []string
+	url          string
+	apiKey       string
+	nominatimURL string
+	cacheTTL     time.Duration
 }
 
 type dbConfig struct {
-	host     string
-	port     string
-	user     string
-	password string
-	dbname   string
-	sslmode  string
+	host         string
+	port         string
+	user         string
+	password     string
+	dbname       string
+	sslmode      string
+	queryTimeout time.Duration
 }
 
 var apiVersion = "v1"
 
+// geocoded places rarely move, a month keeps the provider quota low
+const defaultGeocodeCacheTTL = 30 * 24 * time.Hour
+
+// specialists are scraped again after the interval, a run still going on by then is cancelled
+const refreshInterval = 2 * time.Minute
+
 func loadConfigFromEnv(cfg *config) error {
 	cfg.port = os.Getenv("PORT")
 	cfg.dbConn.host = os.Getenv("DB_HOST")
@@ -59,6 +81,23 @@ func loadConfigFromEnv(cfg *config) error {
 	cfg.dbConn.password = os.Getenv("DB_PASS")
 	cfg.dbConn.dbname = os.Getenv("DB_NAME")
 	cfg.dbConn.sslmode = os.Getenv("SSL_MODE")
+	cfg.adminToken = os.Getenv("ADMIN_TOKEN")
+	cfg.geocoding.providers = splitList(os.Getenv("GEOCODERS"))
+	cfg.geocoding.url = os.Getenv("GEOCODE_URL")
+	cfg.geocoding.apiKey = os.Getenv("GEOCODE_API_KEY")
+	cfg.geocoding.nominatimURL = os.Getenv("NOMINATIM_URL")
+
+	cacheTTL, err := parseDuration(os.Getenv("GEOCODE_CACHE_TTL"), defaultGeocodeCacheTTL)
+	if err != nil {
+		return fmt.Errorf("invalid GEOCODE_CACHE_TTL configuration: %w", err)
+	}
+	cfg.geocoding.cacheTTL = cacheTTL
+
+	queryTimeout, err := parseDuration(os.Getenv("DB_QUERY_TIMEOUT"), models.DefaultQueryTimeout)
+	if err != nil {
+		return fmt.Errorf("invalid DB_QUERY_TIMEOUT configuration: %w", err)
+	}
+	cfg.dbConn.queryTimeout = queryTimeout
 
 	return validateConfig(cfg)
 }
@@ -96,6 +135,7 @@ func initializeDatabase(cfg *dbConfig, logger *zap.Logger) (*sql.DB, error) {
 
 func newServer(logger *zap.Logger, handler *handlers.Handler, scraper *scrapers.Scraper) *server {
 	router := gin.Default()
+	router.Use(handler.RequestID)
 	s := &server{Router: router, Logger: logger, Handler: handler, Scraper: scraper}
 
 	prefix := "/api/" + apiVersion
@@ -108,25 +148,91 @@ func newServer(logger *zap.Logger, handler *handlers.Handler, scraper *scrapers.
 	router.POST(prefix+"/math/subtract", handler.Subtract)
 	router.POST(prefix+"/math/compute", handler.Compute)
 
+	// Emergency
+	router.POST(prefix+"/emergency/nearest", handler.FindNearestEmergency)
+
 	// Time
 	router.POST(prefix+"/time/current", handler.GetCurrentTime)
+	router.GET(prefix+"/time/holidays", handler.GetHolidays)
 
 	// Location
 	router.POST(prefix+"/location/wkt", handler.GetWKTLocation)
+	router.POST(prefix+"/location/wkt/batch", handler.GetWKTLocationBatch)
 	router.POST(prefix+"/location/address", handler.GetAddressFromWKT)
 
 	// Specialist
 	router.POST(prefix+"/specialist/find", handler.FindSpecialist)
+	router.POST(prefix+"/specialist/search", handler.SearchSpecialist)
+	router.GET(prefix+"/specialist/:id", handler.GetSpecialist)
+	router.GET(prefix+"/specialist/:id/history", handler.GetPublicSpecialistHistory)
 	// TODO
 	// closest specialist
 	// all specialists in area
 
 	// Specialties
 	router.POST(prefix+"/specialty/all", handler.GetSpecialties)
+	router.POST(prefix+"/specialty/triage", handler.TriageSpecialty)
+
+	// Admin
+	admin := router.Group(prefix+"/admin", handler.RequireAdmin)
+	admin.POST("/specialist", handler.SaveSpecialist)
+	admin.POST("/specialist/delete", handler.DeleteSpecialist)
+	admin.POST("/specialist/overrides", handler.GetSpecialistOverrides)
+	admin.POST("/specialist/override", handler.SetSpecialistOverrides)
+	admin.POST("/specialist/override/delete", handler.DeleteSpecialistOverrides)
+	admin.GET("/specialist/:id/history", handler.GetSpecialistHistory)
+	admin.POST("/specialist/duplicates", handler.GetDuplicateSpecialists)
+	admin.POST("/specialist/duplicates/dismiss", handler.DismissDuplicateSpecialists)
+	admin.POST("/specialist/merge", handler.MergeSpecialists)
+	admin.POST("/specialty", handler.SaveSpecialty)
+	admin.POST("/specialty/delete", handler.DeleteSpecialty)
+	admin.POST("/specialty/merge", handler.MergeSpecialties)
+	admin.POST("/specialty/symptoms", handler.GetSymptomMappings)
+	admin.POST("/specialty/symptom", handler.SaveSymptomMapping)
+	admin.POST("/specialty/symptom/delete", handler.DeleteSymptomMapping)
+	admin.POST("/review", handler.SaveReview)
+	admin.POST("/review/delete", handler.DeleteReview)
+	admin.POST("/audit", handler.GetAuditLog)
+	admin.POST("/geocode/cache/stats", handler.GetGeocodeCacheStats)
+	admin.POST("/geocode/cache/purge", handler.PurgeGeocodeCache)
+	admin.POST("/specialist/address-checks", handler.GetAddressChecks)
+	admin.POST("/holiday/override", handler.SetHolidayOverride)
+	admin.POST("/holiday/override/delete", handler.DeleteHolidayOverride)
+	admin.POST("/emergency/service", handler.SaveEmergencyService)
+	admin.POST("/emergency/service/delete", handler.DeleteEmergencyService)
 
 	return s
 }
 
+/*
+refreshSteps returns the steps of a refresh run in order:
+scraping the specialists, verifying their addresses, seeding the curated data, loading the gazetteer and purging the geocoding cache
+A failing step does not stop the following ones
+*/
+func refreshSteps(handler *handlers.Handler, scraper *scrapers.Scraper, gazetteer *geocoding.Gazetteer) []func(ctx context.Context) error {
+	return []func(ctx context.Context) error{
+		scraper.ScrapeHandler,
+		scraper.VerifyAddresses,
+		scraper.SeedSpecialtyTaxonomy,
+		scraper.SeedSymptomMappings,
+		func(ctx context.Context) error {
+			places, err := handler.Models.Specialists.GetSpecialistPlaces(ctx)
+			if err != nil {
+				return err
+			}
+			gazetteer.SetAddresses(places)
+			return nil
+		},
+		func(ctx context.Context) error {
+			if handler.GeocodeCache == nil {
+				return nil
+			}
+			_, err := handler.GeocodeCache.Purge(ctx, true)
+			return err
+		},
+	}
+}
+
 func main() {
 	logger, err := zap.NewProduction()
 	if err != nil {
@@ -163,6 +269,25 @@ func main() {
 	}
 	defer db.Close()
 
+	migrator, err := migrations.NewDefault(db, logger)
+	if err != nil {
+		logger.Fatal("failed to load migrations:", zap.Error(err))
+	}
+
+	// go run ./cmd migrate up [version] | down [steps] | status
+	if flag.Arg(0) == "migrate" {
+		if err := runMigrate(migrator, flag.Args()[1:], os.Stdout); err != nil {
+			logger.Fatal("migration failed:", zap.Error(err))
+		}
+		return
+	}
+
+	applied, err := migrator.Up(0)
+	if err != nil {
+		logger.Fatal("failed to apply migrations:", zap.Error(err))
+	}
+	logger.Info("database schema is up to date", zap.Int("applied", applied))
+
 	ginMode := os.Getenv("GIN_MODE")
 	if ginMode == "" {
 		ginMode = "debug"
@@ -170,11 +295,77 @@ func main() {
 	}
 	gin.SetMode(ginMode)
 
-	s := newServer(
-		logger,
-		handlers.NewHandler(logger, models.NewModels(db)),
-		scrapers.NewScraper(logger, models.NewModels(db)),
-	)
+	// offline gazetteer, specialist addresses are loaded after every scrape
+	gazetteer := geocoding.NewGazetteer()
+	municipalities, err := municipalityPlaces()
+	if err != nil {
+		logger.Fatal("failed to load municipalities:", zap.Error(err))
+	}
+	gazetteer.SetMunicipalities(municipalities)
+
+	geocoder, err := geocoding.New(geocoding.Config{
+		Providers:    cfg.geocoding.providers,
+		MapsCoURL:    cfg.geocoding.url,
+		MapsCoAPIKey: cfg.geocoding.apiKey,
+		NominatimURL: cfg.geocoding.nominatimURL,
+		Do:           (&http.Client{Timeout: 10 * time.Second}).Do,
+		Gazetteer:    gazetteer,
+		Logger:       logger,
+	})
+	if err != nil {
+		logger.Fatal("failed to configure geocoding:", zap.Error(err))
+	}
+	logger.Info("geocoding providers configured", zap.String("providers", geocoder.Name()))
+
+	timezones, err := timezone.NewDefault()
+	if err != nil {
+		logger.Fatal("failed to load timezone boundaries:", zap.Error(err))
+	}
+
+	storage := models.NewModels(db)
+	storage.DB.QueryTimeout = cfg.dbConn.queryTimeout
+	if cfg.dbConn.queryTimeout == 0 {
+		logger.Info("DB_QUERY_TIMEOUT is 0, database queries are not bounded")
+	}
+
+	handler := handlers.NewHandler(logger, storage)
+	handler.Timezones = timezones
+	handler.Geocoder = geocoder
+	if cfg.geocoding.cacheTTL > 0 {
+		handler.GeocodeCache = geocoding.NewCache(geocoder, storage.DB, cfg.geocoding.cacheTTL, logger)
+		handler.Geocoder = handler.GeocodeCache
+	} else {
+		logger.Info("GEOCODE_CACHE_TTL is 0, geocoding cache is disabled")
+	}
+	handler.AdminToken = cfg.adminToken
+	if cfg.adminToken == "" {
+		logger.Info("ADMIN_TOKEN not found in env, admin endpoints are disabled")
+	}
+
+	scraper := scrapers.NewScraper(logger, storage)
+
+	// addresses are verified against the online providers only, the gazetteer is built from the very addresses being verified
+	var verifier geocoding.Geocoder
+	verifierProviders := withoutProvider(cfg.geocoding.providers, geocoding.ProviderGazetteer)
+	if len(cfg.geocoding.providers) > 0 && len(verifierProviders) == 0 {
+		err = errors.New("no online geocoding provider configured")
+	} else {
+		verifier, err = geocoding.New(geocoding.Config{
+			Providers:    verifierProviders,
+			MapsCoURL:    cfg.geocoding.url,
+			MapsCoAPIKey: cfg.geocoding.apiKey,
+			NominatimURL: cfg.geocoding.nominatimURL,
+			Do:           (&http.Client{Timeout: 10 * time.Second}).Do,
+			Logger:       logger,
+		})
+	}
+	if err != nil {
+		logger.Info("address verification is disabled", zap.Error(err))
+	} else {
+		scraper.Geocoder = verifier
+	}
+
+	s := newServer(logger, handler, scraper)
 
 	port := os.Getenv("PORT")
 	if port == "" {
@@ -182,18 +373,27 @@ func main() {
 		logger.Info("PORT not found in env, using 8080 as default")
 	}
 
-	// initial scrape
-	if err = s.Scraper.ScrapeHandler(); err != nil {
-		logger.Error("", zap.Error(err))
+	// scrape specialists and apply the curated seed data on top of them
+	refresh := func() {
+		ctx, cancel := context.WithTimeout(context.Background(), refreshInterval)
+		defer cancel()
+
+		steps := refreshSteps(handler, s.Scraper, gazetteer)
+		for _, step := range steps {
+			if err := step(ctx); err != nil {
+				logger.Error("", zap.Error(err))
+			}
+		}
 	}
 
+	// initial scrape
+	refresh()
+
 	// scrape specialists every 2 minutes
-	ticker := time.NewTicker(2 * time.Minute)
+	ticker := time.NewTicker(refreshInterval)
 	go func() {
 		for range ticker.C {
-			if err := s.Scraper.ScrapeHandler(); err != nil {
-				logger.Error("", zap.Error(err))
-			}
+			refresh()
 		}
 	}()
 
@@ -1,14 +1,21 @@
 package main
 
 import (
+	"context"
+	"io"
 	"net/http"
 	"net/http/httptest"
 	"os"
+	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/geocoding"
 	"github.com/acornak/healthcare-poc/handlers"
+	"github.com/acornak/healthcare-poc/models"
 	"github.com/acornak/healthcare-poc/scrapers"
+	"github.com/acornak/healthcare-poc/types"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -63,6 +70,38 @@ func TestMainRoutes(t *testing.T) {
 		{"POST", "/api/v1/math/add", http.StatusBadRequest},
 		{"POST", "/api/v1/math/subtract", http.StatusBadRequest},
 		{"POST", "/api/v1/math/compute", http.StatusBadRequest},
+		{"POST", "/api/v1/location/wkt/batch", http.StatusBadRequest},
+		{"POST", "/api/v1/specialist/search", http.StatusBadRequest},
+		{"GET", "/api/v1/specialist/abc", http.StatusBadRequest},
+		{"GET", "/api/v1/specialist/abc/history", http.StatusBadRequest},
+		{"GET", "/api/v1/time/holidays?year=abc", http.StatusBadRequest},
+		{"POST", "/api/v1/specialty/triage", http.StatusBadRequest},
+		{"POST", "/api/v1/emergency/nearest", http.StatusBadRequest},
+		{"POST", "/api/v1/admin/specialist", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/specialist/delete", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/specialist/overrides", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/specialist/override", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/specialist/override/delete", http.StatusUnauthorized},
+		{"GET", "/api/v1/admin/specialist/1/history", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/specialist/duplicates", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/specialist/duplicates/dismiss", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/specialist/merge", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/specialty", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/specialty/delete", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/specialty/merge", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/specialty/symptoms", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/specialty/symptom", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/specialty/symptom/delete", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/review", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/review/delete", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/audit", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/geocode/cache/stats", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/geocode/cache/purge", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/specialist/address-checks", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/holiday/override", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/holiday/override/delete", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/emergency/service", http.StatusUnauthorized},
+		{"POST", "/api/v1/admin/emergency/service/delete", http.StatusUnauthorized},
 	}
 
 	for _, tt := range tests {
@@ -112,4 +151,108 @@ func TestLoadConfigFromEnv(t *testing.T) {
 	if cfg.dbConn.sslmode != "disable" {
 		t.Errorf("Expected SSL_MODE to be 'disable', got '%s'", cfg.dbConn.sslmode)
 	}
+	if cfg.geocoding.cacheTTL != defaultGeocodeCacheTTL {
+		t.Errorf("Expected geocode cache TTL to be '%s', got '%s'", defaultGeocodeCacheTTL, cfg.geocoding.cacheTTL)
+	}
+	if cfg.dbConn.queryTimeout != models.DefaultQueryTimeout {
+		t.Errorf("Expected query timeout to be '%s', got '%s'", models.DefaultQueryTimeout, cfg.dbConn.queryTimeout)
+	}
+}
+
+func TestLoadConfigFromEnv_InvalidGeocodeCacheTTL(t *testing.T) {
+	t.Setenv("GEOCODE_CACHE_TTL", "a month")
+
+	cfg := config{}
+	err := loadConfigFromEnv(&cfg)
+	if err == nil || !strings.Contains(err.Error(), "GEOCODE_CACHE_TTL") {
+		t.Errorf("Expected GEOCODE_CACHE_TTL error, got %v", err)
+	}
+}
+
+func TestLoadConfigFromEnv_QueryTimeout(t *testing.T) {
+	t.Setenv("DB_QUERY_TIMEOUT", "3s")
+
+	cfg := config{}
+	err := loadConfigFromEnv(&cfg)
+	if err != nil {
+		t.Errorf("Unexpected error loading config: %v", err)
+	}
+	if cfg.dbConn.queryTimeout != 3*time.Second {
+		t.Errorf("Expected query timeout to be '3s', got '%s'", cfg.dbConn.queryTimeout)
+	}
+
+	t.Setenv("DB_QUERY_TIMEOUT", "-1s")
+
+	err = loadConfigFromEnv(&cfg)
+	if err == nil || !strings.Contains(err.Error(), "DB_QUERY_TIMEOUT") {
+		t.Errorf("Expected DB_QUERY_TIMEOUT error, got %v", err)
+	}
+}
+
+// reverseGeocoder resolves every location to the same street in Košice and finds no addresses
+type reverseGeocoder struct{}
+
+func (reverseGeocoder) Name() string { return "reverse" }
+
+func (reverseGeocoder) Search(ctx context.Context, query string) ([]*types.Place, error) {
+	return nil, geocoding.ErrNotFound
+}
+
+func (reverseGeocoder) Reverse(ctx context.Context, lat, lon float64) (*types.Place, error) {
+	return &types.Place{Lat: lat, Lon: lon, Address: &types.PlaceAddress{Street: "Hlavná", HouseNumber: "1", PostalCode: "04001", Municipality: "Košice"}}, nil
+}
+
+func TestRefreshSteps_Memory(t *testing.T) {
+	os.Setenv("SCRAPER_SPECIALISTS_URL", "http://example.com")
+	defer os.Unsetenv("SCRAPER_SPECIALISTS_URL")
+
+	logger := zap.NewNop()
+	storage := models.NewMemoryModels(models.NewMemoryModel())
+
+	resp := `{"features":[
+		{"properties":{"id":1, "nazov_zariadenia": "Kardio Košice", "druh_zariadenia": "ambulancia kardiológie", "poloha_lat": 48.7172, "poloha_lon": 21.2497}},
+		{"properties":{"id":2, "nazov_zariadenia": "Očná Košice", "druh_zariadenia": "ambulancia oftalmológie", "poloha_lat": 48.72, "poloha_lon": 21.26,
+			"streetname": "Hlavná", "buildingnumber": "5", "postalcode": "04001", "municipality": "Košice"}}
+	]}`
+
+	scraper := scrapers.NewScraper(logger, storage)
+	scraper.Get = func(url string) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusOK, Body: io.NopCloser(strings.NewReader(resp))}, nil
+	}
+	scraper.Geocoder = reverseGeocoder{}
+
+	handler := handlers.NewHandler(logger, storage)
+	gazetteer := geocoding.NewGazetteer()
+
+	// twice, as every refresh after the first one runs on the data of the previous one
+	for i := 0; i < 2; i++ {
+		for _, step := range refreshSteps(handler, scraper, gazetteer) {
+			assert.NoError(t, step(context.Background()))
+		}
+	}
+
+	specialties, err := storage.Specialties.GetAllSpecialtiesLocalized(context.Background(), "en")
+	assert.NoError(t, err)
+	// the specialists are scraped concurrently, so the ids of their specialties vary between runs
+	categories := map[string]string{}
+	for _, specialty := range specialties {
+		categories[specialty.Name] = specialty.Category.Code
+	}
+	assert.Equal(t, map[string]string{"Cardiology": "internal-medicine", "Ophthalmology": "sensory-organs"}, categories)
+
+	mappings, err := storage.Symptoms.GetAllSymptomMappings(context.Background())
+	assert.NoError(t, err)
+	assert.NotEmpty(t, mappings)
+
+	checks, err := storage.Addresses.GetAddressChecks(context.Background(), "")
+	assert.NoError(t, err)
+	statuses := map[int]string{}
+	for _, check := range checks {
+		statuses[check.SpecialistID] = check.Status
+	}
+	assert.Equal(t, map[int]string{1: types.AddressCheckFilled, 2: types.AddressCheckVerified}, statuses)
+
+	places, err := gazetteer.Search(context.Background(), "Hlavná 5 Košice")
+	assert.NoError(t, err)
+	assert.NotEmpty(t, places)
 }
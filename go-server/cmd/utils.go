@@ -1,6 +1,17 @@
 package main
 
-import "errors"
+import (
+	"errors"
+	"fmt"
+	"io"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/acornak/healthcare-poc/migrations"
+	"github.com/acornak/healthcare-poc/seeds"
+	"github.com/acornak/healthcare-poc/types"
+)
 
 func validateConfig(cfg *config) error {
 	if cfg.port == "" {
@@ -33,3 +44,128 @@ func validateConfig(cfg *config) error {
 
 	return nil
 }
+
+// splitList splits a comma separated environment variable, empty items are dropped
+func splitList(value string) []string {
+	items := []string{}
+	for _, item := range strings.Split(value, ",") {
+		if item = strings.TrimSpace(item); item != "" {
+			items = append(items, item)
+		}
+	}
+
+	return items
+}
+
+// withoutProvider returns the providers except the excluded one, case insensitively
+func withoutProvider(providers []string, excluded string) []string {
+	items := []string{}
+	for _, provider := range providers {
+		if !strings.EqualFold(strings.TrimSpace(provider), excluded) {
+			items = append(items, provider)
+		}
+	}
+
+	return items
+}
+
+// parseDuration parses a duration environment variable, an empty value returns the fallback
+func parseDuration(value string, fallback time.Duration) (time.Duration, error) {
+	value = strings.TrimSpace(value)
+	if value == "" {
+		return fallback, nil
+	}
+
+	duration, err := time.ParseDuration(value)
+	if err != nil {
+		return 0, err
+	}
+
+	if duration < 0 {
+		return 0, errors.New("duration must not be negative")
+	}
+
+	return duration, nil
+}
+
+// municipalityPlaces returns the embedded Slovak municipalities as gazetteer places
+func municipalityPlaces() ([]*types.Place, error) {
+	seed, err := seeds.Municipalities()
+	if err != nil {
+		return nil, err
+	}
+
+	places := make([]*types.Place, 0, len(seed.Municipalities))
+	for _, municipality := range seed.Municipalities {
+		places = append(places, &types.Place{
+			Lat:         municipality.Lat,
+			Lon:         municipality.Lon,
+			DisplayName: municipality.Name + ", Slovensko",
+			Type:        types.PlaceTypeCity,
+		})
+	}
+
+	return places, nil
+}
+
+// schemaMigrator is the part of migrations.Migrator used by the migrate subcommand
+type schemaMigrator interface {
+	Up(target int64) (int, error)
+	Down(steps int) (int, error)
+	Status() ([]migrations.Status, error)
+}
+
+const migrateUsage = "usage: migrate up [version] | down [steps] | status"
+
+// runMigrate runs the migrate subcommand: up [version], down [steps] or status
+func runMigrate(m schemaMigrator, args []string, out io.Writer) error {
+	if len(args) == 0 || len(args) > 2 {
+		return errors.New(migrateUsage)
+	}
+
+	var argument int64
+	if len(args) == 2 {
+		value, err := strconv.ParseInt(args[1], 10, 64)
+		if err != nil || value < 1 {
+			return fmt.Errorf("invalid argument %q: must be a positive number", args[1])
+		}
+		argument = value
+	}
+
+	switch args[0] {
+	case "up":
+		applied, err := m.Up(argument)
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "applied %d migration(s)\n", applied)
+	case "down":
+		if argument == 0 {
+			argument = 1
+		}
+		reverted, err := m.Down(int(argument))
+		if err != nil {
+			return err
+		}
+		fmt.Fprintf(out, "reverted %d migration(s)\n", reverted)
+	case "status":
+		if len(args) > 1 {
+			return errors.New(migrateUsage)
+		}
+		statuses, err := m.Status()
+		if err != nil {
+			return err
+		}
+		for _, status := range statuses {
+			state := "pending"
+			if status.Applied {
+				state = "applied"
+			}
+			fmt.Fprintf(out, "%04d_%s\t%s\n", status.Migration.Version, status.Migration.Name, state)
+		}
+	default:
+		return errors.New(migrateUsage)
+	}
+
+	return nil
+}
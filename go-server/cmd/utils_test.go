@@ -1,7 +1,13 @@
 package main
 
 import (
+	"bytes"
+	"errors"
 	"testing"
+	"time"
+
+	"github.com/acornak/healthcare-poc/migrations"
+	"github.com/stretchr/testify/assert"
 )
 
 func TestValidateConfig(t *testing.T) {
@@ -137,3 +143,119 @@ func TestValidateConfig(t *testing.T) {
 		})
 	}
 }
+
+func TestSplitList(t *testing.T) {
+	assert.Equal(t, []string{}, splitList(""))
+	assert.Equal(t, []string{"nominatim", "gazetteer"}, splitList(" nominatim, ,gazetteer "))
+}
+
+func TestWithoutProvider(t *testing.T) {
+	assert.Equal(t, []string{}, withoutProvider(nil, "gazetteer"))
+	assert.Equal(t, []string{"nominatim"}, withoutProvider([]string{"nominatim", "Gazetteer"}, "gazetteer"))
+	assert.Equal(t, []string{}, withoutProvider([]string{"gazetteer"}, "gazetteer"))
+}
+
+func TestParseDuration(t *testing.T) {
+	duration, err := parseDuration("", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Hour, duration)
+
+	duration, err = parseDuration(" 48h ", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, 48*time.Hour, duration)
+
+	duration, err = parseDuration("0", time.Hour)
+	assert.NoError(t, err)
+	assert.Equal(t, time.Duration(0), duration)
+
+	_, err = parseDuration("-1h", time.Hour)
+	assert.Error(t, err)
+
+	_, err = parseDuration("a month", time.Hour)
+	assert.Error(t, err)
+}
+
+func TestMunicipalityPlaces(t *testing.T) {
+	places, err := municipalityPlaces()
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, places)
+	for _, place := range places {
+		assert.Contains(t, place.DisplayName, ", Slovensko")
+	}
+}
+
+type stubMigrator struct {
+	target int64
+	steps  int
+	err    error
+}
+
+func (m *stubMigrator) Up(target int64) (int, error) {
+	m.target = target
+	return 2, m.err
+}
+
+func (m *stubMigrator) Down(steps int) (int, error) {
+	m.steps = steps
+	return steps, m.err
+}
+
+func (m *stubMigrator) Status() ([]migrations.Status, error) {
+	return []migrations.Status{
+		{Migration: migrations.Migration{Version: 1, Name: "initial"}, Applied: true},
+		{Migration: migrations.Migration{Version: 2, Name: "geocode_cache"}},
+	}, m.err
+}
+
+func TestRunMigrate(t *testing.T) {
+	tests := []struct {
+		args   []string
+		output string
+		target int64
+		steps  int
+	}{
+		{args: []string{"up"}, output: "applied 2 migration(s)\n"},
+		{args: []string{"up", "3"}, output: "applied 2 migration(s)\n", target: 3},
+		{args: []string{"down"}, output: "reverted 1 migration(s)\n", steps: 1},
+		{args: []string{"down", "2"}, output: "reverted 2 migration(s)\n", steps: 2},
+		{args: []string{"status"}, output: "0001_initial\tapplied\n0002_geocode_cache\tpending\n"},
+	}
+
+	for _, test := range tests {
+		m := &stubMigrator{}
+		var out bytes.Buffer
+
+		err := runMigrate(m, test.args, &out)
+
+		assert.NoError(t, err, test.args)
+		assert.Equal(t, test.output, out.String(), test.args)
+		assert.Equal(t, test.target, m.target, test.args)
+		assert.Equal(t, test.steps, m.steps, test.args)
+	}
+}
+
+func TestRunMigrate_Errors(t *testing.T) {
+	tests := []struct {
+		args     []string
+		err      error
+		expected string
+	}{
+		{args: nil, expected: migrateUsage},
+		{args: []string{"sideways"}, expected: migrateUsage},
+		{args: []string{"status", "1"}, expected: migrateUsage},
+		{args: []string{"up", "1", "2"}, expected: migrateUsage},
+		{args: []string{"down", "zero"}, expected: `invalid argument "zero": must be a positive number`},
+		{args: []string{"down", "0"}, expected: `invalid argument "0": must be a positive number`},
+		{args: []string{"up"}, err: errors.New("mocked error"), expected: "mocked error"},
+		{args: []string{"status"}, err: errors.New("mocked error"), expected: "mocked error"},
+	}
+
+	for _, test := range tests {
+		var out bytes.Buffer
+
+		err := runMigrate(&stubMigrator{err: test.err}, test.args, &out)
+
+		assert.EqualError(t, err, test.expected, test.args)
+	}
+}
@@ -0,0 +1,136 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"time"
+)
+
+/*
+BatchOptions represents the settings of RunBatch
+The struct contains the following fields:
+- Concurrency: the number of items processed at the same time, 4 when 0
+- MaxRetries: how many times a rate limited item is retried, 3 when 0
+- Backoff: the pause after a rate limit when the provider does not send Retry-After, doubled on every retry of the item, 1 s when 0
+- MaxWait: rate limited items are not retried when the provider asks to wait longer than this, 10 s when 0
+*/
+type BatchOptions struct {
+	Concurrency int
+	MaxRetries  int
+	Backoff     time.Duration
+	MaxWait     time.Duration
+}
+
+/*
+RunBatch calls run for every index from 0 to count with bounded concurrency
+When run returns a RateLimitError all workers pause for the time requested by the provider and the item is retried,
+so a batch slows down to the pace of the provider instead of failing every remaining item
+Items not started before the context is done fail with the context error
+The function returns the error of every item, nil for the successful ones
+*/
+func RunBatch(ctx context.Context, count int, opts BatchOptions, run func(i int) error) []error {
+	if opts.Concurrency <= 0 {
+		opts.Concurrency = 4
+	}
+	if opts.MaxRetries <= 0 {
+		opts.MaxRetries = 3
+	}
+	if opts.Backoff <= 0 {
+		opts.Backoff = time.Second
+	}
+	if opts.MaxWait <= 0 {
+		opts.MaxWait = 10 * time.Second
+	}
+
+	errs := make([]error, count)
+	indexes := make(chan int)
+	gate := &batchGate{}
+
+	var wg sync.WaitGroup
+	for w := 0; w < opts.Concurrency && w < count; w++ {
+		wg.Add(1)
+		go func() {
+			defer wg.Done()
+			for i := range indexes {
+				errs[i] = runBatchItem(ctx, gate, opts, func() error { return run(i) })
+			}
+		}()
+	}
+
+	for i := 0; i < count; i++ {
+		select {
+		case indexes <- i:
+		case <-ctx.Done():
+			errs[i] = ctx.Err()
+		}
+	}
+	close(indexes)
+	wg.Wait()
+
+	return errs
+}
+
+func runBatchItem(ctx context.Context, gate *batchGate, opts BatchOptions, run func() error) error {
+	backoff := opts.Backoff
+
+	for attempt := 0; ; attempt++ {
+		if err := gate.wait(ctx); err != nil {
+			return err
+		}
+
+		err := run()
+
+		var rateLimit *RateLimitError
+		if !errors.As(err, &rateLimit) || attempt >= opts.MaxRetries {
+			return err
+		}
+
+		wait := rateLimit.RetryAfter
+		if wait == 0 {
+			wait = backoff
+			backoff *= 2
+		}
+		if wait > opts.MaxWait {
+			return err
+		}
+
+		gate.pause(wait)
+	}
+}
+
+// batchGate holds all workers of a batch back while the provider is rate limiting
+type batchGate struct {
+	mu    sync.Mutex
+	until time.Time
+}
+
+func (g *batchGate) pause(wait time.Duration) {
+	g.mu.Lock()
+	defer g.mu.Unlock()
+
+	if until := time.Now().Add(wait); until.After(g.until) {
+		g.until = until
+	}
+}
+
+// wait returns once no pause is pending, a pause may be extended by another worker while waiting
+func (g *batchGate) wait(ctx context.Context) error {
+	for {
+		g.mu.Lock()
+		wait := time.Until(g.until)
+		g.mu.Unlock()
+
+		if wait <= 0 {
+			return ctx.Err()
+		}
+
+		timer := time.NewTimer(wait)
+		select {
+		case <-timer.C:
+		case <-ctx.Done():
+			timer.Stop()
+			return ctx.Err()
+		}
+	}
+}
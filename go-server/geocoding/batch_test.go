@@ -0,0 +1,96 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"sync"
+	"sync/atomic"
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestRunBatch(t *testing.T) {
+	var running, maxRunning atomic.Int32
+
+	errs := RunBatch(context.Background(), 20, BatchOptions{Concurrency: 3}, func(i int) error {
+		current := running.Add(1)
+		defer running.Add(-1)
+		for {
+			seen := maxRunning.Load()
+			if current <= seen || maxRunning.CompareAndSwap(seen, current) {
+				break
+			}
+		}
+		time.Sleep(time.Millisecond)
+
+		if i%5 == 0 {
+			return ErrNotFound
+		}
+		return nil
+	})
+
+	assert.Len(t, errs, 20)
+	assert.LessOrEqual(t, maxRunning.Load(), int32(3))
+	for i, err := range errs {
+		if i%5 == 0 {
+			assert.ErrorIs(t, err, ErrNotFound)
+		} else {
+			assert.NoError(t, err)
+		}
+	}
+}
+
+func TestRunBatch_RetriesRateLimitedItems(t *testing.T) {
+	var mu sync.Mutex
+	attempts := map[int]int{}
+
+	start := time.Now()
+	errs := RunBatch(context.Background(), 4, BatchOptions{Concurrency: 2, Backoff: 10 * time.Millisecond}, func(i int) error {
+		mu.Lock()
+		defer mu.Unlock()
+		attempts[i]++
+		if i == 1 && attempts[i] < 3 {
+			return &RateLimitError{Provider: "mapsco"}
+		}
+		return nil
+	})
+
+	assert.Equal(t, []error{nil, nil, nil, nil}, errs)
+	assert.Equal(t, 3, attempts[1])
+	// 10 ms and 20 ms of backoff
+	assert.GreaterOrEqual(t, time.Since(start), 30*time.Millisecond)
+}
+
+func TestRunBatch_GivesUp(t *testing.T) {
+	limited := &RateLimitError{Provider: "mapsco", RetryAfter: time.Millisecond}
+	calls := atomic.Int32{}
+
+	errs := RunBatch(context.Background(), 1, BatchOptions{MaxRetries: 2}, func(i int) error {
+		calls.Add(1)
+		return limited
+	})
+	assert.Equal(t, []error{limited}, errs)
+	assert.Equal(t, int32(3), calls.Load())
+
+	// the provider asks to wait longer than allowed, the item fails without waiting
+	tooLong := &RateLimitError{Provider: "mapsco", RetryAfter: time.Hour}
+	errs = RunBatch(context.Background(), 1, BatchOptions{MaxWait: time.Second}, func(i int) error {
+		return tooLong
+	})
+	assert.Equal(t, []error{tooLong}, errs)
+}
+
+func TestRunBatch_Cancelled(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	errs := RunBatch(ctx, 3, BatchOptions{Concurrency: 1}, func(i int) error {
+		return errors.New("should not run")
+	})
+
+	for _, err := range errs {
+		assert.ErrorIs(t, err, context.Canceled)
+	}
+}
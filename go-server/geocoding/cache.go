@@ -0,0 +1,187 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"sync/atomic"
+	"time"
+
+	"github.com/acornak/healthcare-poc/textutil"
+	"github.com/acornak/healthcare-poc/types"
+	"go.uber.org/zap"
+)
+
+// reverse lookups are cached on coordinates rounded to 4 decimals, about 11 m
+const cacheCoordinatePrecision = 4
+
+/*
+CacheStore persists geocoding results
+GetGeocodeCache returns false when the key is missing or expired, an empty slice is a cached "not found"
+*/
+type CacheStore interface {
+	GetGeocodeCache(ctx context.Context, key string) ([]*types.Place, bool, error)
+	SetGeocodeCache(ctx context.Context, key string, places []*types.Place, expiresAt time.Time) error
+	PurgeGeocodeCache(ctx context.Context, expiredOnly bool) (int, error)
+}
+
+/*
+CacheStats represents the cache counters since the start of the server
+The struct contains the following fields:
+- Hits: lookups answered from the cache
+- Misses: lookups passed to the geocoder
+- Errors: failed reads and writes of the cache store, the lookup itself still succeeds
+- HitRatio: hits divided by all lookups, 0 before the first lookup
+*/
+type CacheStats struct {
+	Hits     int64   `json:"hits"`
+	Misses   int64   `json:"misses"`
+	Errors   int64   `json:"errors"`
+	HitRatio float64 `json:"hit_ratio"`
+}
+
+/*
+Cache is a geocoder remembering the results of another geocoder
+Search results are keyed on the normalized query text, reverse results on rounded coordinates
+"Not found" results are cached for NegativeTTL, so unknown places do not exhaust the provider quota either
+Failures of the cache store are logged and never fail the lookup
+The store is queried with the context of the lookup
+*/
+type Cache struct {
+	Next        Geocoder
+	Store       CacheStore
+	TTL         time.Duration
+	NegativeTTL time.Duration
+	Logger      *zap.Logger
+	Now         func() time.Time
+
+	hits   atomic.Int64
+	misses atomic.Int64
+	errors atomic.Int64
+}
+
+/*
+NewCache returns a cache in front of the geocoder
+The negative TTL is a tenth of the TTL
+*/
+func NewCache(next Geocoder, store CacheStore, ttl time.Duration, logger *zap.Logger) *Cache {
+	return &Cache{
+		Next:        next,
+		Store:       store,
+		TTL:         ttl,
+		NegativeTTL: ttl / 10,
+		Logger:      logger,
+		Now:         time.Now,
+	}
+}
+
+func (c *Cache) Name() string {
+	return c.Next.Name()
+}
+
+/*
+Search returns the cached places for the query, or the places found by the next geocoder
+*/
+func (c *Cache) Search(ctx context.Context, query string) ([]*types.Place, error) {
+	key := "search:" + textutil.Normalize(query)
+
+	places, err := c.lookup(ctx, key, func() ([]*types.Place, error) {
+		return c.Next.Search(ctx, query)
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return places, nil
+}
+
+/*
+Reverse returns the cached place at the coordinates, or the place found by the next geocoder
+*/
+func (c *Cache) Reverse(ctx context.Context, lat, lon float64) (*types.Place, error) {
+	key := fmt.Sprintf("reverse:%.*f,%.*f", cacheCoordinatePrecision, lat, cacheCoordinatePrecision, lon)
+
+	places, err := c.lookup(ctx, key, func() ([]*types.Place, error) {
+		place, err := c.Next.Reverse(ctx, lat, lon)
+		if err != nil {
+			return nil, err
+		}
+		return []*types.Place{place}, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	return places[0], nil
+}
+
+/*
+Stats returns the cache counters
+*/
+func (c *Cache) Stats() CacheStats {
+	stats := CacheStats{
+		Hits:   c.hits.Load(),
+		Misses: c.misses.Load(),
+		Errors: c.errors.Load(),
+	}
+
+	if total := stats.Hits + stats.Misses; total > 0 {
+		stats.HitRatio = float64(stats.Hits) / float64(total)
+	}
+
+	return stats
+}
+
+/*
+Purge deletes the cached results, only the expired ones when expiredOnly is set
+The function returns the number of deleted entries
+*/
+func (c *Cache) Purge(ctx context.Context, expiredOnly bool) (int, error) {
+	return c.Store.PurgeGeocodeCache(ctx, expiredOnly)
+}
+
+func (c *Cache) lookup(ctx context.Context, key string, fetch func() ([]*types.Place, error)) ([]*types.Place, error) {
+	places, found, err := c.Store.GetGeocodeCache(ctx, key)
+	if err != nil {
+		c.storeFailed("read", key, err)
+	}
+
+	if found {
+		c.hits.Add(1)
+		if len(places) == 0 {
+			return nil, ErrNotFound
+		}
+		return places, nil
+	}
+
+	c.misses.Add(1)
+
+	places, err = fetch()
+	ttl := c.TTL
+	if errors.Is(err, ErrNotFound) {
+		places = []*types.Place{}
+		ttl = c.NegativeTTL
+	} else if err != nil {
+		// provider failures are not cached
+		return nil, err
+	}
+
+	if ttl > 0 {
+		if err := c.Store.SetGeocodeCache(ctx, key, places, c.Now().Add(ttl)); err != nil {
+			c.storeFailed("write", key, err)
+		}
+	}
+
+	if len(places) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return places, nil
+}
+
+func (c *Cache) storeFailed(operation, key string, err error) {
+	c.errors.Add(1)
+	if c.Logger != nil {
+		c.Logger.Warn("geocode cache "+operation+" failed", zap.String("key", key), zap.Error(err))
+	}
+}
@@ -0,0 +1,153 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type memoryStore struct {
+	entries   map[string][]*types.Place
+	expiresAt map[string]time.Time
+	err       error
+	ctxs      []context.Context
+}
+
+func newMemoryStore() *memoryStore {
+	return &memoryStore{entries: map[string][]*types.Place{}, expiresAt: map[string]time.Time{}}
+}
+
+func (m *memoryStore) GetGeocodeCache(ctx context.Context, key string) ([]*types.Place, bool, error) {
+	m.ctxs = append(m.ctxs, ctx)
+	if m.err != nil {
+		return nil, false, m.err
+	}
+	places, ok := m.entries[key]
+	return places, ok, nil
+}
+
+func (m *memoryStore) SetGeocodeCache(ctx context.Context, key string, places []*types.Place, expiresAt time.Time) error {
+	m.ctxs = append(m.ctxs, ctx)
+	if m.err != nil {
+		return m.err
+	}
+	m.entries[key] = places
+	m.expiresAt[key] = expiresAt
+	return nil
+}
+
+func (m *memoryStore) PurgeGeocodeCache(ctx context.Context, expiredOnly bool) (int, error) {
+	deleted := len(m.entries)
+	m.entries = map[string][]*types.Place{}
+	return deleted, nil
+}
+
+func TestCache_Search(t *testing.T) {
+	next := &stubGeocoder{name: "stub", places: []*types.Place{{Lat: 48.1, Lon: 17.1, DisplayName: "Bratislava"}}}
+	store := newMemoryStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewCache(next, store, time.Hour, zap.NewNop())
+	cache.Now = func() time.Time { return now }
+
+	places, err := cache.Search(context.Background(), "Bratislava")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bratislava", places[0].DisplayName)
+
+	places, err = cache.Search(context.Background(), "  bratislavá ")
+	assert.NoError(t, err)
+	assert.Equal(t, "Bratislava", places[0].DisplayName)
+
+	assert.Equal(t, 1, next.calls)
+	assert.Equal(t, now.Add(time.Hour), store.expiresAt["search:bratislava"])
+	assert.Equal(t, CacheStats{Hits: 1, Misses: 1, HitRatio: 0.5}, cache.Stats())
+	assert.Equal(t, "stub", cache.Name())
+}
+
+func TestCache_Reverse(t *testing.T) {
+	next := &stubGeocoder{name: "stub", places: []*types.Place{{Lat: 48.1, Lon: 17.1, DisplayName: "Hlavná 1"}}}
+	store := newMemoryStore()
+	cache := NewCache(next, store, time.Hour, zap.NewNop())
+
+	place, err := cache.Reverse(context.Background(), 48.123451, 17.123449)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hlavná 1", place.DisplayName)
+
+	place, err = cache.Reverse(context.Background(), 48.12346, 17.12344)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hlavná 1", place.DisplayName)
+
+	assert.Equal(t, 1, next.calls)
+	assert.Contains(t, store.entries, "reverse:48.1235,17.1234")
+}
+
+func TestCache_NotFoundIsCached(t *testing.T) {
+	next := &stubGeocoder{name: "stub", err: ErrNotFound}
+	store := newMemoryStore()
+	now := time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+	cache := NewCache(next, store, 10*time.Hour, zap.NewNop())
+	cache.Now = func() time.Time { return now }
+
+	_, err := cache.Search(context.Background(), "nowhere")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = cache.Search(context.Background(), "nowhere")
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	assert.Equal(t, 1, next.calls)
+	assert.Equal(t, now.Add(time.Hour), store.expiresAt["search:nowhere"])
+}
+
+func TestCache_ProviderErrorIsNotCached(t *testing.T) {
+	next := &stubGeocoder{name: "stub", err: errors.New("quota exceeded")}
+	store := newMemoryStore()
+	cache := NewCache(next, store, time.Hour, zap.NewNop())
+
+	_, err := cache.Search(context.Background(), "Bratislava")
+	assert.EqualError(t, err, "quota exceeded")
+	assert.Empty(t, store.entries)
+	assert.Equal(t, int64(1), cache.Stats().Misses)
+}
+
+func TestCache_StoreFailure(t *testing.T) {
+	next := &stubGeocoder{name: "stub", places: []*types.Place{{DisplayName: "Bratislava"}}}
+	store := newMemoryStore()
+	store.err = errors.New("connection refused")
+	cache := NewCache(next, store, time.Hour, zap.NewNop())
+
+	places, err := cache.Search(context.Background(), "Bratislava")
+	assert.NoError(t, err)
+	assert.Len(t, places, 1)
+	assert.Equal(t, CacheStats{Misses: 1, Errors: 2}, cache.Stats())
+}
+
+func TestCache_LookupContext(t *testing.T) {
+	type ctxKey struct{}
+	ctx := context.WithValue(context.Background(), ctxKey{}, "request")
+	next := &stubGeocoder{name: "stub", places: []*types.Place{{DisplayName: "Bratislava"}}}
+	store := newMemoryStore()
+	cache := NewCache(next, store, time.Hour, zap.NewNop())
+
+	_, err := cache.Search(ctx, "Bratislava")
+	assert.NoError(t, err)
+
+	assert.Len(t, store.ctxs, 2)
+	for _, used := range store.ctxs {
+		assert.Equal(t, "request", used.Value(ctxKey{}))
+	}
+}
+
+func TestCache_Purge(t *testing.T) {
+	store := newMemoryStore()
+	store.entries["search:a"] = []*types.Place{}
+	store.entries["search:b"] = []*types.Place{}
+	cache := NewCache(&stubGeocoder{}, store, time.Hour, nil)
+
+	deleted, err := cache.Purge(context.Background(), false)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, deleted)
+}
@@ -0,0 +1,188 @@
+package geocoding
+
+import (
+	"context"
+	"math"
+	"sort"
+	"strings"
+	"sync"
+
+	"github.com/acornak/healthcare-poc/textutil"
+	"github.com/acornak/healthcare-poc/types"
+)
+
+const (
+	// reverse lookups snap to a known address only when it is this close, in meters
+	gazetteerAddressRadius = 150
+	// otherwise the nearest municipality within this distance is returned, in meters
+	gazetteerMunicipalityRadius = 15000
+)
+
+// words ignored in search queries, the gazetteer only knows Slovak places
+var gazetteerStopWords = map[string]bool{
+	"slovakia":  true,
+	"slovensko": true,
+	"slovenska": true,
+	"republika": true,
+	"sr":        true,
+	"sk":        true,
+}
+
+type gazetteerEntry struct {
+	place *types.Place
+	words []string
+}
+
+/*
+Gazetteer is an offline geocoder built from known specialist addresses and Slovak municipalities
+It needs neither network access nor an API key
+Search matches every query word as a word prefix without diacritics, the most specific entries first
+Reverse returns the nearest known address, or the nearest municipality when no address is close
+*/
+type Gazetteer struct {
+	mu             sync.RWMutex
+	addresses      []gazetteerEntry
+	municipalities []gazetteerEntry
+}
+
+/*
+NewGazetteer returns an empty gazetteer, fill it with SetMunicipalities and SetAddresses
+*/
+func NewGazetteer() *Gazetteer {
+	return &Gazetteer{}
+}
+
+func (g *Gazetteer) Name() string {
+	return ProviderGazetteer
+}
+
+/*
+SetMunicipalities replaces the municipalities known to the gazetteer
+*/
+func (g *Gazetteer) SetMunicipalities(places []*types.Place) {
+	entries := newGazetteerEntries(places)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.municipalities = entries
+}
+
+/*
+SetAddresses replaces the addresses known to the gazetteer
+*/
+func (g *Gazetteer) SetAddresses(places []*types.Place) {
+	entries := newGazetteerEntries(places)
+
+	g.mu.Lock()
+	defer g.mu.Unlock()
+	g.addresses = entries
+}
+
+/*
+Search returns the places matching all words of the query
+Municipalities come before addresses, shorter names before longer ones
+The function returns ErrNotFound if nothing matches
+*/
+func (g *Gazetteer) Search(_ context.Context, query string) ([]*types.Place, error) {
+	terms := []string{}
+	for _, term := range textutil.Terms(query) {
+		if !gazetteerStopWords[term] {
+			terms = append(terms, term)
+		}
+	}
+
+	if len(terms) == 0 {
+		return nil, ErrNotFound
+	}
+
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	places := []*types.Place{}
+	for _, entries := range [][]gazetteerEntry{g.municipalities, g.addresses} {
+		matches := []gazetteerEntry{}
+		for _, entry := range entries {
+			if entry.matches(terms) {
+				matches = append(matches, entry)
+			}
+		}
+
+		sort.SliceStable(matches, func(i, j int) bool {
+			return len(matches[i].words) < len(matches[j].words)
+		})
+
+		for _, match := range matches {
+			places = append(places, match.place)
+		}
+	}
+
+	if len(places) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return places, nil
+}
+
+/*
+Reverse returns the nearest known address within 150 m, or the nearest municipality within 15 km
+The function returns ErrNotFound if nothing is close enough
+*/
+func (g *Gazetteer) Reverse(_ context.Context, lat, lon float64) (*types.Place, error) {
+	g.mu.RLock()
+	defer g.mu.RUnlock()
+
+	if place := nearest(g.addresses, lat, lon, gazetteerAddressRadius); place != nil {
+		return place, nil
+	}
+
+	if place := nearest(g.municipalities, lat, lon, gazetteerMunicipalityRadius); place != nil {
+		return place, nil
+	}
+
+	return nil, ErrNotFound
+}
+
+func newGazetteerEntries(places []*types.Place) []gazetteerEntry {
+	entries := make([]gazetteerEntry, 0, len(places))
+	for _, place := range places {
+		if place == nil || strings.TrimSpace(place.DisplayName) == "" {
+			continue
+		}
+		entries = append(entries, gazetteerEntry{place: place, words: textutil.Words(place.DisplayName)})
+	}
+
+	return entries
+}
+
+func (e gazetteerEntry) matches(terms []string) bool {
+	for _, term := range terms {
+		found := false
+		for _, word := range e.words {
+			if strings.HasPrefix(word, term) {
+				found = true
+				break
+			}
+		}
+
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+func nearest(entries []gazetteerEntry, lat, lon, radius float64) *types.Place {
+	var best *types.Place
+	bestDistance := math.Inf(1)
+
+	for _, entry := range entries {
+		distance := Distance(lat, lon, entry.place.Lat, entry.place.Lon)
+		if distance <= radius && distance < bestDistance {
+			best = entry.place
+			bestDistance = distance
+		}
+	}
+
+	return best
+}
@@ -0,0 +1,71 @@
+package geocoding
+
+import (
+	"context"
+	"testing"
+
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func setupGazetteer() *Gazetteer {
+	gazetteer := NewGazetteer()
+	gazetteer.SetMunicipalities([]*types.Place{
+		{Lat: 48.7164, Lon: 21.2611, DisplayName: "Košice, Slovensko"},
+		{Lat: 48.7543, Lon: 21.9195, DisplayName: "Michalovce, Slovensko"},
+	})
+	gazetteer.SetAddresses([]*types.Place{
+		{Lat: 48.7560, Lon: 21.9150, DisplayName: "Námestie osloboditeľov 25, 07101 Michalovce, Slovenská republika"},
+		{Lat: 48.7200, Lon: 21.2580, DisplayName: "Hlavná 1, 04001 Košice, Slovenská republika"},
+		{DisplayName: " "},
+	})
+
+	return gazetteer
+}
+
+func TestGazetteer_Search(t *testing.T) {
+	gazetteer := setupGazetteer()
+
+	places, err := gazetteer.Search(context.Background(), "michalovce, Slovakia")
+	assert.NoError(t, err)
+	assert.Len(t, places, 2)
+	assert.Equal(t, "Michalovce, Slovensko", places[0].DisplayName)
+
+	places, err = gazetteer.Search(context.Background(), "namestie oslobod michalovce")
+	assert.NoError(t, err)
+	assert.Len(t, places, 1)
+	assert.Equal(t, 48.7560, places[0].Lat)
+
+	places, err = gazetteer.Search(context.Background(), "Hlavná, Košice")
+	assert.NoError(t, err)
+	assert.Equal(t, "Hlavná 1, 04001 Košice, Slovenská republika", places[0].DisplayName)
+}
+
+func TestGazetteer_SearchNotFound(t *testing.T) {
+	gazetteer := setupGazetteer()
+
+	for _, query := range []string{"", "Slovensko", "Praha", "Hlavná Michalovce"} {
+		places, err := gazetteer.Search(context.Background(), query)
+		assert.ErrorIs(t, err, ErrNotFound, query)
+		assert.Nil(t, places, query)
+	}
+}
+
+func TestGazetteer_Reverse(t *testing.T) {
+	gazetteer := setupGazetteer()
+
+	// next to a known address
+	place, err := gazetteer.Reverse(context.Background(), 48.7201, 21.2581)
+	assert.NoError(t, err)
+	assert.Equal(t, "Hlavná 1, 04001 Košice, Slovenská republika", place.DisplayName)
+
+	// outskirts of Michalovce, far from any address
+	place, err = gazetteer.Reverse(context.Background(), 48.77, 21.95)
+	assert.NoError(t, err)
+	assert.Equal(t, "Michalovce, Slovensko", place.DisplayName)
+
+	// Bratislava
+	place, err = gazetteer.Reverse(context.Background(), 48.1486, 17.1077)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Nil(t, place)
+}
@@ -0,0 +1,219 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"math"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/acornak/healthcare-poc/types"
+	"go.uber.org/zap"
+)
+
+// ErrNotFound is returned when a provider has no result for the query
+var ErrNotFound = errors.New("no geocode data found")
+
+/*
+RateLimitError is returned when a provider rejects a request because of its rate limit
+RetryAfter is the wait requested by the provider, 0 when it did not tell
+*/
+type RateLimitError struct {
+	Provider   string
+	RetryAfter time.Duration
+}
+
+func (e *RateLimitError) Error() string {
+	return fmt.Sprintf("%s returned status %d", e.Provider, http.StatusTooManyRequests)
+}
+
+/*
+Geocoder translates between free text locations and coordinates
+Search returns the places matching the query, best match first
+Reverse returns the place at the coordinates
+Both return ErrNotFound when the provider has no result
+The context bounds the provider requests and the cache queries
+*/
+type Geocoder interface {
+	Name() string
+	Search(ctx context.Context, query string) ([]*types.Place, error)
+	Reverse(ctx context.Context, lat, lon float64) (*types.Place, error)
+}
+
+// provider names accepted in the configuration
+const (
+	ProviderMapsCo    = "mapsco"
+	ProviderNominatim = "nominatim"
+	ProviderGazetteer = "gazetteer"
+)
+
+// DefaultProviders is the fallback order used when no providers are configured
+var DefaultProviders = []string{ProviderMapsCo, ProviderNominatim, ProviderGazetteer}
+
+/*
+Config represents the geocoding configuration
+The struct contains the following fields:
+- Providers: the providers in the order they are tried, DefaultProviders without the unconfigured ones when empty
+- MapsCoURL: the geocode.maps.co API URL
+- MapsCoAPIKey: the geocode.maps.co API key
+- NominatimURL: the URL of a Nominatim instance
+- Do: the function sending the HTTP requests
+- Gazetteer: the offline gazetteer
+- Logger: the logger used to report failing providers
+*/
+type Config struct {
+	Providers    []string
+	MapsCoURL    string
+	MapsCoAPIKey string
+	NominatimURL string
+	Do           func(req *http.Request) (resp *http.Response, err error)
+	Gazetteer    *Gazetteer
+	Logger       *zap.Logger
+}
+
+/*
+New returns a geocoder trying the configured providers in order
+Explicitly configured providers have to be fully configured, unconfigured default providers are skipped
+The function returns an error if a provider is unknown or misconfigured, or no provider is left
+*/
+func New(cfg Config) (Geocoder, error) {
+	providers := cfg.Providers
+	explicit := len(providers) > 0
+	if !explicit {
+		providers = DefaultProviders
+	}
+
+	geocoders := []Geocoder{}
+	for _, provider := range providers {
+		var geocoder Geocoder
+		var missing string
+
+		switch strings.ToLower(strings.TrimSpace(provider)) {
+		case ProviderMapsCo:
+			if cfg.MapsCoURL == "" || cfg.MapsCoAPIKey == "" {
+				missing = "GEOCODE_URL and GEOCODE_API_KEY"
+			} else {
+				geocoder = NewMapsCo(cfg.MapsCoURL, cfg.MapsCoAPIKey, cfg.Do)
+			}
+		case ProviderNominatim:
+			if cfg.NominatimURL == "" {
+				missing = "NOMINATIM_URL"
+			} else {
+				geocoder = NewNominatim(cfg.NominatimURL, cfg.Do)
+			}
+		case ProviderGazetteer:
+			if cfg.Gazetteer == nil {
+				missing = "gazetteer"
+			} else {
+				geocoder = cfg.Gazetteer
+			}
+		default:
+			return nil, fmt.Errorf("unknown geocoding provider %q", provider)
+		}
+
+		if missing != "" {
+			if explicit {
+				return nil, fmt.Errorf("geocoding provider %s requires %s", provider, missing)
+			}
+			continue
+		}
+
+		geocoders = append(geocoders, geocoder)
+	}
+
+	if len(geocoders) == 0 {
+		return nil, errors.New("no geocoding provider configured")
+	}
+
+	if len(geocoders) == 1 {
+		return geocoders[0], nil
+	}
+
+	return &Chain{Geocoders: geocoders, Logger: cfg.Logger}, nil
+}
+
+/*
+Chain is a geocoder falling back to the next provider when one fails or has no result
+*/
+type Chain struct {
+	Geocoders []Geocoder
+	Logger    *zap.Logger
+}
+
+func (c *Chain) Name() string {
+	names := make([]string, 0, len(c.Geocoders))
+	for _, geocoder := range c.Geocoders {
+		names = append(names, geocoder.Name())
+	}
+
+	return strings.Join(names, ",")
+}
+
+/*
+Search returns the result of the first provider with a result
+The function returns ErrNotFound if no provider has a result, or the last error if a provider failed
+*/
+func (c *Chain) Search(ctx context.Context, query string) ([]*types.Place, error) {
+	var lastErr error = ErrNotFound
+
+	for _, geocoder := range c.Geocoders {
+		places, err := geocoder.Search(ctx, query)
+		if err == nil {
+			return places, nil
+		}
+
+		if !errors.Is(err, ErrNotFound) {
+			c.logFailure(geocoder, err)
+			lastErr = err
+		}
+	}
+
+	return nil, lastErr
+}
+
+/*
+Reverse returns the result of the first provider with a result
+The function returns ErrNotFound if no provider has a result, or the last error if a provider failed
+*/
+func (c *Chain) Reverse(ctx context.Context, lat, lon float64) (*types.Place, error) {
+	var lastErr error = ErrNotFound
+
+	for _, geocoder := range c.Geocoders {
+		place, err := geocoder.Reverse(ctx, lat, lon)
+		if err == nil {
+			return place, nil
+		}
+
+		if !errors.Is(err, ErrNotFound) {
+			c.logFailure(geocoder, err)
+			lastErr = err
+		}
+	}
+
+	return nil, lastErr
+}
+
+func (c *Chain) logFailure(geocoder Geocoder, err error) {
+	if c.Logger != nil {
+		c.Logger.Warn("geocoding provider failed, trying the next one", zap.String("provider", geocoder.Name()), zap.Error(err))
+	}
+}
+
+/*
+Distance returns the great-circle distance between two points in meters
+*/
+func Distance(lat1, lon1, lat2, lon2 float64) float64 {
+	const earthRadius = 6371000
+
+	toRadians := func(deg float64) float64 { return deg * math.Pi / 180 }
+
+	dLat := toRadians(lat2 - lat1)
+	dLon := toRadians(lon2 - lon1)
+
+	a := math.Sin(dLat/2)*math.Sin(dLat/2) +
+		math.Cos(toRadians(lat1))*math.Cos(toRadians(lat2))*math.Sin(dLon/2)*math.Sin(dLon/2)
+
+	return 2 * earthRadius * math.Asin(math.Sqrt(a))
+}
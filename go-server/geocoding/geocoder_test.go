@@ -0,0 +1,109 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type stubGeocoder struct {
+	name   string
+	places []*types.Place
+	err    error
+	calls  int
+}
+
+func (s *stubGeocoder) Name() string {
+	return s.name
+}
+
+func (s *stubGeocoder) Search(ctx context.Context, query string) ([]*types.Place, error) {
+	s.calls++
+	return s.places, s.err
+}
+
+func (s *stubGeocoder) Reverse(ctx context.Context, lat, lon float64) (*types.Place, error) {
+	s.calls++
+	if s.err != nil {
+		return nil, s.err
+	}
+	return s.places[0], nil
+}
+
+func TestNew_Defaults(t *testing.T) {
+	geocoder, err := New(Config{Gazetteer: NewGazetteer()})
+	assert.NoError(t, err)
+	assert.Equal(t, ProviderGazetteer, geocoder.Name())
+
+	geocoder, err = New(Config{MapsCoURL: "http://geocode.url", MapsCoAPIKey: "some-key", Gazetteer: NewGazetteer()})
+	assert.NoError(t, err)
+	assert.Equal(t, "mapsco,gazetteer", geocoder.Name())
+}
+
+func TestNew_ExplicitOrder(t *testing.T) {
+	geocoder, err := New(Config{
+		Providers:    []string{"gazetteer", " Nominatim "},
+		NominatimURL: "http://nominatim.local",
+		Gazetteer:    NewGazetteer(),
+	})
+
+	assert.NoError(t, err)
+	assert.Equal(t, "gazetteer,nominatim", geocoder.Name())
+}
+
+func TestNew_Errors(t *testing.T) {
+	tests := []struct {
+		cfg      Config
+		expected string
+	}{
+		{Config{}, "no geocoding provider configured"},
+		{Config{Providers: []string{"google"}}, `unknown geocoding provider "google"`},
+		{Config{Providers: []string{"mapsco"}, MapsCoURL: "http://geocode.url"}, "geocoding provider mapsco requires GEOCODE_URL and GEOCODE_API_KEY"},
+		{Config{Providers: []string{"nominatim"}}, "geocoding provider nominatim requires NOMINATIM_URL"},
+		{Config{Providers: []string{"gazetteer"}}, "geocoding provider gazetteer requires gazetteer"},
+	}
+
+	for _, test := range tests {
+		geocoder, err := New(test.cfg)
+		assert.EqualError(t, err, test.expected)
+		assert.Nil(t, geocoder)
+	}
+}
+
+func TestChain_Search(t *testing.T) {
+	failing := &stubGeocoder{name: "failing", err: errors.New("quota exceeded")}
+	empty := &stubGeocoder{name: "empty", err: ErrNotFound}
+	working := &stubGeocoder{name: "working", places: []*types.Place{{Lat: 48.7, Lon: 21.2, DisplayName: "Košice"}}}
+	unused := &stubGeocoder{name: "unused"}
+
+	chain := &Chain{Geocoders: []Geocoder{failing, empty, working, unused}, Logger: zap.NewNop()}
+
+	places, err := chain.Search(context.Background(), "Košice")
+
+	assert.NoError(t, err)
+	assert.Equal(t, working.places, places)
+	assert.Equal(t, 0, unused.calls)
+}
+
+func TestChain_Errors(t *testing.T) {
+	failing := &stubGeocoder{name: "failing", err: errors.New("quota exceeded")}
+	empty := &stubGeocoder{name: "empty", err: ErrNotFound}
+
+	places, err := (&Chain{Geocoders: []Geocoder{empty, empty}}).Search(context.Background(), "Košice")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Nil(t, places)
+
+	place, err := (&Chain{Geocoders: []Geocoder{failing, empty}}).Reverse(context.Background(), 48.7, 21.2)
+	assert.EqualError(t, err, "quota exceeded")
+	assert.Nil(t, place)
+}
+
+func TestDistance(t *testing.T) {
+	// Košice to Michalovce
+	assert.InDelta(t, 48500, Distance(48.7164, 21.2611, 48.7543, 21.9195), 500)
+	assert.Equal(t, 0.0, Distance(48.7164, 21.2611, 48.7164, 21.2611))
+}
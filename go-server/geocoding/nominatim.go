@@ -0,0 +1,284 @@
+package geocoding
+
+import (
+	"context"
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/acornak/healthcare-poc/types"
+)
+
+/*
+Nominatim is a geocoder for the Nominatim API
+geocode.maps.co is a hosted Nominatim, so both providers share this implementation
+and differ only in name and the extra query parameters
+*/
+type Nominatim struct {
+	name    string
+	baseURL string
+	params  url.Values
+	do      func(req *http.Request) (resp *http.Response, err error)
+}
+
+// nominatimPlace is a search or reverse result, "class" is called "category" in the jsonv2 format
+type nominatimPlace struct {
+	Lat         string            `json:"lat"`
+	Lon         string            `json:"lon"`
+	DisplayName string            `json:"display_name"`
+	Class       string            `json:"class"`
+	Category    string            `json:"category"`
+	Type        string            `json:"type"`
+	AddressType string            `json:"addresstype"`
+	Importance  float64           `json:"importance"`
+	BoundingBox []string          `json:"boundingbox"`
+	Address     map[string]string `json:"address"`
+}
+
+// values of the Nominatim "type" and "addresstype" fields describing settlements
+var nominatimSettlements = map[string]bool{
+	"city": true, "town": true, "village": true, "hamlet": true, "municipality": true,
+	"suburb": true, "quarter": true, "neighbourhood": true, "city_district": true, "borough": true, "locality": true,
+}
+
+// values of the Nominatim "type" and "addresstype" fields describing areas larger than a settlement
+var nominatimRegions = map[string]bool{
+	"country": true, "state": true, "region": true, "province": true, "county": true, "district": true,
+}
+
+// Nominatim classes of single buildings and points of interest, they are located by a house address
+var nominatimHouseClasses = map[string]bool{
+	"building": true, "amenity": true, "shop": true, "office": true, "healthcare": true, "craft": true, "tourism": true, "leisure": true,
+}
+
+/*
+NewMapsCo returns a geocoder for geocode.maps.co
+*/
+func NewMapsCo(baseURL, apiKey string, do func(req *http.Request) (resp *http.Response, err error)) *Nominatim {
+	return &Nominatim{
+		name:    ProviderMapsCo,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		params:  url.Values{"api_key": {apiKey}},
+		do:      do,
+	}
+}
+
+/*
+NewNominatim returns a geocoder for a self-hosted Nominatim instance
+*/
+func NewNominatim(baseURL string, do func(req *http.Request) (resp *http.Response, err error)) *Nominatim {
+	return &Nominatim{
+		name:    ProviderNominatim,
+		baseURL: strings.TrimRight(baseURL, "/"),
+		params:  url.Values{"format": {"jsonv2"}},
+		do:      do,
+	}
+}
+
+func (n *Nominatim) Name() string {
+	return n.name
+}
+
+/*
+Search returns the places matching the query in the order returned by the provider
+The function returns ErrNotFound if the provider has no result
+*/
+func (n *Nominatim) Search(ctx context.Context, query string) ([]*types.Place, error) {
+	params := n.query()
+	params.Set("q", query)
+
+	var results []nominatimPlace
+	if err := n.fetch(ctx, "/search", params, &results); err != nil {
+		return nil, err
+	}
+
+	places := make([]*types.Place, 0, len(results))
+	for _, result := range results {
+		place, err := result.toPlace()
+		if err != nil {
+			return nil, err
+		}
+		places = append(places, place)
+	}
+
+	if len(places) == 0 {
+		return nil, ErrNotFound
+	}
+
+	return places, nil
+}
+
+/*
+Reverse returns the place at the coordinates
+The function returns ErrNotFound if the provider has no result
+*/
+func (n *Nominatim) Reverse(ctx context.Context, lat, lon float64) (*types.Place, error) {
+	params := n.query()
+	params.Set("lat", strconv.FormatFloat(lat, 'f', -1, 64))
+	params.Set("lon", strconv.FormatFloat(lon, 'f', -1, 64))
+
+	var result nominatimPlace
+	if err := n.fetch(ctx, "/reverse", params, &result); err != nil {
+		return nil, err
+	}
+
+	// an unknown location is reported as {"error": "Unable to geocode"}
+	if result.DisplayName == "" {
+		return nil, ErrNotFound
+	}
+
+	place := &types.Place{Lat: lat, Lon: lon, DisplayName: result.DisplayName, Type: result.placeType(), Address: result.address()}
+	if parsed, err := result.toPlace(); err == nil {
+		place = parsed
+	}
+
+	return place, nil
+}
+
+func (n *Nominatim) query() url.Values {
+	params := url.Values{}
+	for key, values := range n.params {
+		params[key] = append([]string{}, values...)
+	}
+
+	return params
+}
+
+func (n *Nominatim) fetch(ctx context.Context, path string, params url.Values, target any) error {
+	req, err := http.NewRequestWithContext(ctx, http.MethodGet, n.baseURL+path+"?"+params.Encode(), nil)
+	if err != nil {
+		return err
+	}
+
+	resp, err := n.do(req)
+	if err != nil {
+		return err
+	}
+
+	if resp.StatusCode != http.StatusOK {
+		if resp.Body != nil {
+			resp.Body.Close()
+		}
+		if resp.StatusCode == http.StatusTooManyRequests {
+			return &RateLimitError{Provider: n.name, RetryAfter: retryAfter(resp.Header.Get("Retry-After"))}
+		}
+		return fmt.Errorf("%s returned status %d", n.name, resp.StatusCode)
+	}
+	defer resp.Body.Close()
+
+	return json.NewDecoder(resp.Body).Decode(target)
+}
+
+func (p nominatimPlace) toPlace() (*types.Place, error) {
+	lat, err := strconv.ParseFloat(p.Lat, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid latitude %q", p.Lat)
+	}
+	lon, err := strconv.ParseFloat(p.Lon, 64)
+	if err != nil {
+		return nil, fmt.Errorf("invalid longitude %q", p.Lon)
+	}
+
+	return &types.Place{
+		Lat:         lat,
+		Lon:         lon,
+		DisplayName: p.DisplayName,
+		Type:        p.placeType(),
+		Importance:  p.Importance,
+		BoundingBox: p.boundingBox(),
+		Address:     p.address(),
+	}, nil
+}
+
+// address returns the parts of the Nominatim address details, the municipality is the most specific settlement
+func (p nominatimPlace) address() *types.PlaceAddress {
+	if len(p.Address) == 0 {
+		return nil
+	}
+
+	address := &types.PlaceAddress{
+		Street:      p.Address["road"],
+		HouseNumber: p.Address["house_number"],
+		PostalCode:  strings.ReplaceAll(p.Address["postcode"], " ", ""),
+	}
+	for _, key := range []string{"city", "town", "village", "hamlet", "municipality"} {
+		if municipality := p.Address[key]; municipality != "" {
+			address.Municipality = municipality
+			break
+		}
+	}
+
+	return address
+}
+
+func (p nominatimPlace) placeType() string {
+	class := p.Class
+	if class == "" {
+		class = p.Category
+	}
+
+	for _, kind := range []string{p.AddressType, p.Type} {
+		switch {
+		case kind == "house" || kind == "building":
+			return types.PlaceTypeHouse
+		case kind == "road":
+			return types.PlaceTypeStreet
+		case nominatimSettlements[kind]:
+			return types.PlaceTypeCity
+		case nominatimRegions[kind]:
+			return types.PlaceTypeRegion
+		}
+	}
+
+	switch {
+	case class == "highway":
+		return types.PlaceTypeStreet
+	case nominatimHouseClasses[class]:
+		return types.PlaceTypeHouse
+	case class == "" && p.Type == "":
+		return ""
+	}
+
+	return types.PlaceTypeOther
+}
+
+// boundingBox parses the Nominatim bounding box, given as [south, north, west, east] strings
+func (p nominatimPlace) boundingBox() *types.BoundingBox {
+	if len(p.BoundingBox) != 4 {
+		return nil
+	}
+
+	edges := make([]float64, 4)
+	for i, edge := range p.BoundingBox {
+		value, err := strconv.ParseFloat(edge, 64)
+		if err != nil {
+			return nil
+		}
+		edges[i] = value
+	}
+
+	return &types.BoundingBox{MinLat: edges[0], MaxLat: edges[1], MinLon: edges[2], MaxLon: edges[3]}
+}
+
+// retryAfter parses the Retry-After header, given either in seconds or as an HTTP date
+func retryAfter(header string) time.Duration {
+	if header == "" {
+		return 0
+	}
+
+	if seconds, err := strconv.Atoi(header); err == nil && seconds > 0 {
+		return time.Duration(seconds) * time.Second
+	}
+
+	if date, err := http.ParseTime(header); err == nil {
+		if wait := time.Until(date); wait > 0 {
+			return wait
+		}
+	}
+
+	return 0
+}
@@ -0,0 +1,157 @@
+package geocoding
+
+import (
+	"context"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func mockDo(status int, body string, requested *string) func(req *http.Request) (*http.Response, error) {
+	return func(req *http.Request) (*http.Response, error) {
+		if requested != nil {
+			*requested = req.URL.String()
+		}
+		return &http.Response{
+			StatusCode: status,
+			Body:       io.NopCloser(strings.NewReader(body)),
+		}, nil
+	}
+}
+
+func TestMapsCo_Search(t *testing.T) {
+	var requested string
+	geocoder := NewMapsCo("http://geocode.url/", "some-key", mockDo(http.StatusOK,
+		`[{"lat": "48.7164", "lon": "21.2611", "display_name": "Košice, Slovensko"}, {"lat": "48.7", "lon": "21.2", "display_name": "Košice-okolie"}]`, &requested))
+
+	places, err := geocoder.Search(context.Background(), "Hlavná 1, Košice")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "http://geocode.url/search?api_key=some-key&q=Hlavn%C3%A1+1%2C+Ko%C5%A1ice", requested)
+	assert.Equal(t, []*types.Place{
+		{Lat: 48.7164, Lon: 21.2611, DisplayName: "Košice, Slovensko"},
+		{Lat: 48.7, Lon: 21.2, DisplayName: "Košice-okolie"},
+	}, places)
+}
+
+func TestNominatim_SearchDetails(t *testing.T) {
+	geocoder := NewNominatim("http://nominatim.local", mockDo(http.StatusOK, `[
+		{"lat": "48.7543", "lon": "21.9195", "display_name": "Michalovce", "category": "boundary", "type": "administrative", "addresstype": "town", "importance": 0.52, "boundingbox": ["48.7201", "48.7869", "21.8563", "21.9788"]},
+		{"lat": "48.72", "lon": "21.25", "display_name": "Hlavná", "category": "highway", "type": "pedestrian", "importance": 0.2},
+		{"lat": "48.72", "lon": "21.258", "display_name": "Hlavná 1", "category": "place", "type": "house"},
+		{"lat": "48.71", "lon": "21.24", "display_name": "Nemocnica", "category": "amenity", "type": "hospital"},
+		{"lat": "48.6", "lon": "21.3", "display_name": "Košický kraj", "class": "boundary", "type": "administrative", "addresstype": "state"},
+		{"lat": "48.5", "lon": "21.1", "display_name": "Hornád", "class": "waterway", "type": "river", "boundingbox": ["48.1", "49.0", "x", "21.5"]}
+	]`, nil))
+
+	places, err := geocoder.Search(context.Background(), "Michalovce")
+
+	assert.NoError(t, err)
+	assert.Equal(t, &types.Place{
+		Lat: 48.7543, Lon: 21.9195, DisplayName: "Michalovce", Type: types.PlaceTypeCity, Importance: 0.52,
+		BoundingBox: &types.BoundingBox{MinLat: 48.7201, MaxLat: 48.7869, MinLon: 21.8563, MaxLon: 21.9788},
+	}, places[0])
+
+	kinds := []string{}
+	for _, place := range places {
+		kinds = append(kinds, place.Type)
+	}
+	assert.Equal(t, []string{types.PlaceTypeCity, types.PlaceTypeStreet, types.PlaceTypeHouse, types.PlaceTypeHouse, types.PlaceTypeRegion, types.PlaceTypeOther}, kinds)
+	assert.Nil(t, places[5].BoundingBox)
+}
+
+func TestNominatim_Reverse(t *testing.T) {
+	var requested string
+	geocoder := NewNominatim("http://nominatim.local", mockDo(http.StatusOK,
+		`{"lat": "48.71", "lon": "21.26", "display_name": "Hlavná 1, Košice"}`, &requested))
+
+	place, err := geocoder.Reverse(context.Background(), 48.7164, 21.2611)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "http://nominatim.local/reverse?format=jsonv2&lat=48.7164&lon=21.2611", requested)
+	assert.Equal(t, &types.Place{Lat: 48.71, Lon: 21.26, DisplayName: "Hlavná 1, Košice"}, place)
+}
+
+func TestNominatim_NotFound(t *testing.T) {
+	places, err := NewNominatim("http://nominatim.local", mockDo(http.StatusOK, `[]`, nil)).Search(context.Background(), "nowhere")
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Nil(t, places)
+
+	place, err := NewNominatim("http://nominatim.local", mockDo(http.StatusOK, `{"error": "Unable to geocode"}`, nil)).Reverse(context.Background(), 0, 0)
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.Nil(t, place)
+}
+
+func TestNominatim_Errors(t *testing.T) {
+	failing := func(req *http.Request) (*http.Response, error) {
+		return nil, errors.New("http get error")
+	}
+
+	_, err := NewNominatim("http://nominatim.local", failing).Search(context.Background(), "Košice")
+	assert.EqualError(t, err, "http get error")
+
+	_, err = NewMapsCo("http://geocode.url", "some-key", mockDo(http.StatusTooManyRequests, "", nil)).Search(context.Background(), "Košice")
+	assert.EqualError(t, err, "mapsco returned status 429")
+
+	_, err = NewNominatim("http://nominatim.local", mockDo(http.StatusOK, `{invalid_json}`, nil)).Reverse(context.Background(), 48.7, 21.2)
+	assert.Error(t, err)
+
+	_, err = NewNominatim("http://nominatim.local", mockDo(http.StatusOK, `[{"lat": "north", "lon": "21.2"}]`, nil)).Search(context.Background(), "Košice")
+	assert.EqualError(t, err, `invalid latitude "north"`)
+}
+
+func TestNominatim_RateLimited(t *testing.T) {
+	geocoder := NewMapsCo("http://geocode.url", "some-key", func(req *http.Request) (*http.Response, error) {
+		return &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"2"}}}, nil
+	})
+
+	_, err := geocoder.Search(context.Background(), "Košice")
+
+	var rateLimit *RateLimitError
+	assert.ErrorAs(t, err, &rateLimit)
+	assert.Equal(t, &RateLimitError{Provider: ProviderMapsCo, RetryAfter: 2 * time.Second}, rateLimit)
+	assert.EqualError(t, err, "mapsco returned status 429")
+}
+
+func TestNominatim_RequestContext(t *testing.T) {
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	geocoder := NewNominatim("http://nominatim.local", func(req *http.Request) (*http.Response, error) {
+		return nil, req.Context().Err()
+	})
+
+	_, err := geocoder.Search(ctx, "Košice")
+	assert.ErrorIs(t, err, context.Canceled)
+}
+
+func TestRetryAfter(t *testing.T) {
+	assert.Equal(t, time.Duration(0), retryAfter(""))
+	assert.Equal(t, time.Duration(0), retryAfter("soon"))
+	assert.Equal(t, 5*time.Second, retryAfter("5"))
+	assert.Equal(t, time.Duration(0), retryAfter("Mon, 01 Jan 2001 00:00:00 GMT"))
+
+	wait := retryAfter(time.Now().Add(time.Minute).UTC().Format(http.TimeFormat))
+	assert.Greater(t, wait, 50*time.Second)
+}
+
+func TestNominatim_ReverseAddress(t *testing.T) {
+	geocoder := NewNominatim("http://nominatim.local", mockDo(http.StatusOK, `{
+		"lat": "48.7203", "lon": "21.2578", "display_name": "1, Hlavná, Staré Mesto, Košice, okres Košice I, Košický kraj, 040 01, Slovensko",
+		"category": "building", "type": "yes", "addresstype": "building",
+		"address": {"house_number": "1", "road": "Hlavná", "suburb": "Staré Mesto", "city": "Košice", "postcode": "040 01", "country": "Slovensko"}
+	}`, nil))
+
+	place, err := geocoder.Reverse(context.Background(), 48.7203, 21.2578)
+
+	assert.NoError(t, err)
+	assert.Equal(t, types.PlaceTypeHouse, place.Type)
+	assert.Equal(t, &types.PlaceAddress{Street: "Hlavná", HouseNumber: "1", PostalCode: "04001", Municipality: "Košice"}, place.Address)
+	assert.Equal(t, "Hlavná 1, 04001 Košice, Slovenská republika", place.Address.String())
+}
@@ -0,0 +1,396 @@
+package handlers
+
+import (
+	"crypto/subtle"
+	"errors"
+	"io"
+	"net/http"
+	"strings"
+	"time"
+
+	"github.com/acornak/healthcare-poc/holidays"
+	"github.com/acornak/healthcare-poc/models"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+type MergeSpecialtiesPayload struct {
+	DuplicateID int `json:"duplicate_id"`
+	CanonicalID int `json:"canonical_id"`
+}
+
+type MergeSpecialtiesResponse struct {
+	MovedSpecialists int `json:"moved_specialists"`
+}
+
+type PurgeGeocodeCachePayload struct {
+	ExpiredOnly bool `json:"expired_only"`
+}
+
+type PurgeGeocodeCacheResponse struct {
+	Deleted int `json:"deleted"`
+}
+
+type SetHolidayOverridePayload struct {
+	Date   string `json:"date" example:"2024-12-31"`
+	Name   string `json:"name"`
+	Region string `json:"region"`
+	Closed *bool  `json:"closed"`
+}
+
+type DeleteHolidayOverridePayload struct {
+	Date   string `json:"date" example:"2024-12-31"`
+	Region string `json:"region"`
+}
+
+type GetAddressChecksPayload struct {
+	Status string `json:"status"`
+}
+
+// RequireAdmin rejects requests without a bearer token matching the configured admin token
+// admin routes are disabled when no admin token is configured
+func (h *Handler) RequireAdmin(c *gin.Context) {
+	token, ok := strings.CutPrefix(c.GetHeader("Authorization"), "Bearer ")
+	if h.AdminToken == "" || !ok || subtle.ConstantTimeCompare([]byte(token), []byte(h.AdminToken)) != 1 {
+		h.respondError(c, types.NewError(types.ErrorCodeUnauthorized, "Unauthorized"))
+		return
+	}
+
+	// the history of specialists records the changes under the member of staff, as the audit log does
+	c.Request = c.Request.WithContext(models.WithActor(c.Request.Context(), auditActor(c)))
+
+	c.Next()
+}
+
+// @Summary		Merge specialties
+// @Description	Merge a duplicate specialty into the canonical one, moving its specialists, symptom mappings and translations
+// @ID			admin-specialty-merge
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		payload	body		MergeSpecialtiesPayload	true	"Duplicate and canonical specialty ids"
+// @Success		200		{object}	MergeSpecialtiesResponse
+// @Failure		400		{object}	ErrorResponse
+// @Failure		401		{object}	ErrorResponse
+// @Failure		404		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/admin/specialty/merge [post]
+func (h *Handler) MergeSpecialties(c *gin.Context) {
+	var payload MergeSpecialtiesPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	if payload.DuplicateID == 0 {
+		h.respondError(c, types.NewMissingFieldError("duplicate_id"))
+		return
+	}
+
+	if payload.CanonicalID == 0 {
+		h.respondError(c, types.NewMissingFieldError("canonical_id"))
+		return
+	}
+
+	if payload.DuplicateID == payload.CanonicalID {
+		h.respondError(c, types.NewValidationError("Invalid payload: duplicate_id and canonical_id must differ"))
+		return
+	}
+
+	specialties := make([]*types.Specialty, 2)
+	for i, id := range []int{payload.DuplicateID, payload.CanonicalID} {
+		specialty, err := h.Models.Specialties.GetSpecialtyByID(c.Request.Context(), id)
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+
+		if specialty == nil {
+			h.respondError(c, types.NewNotFoundError("Specialty not found"))
+			return
+		}
+
+		specialties[i] = specialty
+	}
+	duplicate, canonical := specialties[0], specialties[1]
+
+	// the canonical specialty takes over the specialists, mappings, translations and aliases of the deleted duplicate
+	ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+		return []auditChange{
+			{types.AuditActionUpdate, types.AuditEntitySpecialty, canonical.ID, canonical, canonical},
+			{types.AuditActionDelete, types.AuditEntitySpecialty, duplicate.ID, duplicate, nil},
+		}
+	})
+
+	moved, err := h.Models.Specialties.MergeSpecialties(ctx, payload.DuplicateID, payload.CanonicalID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	logChange()
+
+	h.Logger.Info("specialties merged",
+		zap.Int("duplicate_id", payload.DuplicateID),
+		zap.Int("canonical_id", payload.CanonicalID),
+		zap.Int("moved_specialists", moved),
+	)
+
+	c.JSON(http.StatusOK, MergeSpecialtiesResponse{MovedSpecialists: moved})
+}
+
+// @Summary		Geocoding cache statistics
+// @Description	Get the hits, misses and store errors of the geocoding cache since the start of the server
+// @ID			admin-geocode-cache-stats
+// @Produce		json
+// @Security	BearerAuth
+// @Success		200		{object}	geocoding.CacheStats
+// @Failure		401		{object}	ErrorResponse
+// @Failure		404		{object}	ErrorResponse
+// @Router		/admin/geocode/cache/stats [post]
+func (h *Handler) GetGeocodeCacheStats(c *gin.Context) {
+	if h.GeocodeCache == nil {
+		h.respondError(c, types.NewNotFoundError("Geocoding cache is disabled"))
+		return
+	}
+
+	c.JSON(http.StatusOK, h.GeocodeCache.Stats())
+}
+
+// @Summary		Purge geocoding cache
+// @Description	Delete all cached geocoding results, or only the expired ones
+// @ID			admin-geocode-cache-purge
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		payload	body		PurgeGeocodeCachePayload	false	"Delete only expired entries"
+// @Success		200		{object}	PurgeGeocodeCacheResponse
+// @Failure		400		{object}	ErrorResponse
+// @Failure		401		{object}	ErrorResponse
+// @Failure		404		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/admin/geocode/cache/purge [post]
+func (h *Handler) PurgeGeocodeCache(c *gin.Context) {
+	var payload PurgeGeocodeCachePayload
+
+	// the payload is optional, an empty body purges everything
+	if c.Request.Body != nil && c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil && !errors.Is(err, io.EOF) {
+			h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+			return
+		}
+	}
+
+	if h.GeocodeCache == nil {
+		h.respondError(c, types.NewNotFoundError("Geocoding cache is disabled"))
+		return
+	}
+
+	deleted, err := h.GeocodeCache.Purge(c.Request.Context(), payload.ExpiredOnly)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	h.Logger.Info("geocode cache purged", zap.Bool("expired_only", payload.ExpiredOnly), zap.Int("deleted", deleted))
+
+	c.JSON(http.StatusOK, PurgeGeocodeCacheResponse{Deleted: deleted})
+}
+
+// @Summary		Specialist address checks
+// @Description	Get the results of verifying specialist addresses against their coordinates, optionally only those with a specific status
+// @ID			admin-specialist-address-checks
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		payload	body		GetAddressChecksPayload	false	"Status: verified, mismatch, filled or unresolved"
+// @Success		200		{array}		types.AddressCheck
+// @Failure		400		{object}	ErrorResponse
+// @Failure		401		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/admin/specialist/address-checks [post]
+func (h *Handler) GetAddressChecks(c *gin.Context) {
+	var payload GetAddressChecksPayload
+
+	// the payload is optional, an empty body returns all checks
+	if c.Request.Body != nil && c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil && !errors.Is(err, io.EOF) {
+			h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+			return
+		}
+	}
+
+	switch payload.Status {
+	case "", types.AddressCheckVerified, types.AddressCheckMismatch, types.AddressCheckFilled, types.AddressCheckUnresolved:
+	default:
+		h.respondError(c, types.NewInvalidFieldError("status", "must be one of verified, mismatch, filled, unresolved"))
+		return
+	}
+
+	checks, err := h.Models.Addresses.GetAddressChecks(c.Request.Context(), payload.Status)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, checks)
+}
+
+// @Summary		Set holiday override
+// @Description	Close the clinics on a day, or with closed false cancel a public holiday, in the whole country or in one region
+// @Description	The region is a municipality or a district (okres), it applies to the specialists in the municipality or the district, an override replaces the one on the same date and region
+// @ID			admin-holiday-override-set
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		payload	body		SetHolidayOverridePayload	true	"Date, name, optional region and closed, true by default"
+// @Success		200		{object}	types.Holiday
+// @Failure		400		{object}	ErrorResponse
+// @Failure		401		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/admin/holiday/override [post]
+func (h *Handler) SetHolidayOverride(c *gin.Context) {
+	var payload SetHolidayOverridePayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	if payload.Date == "" {
+		h.respondError(c, types.NewMissingFieldError("date"))
+		return
+	}
+
+	if _, err := time.Parse(holidays.DateFormat, payload.Date); err != nil {
+		h.respondError(c, types.NewInvalidFieldError("date", "must be in the YYYY-MM-DD format"))
+		return
+	}
+
+	if strings.TrimSpace(payload.Name) == "" {
+		h.respondError(c, types.NewMissingFieldError("name"))
+		return
+	}
+
+	holiday := types.Holiday{
+		Date:   payload.Date,
+		Name:   strings.TrimSpace(payload.Name),
+		Region: strings.TrimSpace(payload.Region),
+		Closed: payload.Closed == nil || *payload.Closed,
+		Source: types.HolidaySourceOverride,
+	}
+
+	before, err := h.holidayOverride(c, holiday.Date, holiday.Region)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+		if before == nil {
+			return []auditChange{{types.AuditActionCreate, types.AuditEntityHolidayOverride, id, nil, holiday}}
+		}
+		return []auditChange{{types.AuditActionUpdate, types.AuditEntityHolidayOverride, id, before, holiday}}
+	})
+
+	if err := h.Models.Holidays.SetHolidayOverride(ctx, holiday); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	logChange()
+
+	h.Logger.Info("holiday override set",
+		zap.String("date", holiday.Date),
+		zap.String("region", holiday.Region),
+		zap.Bool("closed", holiday.Closed),
+	)
+
+	c.JSON(http.StatusOK, holiday)
+}
+
+// @Summary		Delete holiday override
+// @Description	Delete the holiday override on a date and region, the public holiday calendar applies again
+// @ID			admin-holiday-override-delete
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		payload	body		DeleteHolidayOverridePayload	true	"Date and optional region"
+// @Success		200		{object}	DeleteHolidayOverridePayload
+// @Failure		400		{object}	ErrorResponse
+// @Failure		401		{object}	ErrorResponse
+// @Failure		404		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/admin/holiday/override/delete [post]
+func (h *Handler) DeleteHolidayOverride(c *gin.Context) {
+	var payload DeleteHolidayOverridePayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	if payload.Date == "" {
+		h.respondError(c, types.NewMissingFieldError("date"))
+		return
+	}
+
+	if _, err := time.Parse(holidays.DateFormat, payload.Date); err != nil {
+		h.respondError(c, types.NewInvalidFieldError("date", "must be in the YYYY-MM-DD format"))
+		return
+	}
+
+	payload.Region = strings.TrimSpace(payload.Region)
+
+	before, err := h.holidayOverride(c, payload.Date, payload.Region)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if before == nil {
+		h.respondError(c, types.NewNotFoundError("Holiday override not found"))
+		return
+	}
+
+	ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+		return []auditChange{{types.AuditActionDelete, types.AuditEntityHolidayOverride, id, before, nil}}
+	})
+
+	deleted, err := h.Models.Holidays.DeleteHolidayOverride(ctx, payload.Date, payload.Region)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if !deleted {
+		h.respondError(c, types.NewNotFoundError("Holiday override not found"))
+		return
+	}
+	logChange()
+
+	h.Logger.Info("holiday override deleted", zap.String("date", payload.Date), zap.String("region", payload.Region))
+
+	c.JSON(http.StatusOK, payload)
+}
+
+// holidayOverride returns the holiday override on the date and region, nil if there is none
+func (h *Handler) holidayOverride(c *gin.Context, date, region string) (*types.Holiday, error) {
+	day, err := time.Parse(holidays.DateFormat, date)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := h.Models.Holidays.GetHolidayOverrides(c.Request.Context(), day.Year())
+	if err != nil {
+		return nil, err
+	}
+
+	for _, override := range overrides {
+		if override.Date == date && override.Region == region {
+			return override, nil
+		}
+	}
+
+	return nil, nil
+}
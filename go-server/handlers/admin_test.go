@@ -0,0 +1,465 @@
+package handlers
+
+import (
+	"context"
+	"database/sql/driver"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/geocoding"
+	"github.com/acornak/healthcare-poc/models"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestRequireAdmin(t *testing.T) {
+	tests := []struct {
+		adminToken string
+		header     string
+		expected   int
+	}{
+		{"", "", http.StatusUnauthorized},
+		{"", "Bearer ", http.StatusUnauthorized},
+		{"secret", "", http.StatusUnauthorized},
+		{"secret", "secret", http.StatusUnauthorized},
+		{"secret", "Bearer wrong", http.StatusUnauthorized},
+		{"secret", "Bearer secret", http.StatusOK},
+	}
+
+	for _, test := range tests {
+		handler := &Handler{AdminToken: test.adminToken}
+
+		r := gin.New()
+		r.POST("/admin", handler.RequireAdmin, func(c *gin.Context) {
+			c.Status(http.StatusOK)
+		})
+
+		req, _ := http.NewRequest("POST", "/admin", nil)
+		if test.header != "" {
+			req.Header.Set("Authorization", test.header)
+		}
+
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, test.expected, w.Code, test.header)
+	}
+}
+
+func TestMergeSpecialtiesHandler_InvalidPayload(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := &Handler{
+		Logger: logger,
+	}
+
+	tests := []struct {
+		payload  string
+		expected string
+	}{
+		{"{invalid_json}", "Invalid JSON payload"},
+		{`{"canonical_id": 1}`, "Invalid payload: missing duplicate_id field"},
+		{`{"duplicate_id": 2}`, "Invalid payload: missing canonical_id field"},
+		{`{"duplicate_id": 2, "canonical_id": 2}`, "Invalid payload: duplicate_id and canonical_id must differ"},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", "/admin/specialty/merge", strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/admin/specialty/merge", handler.MergeSpecialties)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response ErrorResponse
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			t.Errorf("Error unmarshaling response: %v", err)
+		}
+
+		assert.Equal(t, test.expected, response.Error)
+	}
+}
+
+func TestMergeSpecialtiesHandler_NotFound(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.id=\$1`).WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}))
+
+	handler := &Handler{
+		Logger: logger,
+		Models: models.NewModels(db),
+	}
+
+	req, _ := http.NewRequest("POST", "/admin/specialty/merge", strings.NewReader(`{"duplicate_id": 2, "canonical_id": 1}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/admin/specialty/merge", handler.MergeSpecialties)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMergeSpecialtiesHandler_Success(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.id=\$1`).WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(2, "Ortopéd", ""))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.id=\$1`).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "ortopéd", ""))
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE specialist SET specialty_id").WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 4))
+	for i := 0; i < 7; i++ {
+		mock.ExpectExec("").WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	mock.ExpectExec(`INSERT INTO admin_audit_log`).WithArgs("admin", "", "update", "specialty", 1, sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO admin_audit_log`).WithArgs("admin", "", "delete", "specialty", 2, sqlmock.AnyArg(), nil).WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	handler := &Handler{
+		Logger: logger,
+		Models: models.NewModels(db),
+	}
+
+	req, _ := http.NewRequest("POST", "/admin/specialty/merge", strings.NewReader(`{"duplicate_id": 2, "canonical_id": 1}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/admin/specialty/merge", handler.MergeSpecialties)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response MergeSpecialtiesResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Equal(t, 4, response.MovedSpecialists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGeocodeCacheHandlers_Disabled(t *testing.T) {
+	handler := &Handler{Logger: zap.NewNop()}
+
+	r := gin.New()
+	r.POST("/admin/geocode/cache/stats", handler.GetGeocodeCacheStats)
+	r.POST("/admin/geocode/cache/purge", handler.PurgeGeocodeCache)
+
+	for _, path := range []string{"/admin/geocode/cache/stats", "/admin/geocode/cache/purge"} {
+		req, _ := http.NewRequest("POST", path, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusNotFound, w.Code, path)
+		assert.JSONEq(t, `{"error":"Geocoding cache is disabled","code":"not_found"}`, w.Body.String(), path)
+	}
+}
+
+func TestGetGeocodeCacheStatsHandler(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	dbModels := models.NewModels(db)
+	mock.ExpectQuery(`SELECT places FROM geocode_cache`).WithArgs("search:bratislava").
+		WillReturnRows(sqlmock.NewRows([]string{"places"}).AddRow(`[{"lat":48.1,"lon":17.1,"display_name":"Bratislava"}]`))
+
+	cache := geocoding.NewCache(geocoding.NewGazetteer(), dbModels.DB, time.Hour, zap.NewNop())
+	_, err = cache.Search(context.Background(), "Bratislava")
+	assert.NoError(t, err)
+
+	handler := &Handler{Logger: zap.NewNop(), GeocodeCache: cache}
+
+	req, _ := http.NewRequest("POST", "/admin/geocode/cache/stats", nil)
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/admin/geocode/cache/stats", handler.GetGeocodeCacheStats)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"hits":1,"misses":0,"errors":0,"hit_ratio":1}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeGeocodeCacheHandler(t *testing.T) {
+	tests := []struct {
+		payload     string
+		expiredOnly bool
+	}{
+		{"", false},
+		{`{"expired_only": true}`, true},
+	}
+
+	for _, test := range tests {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to open sqlmock database: %s", err)
+		}
+
+		mock.ExpectExec(`DELETE FROM geocode_cache`).WithArgs(test.expiredOnly).WillReturnResult(sqlmock.NewResult(0, 3))
+
+		dbModels := models.NewModels(db)
+		handler := &Handler{
+			Logger:       zap.NewNop(),
+			GeocodeCache: geocoding.NewCache(geocoding.NewGazetteer(), dbModels.DB, time.Hour, zap.NewNop()),
+		}
+
+		req, _ := http.NewRequest("POST", "/admin/geocode/cache/purge", strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/admin/geocode/cache/purge", handler.PurgeGeocodeCache)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, test.payload)
+		assert.JSONEq(t, `{"deleted":3}`, w.Body.String())
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	}
+}
+
+func TestPurgeGeocodeCacheHandler_InvalidPayload(t *testing.T) {
+	handler := &Handler{Logger: zap.NewNop()}
+
+	req, _ := http.NewRequest("POST", "/admin/geocode/cache/purge", strings.NewReader("{invalid_json}"))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/admin/geocode/cache/purge", handler.PurgeGeocodeCache)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"Invalid JSON payload","code":"invalid_payload"}`, w.Body.String())
+}
+
+func TestGetAddressChecksHandler(t *testing.T) {
+	tests := []struct {
+		payload string
+		status  string
+	}{
+		{"", ""},
+		{`{"status": "mismatch"}`, "mismatch"},
+	}
+
+	checkedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+	for _, test := range tests {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to open sqlmock database: %s", err)
+		}
+
+		mock.ExpectQuery(`SELECT (.+) FROM specialist_address_check`).WithArgs(test.status).
+			WillReturnRows(sqlmock.NewRows([]string{"specialist_id", "name", "status", "stated_address", "geocoded_address", "distance_meters", "checked_at"}).
+				AddRow(8, "Poliklinika", "mismatch", "Ulica 9, 07101 Michalovce", "Hlavná 1, 04001 Košice, Slovenská republika", 0.0, checkedAt))
+
+		handler := &Handler{Logger: zap.NewNop(), Models: models.NewModels(db)}
+
+		req, _ := http.NewRequest("POST", "/admin/specialist/address-checks", strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/admin/specialist/address-checks", handler.GetAddressChecks)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, test.payload)
+		assert.Contains(t, w.Body.String(), `"status":"mismatch"`)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	}
+}
+
+func TestGetAddressChecksHandler_InvalidPayload(t *testing.T) {
+	tests := map[string]string{
+		"{invalid_json}":       `{"error":"Invalid JSON payload","code":"invalid_payload"}`,
+		`{"status": "broken"}`: `{"error":"Invalid payload: status must be one of verified, mismatch, filled, unresolved","code":"invalid_payload","fields":[{"field":"status","message":"must be one of verified, mismatch, filled, unresolved"}]}`,
+	}
+
+	for payload, expected := range tests {
+		handler := &Handler{Logger: zap.NewNop()}
+
+		req, _ := http.NewRequest("POST", "/admin/specialist/address-checks", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/admin/specialist/address-checks", handler.GetAddressChecks)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, payload)
+		assert.JSONEq(t, expected, w.Body.String())
+	}
+}
+
+func TestSetHolidayOverrideHandler(t *testing.T) {
+	tests := []struct {
+		payload string
+		args    []driver.Value
+		action  string
+	}{
+		{`{"date": "2024-12-31", "name": "Silvester"}`, []driver.Value{"2024-12-31", "", "Silvester", true}, "create"},
+		{`{"date": "2024-07-05", "name": " Cyril a Metod ", "region": "Košice", "closed": false}`, []driver.Value{"2024-07-05", "Košice", "Cyril a Metod", false}, "update"},
+	}
+
+	for _, test := range tests {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to open sqlmock database: %s", err)
+		}
+
+		// the override of Košice replaces an existing one
+		mock.ExpectQuery(`SELECT (.+) FROM holiday_override`).WithArgs(2024).
+			WillReturnRows(sqlmock.NewRows([]string{"date", "name", "region", "closed"}).AddRow("2024-07-05", "Mestské dni", "Košice", true))
+		mock.ExpectBegin()
+		mock.ExpectExec(`INSERT INTO holiday_override`).WithArgs(test.args...).WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`INSERT INTO admin_audit_log`).WithArgs("admin", "", test.action, "holiday_override", 0, sqlmock.AnyArg(), sqlmock.AnyArg()).
+			WillReturnResult(sqlmock.NewResult(1, 1))
+		mock.ExpectCommit()
+
+		handler := &Handler{Logger: zap.NewNop(), Models: models.NewModels(db)}
+
+		req, _ := http.NewRequest("POST", "/admin/holiday/override", strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/admin/holiday/override", handler.SetHolidayOverride)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, test.payload)
+		assert.Contains(t, w.Body.String(), `"source":"override"`)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	}
+}
+
+func TestSetHolidayOverrideHandler_InvalidPayload(t *testing.T) {
+	tests := map[string]string{
+		"{invalid_json}":                      `{"error":"Invalid JSON payload","code":"invalid_payload"}`,
+		`{"name": "Silvester"}`:               `{"error":"Invalid payload: missing date field","code":"invalid_payload","fields":[{"field":"date","message":"is required"}]}`,
+		`{"date": "31.12.2024", "name": "x"}`: `{"error":"Invalid payload: date must be in the YYYY-MM-DD format","code":"invalid_payload","fields":[{"field":"date","message":"must be in the YYYY-MM-DD format"}]}`,
+		`{"date": "2024-12-31", "name": " "}`: `{"error":"Invalid payload: missing name field","code":"invalid_payload","fields":[{"field":"name","message":"is required"}]}`,
+	}
+
+	for payload, expected := range tests {
+		handler := &Handler{Logger: zap.NewNop()}
+
+		req, _ := http.NewRequest("POST", "/admin/holiday/override", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/admin/holiday/override", handler.SetHolidayOverride)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, payload)
+		assert.JSONEq(t, expected, w.Body.String())
+	}
+}
+
+func TestDeleteHolidayOverrideHandler(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	overrides := func() *sqlmock.Rows {
+		return sqlmock.NewRows([]string{"date", "name", "region", "closed"}).AddRow("2024-12-31", "Silvester", "", true)
+	}
+	mock.ExpectQuery(`SELECT (.+) FROM holiday_override`).WithArgs(2024).WillReturnRows(overrides())
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM holiday_override`).WithArgs("2024-12-31", "").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO admin_audit_log`).WithArgs("admin", "", "delete", "holiday_override", 0, sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT (.+) FROM holiday_override`).WithArgs(2024).WillReturnRows(overrides())
+
+	handler := &Handler{Logger: zap.NewNop(), Models: models.NewModels(db)}
+	r := gin.New()
+	r.POST("/admin/holiday/override/delete", handler.DeleteHolidayOverride)
+
+	req, _ := http.NewRequest("POST", "/admin/holiday/override/delete", strings.NewReader(`{"date": "2024-12-31"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"date":"2024-12-31","region":""}`, w.Body.String())
+
+	req, _ = http.NewRequest("POST", "/admin/holiday/override/delete", strings.NewReader(`{"date": "2024-12-31", "region": "Košice"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"Holiday override not found","code":"not_found"}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMergeSpecialtiesHandler_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	req, _ := http.NewRequest("POST", "/admin/specialty/merge", strings.NewReader(`{"duplicate_id": 1, "canonical_id": 2}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/admin/specialty/merge", handler.MergeSpecialties)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"moved_specialists": 2}`, w.Body.String())
+
+	specialty, err := handler.Models.Specialties.GetSpecialtyByNormalizedName(context.Background(), "Kardiológia")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, specialty.ID)
+
+	entries, err := handler.Models.Audit.GetAuditLog(context.Background(), types.AuditEntitySpecialty, 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, []int{1, 2}, []int{entries[0].EntityID, entries[1].EntityID})
+	assert.Equal(t, types.AuditActionDelete, entries[0].Action)
+	assert.Equal(t, types.AuditActionUpdate, entries[1].Action)
+
+	// the duplicate is gone, merging it again is a 404
+	w = httptest.NewRecorder()
+	req, _ = http.NewRequest("POST", "/admin/specialty/merge", strings.NewReader(`{"duplicate_id": 1, "canonical_id": 2}`))
+	req.Header.Set("Content-Type", "application/json")
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
@@ -0,0 +1,170 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"io"
+	"net/http"
+	"slices"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/acornak/healthcare-poc/models"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+/*
+AdminUserHeader names the member of staff making an admin change, it is recorded in the audit log
+The name is declared by the client and not authenticated, every member of staff shares the admin token
+*/
+const AdminUserHeader = "X-Admin-User"
+
+const (
+	// the actor of changes made without the admin user header
+	defaultAuditActor    = "admin"
+	auditActorMaxLength  = 255
+	auditLogDefaultLimit = 50
+	auditLogMaxLimit     = 500
+)
+
+type GetAuditLogPayload struct {
+	Entity   string `json:"entity,omitempty"`
+	EntityID int    `json:"entity_id,omitempty"`
+	Limit    int    `json:"limit,omitempty"`
+}
+
+type GetAuditLogResponse struct {
+	Entries []*types.AuditEntry `json:"entries"`
+}
+
+// auditActor returns the member of staff named by the admin user header, admin when the header is missing
+func auditActor(c *gin.Context) string {
+	actor := strings.TrimSpace(c.GetHeader(AdminUserHeader))
+	if actor == "" {
+		return defaultAuditActor
+	}
+
+	for len(actor) > auditActorMaxLength {
+		_, size := utf8.DecodeLastRuneInString(actor)
+		actor = actor[:len(actor)-size]
+	}
+
+	return actor
+}
+
+// auditChange is a change of a record made through the admin API, before and after are the record before and after it, nil if there is none
+type auditChange struct {
+	action string
+	entity string
+	id     int
+	before any
+	after  any
+}
+
+/*
+auditContext returns the request context of a change made through the admin API
+The repositories record the change in the audit log in the transaction of the change, so the change is never stored without its audit entry
+changes returns the changed records, id is the id of the changed record, for a create the id assigned by the storage
+logChange logs the recorded changes, it is called once the change is stored
+*/
+func (h *Handler) auditContext(c *gin.Context, changes func(id int) []auditChange) (ctx context.Context, logChange func()) {
+	actor, requestID := auditActor(c), c.GetString(requestIDKey)
+
+	var recorded []types.AuditEntry
+	ctx = models.WithAudit(c.Request.Context(), func(id int) ([]types.AuditEntry, error) {
+		entries := []types.AuditEntry{}
+		for _, change := range changes(id) {
+			entry := types.AuditEntry{Actor: actor, RequestID: requestID, Action: change.action, Entity: change.entity, EntityID: change.id}
+
+			var err error
+			if entry.Before, err = auditRecord(change.before); err != nil {
+				return nil, err
+			}
+			if entry.After, err = auditRecord(change.after); err != nil {
+				return nil, err
+			}
+			entries = append(entries, entry)
+		}
+
+		recorded = entries
+		return entries, nil
+	})
+
+	logChange = func() {
+		for _, entry := range recorded {
+			h.Logger.Info("admin change",
+				zap.String("action", entry.Action),
+				zap.String("entity", entry.Entity),
+				zap.Int("id", entry.EntityID),
+				zap.String("actor", entry.Actor),
+				zap.String("request_id", entry.RequestID),
+			)
+		}
+	}
+
+	return ctx, logChange
+}
+
+// auditRecord encodes the record as stored in the audit log, nil for a missing record
+func auditRecord(record any) (json.RawMessage, error) {
+	if record == nil {
+		return nil, nil
+	}
+
+	return json.Marshal(record)
+}
+
+// @Summary		Audit log
+// @Description	Get the changes made through the admin API, the newest first, optionally only those of an entity or a single record
+// @Description	The actor is the X-Admin-User header of the change, it is declared by the client and not authenticated
+// @ID			admin-audit-log
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		payload	body		GetAuditLogPayload	false	"Entity (specialist, specialty, review, symptom_mapping, holiday_override or emergency_service), entity id and limit (default 50, max 500)"
+// @Success		200		{object}	GetAuditLogResponse
+// @Failure		400		{object}	ErrorResponse
+// @Failure		401		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/admin/audit [post]
+func (h *Handler) GetAuditLog(c *gin.Context) {
+	var payload GetAuditLogPayload
+
+	// the payload is optional, an empty body returns the latest changes
+	if c.Request.Body != nil && c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil && !errors.Is(err, io.EOF) {
+			h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+			return
+		}
+	}
+
+	if payload.Entity != "" && !slices.Contains(types.AuditEntities, payload.Entity) {
+		h.respondError(c, types.NewInvalidFieldError("entity", "must be one of "+strings.Join(types.AuditEntities, ", ")))
+		return
+	}
+
+	if payload.EntityID != 0 && payload.Entity == "" {
+		h.respondError(c, types.NewMissingFieldError("entity"))
+		return
+	}
+
+	if payload.Limit == 0 {
+		payload.Limit = auditLogDefaultLimit
+	}
+
+	if payload.Limit < 1 || payload.Limit > auditLogMaxLimit {
+		h.respondError(c, types.NewInvalidFieldError("limit", "must be between 1 and 500"))
+		return
+	}
+
+	entries, err := h.Models.Audit.GetAuditLog(c.Request.Context(), payload.Entity, payload.EntityID, payload.Limit)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, GetAuditLogResponse{Entries: entries})
+}
@@ -0,0 +1,83 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestAuditActor(t *testing.T) {
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", "admin"},
+		{"  Jana Nováková ", "Jana Nováková"},
+		{strings.Repeat("á", 200), strings.Repeat("á", 127)},
+	}
+
+	for _, test := range tests {
+		c, _ := gin.CreateTestContext(httptest.NewRecorder())
+		c.Request, _ = http.NewRequest("POST", "/", nil)
+		c.Request.Header.Set(AdminUserHeader, test.header)
+
+		assert.Equal(t, test.expected, auditActor(c))
+	}
+}
+
+func TestGetAuditLogHandler(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	r := gin.New()
+	r.Use(handler.RequestID)
+	r.POST("/admin/specialty", handler.SaveSpecialty)
+	r.POST("/admin/audit", handler.GetAuditLog)
+
+	req, _ := http.NewRequest("POST", "/admin/specialty", strings.NewReader(`{"name": "dermatológia"}`))
+	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set(AdminUserHeader, "jana")
+	req.Header.Set(RequestIDHeader, "abc-123")
+	r.ServeHTTP(httptest.NewRecorder(), req)
+
+	tests := []struct {
+		payload  string
+		expected int
+		entries  int
+	}{
+		{``, http.StatusOK, 1},
+		{`{"entity": "specialty", "entity_id": 3}`, http.StatusOK, 1},
+		{`{"entity": "review"}`, http.StatusOK, 0},
+		{`{"entity": "clinic"}`, http.StatusBadRequest, 0},
+		{`{"entity_id": 3}`, http.StatusBadRequest, 0},
+		{`{"limit": 501}`, http.StatusBadRequest, 0},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", "/admin/audit", strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, test.expected, w.Code, test.payload)
+		if test.expected == http.StatusOK {
+			var resp GetAuditLogResponse
+			assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &resp))
+			assert.Len(t, resp.Entries, test.entries, test.payload)
+		}
+	}
+
+	entries, err := handler.Models.Audit.GetAuditLog(context.Background(), "", 0, 10)
+	assert.NoError(t, err)
+	assert.Equal(t, "jana", entries[0].Actor)
+	assert.Equal(t, "abc-123", entries[0].RequestID)
+	assert.Equal(t, types.AuditActionCreate, entries[0].Action)
+	assert.Nil(t, entries[0].Before)
+	assert.JSONEq(t, `{"id": 3, "name": "dermatológia", "description": ""}`, string(entries[0].After))
+}
@@ -0,0 +1,260 @@
+package handlers
+
+import (
+	"errors"
+	"io"
+	"net/http"
+
+	"github.com/acornak/healthcare-poc/models"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	duplicatesDefaultMinScore = 0.5
+	duplicatesDefaultLimit    = 50
+	duplicatesMaxLimit        = 500
+)
+
+type GetDuplicateSpecialistsPayload struct {
+	MinScore *float64 `json:"min_score" example:"0.5"`
+	Limit    int      `json:"limit"`
+}
+
+type GetDuplicateSpecialistsResponse struct {
+	Candidates []*types.DuplicateCandidate `json:"candidates"`
+}
+
+type DismissDuplicateSpecialistsPayload struct {
+	ID          int `json:"id"`
+	DuplicateID int `json:"duplicate_id"`
+}
+
+type MergeSpecialistsPayload struct {
+	SurvivorID       int `json:"survivor_id"`
+	SurvivorVersion  int `json:"survivor_version"`
+	DuplicateID      int `json:"duplicate_id"`
+	DuplicateVersion int `json:"duplicate_version"`
+}
+
+type MergeSpecialistsResponse struct {
+	Specialist   *types.Specialist `json:"specialist"`
+	MovedReviews int               `json:"moved_reviews"`
+}
+
+// @Summary		Duplicate specialists
+// @Description	Get pairs of specialists that may be the same clinic, the most likely first, scored by the similarity of their names, addresses, locations, phone numbers and KPZS codes
+// @Description	Specialists are compared when they share the KPZS code, or have the same specialty and are within 500 meters or have the same phone numbers, dismissed pairs are skipped
+// @ID			admin-specialist-duplicates
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		payload	body		GetDuplicateSpecialistsPayload	false	"Minimum score from 0 to 1 (default 0.5) and limit (default 50, max 500)"
+// @Success		200		{object}	GetDuplicateSpecialistsResponse
+// @Failure		400		{object}	ErrorResponse
+// @Failure		401		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/admin/specialist/duplicates [post]
+func (h *Handler) GetDuplicateSpecialists(c *gin.Context) {
+	var payload GetDuplicateSpecialistsPayload
+
+	// the payload is optional, an empty body returns the candidates with the default score
+	if c.Request.Body != nil && c.Request.ContentLength != 0 {
+		if err := c.ShouldBindJSON(&payload); err != nil && !errors.Is(err, io.EOF) {
+			h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+			return
+		}
+	}
+
+	minScore := duplicatesDefaultMinScore
+	if payload.MinScore != nil {
+		minScore = *payload.MinScore
+	}
+
+	if minScore < 0 || minScore > 1 {
+		h.respondError(c, types.NewInvalidFieldError("min_score", "must be between 0 and 1"))
+		return
+	}
+
+	if payload.Limit == 0 {
+		payload.Limit = duplicatesDefaultLimit
+	}
+
+	if payload.Limit < 1 || payload.Limit > duplicatesMaxLimit {
+		h.respondError(c, types.NewInvalidFieldError("limit", "must be between 1 and 500"))
+		return
+	}
+
+	candidates, err := h.Models.Specialists.GetDuplicateSpecialists(c.Request.Context(), minScore, payload.Limit)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, GetDuplicateSpecialistsResponse{Candidates: candidates})
+}
+
+// @Summary		Dismiss duplicate specialists
+// @Description	Mark two specialists listed as duplicates as distinct clinics, the pair is no longer listed
+// @ID			admin-specialist-duplicates-dismiss
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		X-Admin-User	header		string								false	"Member of staff making the change"
+// @Param		payload			body		DismissDuplicateSpecialistsPayload	true	"Ids of the two specialists"
+// @Success		200				{object}	DismissDuplicateSpecialistsPayload
+// @Failure		400				{object}	ErrorResponse
+// @Failure		401				{object}	ErrorResponse
+// @Failure		404				{object}	ErrorResponse
+// @Failure		500				{object}	ErrorResponse
+// @Router		/admin/specialist/duplicates/dismiss [post]
+func (h *Handler) DismissDuplicateSpecialists(c *gin.Context) {
+	var payload DismissDuplicateSpecialistsPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	missingParams := []string{}
+	if payload.ID == 0 {
+		missingParams = append(missingParams, "id")
+	}
+	if payload.DuplicateID == 0 {
+		missingParams = append(missingParams, "duplicate_id")
+	}
+
+	if len(missingParams) > 0 {
+		h.respondError(c, types.NewMissingFieldsError(missingParams))
+		return
+	}
+
+	if payload.ID == payload.DuplicateID {
+		h.respondError(c, types.NewValidationError("Invalid payload: id and duplicate_id must differ"))
+		return
+	}
+
+	for _, id := range []int{payload.ID, payload.DuplicateID} {
+		specialist, err := h.Models.Specialists.GetSpecialistByID(c.Request.Context(), id)
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+
+		if specialist == nil {
+			h.respondError(c, types.NewNotFoundError("Specialist not found"))
+			return
+		}
+	}
+
+	if err := h.Models.Specialists.DismissDuplicateSpecialists(c.Request.Context(), payload.ID, payload.DuplicateID); err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, payload)
+}
+
+// @Summary		Merge specialists
+// @Description	Merge a duplicate specialist into the surviving one, moving its reviews and adding its staff, the duplicate is deleted
+// @Description	The added staff is kept as an override of the survivor, the scraper skips the duplicate from then on, so the survivor should be the specialist the geoportal lists
+// @Description	The merge names the versions of both specialists it is based on
+// @ID			admin-specialist-merge
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		X-Admin-User	header		string					false	"Member of staff making the change"
+// @Param		payload			body		MergeSpecialistsPayload	true	"Ids and versions of the surviving and duplicate specialist"
+// @Success		200				{object}	MergeSpecialistsResponse
+// @Failure		400				{object}	ErrorResponse
+// @Failure		401				{object}	ErrorResponse
+// @Failure		404				{object}	ErrorResponse
+// @Failure		409				{object}	ErrorResponse
+// @Failure		500				{object}	ErrorResponse
+// @Router		/admin/specialist/merge [post]
+func (h *Handler) MergeSpecialists(c *gin.Context) {
+	var payload MergeSpecialistsPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	missingParams := []string{}
+	if payload.SurvivorID == 0 {
+		missingParams = append(missingParams, "survivor_id")
+	}
+	if payload.SurvivorVersion == 0 {
+		missingParams = append(missingParams, "survivor_version")
+	}
+	if payload.DuplicateID == 0 {
+		missingParams = append(missingParams, "duplicate_id")
+	}
+	if payload.DuplicateVersion == 0 {
+		missingParams = append(missingParams, "duplicate_version")
+	}
+
+	if len(missingParams) > 0 {
+		h.respondError(c, types.NewMissingFieldsError(missingParams))
+		return
+	}
+
+	if payload.SurvivorID == payload.DuplicateID {
+		h.respondError(c, types.NewValidationError("Invalid payload: survivor_id and duplicate_id must differ"))
+		return
+	}
+
+	profiles := make([]*types.SpecialistProfile, 2)
+	for i, id := range []int{payload.SurvivorID, payload.DuplicateID} {
+		profile, err := h.Models.Specialists.GetSpecialistProfile(c.Request.Context(), id)
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+
+		if profile == nil {
+			h.respondError(c, types.NewNotFoundError("Specialist not found"))
+			return
+		}
+
+		profiles[i] = profile
+	}
+	survivor, duplicate := profiles[0].Specialist, profiles[1].Specialist
+
+	ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+		changes := []auditChange{}
+		if staff := models.MergeStaff(survivor.Staff, duplicate.Staff); staff != survivor.Staff {
+			merged := overriddenSpecialist(survivor, map[string]string{"staff": staff}, types.FieldSourceOverride)
+			changes = append(changes, auditChange{types.AuditActionUpdate, types.AuditEntitySpecialist, survivor.ID, survivor, merged})
+		}
+
+		return append(changes, auditChange{types.AuditActionDelete, types.AuditEntitySpecialist, duplicate.ID, duplicate, nil})
+	})
+
+	_, moved, err := h.Models.Specialists.MergeSpecialists(ctx, payload.DuplicateID, payload.DuplicateVersion, payload.SurvivorID, payload.SurvivorVersion)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	logChange()
+
+	after, err := h.Models.Specialists.GetSpecialistProfile(c.Request.Context(), payload.SurvivorID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if after == nil {
+		h.respondError(c, types.NewNotFoundError("Specialist not found"))
+		return
+	}
+
+	h.Logger.Info("specialists merged",
+		zap.Int("duplicate_id", payload.DuplicateID),
+		zap.Int("survivor_id", payload.SurvivorID),
+		zap.Int("moved_reviews", moved),
+	)
+
+	c.JSON(http.StatusOK, MergeSpecialistsResponse{Specialist: after.Specialist, MovedReviews: moved})
+}
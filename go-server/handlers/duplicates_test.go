@@ -0,0 +1,154 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+// newDuplicateHandler returns a memory handler with a third cardiologist, a duplicate of the first one
+func newDuplicateHandler(t *testing.T) (*Handler, *gin.Engine) {
+	handler := newMemoryHandler(t)
+
+	duplicate := types.Specialist{Name: "Kardio Kosice", SpecialtyID: 1, Location: "POINT(21.2497 48.7172)", Address: "Hlavna 1, Kosice", Staff: "MUDr. Eva Malá"}
+	if _, err := handler.Models.Specialists.InsertSpecialist(context.Background(), duplicate); err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.POST("/admin/specialist/duplicates", handler.GetDuplicateSpecialists)
+	r.POST("/admin/specialist/duplicates/dismiss", handler.DismissDuplicateSpecialists)
+	r.POST("/admin/specialist/merge", handler.MergeSpecialists)
+
+	return handler, r
+}
+
+func TestGetDuplicateSpecialistsHandler_Memory(t *testing.T) {
+	_, r := newDuplicateHandler(t)
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/admin/specialist/duplicates", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response GetDuplicateSpecialistsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Len(t, response.Candidates, 1)
+	assert.Equal(t, "Kardio Košice", response.Candidates[0].Specialist.Name)
+	assert.Equal(t, "Kardio Kosice", response.Candidates[0].Duplicate.Name)
+	assert.Greater(t, response.Candidates[0].Score, 0.5)
+
+	w = post(`{"min_score": 1}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"candidates": []}`, w.Body.String())
+
+	// a dismissed pair is no longer listed
+	req, _ := http.NewRequest("POST", "/admin/specialist/duplicates/dismiss", strings.NewReader(`{"id": 3, "duplicate_id": 1}`))
+	req.Header.Set("Content-Type", "application/json")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	w = post("{}")
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"candidates": []}`, w.Body.String())
+}
+
+func TestMergeSpecialistsHandler_Memory(t *testing.T) {
+	handler, r := newDuplicateHandler(t)
+
+	if _, err := handler.Models.Reviews.InsertReview(context.Background(), types.Review{SpecialistId: 3, Url: "https://example.com/review", Rating: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	post := func(body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/admin/specialist/merge", strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(AdminUserHeader, "jana")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"survivor_id": 1, "survivor_version": 2, "duplicate_id": 3, "duplicate_version": 1}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = post(`{"survivor_id": 1, "survivor_version": 1, "duplicate_id": 3, "duplicate_version": 1}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response MergeSpecialistsResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Equal(t, 1, response.MovedReviews)
+	assert.Equal(t, "MUDr. Eva Malá", response.Specialist.Staff)
+	assert.Equal(t, 2, response.Specialist.Version)
+	assert.Equal(t, types.FieldSourceOverride, response.Specialist.FieldSources["staff"])
+
+	duplicate, _ := handler.Models.Specialists.GetSpecialistByID(context.Background(), 3)
+	assert.Nil(t, duplicate)
+
+	entries, _ := handler.Models.Audit.GetAuditLog(context.Background(), types.AuditEntitySpecialist, 0, 10)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, types.AuditActionDelete, entries[0].Action)
+	assert.Equal(t, 3, entries[0].EntityID)
+	assert.Equal(t, types.AuditActionUpdate, entries[1].Action)
+	assert.Equal(t, "jana", entries[1].Actor)
+
+	w = post(`{"survivor_id": 1, "survivor_version": 2, "duplicate_id": 3, "duplicate_version": 1}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestDuplicateSpecialistsHandlers_Validation(t *testing.T) {
+	_, r := newDuplicateHandler(t)
+
+	tests := []struct {
+		url      string
+		body     string
+		code     int
+		expected string
+	}{
+		{"/admin/specialist/duplicates", `{"min_score": 1.5}`, http.StatusBadRequest, "Invalid payload: min_score must be between 0 and 1"},
+		{"/admin/specialist/duplicates", `{"limit": 501}`, http.StatusBadRequest, "Invalid payload: limit must be between 1 and 500"},
+		{"/admin/specialist/duplicates", `{"limit": "a"}`, http.StatusBadRequest, "Invalid JSON payload"},
+		{"/admin/specialist/duplicates/dismiss", `{}`, http.StatusBadRequest, "Invalid payload: missing id, duplicate_id"},
+		{"/admin/specialist/duplicates/dismiss", `{"id": 1, "duplicate_id": 1}`, http.StatusBadRequest, "Invalid payload: id and duplicate_id must differ"},
+		{"/admin/specialist/duplicates/dismiss", `{"id": 1, "duplicate_id": 42}`, http.StatusNotFound, "Specialist not found"},
+		{"/admin/specialist/merge", `{"survivor_id": 1}`, http.StatusBadRequest, "Invalid payload: missing survivor_version, duplicate_id, duplicate_version"},
+		{"/admin/specialist/merge", `{"survivor_id": 1, "survivor_version": 1, "duplicate_id": 1, "duplicate_version": 1}`, http.StatusBadRequest, "Invalid payload: survivor_id and duplicate_id must differ"},
+		{"/admin/specialist/merge", `{"survivor_id": 42, "survivor_version": 1, "duplicate_id": 1, "duplicate_version": 1}`, http.StatusNotFound, "Specialist not found"},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", test.url, strings.NewReader(test.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, test.code, w.Code, test.body)
+
+		var response ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Errorf("Error unmarshaling response: %v", err)
+		}
+
+		assert.Equal(t, test.expected, response.Error, test.body)
+	}
+}
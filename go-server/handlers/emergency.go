@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"fmt"
+	"net/http"
+	"slices"
+	"strings"
+	"time"
+
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
+	"go.uber.org/zap"
+)
+
+const (
+	emergencyDefaultRadius = 20000
+	emergencyMaxRadius     = 100000
+	emergencyDefaultLimit  = 5
+	emergencyMaxLimit      = 20
+)
+
+type FindNearestEmergencyPayload struct {
+	UserLocation types.LocationInput `json:"user_location" swaggertype:"string" example:"POINT(21.2496774 48.7172272)"`
+	Radius       int                 `json:"radius,omitempty"`
+	Kind         string              `json:"kind,omitempty"`
+	Limit        int                 `json:"limit,omitempty"`
+}
+
+type FindNearestEmergencyResponse struct {
+	CheckedAt time.Time                       `json:"checked_at"`
+	Services  []*types.NearbyEmergencyService `json:"services"`
+}
+
+type SaveEmergencyServicePayload struct {
+	ID        int                    `json:"id,omitempty"`
+	Name      string                 `json:"name"`
+	Kind      string                 `json:"kind"`
+	Location  types.LocationInput    `json:"location" swaggertype:"string" example:"POINT(21.2496774 48.7172272)"`
+	Address   string                 `json:"address"`
+	Telephone string                 `json:"telephone"`
+	Url       string                 `json:"url"`
+	Note      string                 `json:"note"`
+	Shifts    []types.EmergencyShift `json:"shifts"`
+}
+
+type DeleteEmergencyServicePayload struct {
+	ID int `json:"id"`
+}
+
+// @Summary		Find nearest emergency services
+// @Description	Find the emergency and on-call services open right now near the user's location, the nearest first
+// @Description	Kinds: aps (ambulantná pohotovostná služba), er (hospital emergency department), pharmacy (pharmacy on duty)
+// @ID			emergency-nearest
+// @Accept		json
+// @Produce		json
+// @Param		payload	body		FindNearestEmergencyPayload	true	"User location, optional radius in meters (default 20000, max 100000), kind and limit (default 5, max 20)"
+// @Success		200		{object}	FindNearestEmergencyResponse
+// @Failure		400		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Failure		503		{object}	ErrorResponse
+// @Router		/emergency/nearest [post]
+func (h *Handler) FindNearestEmergency(c *gin.Context) {
+	var payload FindNearestEmergencyPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	if payload.UserLocation == "" {
+		h.respondError(c, types.NewMissingFieldError("user_location"))
+		return
+	}
+
+	location, err := parseLocation(payload.UserLocation)
+	if err != nil {
+		h.respondError(c, types.NewInvalidFieldError("user_location", "is not a valid location: "+err.Error()))
+		return
+	}
+
+	if payload.Radius == 0 {
+		payload.Radius = emergencyDefaultRadius
+	}
+	if payload.Radius < 0 || payload.Radius > emergencyMaxRadius {
+		h.respondError(c, types.NewInvalidFieldError("radius", fmt.Sprintf("must be between 1 and %d", emergencyMaxRadius)))
+		return
+	}
+
+	if payload.Kind != "" && !slices.Contains(types.EmergencyKinds, payload.Kind) {
+		h.respondError(c, types.NewInvalidFieldError("kind", "must be one of "+strings.Join(types.EmergencyKinds, ", ")))
+		return
+	}
+
+	if payload.Limit == 0 {
+		payload.Limit = emergencyDefaultLimit
+	}
+	if payload.Limit < 0 || payload.Limit > emergencyMaxLimit {
+		h.respondError(c, types.NewInvalidFieldError("limit", fmt.Sprintf("must be between 1 and %d", emergencyMaxLimit)))
+		return
+	}
+
+	// the opening hours are probed from yesterday to a week ahead, which may cross into another year
+	now := clinicTime(h.now())
+	calendar, err := h.holidayCalendar(c.Request.Context(), now.AddDate(0, 0, -1).Year(), now.Year(), now.AddDate(0, 0, 7).Year())
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	db := h.database(c)
+	if db == nil {
+		return
+	}
+
+	nearby, err := db.GetEmergencyServicesNear(c.Request.Context(), location.WKT(), payload.Radius, payload.Kind)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	open := []*types.NearbyEmergencyService{}
+	for _, n := range nearby {
+		address := n.Service.Address
+		isOpen, until := n.Service.OpenAt(now, func(day time.Time) bool {
+			return calendar.Holiday(day, address) != nil
+		})
+		if !isOpen {
+			continue
+		}
+
+		n.OpenUntil = until
+		if links, err := types.NewNavigationLinks(n.Service.Location, location.WKT()); err == nil {
+			n.Service.Navigation = links
+		}
+		open = append(open, n)
+
+		if len(open) == payload.Limit {
+			break
+		}
+	}
+
+	c.JSON(http.StatusOK, FindNearestEmergencyResponse{CheckedAt: now, Services: open})
+}
+
+// @Summary		Save emergency service
+// @Description	Create an emergency service, or update the one with the id, its rota is replaced
+// @Description	A shift has either a weekday (0 Monday to 6 Sunday, 7 holidays) or a date, and opens and closes times in HH:MM, a shift closing at or before its opening ends the next day
+// @ID			admin-emergency-service-save
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		payload	body		SaveEmergencyServicePayload	true	"Emergency service with its rota"
+// @Success		200		{object}	types.EmergencyService
+// @Failure		400		{object}	ErrorResponse
+// @Failure		401		{object}	ErrorResponse
+// @Failure		404		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Failure		503		{object}	ErrorResponse
+// @Router		/admin/emergency/service [post]
+func (h *Handler) SaveEmergencyService(c *gin.Context) {
+	var payload SaveEmergencyServicePayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	missingParams := []string{}
+	if strings.TrimSpace(payload.Name) == "" {
+		missingParams = append(missingParams, "name")
+	}
+	if payload.Kind == "" {
+		missingParams = append(missingParams, "kind")
+	}
+	if payload.Location == "" {
+		missingParams = append(missingParams, "location")
+	}
+
+	if len(missingParams) > 0 {
+		h.respondError(c, types.NewMissingFieldsError(missingParams))
+		return
+	}
+
+	if !slices.Contains(types.EmergencyKinds, payload.Kind) {
+		h.respondError(c, types.NewInvalidFieldError("kind", "must be one of "+strings.Join(types.EmergencyKinds, ", ")))
+		return
+	}
+
+	location, err := parseLocation(payload.Location)
+	if err != nil {
+		h.respondError(c, types.NewInvalidFieldError("location", "is not a valid location: "+err.Error()))
+		return
+	}
+
+	for i, shift := range payload.Shifts {
+		if err := shift.Validate(); err != nil {
+			h.respondError(c, types.NewValidationError(fmt.Sprintf("Invalid payload: shift %d: %s", i+1, err), types.FieldError{Field: fmt.Sprintf("shifts[%d]", i), Message: err.Error()}))
+			return
+		}
+	}
+
+	service := types.EmergencyService{
+		ID:        payload.ID,
+		Name:      strings.TrimSpace(payload.Name),
+		Kind:      payload.Kind,
+		Location:  location.WKT(),
+		Address:   strings.TrimSpace(payload.Address),
+		Telephone: strings.TrimSpace(payload.Telephone),
+		Url:       strings.TrimSpace(payload.Url),
+		Note:      strings.TrimSpace(payload.Note),
+		Shifts:    payload.Shifts,
+	}
+	if service.Shifts == nil {
+		service.Shifts = []types.EmergencyShift{}
+	}
+
+	db := h.database(c)
+	if db == nil {
+		return
+	}
+
+	var before *types.EmergencyService
+	if service.ID != 0 {
+		before, err = db.GetEmergencyServiceByID(c.Request.Context(), service.ID)
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+
+		if before == nil {
+			h.respondError(c, types.NewNotFoundError("Emergency service not found"))
+			return
+		}
+	}
+
+	ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+		saved := service
+		saved.ID = id
+		if before == nil {
+			return []auditChange{{types.AuditActionCreate, types.AuditEntityEmergencyService, id, nil, saved}}
+		}
+		return []auditChange{{types.AuditActionUpdate, types.AuditEntityEmergencyService, id, before, saved}}
+	})
+
+	id, err := db.SaveEmergencyService(ctx, service)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if id == 0 {
+		h.respondError(c, types.NewNotFoundError("Emergency service not found"))
+		return
+	}
+	logChange()
+
+	service.ID = id
+	h.Logger.Info("emergency service saved", zap.Int("id", id), zap.String("kind", service.Kind), zap.Int("shifts", len(service.Shifts)))
+
+	c.JSON(http.StatusOK, service)
+}
+
+// @Summary		Delete emergency service
+// @Description	Delete an emergency service together with its rota
+// @ID			admin-emergency-service-delete
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		payload	body		DeleteEmergencyServicePayload	true	"Emergency service id"
+// @Success		200		{object}	DeleteEmergencyServicePayload
+// @Failure		400		{object}	ErrorResponse
+// @Failure		401		{object}	ErrorResponse
+// @Failure		404		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Failure		503		{object}	ErrorResponse
+// @Router		/admin/emergency/service/delete [post]
+func (h *Handler) DeleteEmergencyService(c *gin.Context) {
+	var payload DeleteEmergencyServicePayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	if payload.ID == 0 {
+		h.respondError(c, types.NewMissingFieldError("id"))
+		return
+	}
+
+	db := h.database(c)
+	if db == nil {
+		return
+	}
+
+	before, err := db.GetEmergencyServiceByID(c.Request.Context(), payload.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if before == nil {
+		h.respondError(c, types.NewNotFoundError("Emergency service not found"))
+		return
+	}
+
+	ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+		return []auditChange{{types.AuditActionDelete, types.AuditEntityEmergencyService, id, before, nil}}
+	})
+
+	deleted, err := db.DeleteEmergencyService(ctx, payload.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if !deleted {
+		h.respondError(c, types.NewNotFoundError("Emergency service not found"))
+		return
+	}
+	logChange()
+
+	h.Logger.Info("emergency service deleted", zap.Int("id", payload.ID))
+
+	c.JSON(http.StatusOK, payload)
+}
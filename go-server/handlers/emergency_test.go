@@ -0,0 +1,337 @@
+package handlers
+
+import (
+	"database/sql"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+	"time"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/models"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func emergencyServiceRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"id", "name", "kind", "location", "address", "telephone", "url", "note", "distance"}).
+		AddRow(3, "APS Košice", "aps", "POINT(21.25 48.72)", "Rastislavova 43, Košice", "", "", "", 850.5).
+		AddRow(4, "Urgentný príjem UNLP", "er", "POINT(21.24 48.71)", "Trieda SNP 1, Košice", "", "", "", 1100.0).
+		AddRow(5, "Lekáreň Pod Hradom", "pharmacy", "POINT(21.26 48.73)", "Hlavná 2, Košice", "", "", "", 1200.0)
+}
+
+func emergencyShiftRows() *sqlmock.Rows {
+	return sqlmock.NewRows([]string{"service_id", "weekday", "date", "opens", "closes"}).
+		AddRow(3, 0, "", "15:30", "07:00").
+		AddRow(3, 6, "", "00:00", "24:00").
+		AddRow(4, 0, "", "00:00", "00:00").
+		AddRow(4, 6, "", "00:00", "00:00").
+		AddRow(5, nil, "2024-03-04", "08:00", "20:00")
+}
+
+func TestFindNearestEmergencyHandler(t *testing.T) {
+	tests := []struct {
+		name     string
+		now      time.Time
+		expected []int
+		until    []string
+	}{
+		// Monday 10:00 in Bratislava
+		{"morning", time.Date(2024, 3, 4, 9, 0, 0, 0, time.UTC), []int{4, 5}, []string{"2024-03-05T00:00:00+01:00", "2024-03-04T20:00:00+01:00"}},
+		// Monday 21:00 in Bratislava
+		{"evening", time.Date(2024, 3, 4, 20, 0, 0, 0, time.UTC), []int{3, 4}, []string{"2024-03-05T07:00:00+01:00", "2024-03-05T00:00:00+01:00"}},
+		// Easter Monday 10:00, the Sunday shifts apply
+		{"holiday", time.Date(2024, 4, 1, 8, 0, 0, 0, time.UTC), []int{3, 4}, []string{"2024-04-02T00:00:00+02:00", "2024-04-02T00:00:00+02:00"}},
+	}
+
+	for _, test := range tests {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to open sqlmock database: %s", err)
+		}
+
+		mock.ExpectQuery(`SELECT (.+) FROM holiday_override`).WithArgs(2024).WillReturnRows(sqlmock.NewRows([]string{"date", "name", "region", "closed"}))
+		mock.ExpectQuery(`SELECT (.+) FROM emergency_service`).WithArgs("POINT(21.2496774 48.7172272)", 20000, "").WillReturnRows(emergencyServiceRows())
+		mock.ExpectQuery(`SELECT (.+) FROM emergency_shift`).WillReturnRows(emergencyShiftRows())
+
+		now := test.now
+		handler := &Handler{Logger: zap.NewNop(), Models: models.NewModels(db), Now: func() time.Time { return now }}
+
+		req, _ := http.NewRequest("POST", "/emergency/nearest", strings.NewReader(`{"user_location": "POINT(21.2496774 48.7172272)"}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/emergency/nearest", handler.FindNearestEmergency)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, test.name)
+
+		var response FindNearestEmergencyResponse
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			t.Errorf("Error unmarshaling response: %v", err)
+		}
+
+		ids := []int{}
+		until := []string{}
+		for _, service := range response.Services {
+			ids = append(ids, service.Service.ID)
+			until = append(until, service.OpenUntil.Format(time.RFC3339))
+			assert.NotNil(t, service.Service.Navigation, test.name)
+		}
+		assert.Equal(t, test.expected, ids, test.name)
+		assert.Equal(t, test.until, until, test.name)
+		assert.NoError(t, mock.ExpectationsWereMet(), test.name)
+		db.Close()
+	}
+}
+
+func TestFindNearestEmergencyHandler_YearBoundary(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	// New Year's Eve and the 2nd of January are closed, the Sunday shifts apply until the 3rd of January
+	mock.ExpectQuery(`SELECT (.+) FROM holiday_override`).WithArgs(2024).WillReturnRows(sqlmock.NewRows([]string{"date", "name", "region", "closed"}).
+		AddRow("2024-12-31", "Silvester", "", true))
+	mock.ExpectQuery(`SELECT (.+) FROM holiday_override`).WithArgs(2025).WillReturnRows(sqlmock.NewRows([]string{"date", "name", "region", "closed"}).
+		AddRow("2025-01-02", "Deň po Novom roku", "", true))
+	mock.ExpectQuery(`SELECT (.+) FROM emergency_service`).WithArgs("POINT(21.2496774 48.7172272)", 20000, "").WillReturnRows(emergencyServiceRows())
+	mock.ExpectQuery(`SELECT (.+) FROM emergency_shift`).WillReturnRows(emergencyShiftRows())
+
+	handler := &Handler{
+		Logger: zap.NewNop(),
+		Models: models.NewModels(db),
+		// Tuesday 21:00 in Bratislava
+		Now: func() time.Time { return time.Date(2024, 12, 31, 20, 0, 0, 0, time.UTC) },
+	}
+
+	req, _ := http.NewRequest("POST", "/emergency/nearest", strings.NewReader(`{"user_location": "POINT(21.2496774 48.7172272)"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/emergency/nearest", handler.FindNearestEmergency)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response FindNearestEmergencyResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Len(t, response.Services, 2)
+	assert.Equal(t, 4, response.Services[1].Service.ID)
+	assert.Equal(t, "2025-01-03T00:00:00+01:00", response.Services[1].OpenUntil.Format(time.RFC3339))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindNearestEmergencyHandler_Limit(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM holiday_override`).WithArgs(2024).WillReturnRows(sqlmock.NewRows([]string{"date", "name", "region", "closed"}))
+	mock.ExpectQuery(`SELECT (.+) FROM emergency_service`).WithArgs("POINT(21.2496774 48.7172272)", 5000, "er").WillReturnRows(emergencyServiceRows())
+	mock.ExpectQuery(`SELECT (.+) FROM emergency_shift`).WillReturnRows(emergencyShiftRows())
+
+	handler := &Handler{
+		Logger: zap.NewNop(),
+		Models: models.NewModels(db),
+		Now:    func() time.Time { return time.Date(2024, 3, 4, 20, 0, 0, 0, time.UTC) },
+	}
+
+	req, _ := http.NewRequest("POST", "/emergency/nearest", strings.NewReader(`{"user_location": {"lat": 48.7172272, "lon": 21.2496774}, "radius": 5000, "kind": "er", "limit": 1}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/emergency/nearest", handler.FindNearestEmergency)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response FindNearestEmergencyResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Len(t, response.Services, 1)
+	assert.Equal(t, 3, response.Services[0].Service.ID)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindNearestEmergencyHandler_InvalidPayload(t *testing.T) {
+	tests := map[string]string{
+		"{invalid_json}": `{"error":"Invalid JSON payload","code":"invalid_payload"}`,
+		`{}`:             `{"error":"Invalid payload: missing user_location field","code":"invalid_payload","fields":[{"field":"user_location","message":"is required"}]}`,
+		`{"user_location": "POINT(48.72 21.25)"}`:                    `{"error":"Invalid payload: user_location is not a valid location: POINT(48.72 21.25) lies outside the service area but POINT(21.25 48.72) lies inside it, the coordinates look swapped: WKT and GeoJSON list the longitude first, e.g. POINT(21.2496774 48.7172272)","code":"invalid_payload","fields":[{"field":"user_location","message":"is not a valid location: POINT(48.72 21.25) lies outside the service area but POINT(21.25 48.72) lies inside it, the coordinates look swapped: WKT and GeoJSON list the longitude first, e.g. POINT(21.2496774 48.7172272)"}]}`,
+		`{"user_location": "POINT(21.25 48.72)", "radius": 200000}`:  `{"error":"Invalid payload: radius must be between 1 and 100000","code":"invalid_payload","fields":[{"field":"radius","message":"must be between 1 and 100000"}]}`,
+		`{"user_location": "POINT(21.25 48.72)", "kind": "dentist"}`: `{"error":"Invalid payload: kind must be one of aps, er, pharmacy","code":"invalid_payload","fields":[{"field":"kind","message":"must be one of aps, er, pharmacy"}]}`,
+		`{"user_location": "POINT(21.25 48.72)", "limit": 21}`:       `{"error":"Invalid payload: limit must be between 1 and 20","code":"invalid_payload","fields":[{"field":"limit","message":"must be between 1 and 20"}]}`,
+	}
+
+	for payload, expected := range tests {
+		handler := &Handler{Logger: zap.NewNop()}
+
+		req, _ := http.NewRequest("POST", "/emergency/nearest", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/emergency/nearest", handler.FindNearestEmergency)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, payload)
+		assert.JSONEq(t, expected, w.Body.String(), payload)
+	}
+}
+
+func TestSaveEmergencyServiceHandler(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO emergency_service`).WithArgs("APS Košice", "aps", "POINT(21.25 48.72)", "Rastislavova 43, Košice", "", "", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	mock.ExpectExec(`INSERT INTO emergency_shift`).WithArgs(9, 0, nil, "15:30", "07:00").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO admin_audit_log`).WithArgs("admin", "", "create", "emergency_service", 9, nil, sqlmock.AnyArg()).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	handler := &Handler{Logger: zap.NewNop(), Models: models.NewModels(db)}
+
+	payload := `{"name": "APS Košice", "kind": "aps", "location": "POINT(21.25 48.72)", "address": "Rastislavova 43, Košice", "shifts": [{"weekday": 0, "opens": "15:30", "closes": "07:00"}]}`
+	req, _ := http.NewRequest("POST", "/admin/emergency/service", strings.NewReader(payload))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/admin/emergency/service", handler.SaveEmergencyService)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"id":9`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveEmergencyServiceHandler_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM emergency_service WHERE id=\$1`).WithArgs(42).WillReturnError(sql.ErrNoRows)
+
+	handler := &Handler{Logger: zap.NewNop(), Models: models.NewModels(db)}
+
+	req, _ := http.NewRequest("POST", "/admin/emergency/service", strings.NewReader(`{"id": 42, "name": "APS Košice", "kind": "aps", "location": "POINT(21.25 48.72)"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/admin/emergency/service", handler.SaveEmergencyService)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+	assert.JSONEq(t, `{"error":"Emergency service not found","code":"not_found"}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveEmergencyServiceHandler_InvalidPayload(t *testing.T) {
+	tests := map[string]string{
+		"{invalid_json}":  `{"error":"Invalid JSON payload","code":"invalid_payload"}`,
+		`{"kind": "aps"}`: `{"error":"Invalid payload: missing name, location","code":"invalid_payload","fields":[{"field":"name","message":"is required"},{"field":"location","message":"is required"}]}`,
+		`{"name": "APS", "kind": "dentist", "location": "POINT(21.25 48.72)"}`:                                                             `{"error":"Invalid payload: kind must be one of aps, er, pharmacy","code":"invalid_payload","fields":[{"field":"kind","message":"must be one of aps, er, pharmacy"}]}`,
+		`{"name": "APS", "kind": "aps", "location": "Košice"}`:                                                                             `{"error":"Invalid payload: location is not a valid location: invalid location \"Košice\", expected a WKT point, e.g. POINT(21.2496774 48.7172272)","code":"invalid_payload","fields":[{"field":"location","message":"is not a valid location: invalid location \"Košice\", expected a WKT point, e.g. POINT(21.2496774 48.7172272)"}]}`,
+		`{"name": "APS", "kind": "aps", "location": "POINT(21.25 48.72)", "shifts": [{"weekday": 0, "opens": "8:00", "closes": "25:00"}]}`: `{"error":"Invalid payload: shift 1: time \"25:00\" must be in the HH:MM format","code":"invalid_payload","fields":[{"field":"shifts[0]","message":"time \"25:00\" must be in the HH:MM format"}]}`,
+	}
+
+	for payload, expected := range tests {
+		handler := &Handler{Logger: zap.NewNop()}
+
+		req, _ := http.NewRequest("POST", "/admin/emergency/service", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/admin/emergency/service", handler.SaveEmergencyService)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, payload)
+		assert.JSONEq(t, expected, w.Body.String(), payload)
+	}
+}
+
+func TestDeleteEmergencyServiceHandler(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM emergency_service WHERE id=\$1`).WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "kind", "location", "address", "telephone", "url", "note"}).
+			AddRow(9, "APS Košice", "aps", "POINT(21.25 48.72)", "", "", "", ""))
+	mock.ExpectQuery(`SELECT (.+) FROM emergency_shift`).WillReturnRows(sqlmock.NewRows([]string{"service_id", "weekday", "date", "opens", "closes"}))
+	mock.ExpectBegin()
+	mock.ExpectExec(`DELETE FROM emergency_service`).WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO admin_audit_log`).WithArgs("admin", "", "delete", "emergency_service", 9, sqlmock.AnyArg(), nil).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+	mock.ExpectQuery(`SELECT (.+) FROM emergency_service WHERE id=\$1`).WithArgs(10).WillReturnError(sql.ErrNoRows)
+
+	handler := &Handler{Logger: zap.NewNop(), Models: models.NewModels(db)}
+	r := gin.New()
+	r.POST("/admin/emergency/service/delete", handler.DeleteEmergencyService)
+
+	tests := []struct {
+		id       string
+		expected int
+	}{
+		{"9", http.StatusOK},
+		{"10", http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", "/admin/emergency/service/delete", strings.NewReader(`{"id": `+test.id+`}`))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, test.expected, w.Code, test.id)
+	}
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestFindNearestEmergencyHandler_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	req, _ := http.NewRequest("POST", "/emergency/nearest", strings.NewReader(`{"user_location": "POINT(21.25 48.72)"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/emergency/nearest", handler.FindNearestEmergency)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+
+	var response ErrorResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Equal(t, types.ErrorCodeUnavailable, response.Code)
+	assert.Equal(t, "The feature needs the database", response.Error)
+}
@@ -0,0 +1,135 @@
+package handlers
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/hex"
+	"errors"
+	"net/http"
+	"regexp"
+
+	"github.com/acornak/healthcare-poc/geocoding"
+	"github.com/acornak/healthcare-poc/models"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"go.uber.org/zap"
+)
+
+// RequestIDHeader carries the id of a request, clients may send their own to correlate logs
+const RequestIDHeader = "X-Request-ID"
+
+// the gin context key of the request id
+const requestIDKey = "request_id"
+
+// message of the errors whose details are not returned to clients
+const internalErrorMessage = "Something went wrong, please try again later"
+
+// request ids sent by clients are kept only when they are short and printable
+var requestIDRegexp = regexp.MustCompile(`^[A-Za-z0-9._-]{1,64}$`)
+
+/*
+ErrorResponse is the envelope of every error returned by the API
+The struct contains the following fields:
+- Error: the description of the error
+- Code: the machine-readable kind of the error, e.g. not_found
+- Fields: the invalid fields of a rejected request
+- RequestID: the id of the request, to be quoted when reporting a problem
+*/
+type ErrorResponse struct {
+	Error     string             `json:"error"`
+	Code      types.ErrorCode    `json:"code"`
+	Fields    []types.FieldError `json:"fields,omitempty"`
+	RequestID string             `json:"request_id,omitempty"`
+}
+
+// the HTTP status code of every error code
+var errorStatus = map[types.ErrorCode]int{
+	types.ErrorCodeInvalidPayload: http.StatusBadRequest,
+	types.ErrorCodeUnauthorized:   http.StatusUnauthorized,
+	types.ErrorCodeNotFound:       http.StatusNotFound,
+	types.ErrorCodeConflict:       http.StatusConflict,
+	types.ErrorCodeRateLimited:    http.StatusTooManyRequests,
+	types.ErrorCodeInternal:       http.StatusInternalServerError,
+	types.ErrorCodeUpstream:       http.StatusBadGateway,
+	types.ErrorCodeUnavailable:    http.StatusServiceUnavailable,
+	types.ErrorCodeTimeout:        http.StatusGatewayTimeout,
+}
+
+// RequestID assigns an id to the request, reusing the X-Request-ID header when it is valid, and echoes it in the response
+func (h *Handler) RequestID(c *gin.Context) {
+	id := c.GetHeader(RequestIDHeader)
+	if !requestIDRegexp.MatchString(id) {
+		id = newRequestID()
+	}
+
+	c.Set(requestIDKey, id)
+	c.Header(RequestIDHeader, id)
+
+	c.Next()
+}
+
+func newRequestID() string {
+	b := make([]byte, 8)
+	if _, err := rand.Read(b); err != nil {
+		return "unknown"
+	}
+
+	return hex.EncodeToString(b)
+}
+
+/*
+respondError aborts the request with the error envelope of err
+Domain errors keep their code and message, errors without a safe message are logged and returned as internal errors
+*/
+func (h *Handler) respondError(c *gin.Context, err error) {
+	resp := h.errorResponse(err, c.GetString(requestIDKey))
+	c.AbortWithStatusJSON(errorStatus[resp.Code], resp)
+}
+
+// errorResponse returns the envelope of err, logging the errors not shown to the client
+func (h *Handler) errorResponse(err error, requestID string) ErrorResponse {
+	appErr := classifyError(err)
+
+	if appErr.Code == types.ErrorCodeInternal || appErr.Code == types.ErrorCodeUpstream {
+		h.Logger.Error(appErr.Message, zap.String("request_id", requestID), zap.Error(err))
+	}
+
+	return ErrorResponse{
+		Error:     appErr.Message,
+		Code:      appErr.Code,
+		Fields:    appErr.Fields,
+		RequestID: requestID,
+	}
+}
+
+// classifyError maps any error to a domain error, the details of unknown errors are hidden
+func classifyError(err error) *types.Error {
+	var appErr *types.Error
+	var pqErr *pq.Error
+	var rateLimit *geocoding.RateLimitError
+
+	switch {
+	case errors.As(err, &appErr):
+		if _, ok := errorStatus[appErr.Code]; ok {
+			return appErr
+		}
+	case errors.Is(err, models.ErrNotFound):
+		return types.NewNotFoundError("The record was not found")
+	case errors.Is(err, models.ErrStaleVersion):
+		return types.NewConflictError("The record was changed in the meantime, reload it and try again", err)
+	case errors.Is(err, geocoding.ErrNotFound):
+		return types.NewNotFoundError("No geocode data found for the location provided")
+	case errors.As(err, &rateLimit):
+		return types.NewError(types.ErrorCodeRateLimited, "Geocoding rate limit exceeded, please try again later")
+	case errors.Is(err, context.DeadlineExceeded):
+		return types.NewError(types.ErrorCodeTimeout, "The request took too long, please try again later")
+	case errors.Is(err, context.Canceled):
+		return types.NewError(types.ErrorCodeUnavailable, "The request was cancelled")
+	case errors.As(err, &pqErr) && pqErr.Code.Class() == "23":
+		// integrity constraint violations: duplicates, missing references
+		return types.NewConflictError("The change conflicts with the stored data", err)
+	}
+
+	return &types.Error{Code: types.ErrorCodeInternal, Message: internalErrorMessage, Err: err}
+}
@@ -0,0 +1,90 @@
+package handlers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+
+	"github.com/acornak/healthcare-poc/geocoding"
+	"github.com/acornak/healthcare-poc/models"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestClassifyError(t *testing.T) {
+	tests := []struct {
+		err     error
+		code    types.ErrorCode
+		message string
+	}{
+		{types.NewMissingFieldError("query"), types.ErrorCodeInvalidPayload, "Invalid payload: missing query field"},
+		{fmt.Errorf("saving: %w", types.NewNotFoundError("Specialist not found")), types.ErrorCodeNotFound, "Specialist not found"},
+		{models.ErrNotFound, types.ErrorCodeNotFound, "The record was not found"},
+		{fmt.Errorf("updating: %w", models.ErrStaleVersion), types.ErrorCodeConflict, "The record was changed in the meantime, reload it and try again"},
+		{geocoding.ErrNotFound, types.ErrorCodeNotFound, "No geocode data found for the location provided"},
+		{&geocoding.RateLimitError{Provider: "maps.co"}, types.ErrorCodeRateLimited, "Geocoding rate limit exceeded, please try again later"},
+		{context.DeadlineExceeded, types.ErrorCodeTimeout, "The request took too long, please try again later"},
+		{context.Canceled, types.ErrorCodeUnavailable, "The request was cancelled"},
+		{&pq.Error{Code: "23505"}, types.ErrorCodeConflict, "The change conflicts with the stored data"},
+		{&pq.Error{Code: "42P01", Message: `relation "specialties" does not exist`}, types.ErrorCodeInternal, "Something went wrong, please try again later"},
+		{types.NewError("unknown", "Unknown"), types.ErrorCodeInternal, "Something went wrong, please try again later"},
+		{errors.New("sql: connection refused"), types.ErrorCodeInternal, "Something went wrong, please try again later"},
+	}
+
+	for _, test := range tests {
+		appErr := classifyError(test.err)
+		assert.Equal(t, test.code, appErr.Code, test.err.Error())
+		assert.Equal(t, test.message, appErr.Message, test.err.Error())
+		assert.Contains(t, errorStatus, appErr.Code, test.err.Error())
+	}
+}
+
+func TestRequestID(t *testing.T) {
+	handler := &Handler{Logger: zap.NewNop()}
+
+	r := gin.New()
+	r.Use(handler.RequestID)
+	r.GET("/fail", func(c *gin.Context) {
+		handler.respondError(c, errors.New("pq: password authentication failed"))
+	})
+
+	req, _ := http.NewRequest("GET", "/fail", nil)
+	req.Header.Set(RequestIDHeader, "abc-123")
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, "abc-123", w.Header().Get(RequestIDHeader))
+	assert.JSONEq(t, `{"error":"Something went wrong, please try again later","code":"internal_error","request_id":"abc-123"}`, w.Body.String())
+
+	// ids that cannot be logged safely are replaced
+	req, _ = http.NewRequest("GET", "/fail", nil)
+	req.Header.Set(RequestIDHeader, "bad id\n")
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Regexp(t, "^[0-9a-f]{16}$", w.Header().Get(RequestIDHeader))
+	assert.Contains(t, w.Body.String(), w.Header().Get(RequestIDHeader))
+}
+
+func TestRespondError_ValidationFields(t *testing.T) {
+	handler := &Handler{Logger: zap.NewNop()}
+
+	r := gin.New()
+	r.POST("/fail", func(c *gin.Context) {
+		handler.respondError(c, types.NewMissingFieldsError([]string{"name", "location"}))
+	})
+
+	req, _ := http.NewRequest("POST", "/fail", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"Invalid payload: missing name, location","code":"invalid_payload","fields":[{"field":"name","message":"is required"},{"field":"location","message":"is required"}]}`, w.Body.String())
+}
@@ -1,26 +1,48 @@
 package handlers
 
 import (
-	"net/http"
+	"time"
 
+	"github.com/acornak/healthcare-poc/geocoding"
 	"github.com/acornak/healthcare-poc/models"
+	"github.com/acornak/healthcare-poc/timezone"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
 type Handler struct {
-	Logger *zap.Logger
-	Models models.Models
-	Get    func(url string) (resp *http.Response, err error)
+	Logger       *zap.Logger
+	Models       models.Models
+	Geocoder     geocoding.Geocoder
+	GeocodeCache *geocoding.Cache
+	Timezones    *timezone.Finder
+	AdminToken   string
+	Now          func() time.Time
 }
 
 func NewHandler(logger *zap.Logger, models models.Models) *Handler {
 	return &Handler{
 		Logger: logger,
 		Models: models,
-		Get:    http.Get,
 	}
 }
 
-type ErrorResponse struct {
-	Error string `json:"error"`
+// now returns the current time, Now can be replaced in tests
+func (h *Handler) now() time.Time {
+	if h.Now != nil {
+		return h.Now()
+	}
+
+	return time.Now()
+}
+
+// database returns the Postgres storage, with models kept in memory it responds with an unavailable error and returns nil
+func (h *Handler) database(c *gin.Context) *models.DBModel {
+	if h.Models.DB == nil {
+		h.respondError(c, types.NewError(types.ErrorCodeUnavailable, "The feature needs the database"))
+		return nil
+	}
+
+	return h.Models.DB
 }
@@ -0,0 +1,153 @@
+package handlers
+
+import (
+	"net/http"
+	"strconv"
+	"time"
+
+	"github.com/acornak/healthcare-poc/holidays"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	historyDefaultLimit = 50
+	historyMaxLimit     = 500
+)
+
+type GetSpecialistHistoryResponse struct {
+	Changes []*types.SpecialistChange `json:"changes"`
+	AsOf    *types.SpecialistSnapshot `json:"as_of,omitempty"`
+}
+
+/*
+parseAsOf parses a point in time given as an RFC 3339 timestamp or a date
+A date means the end of the day in the local time of the specialists, i.e. everything the API presented that day
+*/
+func parseAsOf(value string) (time.Time, error) {
+	if at, err := time.Parse(time.RFC3339, value); err == nil {
+		return at, nil
+	}
+
+	day, err := time.ParseInLocation(holidays.DateFormat, value, clinicTime(time.Now()).Location())
+	if err != nil {
+		return time.Time{}, err
+	}
+
+	return day.AddDate(0, 0, 1).Add(-time.Nanosecond), nil
+}
+
+// @Summary		Specialist history
+// @Description	Get the changes of a specialist, the newest first, with the changed fields before and after, without who made them
+// @Description	With as_of only the changes made until then are listed and the specialist is returned as it was at that time, a date means the end of the day
+// @Description	Specialists existing before the history was introduced start it with their state at that time, stamped with the epoch
+// @ID			specialist-history
+// @Produce		json
+// @Param		id		path		int		true	"Specialist id"
+// @Param		as_of	query		string	false	"RFC 3339 timestamp or date, e.g. 2024-03-01"
+// @Param		limit	query		int		false	"Maximum number of changes (default 50, max 500)"
+// @Success		200		{object}	GetSpecialistHistoryResponse
+// @Failure		400		{object}	ErrorResponse
+// @Failure		404		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/specialist/{id}/history [get]
+func (h *Handler) GetPublicSpecialistHistory(c *gin.Context) {
+	response, ok := h.specialistHistory(c)
+	if !ok {
+		return
+	}
+
+	// the members of staff are not disclosed to the public
+	for _, change := range response.Changes {
+		change.Actor = ""
+	}
+	if response.AsOf != nil {
+		response.AsOf.Actor = ""
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// @Summary		Specialist history with actors
+// @Description	Get the changes of a specialist made by the scraper, the address check and the admin API, the newest first, with the changed fields before and after and who made them
+// @Description	With as_of only the changes made until then are listed and the specialist is returned as it was at that time, a date means the end of the day
+// @Description	Specialists existing before the history was introduced start it with their state at that time, stamped with the epoch
+// @ID			admin-specialist-history
+// @Produce		json
+// @Security	BearerAuth
+// @Param		id		path		int		true	"Specialist id"
+// @Param		as_of	query		string	false	"RFC 3339 timestamp or date, e.g. 2024-03-01"
+// @Param		limit	query		int		false	"Maximum number of changes (default 50, max 500)"
+// @Success		200		{object}	GetSpecialistHistoryResponse
+// @Failure		400		{object}	ErrorResponse
+// @Failure		401		{object}	ErrorResponse
+// @Failure		404		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/admin/specialist/{id}/history [get]
+func (h *Handler) GetSpecialistHistory(c *gin.Context) {
+	response, ok := h.specialistHistory(c)
+	if !ok {
+		return
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// specialistHistory reads the history of the specialist in the request, it responds with the error when it fails
+func (h *Handler) specialistHistory(c *gin.Context) (*GetSpecialistHistoryResponse, bool) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		h.respondError(c, types.NewValidationError("Invalid specialist id"))
+		return nil, false
+	}
+
+	limit := historyDefaultLimit
+	if value := c.Query("limit"); value != "" {
+		limit, err = strconv.Atoi(value)
+		if err != nil || limit < 1 || limit > historyMaxLimit {
+			h.respondError(c, types.NewInvalidFieldError("limit", "must be between 1 and 500"))
+			return nil, false
+		}
+	}
+
+	var asOf time.Time
+	if value := c.Query("as_of"); value != "" {
+		asOf, err = parseAsOf(value)
+		if err != nil {
+			h.respondError(c, types.NewInvalidFieldError("as_of", "must be an RFC 3339 timestamp or a date in the format YYYY-MM-DD"))
+			return nil, false
+		}
+	}
+
+	changes, err := h.Models.Specialists.GetSpecialistHistory(c.Request.Context(), id, asOf, limit)
+	if err != nil {
+		h.respondError(c, err)
+		return nil, false
+	}
+
+	// the history outlives a deleted specialist, a specialist without any is unknown
+	if len(changes) == 0 && asOf.IsZero() {
+		specialist, err := h.Models.Specialists.GetSpecialistByID(c.Request.Context(), id)
+		if err != nil {
+			h.respondError(c, err)
+			return nil, false
+		}
+
+		if specialist == nil {
+			h.respondError(c, types.NewNotFoundError("Specialist not found"))
+			return nil, false
+		}
+	}
+
+	response := &GetSpecialistHistoryResponse{Changes: changes}
+
+	if !asOf.IsZero() {
+		response.AsOf, err = h.Models.Specialists.GetSpecialistAsOf(c.Request.Context(), id, asOf)
+		if err != nil {
+			h.respondError(c, err)
+			return nil, false
+		}
+	}
+
+	return response, true
+}
@@ -0,0 +1,152 @@
+package handlers
+
+import (
+	"context"
+	"encoding/json"
+	"net/http"
+	"net/http/httptest"
+	"testing"
+	"time"
+
+	"github.com/acornak/healthcare-poc/models"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseAsOf(t *testing.T) {
+	at, err := parseAsOf("2024-03-01T10:00:00Z")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 10, 0, 0, 0, time.UTC), at)
+
+	// a date is the end of the day in Slovakia, in winter an hour ahead of UTC
+	at, err = parseAsOf("2024-03-01")
+	assert.NoError(t, err)
+	assert.Equal(t, time.Date(2024, 3, 1, 22, 59, 59, 999999999, time.UTC), at.UTC())
+
+	_, err = parseAsOf("1. 3. 2024")
+	assert.Error(t, err)
+}
+
+func TestGetSpecialistHistoryHandler_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	// an admin corrects the address after the specialist was scraped
+	ctx := models.WithActor(context.Background(), "jana")
+	_, err := handler.Models.Specialists.UpdateSpecialist(ctx, types.Specialist{ID: 1, Name: "Kardio Košice", SpecialtyID: 1, Location: "POINT(21.2496774 48.7172272)", Address: "Hlavná 10, Košice", Version: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.GET("/admin/specialist/:id/history", handler.GetSpecialistHistory)
+
+	req, _ := http.NewRequest("GET", "/admin/specialist/1/history", nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response GetSpecialistHistoryResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Len(t, response.Changes, 2)
+	assert.Equal(t, "jana", response.Changes[0].Actor)
+	assert.JSONEq(t, `{"address": "Hlavná 10, Košice"}`, string(response.Changes[0].After))
+	assert.Nil(t, response.AsOf)
+
+	req, _ = http.NewRequest("GET", "/admin/specialist/1/history?as_of="+time.Now().Add(time.Hour).Format(time.RFC3339)+"&limit=1", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	response = GetSpecialistHistoryResponse{}
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Len(t, response.Changes, 1)
+	assert.Equal(t, "Hlavná 10, Košice", response.AsOf.Specialist.Address)
+	assert.Equal(t, "jana", response.AsOf.Actor)
+
+	// the specialist did not exist yet
+	req, _ = http.NewRequest("GET", "/admin/specialist/1/history?as_of=2020-01-01", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"changes": []}`, w.Body.String())
+}
+
+func TestGetPublicSpecialistHistoryHandler_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	ctx := models.WithActor(context.Background(), "jana")
+	_, err := handler.Models.Specialists.UpdateSpecialist(ctx, types.Specialist{ID: 1, Name: "Kardio Košice", SpecialtyID: 1, Location: "POINT(21.2496774 48.7172272)", Address: "Hlavná 10, Košice", Version: 1})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.GET("/specialist/:id/history", handler.GetPublicSpecialistHistory)
+
+	req, _ := http.NewRequest("GET", "/specialist/1/history?as_of="+time.Now().Add(time.Hour).Format(time.RFC3339), nil)
+	w := httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NotContains(t, w.Body.String(), "actor")
+	assert.NotContains(t, w.Body.String(), "jana")
+
+	var response GetSpecialistHistoryResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Len(t, response.Changes, 2)
+	assert.JSONEq(t, `{"address": "Hlavná 10, Košice"}`, string(response.Changes[0].After))
+	assert.Equal(t, "Hlavná 10, Košice", response.AsOf.Specialist.Address)
+
+	req, _ = http.NewRequest("GET", "/specialist/42/history", nil)
+	w = httptest.NewRecorder()
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestGetSpecialistHistoryHandler_Errors(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	r := gin.New()
+	r.GET("/admin/specialist/:id/history", handler.GetSpecialistHistory)
+
+	tests := []struct {
+		url      string
+		code     int
+		expected string
+	}{
+		{"/admin/specialist/abc/history", http.StatusBadRequest, "Invalid specialist id"},
+		{"/admin/specialist/1/history?limit=0", http.StatusBadRequest, "Invalid payload: limit must be between 1 and 500"},
+		{"/admin/specialist/1/history?limit=501", http.StatusBadRequest, "Invalid payload: limit must be between 1 and 500"},
+		{"/admin/specialist/1/history?as_of=yesterday", http.StatusBadRequest, "Invalid payload: as_of must be an RFC 3339 timestamp or a date in the format YYYY-MM-DD"},
+		{"/admin/specialist/42/history", http.StatusNotFound, "Specialist not found"},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("GET", test.url, nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, test.code, w.Code, test.url)
+
+		var response ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Errorf("Error unmarshaling response: %v", err)
+		}
+
+		assert.Equal(t, test.expected, response.Error, test.url)
+	}
+}
@@ -1,105 +1,258 @@
 package handlers
 
 import (
-	"encoding/json"
+	"context"
+	"errors"
 	"fmt"
 	"net/http"
-	"os"
 
+	"github.com/acornak/healthcare-poc/geocoding"
+	"github.com/acornak/healthcare-poc/textutil"
+	"github.com/acornak/healthcare-poc/types"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
 
-type wktResult struct {
-	Lat string `json:"lat"`
-	Lon string `json:"lon"`
-}
+const (
+	geocodeDefaultLimit = 5
+	geocodeMaxLimit     = 10
+	// the best candidate is ambiguous when the next one is at least this close to its importance
+	geocodeAmbiguityMargin = 0.1
+
+	geocodeBatchMaxQueries  = 500
+	geocodeBatchConcurrency = 4
+)
 
 type GetWKTLocationPayload struct {
 	UserLocation string `json:"user_location"`
+	Limit        int    `json:"limit,omitempty"`
+}
+
+type LocationCandidate struct {
+	WKTLocation string             `json:"wkt_location"`
+	DisplayName string             `json:"display_name"`
+	Type        string             `json:"type,omitempty"`
+	Importance  float64            `json:"importance,omitempty"`
+	BoundingBox *types.BoundingBox `json:"bounding_box,omitempty"`
 }
 
 type GetWKTLocationResponse struct {
-	WKTLocation string `json:"wkt_location"`
+	WKTLocation string               `json:"wkt_location"`
+	Ambiguous   bool                 `json:"ambiguous"`
+	Candidates  []*LocationCandidate `json:"candidates"`
 }
 
 // @Summary		Get WKT location
 // @Description	Get the WKT location based on the user's location
+// @Description	wkt_location is the best match, candidates lists up to limit matches in order of relevance
+// @Description	ambiguous is set when the best match is not clearly better than the next one, the user should pick a candidate
 // @ID			location
 // @Accept		json
 // @Produce		json
-// @Param		payload	body		GetWKTLocationPayload	true	"User location"
-// @Success		200		{object}	SuccessResponse
+// @Param		payload	body		GetWKTLocationPayload	true	"User location and optional number of candidates"
+// @Success		200		{object}	GetWKTLocationResponse
 // @Failure		400		{object}	ErrorResponse
+// @Failure		404		{object}	ErrorResponse
+// @Failure		429		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Failure		502		{object}	ErrorResponse
+// @Failure		503		{object}	ErrorResponse
 // @Router		/location/wkt [post]
 func (h *Handler) GetWKTLocation(c *gin.Context) {
 	var payload GetWKTLocationPayload
-	var errResp ErrorResponse
 
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		errResp.Error = "Invalid JSON payload"
-		c.JSON(http.StatusBadRequest, errResp)
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
 		return
 	}
 
 	if payload.UserLocation == "" {
-		errResp.Error = "Invalid payload: missing user_location field"
-		c.JSON(http.StatusBadRequest, errResp)
+		h.respondError(c, types.NewMissingFieldError("user_location"))
 		return
 	}
 
-	apiKey := os.Getenv("GEOCODE_API_KEY")
-	if apiKey == "" {
-		h.Logger.Error("API key is required")
-		errResp.Error = "Something went wrong, please try again later"
-		c.JSON(http.StatusInternalServerError, errResp)
+	if payload.Limit < 0 || payload.Limit > geocodeMaxLimit {
+		h.respondError(c, types.NewInvalidFieldError("limit", fmt.Sprintf("must be between 1 and %d", geocodeMaxLimit)))
 		return
 	}
 
-	geocodeUrl := os.Getenv("GEOCODE_URL")
-	if geocodeUrl == "" {
-		h.Logger.Error("Geocode URL is required")
-		errResp.Error = "Something went wrong, please try again later"
-		c.JSON(http.StatusInternalServerError, errResp)
+	limit := payload.Limit
+	if limit == 0 {
+		limit = geocodeDefaultLimit
+	}
+
+	if h.Geocoder == nil {
+		h.respondError(c, errors.New("geocoder is not configured"))
 		return
 	}
 
-	geocodeResp, err := h.Get(fmt.Sprintf("%s/search?q=%s&api_key=%s", geocodeUrl, payload.UserLocation, apiKey))
+	places, err := h.Geocoder.Search(c.Request.Context(), payload.UserLocation)
 	if err != nil {
-		h.Logger.Error("Failed to get geocode location", zap.Error(err))
-		errResp.Error = "Failed to get geocode location"
-		c.JSON(http.StatusInternalServerError, errResp)
+		h.respondError(c, h.geocodeError(payload.UserLocation, err))
 		return
 	}
 
-	if geocodeResp.StatusCode != http.StatusOK {
-		h.Logger.Error("Failed to get geocode location", zap.Any("status_code", geocodeResp.StatusCode))
-		errResp.Error = "Failed to get geocode location"
-		c.JSON(http.StatusInternalServerError, errResp)
+	c.JSON(http.StatusOK, locationResponse(places, limit))
+}
+
+type GetWKTLocationBatchPayload struct {
+	Queries []string `json:"queries"`
+	Limit   int      `json:"limit,omitempty"`
+}
+
+type WKTLocationBatchResult struct {
+	Query  string                  `json:"query"`
+	Status int                     `json:"status"`
+	Error  string                  `json:"error,omitempty"`
+	Code   types.ErrorCode         `json:"code,omitempty"`
+	Result *GetWKTLocationResponse `json:"result,omitempty"`
+}
+
+type GetWKTLocationBatchResponse struct {
+	Succeeded int                       `json:"succeeded"`
+	Failed    int                       `json:"failed"`
+	Results   []*WKTLocationBatchResult `json:"results"`
+}
+
+// @Summary		Get WKT locations in batch
+// @Description	Geocode up to 500 locations at once, e.g. a list of municipalities
+// @Description	Every query gets its own result with the status and body /location/wkt would answer with
+// @Description	Queries differing only in case, diacritics or punctuation are geocoded once
+// @ID			location-batch
+// @Accept		json
+// @Produce		json
+// @Param		payload	body		GetWKTLocationBatchPayload	true	"Locations and optional number of candidates per location"
+// @Success		200		{object}	GetWKTLocationBatchResponse
+// @Failure		400		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/location/wkt/batch [post]
+func (h *Handler) GetWKTLocationBatch(c *gin.Context) {
+	var payload GetWKTLocationBatchPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
 		return
 	}
-	defer geocodeResp.Body.Close()
 
-	var geocodeData []wktResult
-	if err := json.NewDecoder(geocodeResp.Body).Decode(&geocodeData); err != nil {
-		h.Logger.Error("Failed to decode geocode response", zap.Error(err))
-		errResp.Error = "Failed to get geocode location"
-		c.JSON(http.StatusInternalServerError, errResp)
+	if len(payload.Queries) == 0 {
+		h.respondError(c, types.NewMissingFieldError("queries"))
 		return
 	}
 
-	if len(geocodeData) == 0 {
-		h.Logger.Error("No geocode data found for the location", zap.Any("location", payload.UserLocation))
-		errResp.Error = "No geocode data found for the location provided"
-		c.JSON(http.StatusOK, errResp)
+	if len(payload.Queries) > geocodeBatchMaxQueries {
+		h.respondError(c, types.NewValidationError(fmt.Sprintf("Invalid payload: at most %d queries are allowed", geocodeBatchMaxQueries)))
 		return
 	}
 
-	c.JSON(http.StatusOK, GetWKTLocationResponse{WKTLocation: fmt.Sprintf("POINT(%s %s)", geocodeData[0].Lon, geocodeData[0].Lat)})
+	if payload.Limit < 0 || payload.Limit > geocodeMaxLimit {
+		h.respondError(c, types.NewInvalidFieldError("limit", fmt.Sprintf("must be between 1 and %d", geocodeMaxLimit)))
+		return
+	}
+
+	limit := payload.Limit
+	if limit == 0 {
+		limit = geocodeDefaultLimit
+	}
+
+	if h.Geocoder == nil {
+		h.respondError(c, errors.New("geocoder is not configured"))
+		return
+	}
+
+	// every distinct query is geocoded once, the results are shared by its duplicates
+	keys := make([]string, len(payload.Queries))
+	unique := []string{}
+	seen := make(map[string]bool)
+	for i, query := range payload.Queries {
+		keys[i] = textutil.Normalize(query)
+		if keys[i] != "" && !seen[keys[i]] {
+			seen[keys[i]] = true
+			unique = append(unique, query)
+		}
+	}
+
+	found := make([][]*types.Place, len(unique))
+	errs := geocoding.RunBatch(c.Request.Context(), len(unique), geocoding.BatchOptions{Concurrency: geocodeBatchConcurrency}, func(i int) error {
+		places, err := h.Geocoder.Search(c.Request.Context(), unique[i])
+		found[i] = places
+		return err
+	})
+
+	byKey := make(map[string]*WKTLocationBatchResult, len(unique))
+	for i, query := range unique {
+		result := &WKTLocationBatchResult{Status: http.StatusOK}
+		if errs[i] != nil {
+			errResp := h.errorResponse(h.geocodeError(query, errs[i]), c.GetString(requestIDKey))
+			result.Status, result.Error, result.Code = errorStatus[errResp.Code], errResp.Error, errResp.Code
+		} else {
+			result.Result = locationResponse(found[i], limit)
+		}
+		byKey[textutil.Normalize(query)] = result
+	}
+
+	response := GetWKTLocationBatchResponse{Results: make([]*WKTLocationBatchResult, 0, len(payload.Queries))}
+	for i, query := range payload.Queries {
+		result := WKTLocationBatchResult{Status: http.StatusBadRequest, Error: "Invalid payload: empty query", Code: types.ErrorCodeInvalidPayload}
+		if shared, ok := byKey[keys[i]]; ok {
+			result = *shared
+		}
+		result.Query = query
+
+		if result.Status == http.StatusOK {
+			response.Succeeded++
+		} else {
+			response.Failed++
+		}
+		response.Results = append(response.Results, &result)
+	}
+
+	c.JSON(http.StatusOK, response)
+}
+
+// locationResponse returns the best geocoded places as the /location/wkt response
+func locationResponse(places []*types.Place, limit int) *GetWKTLocationResponse {
+	if len(places) > limit {
+		places = places[:limit]
+	}
+
+	candidates := make([]*LocationCandidate, 0, len(places))
+	for _, place := range places {
+		candidates = append(candidates, &LocationCandidate{
+			WKTLocation: fmt.Sprintf("POINT(%s %s)", formatCoordinate(place.Lon), formatCoordinate(place.Lat)),
+			DisplayName: place.DisplayName,
+			Type:        place.Type,
+			Importance:  place.Importance,
+			BoundingBox: place.BoundingBox,
+		})
+	}
+
+	return &GetWKTLocationResponse{
+		WKTLocation: candidates[0].WKTLocation,
+		Ambiguous:   isAmbiguous(places),
+		Candidates:  candidates,
+	}
+}
+
+// geocodeError logs a failed geocoding of the query and returns the error shown to the client
+func (h *Handler) geocodeError(query string, err error) error {
+	var rateLimit *geocoding.RateLimitError
+
+	switch {
+	case errors.Is(err, geocoding.ErrNotFound):
+		h.Logger.Info("No geocode data found for the location", zap.String("location", query))
+		return err
+	case errors.As(err, &rateLimit):
+		h.Logger.Warn("Geocoding provider rate limit exceeded", zap.String("location", query), zap.Error(err))
+		return err
+	case errors.Is(err, context.Canceled), errors.Is(err, context.DeadlineExceeded):
+		return types.NewError(types.ErrorCodeUnavailable, "Geocoding was cancelled")
+	}
+
+	return types.NewUpstreamError("Failed to get geocode location", err)
 }
 
 type GetAddressFromWKTPayload struct {
-	WKTLocation string `json:"wkt_location"`
+	WKTLocation types.LocationInput `json:"wkt_location" swaggertype:"string" example:"POINT(21.2496774 48.7172272)"`
 }
 
 type GetAddressFromWKTResponse struct {
@@ -107,84 +260,48 @@ type GetAddressFromWKTResponse struct {
 }
 
 // @Summary		Get address from WKT
-// @Description	Get the address based on the WKT location
+// @Description	Get the address based on the location, given as WKT, EWKT, GeoJSON Point or {lat, lon} object
 // @ID			address
 // @Accept		json
 // @Produce		json
 // @Param		payload	body		GetAddressFromWKTPayload	true	"WKT location"
 // @Success		200		{object}	GetAddressFromWKTResponse
 // @Failure		400		{object}	ErrorResponse
+// @Failure		404		{object}	ErrorResponse
+// @Failure		429		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Failure		502		{object}	ErrorResponse
+// @Failure		503		{object}	ErrorResponse
 // @Router		/location/address [post]
 func (h *Handler) GetAddressFromWKT(c *gin.Context) {
 	var payload GetAddressFromWKTPayload
-	var errResp ErrorResponse
 
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		errResp.Error = "Invalid JSON payload"
-		c.JSON(http.StatusBadRequest, errResp)
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
 		return
 	}
 
 	if payload.WKTLocation == "" {
-		errResp.Error = "Invalid payload: missing wkt_location field"
-		c.JSON(http.StatusBadRequest, errResp)
-		return
-	}
-
-	apiKey := os.Getenv("GEOCODE_API_KEY")
-	if apiKey == "" {
-		h.Logger.Error("API key is required")
-		errResp.Error = "Something went wrong, please try again later"
-		c.JSON(http.StatusInternalServerError, errResp)
+		h.respondError(c, types.NewMissingFieldError("wkt_location"))
 		return
 	}
 
-	geocodeUrl := os.Getenv("GEOCODE_URL")
-	if geocodeUrl == "" {
-		h.Logger.Error("Geocode URL is required")
-		errResp.Error = "Something went wrong, please try again later"
-		c.JSON(http.StatusInternalServerError, errResp)
-		return
-	}
-
-	lon, lat, err := getLatAndLonFromWKT(payload.WKTLocation)
+	location, err := parseLocation(payload.WKTLocation)
 	if err != nil {
-		h.Logger.Error("Failed to get lat lon from WKT", zap.Error(err))
-		errResp.Error = "Param 'wkt_location' is not a valid WKT location"
-		c.JSON(http.StatusInternalServerError, errResp)
+		h.respondError(c, types.NewValidationError("Param 'wkt_location' is not a valid location: "+err.Error()))
 		return
 	}
 
-	geocodeResp, err := h.Get(fmt.Sprintf("%s/reverse?lat=%s&lon=%s&api_key=%s", geocodeUrl, lat, lon, apiKey))
-	if err != nil {
-		h.Logger.Error("Failed to get geocode location", zap.Error(err))
-		errResp.Error = "Failed to get geocode location"
-		c.JSON(http.StatusInternalServerError, errResp)
+	if h.Geocoder == nil {
+		h.respondError(c, errors.New("geocoder is not configured"))
 		return
 	}
 
-	if geocodeResp.StatusCode != http.StatusOK {
-		h.Logger.Error("Failed to get geocode location", zap.Any("status_code", geocodeResp.StatusCode))
-		errResp.Error = "Failed to get geocode location"
-		c.JSON(http.StatusInternalServerError, errResp)
-		return
-	}
-	defer geocodeResp.Body.Close()
-
-	var geocodeData GetAddressFromWKTResponse
-	if err := json.NewDecoder(geocodeResp.Body).Decode(&geocodeData); err != nil {
-		h.Logger.Error("Failed to decode geocode response", zap.Error(err))
-		errResp.Error = "Failed to get geocode location"
-		c.JSON(http.StatusInternalServerError, errResp)
-		return
-	}
-
-	if geocodeData.Address == "" {
-		h.Logger.Error("No geocode data found for the location", zap.Any("location", payload.WKTLocation))
-		errResp.Error = "No geocode data found for the location provided"
-		c.JSON(http.StatusOK, errResp)
+	place, err := h.Geocoder.Reverse(c.Request.Context(), location.Lat, location.Lon)
+	if err != nil {
+		h.respondError(c, h.geocodeError(location.WKT(), err))
 		return
 	}
 
-	c.JSON(http.StatusOK, GetAddressFromWKTResponse{Address: geocodeData.Address})
+	c.JSON(http.StatusOK, GetAddressFromWKTResponse{Address: place.DisplayName})
 }
@@ -7,10 +7,11 @@ import (
 	"io"
 	"net/http"
 	"net/http/httptest"
-	"os"
 	"strings"
 	"testing"
 
+	"github.com/acornak/healthcare-poc/geocoding"
+	"github.com/acornak/healthcare-poc/types"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -90,95 +91,7 @@ func TestGetWKTLocationHandler_IncompletePayload(t *testing.T) {
 	assert.Equal(t, "Invalid payload: missing user_location field", response.Error)
 }
 
-func TestGetWKTLocationHandler_MissingGeocodeApiKey(t *testing.T) {
-	os.Unsetenv("GEOCODE_API_KEY")
-
-	r := gin.New()
-
-	logger, err := zap.NewProduction()
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	handler := &Handler{
-		Logger: logger,
-	}
-
-	payload := GetWKTLocationPayload{UserLocation: "New York, NY"}
-
-	payloadJSON, err := json.Marshal(payload)
-	if err != nil {
-		t.Fatal(err)
-	}
-	req, err := http.NewRequest("POST", "/location/wkt", bytes.NewBuffer(payloadJSON))
-	if err != nil {
-		t.Fatal(err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	w := httptest.NewRecorder()
-
-	r.POST("/location/wkt", handler.GetWKTLocation)
-	r.ServeHTTP(w, req)
-
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-
-	var response ErrorResponse
-	err = json.Unmarshal(w.Body.Bytes(), &response)
-	if err != nil {
-		t.Errorf("Error unmarshaling response: %v", err)
-	}
-
-	assert.Equal(t, "Something went wrong, please try again later", response.Error)
-}
-
-func TestGetWKTLocationHandler_MissingGeocodeUrl(t *testing.T) {
-	os.Setenv("GEOCODE_API_KEY", "some-key")
-	os.Unsetenv("GEOCODE_URL")
-
-	r := gin.New()
-
-	logger, err := zap.NewProduction()
-	if err != nil {
-		t.Fatal(err)
-	}
-
-	handler := &Handler{
-		Logger: logger,
-	}
-
-	payload := GetWKTLocationPayload{UserLocation: "New York, NY"}
-
-	payloadJSON, err := json.Marshal(payload)
-	if err != nil {
-		t.Fatal(err)
-	}
-	req, err := http.NewRequest("POST", "/location/wkt", bytes.NewBuffer(payloadJSON))
-	if err != nil {
-		t.Fatal(err)
-	}
-	req.Header.Set("Content-Type", "application/json")
-
-	w := httptest.NewRecorder()
-
-	r.POST("/location/wkt", handler.GetWKTLocation)
-	r.ServeHTTP(w, req)
-
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
-
-	var response ErrorResponse
-	err = json.Unmarshal(w.Body.Bytes(), &response)
-	if err != nil {
-		t.Errorf("Error unmarshaling response: %v", err)
-	}
-
-	assert.Equal(t, "Something went wrong, please try again later", response.Error)
-}
-
 func TestGetWKTLocationHandler_GetError(t *testing.T) {
-	os.Setenv("GEOCODE_API_KEY", "some-key")
-	os.Setenv("GEOCODE_URL", "http://geocode.url")
-
 	r := gin.New()
 
 	logger, err := zap.NewProduction()
@@ -187,8 +100,8 @@ func TestGetWKTLocationHandler_GetError(t *testing.T) {
 	}
 
 	handler := &Handler{
-		Logger: logger,
-		Get:    func(url string) (*http.Response, error) { return nil, errors.New("http get error") },
+		Logger:   logger,
+		Geocoder: geocoding.NewMapsCo("http://geocode.url", "some-key", func(req *http.Request) (*http.Response, error) { return nil, errors.New("http get error") }),
 	}
 
 	payload := GetWKTLocationPayload{UserLocation: "New York, NY"}
@@ -208,7 +121,7 @@ func TestGetWKTLocationHandler_GetError(t *testing.T) {
 	r.POST("/location/wkt", handler.GetWKTLocation)
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusBadGateway, w.Code)
 
 	var response ErrorResponse
 	err = json.Unmarshal(w.Body.Bytes(), &response)
@@ -217,12 +130,10 @@ func TestGetWKTLocationHandler_GetError(t *testing.T) {
 	}
 
 	assert.Equal(t, "Failed to get geocode location", response.Error)
+	assert.Equal(t, types.ErrorCodeUpstream, response.Code)
 }
 
 func TestGetWKTLocationHandler_GetInternalServerError(t *testing.T) {
-	os.Setenv("GEOCODE_API_KEY", "some-key")
-	os.Setenv("GEOCODE_URL", "http://geocode.url")
-
 	r := gin.New()
 
 	logger, err := zap.NewProduction()
@@ -232,9 +143,9 @@ func TestGetWKTLocationHandler_GetInternalServerError(t *testing.T) {
 
 	handler := &Handler{
 		Logger: logger,
-		Get: func(url string) (*http.Response, error) {
+		Geocoder: geocoding.NewMapsCo("http://geocode.url", "some-key", func(req *http.Request) (*http.Response, error) {
 			return &http.Response{StatusCode: http.StatusInternalServerError}, nil
-		},
+		}),
 	}
 
 	payload := GetWKTLocationPayload{UserLocation: "New York, NY"}
@@ -254,7 +165,7 @@ func TestGetWKTLocationHandler_GetInternalServerError(t *testing.T) {
 	r.POST("/location/wkt", handler.GetWKTLocation)
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusBadGateway, w.Code)
 
 	var response ErrorResponse
 	err = json.Unmarshal(w.Body.Bytes(), &response)
@@ -263,12 +174,10 @@ func TestGetWKTLocationHandler_GetInternalServerError(t *testing.T) {
 	}
 
 	assert.Equal(t, "Failed to get geocode location", response.Error)
+	assert.Equal(t, types.ErrorCodeUpstream, response.Code)
 }
 
 func TestGetWKTLocationHandler_ErrorDecodingBody(t *testing.T) {
-	os.Setenv("GEOCODE_API_KEY", "some-key")
-	os.Setenv("GEOCODE_URL", "http://geocode.url")
-
 	r := gin.New()
 
 	logger, err := zap.NewProduction()
@@ -278,12 +187,12 @@ func TestGetWKTLocationHandler_ErrorDecodingBody(t *testing.T) {
 
 	handler := &Handler{
 		Logger: logger,
-		Get: func(url string) (*http.Response, error) {
+		Geocoder: geocoding.NewMapsCo("http://geocode.url", "some-key", func(req *http.Request) (*http.Response, error) {
 			return &http.Response{
 				StatusCode: http.StatusOK,
 				Body:       io.NopCloser(strings.NewReader("{invalid_json}")),
 			}, nil
-		},
+		}),
 	}
 
 	payload := GetWKTLocationPayload{UserLocation: "New York, NY"}
@@ -303,7 +212,7 @@ func TestGetWKTLocationHandler_ErrorDecodingBody(t *testing.T) {
 	r.POST("/location/wkt", handler.GetWKTLocation)
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusBadGateway, w.Code)
 
 	var response ErrorResponse
 	err = json.Unmarshal(w.Body.Bytes(), &response)
@@ -312,12 +221,10 @@ func TestGetWKTLocationHandler_ErrorDecodingBody(t *testing.T) {
 	}
 
 	assert.Equal(t, "Failed to get geocode location", response.Error)
+	assert.Equal(t, types.ErrorCodeUpstream, response.Code)
 }
 
 func TestGetWKTLocationHandler_EmptyGeocodeResponse(t *testing.T) {
-	os.Setenv("GEOCODE_API_KEY", "some-key")
-	os.Setenv("GEOCODE_URL", "http://geocode.url")
-
 	r := gin.New()
 
 	logger, err := zap.NewProduction()
@@ -327,12 +234,12 @@ func TestGetWKTLocationHandler_EmptyGeocodeResponse(t *testing.T) {
 
 	handler := &Handler{
 		Logger: logger,
-		Get: func(url string) (*http.Response, error) {
+		Geocoder: geocoding.NewMapsCo("http://geocode.url", "some-key", func(req *http.Request) (*http.Response, error) {
 			return &http.Response{
 				StatusCode: http.StatusOK,
 				Body:       io.NopCloser(bytes.NewReader([]byte(`[]`))),
 			}, nil
-		},
+		}),
 	}
 
 	payload := GetWKTLocationPayload{UserLocation: "New York, NY"}
@@ -352,7 +259,7 @@ func TestGetWKTLocationHandler_EmptyGeocodeResponse(t *testing.T) {
 	r.POST("/location/wkt", handler.GetWKTLocation)
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, http.StatusNotFound, w.Code)
 
 	var response ErrorResponse
 	err = json.Unmarshal(w.Body.Bytes(), &response)
@@ -364,9 +271,6 @@ func TestGetWKTLocationHandler_EmptyGeocodeResponse(t *testing.T) {
 }
 
 func TestGetWKTLocationHandler_Success(t *testing.T) {
-	os.Setenv("GEOCODE_API_KEY", "some-key")
-	os.Setenv("GEOCODE_URL", "http://geocode.url")
-
 	r := gin.New()
 
 	logger, err := zap.NewProduction()
@@ -374,15 +278,9 @@ func TestGetWKTLocationHandler_Success(t *testing.T) {
 		t.Fatal(err)
 	}
 
-	geocodeResp := []wktResult{
-		{
-			Lat: "12.34567",
-			Lon: "-12.34567",
-		},
-		{
-			Lat: "98.76543",
-			Lon: "-98.76543",
-		},
+	geocodeResp := []map[string]string{
+		{"lat": "12.34567", "lon": "-12.34567"},
+		{"lat": "98.76543", "lon": "-98.76543"},
 	}
 
 	geocodeDataJSON, err := json.Marshal(geocodeResp)
@@ -392,12 +290,12 @@ func TestGetWKTLocationHandler_Success(t *testing.T) {
 
 	handler := &Handler{
 		Logger: logger,
-		Get: func(url string) (*http.Response, error) {
+		Geocoder: geocoding.NewMapsCo("http://geocode.url", "some-key", func(req *http.Request) (*http.Response, error) {
 			return &http.Response{
 				StatusCode: http.StatusOK,
 				Body:       io.NopCloser(bytes.NewReader(geocodeDataJSON)),
 			}, nil
-		},
+		}),
 	}
 
 	payload := GetWKTLocationPayload{UserLocation: "New York, NY"}
@@ -503,9 +401,7 @@ func TestGetAddressFromWKTHandler_IncompletePayload(t *testing.T) {
 	assert.Equal(t, "Invalid payload: missing wkt_location field", response.Error)
 }
 
-func TestGetAddressFromWKTHandler_MissingGeocodeApiKey(t *testing.T) {
-	os.Unsetenv("GEOCODE_API_KEY")
-
+func TestGetAddressFromWKTHandler_InvalidWKTLocation(t *testing.T) {
 	r := gin.New()
 
 	logger, err := zap.NewProduction()
@@ -517,7 +413,7 @@ func TestGetAddressFromWKTHandler_MissingGeocodeApiKey(t *testing.T) {
 		Logger: logger,
 	}
 
-	payload := GetAddressFromWKTPayload{WKTLocation: "POINT(-12.34567 12.34567)"}
+	payload := GetAddressFromWKTPayload{WKTLocation: "POINT(-12.34567)"}
 
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
@@ -534,7 +430,7 @@ func TestGetAddressFromWKTHandler_MissingGeocodeApiKey(t *testing.T) {
 	r.POST("/location/address", handler.GetAddressFromWKT)
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusBadRequest, w.Code)
 
 	var response ErrorResponse
 	err = json.Unmarshal(w.Body.Bytes(), &response)
@@ -542,13 +438,10 @@ func TestGetAddressFromWKTHandler_MissingGeocodeApiKey(t *testing.T) {
 		t.Errorf("Error unmarshaling response: %v", err)
 	}
 
-	assert.Equal(t, "Something went wrong, please try again later", response.Error)
+	assert.Equal(t, "Param 'wkt_location' is not a valid location: WKT point must have 2 coordinates, got 1", response.Error)
 }
 
-func TestGetAddressFromWKTHandler_MissingGeocodeUrl(t *testing.T) {
-	os.Setenv("GEOCODE_API_KEY", "some-key")
-	os.Unsetenv("GEOCODE_URL")
-
+func TestGetAddressFromWKTHandler_GetError(t *testing.T) {
 	r := gin.New()
 
 	logger, err := zap.NewProduction()
@@ -557,7 +450,8 @@ func TestGetAddressFromWKTHandler_MissingGeocodeUrl(t *testing.T) {
 	}
 
 	handler := &Handler{
-		Logger: logger,
+		Logger:   logger,
+		Geocoder: geocoding.NewMapsCo("http://geocode.url", "some-key", func(req *http.Request) (*http.Response, error) { return nil, errors.New("http get error") }),
 	}
 
 	payload := GetAddressFromWKTPayload{WKTLocation: "POINT(-12.34567 12.34567)"}
@@ -577,7 +471,7 @@ func TestGetAddressFromWKTHandler_MissingGeocodeUrl(t *testing.T) {
 	r.POST("/location/address", handler.GetAddressFromWKT)
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusBadGateway, w.Code)
 
 	var response ErrorResponse
 	err = json.Unmarshal(w.Body.Bytes(), &response)
@@ -585,13 +479,11 @@ func TestGetAddressFromWKTHandler_MissingGeocodeUrl(t *testing.T) {
 		t.Errorf("Error unmarshaling response: %v", err)
 	}
 
-	assert.Equal(t, "Something went wrong, please try again later", response.Error)
+	assert.Equal(t, "Failed to get geocode location", response.Error)
+	assert.Equal(t, types.ErrorCodeUpstream, response.Code)
 }
 
-func TestGetAddressFromWKTHandler_InvalidWKTLocation(t *testing.T) {
-	os.Setenv("GEOCODE_API_KEY", "some-key")
-	os.Setenv("GEOCODE_URL", "http://geocode.url")
-
+func TestGetAddressFromWKTHandler_GetInternalServerError(t *testing.T) {
 	r := gin.New()
 
 	logger, err := zap.NewProduction()
@@ -601,9 +493,12 @@ func TestGetAddressFromWKTHandler_InvalidWKTLocation(t *testing.T) {
 
 	handler := &Handler{
 		Logger: logger,
+		Geocoder: geocoding.NewMapsCo("http://geocode.url", "some-key", func(req *http.Request) (*http.Response, error) {
+			return &http.Response{StatusCode: http.StatusInternalServerError}, nil
+		}),
 	}
 
-	payload := GetAddressFromWKTPayload{WKTLocation: "POINT(-12.34567)"}
+	payload := GetAddressFromWKTPayload{WKTLocation: "POINT(-12.34567 12.34567)"}
 
 	payloadJSON, err := json.Marshal(payload)
 	if err != nil {
@@ -620,7 +515,7 @@ func TestGetAddressFromWKTHandler_InvalidWKTLocation(t *testing.T) {
 	r.POST("/location/address", handler.GetAddressFromWKT)
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusBadGateway, w.Code)
 
 	var response ErrorResponse
 	err = json.Unmarshal(w.Body.Bytes(), &response)
@@ -628,13 +523,11 @@ func TestGetAddressFromWKTHandler_InvalidWKTLocation(t *testing.T) {
 		t.Errorf("Error unmarshaling response: %v", err)
 	}
 
-	assert.Equal(t, "Param 'wkt_location' is not a valid WKT location", response.Error)
+	assert.Equal(t, "Failed to get geocode location", response.Error)
+	assert.Equal(t, types.ErrorCodeUpstream, response.Code)
 }
 
-func TestGetAddressFromWKTHandler_GetError(t *testing.T) {
-	os.Setenv("GEOCODE_API_KEY", "some-key")
-	os.Setenv("GEOCODE_URL", "http://geocode.url")
-
+func TestGetAddressFromWKTHandler_ErrorDecodingBody(t *testing.T) {
 	r := gin.New()
 
 	logger, err := zap.NewProduction()
@@ -644,7 +537,12 @@ func TestGetAddressFromWKTHandler_GetError(t *testing.T) {
 
 	handler := &Handler{
 		Logger: logger,
-		Get:    func(url string) (*http.Response, error) { return nil, errors.New("http get error") },
+		Geocoder: geocoding.NewMapsCo("http://geocode.url", "some-key", func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader("{invalid_json}")),
+			}, nil
+		}),
 	}
 
 	payload := GetAddressFromWKTPayload{WKTLocation: "POINT(-12.34567 12.34567)"}
@@ -664,7 +562,7 @@ func TestGetAddressFromWKTHandler_GetError(t *testing.T) {
 	r.POST("/location/address", handler.GetAddressFromWKT)
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusBadGateway, w.Code)
 
 	var response ErrorResponse
 	err = json.Unmarshal(w.Body.Bytes(), &response)
@@ -673,12 +571,10 @@ func TestGetAddressFromWKTHandler_GetError(t *testing.T) {
 	}
 
 	assert.Equal(t, "Failed to get geocode location", response.Error)
+	assert.Equal(t, types.ErrorCodeUpstream, response.Code)
 }
 
-func TestGetAddressFromWKTHandler_GetInternalServerError(t *testing.T) {
-	os.Setenv("GEOCODE_API_KEY", "some-key")
-	os.Setenv("GEOCODE_URL", "http://geocode.url")
-
+func TestGetAddressFromWKTHandler_EmptyGeocodeResponse(t *testing.T) {
 	r := gin.New()
 
 	logger, err := zap.NewProduction()
@@ -686,11 +582,23 @@ func TestGetAddressFromWKTHandler_GetInternalServerError(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	geocodeResp := GetAddressFromWKTResponse{
+		Address: "",
+	}
+
+	geocodeDataJSON, err := json.Marshal(geocodeResp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	handler := &Handler{
 		Logger: logger,
-		Get: func(url string) (*http.Response, error) {
-			return &http.Response{StatusCode: http.StatusInternalServerError}, nil
-		},
+		Geocoder: geocoding.NewMapsCo("http://geocode.url", "some-key", func(req *http.Request) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(bytes.NewReader(geocodeDataJSON)),
+			}, nil
+		}),
 	}
 
 	payload := GetAddressFromWKTPayload{WKTLocation: "POINT(-12.34567 12.34567)"}
@@ -710,7 +618,7 @@ func TestGetAddressFromWKTHandler_GetInternalServerError(t *testing.T) {
 	r.POST("/location/address", handler.GetAddressFromWKT)
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusNotFound, w.Code)
 
 	var response ErrorResponse
 	err = json.Unmarshal(w.Body.Bytes(), &response)
@@ -718,13 +626,10 @@ func TestGetAddressFromWKTHandler_GetInternalServerError(t *testing.T) {
 		t.Errorf("Error unmarshaling response: %v", err)
 	}
 
-	assert.Equal(t, "Failed to get geocode location", response.Error)
+	assert.Equal(t, "No geocode data found for the location provided", response.Error)
 }
 
-func TestGetAddressFromWKTHandler_ErrorDecodingBody(t *testing.T) {
-	os.Setenv("GEOCODE_API_KEY", "some-key")
-	os.Setenv("GEOCODE_URL", "http://geocode.url")
-
+func TestGetAddressFromWKTHandler_Success(t *testing.T) {
 	r := gin.New()
 
 	logger, err := zap.NewProduction()
@@ -732,14 +637,23 @@ func TestGetAddressFromWKTHandler_ErrorDecodingBody(t *testing.T) {
 		t.Fatal(err)
 	}
 
+	geocodeResp := GetAddressFromWKTResponse{
+		Address: "New York, NY",
+	}
+
+	geocodeDataJSON, err := json.Marshal(geocodeResp)
+	if err != nil {
+		t.Fatal(err)
+	}
+
 	handler := &Handler{
 		Logger: logger,
-		Get: func(url string) (*http.Response, error) {
+		Geocoder: geocoding.NewMapsCo("http://geocode.url", "some-key", func(req *http.Request) (*http.Response, error) {
 			return &http.Response{
 				StatusCode: http.StatusOK,
-				Body:       io.NopCloser(strings.NewReader("{invalid_json}")),
+				Body:       io.NopCloser(bytes.NewReader(geocodeDataJSON)),
 			}, nil
-		},
+		}),
 	}
 
 	payload := GetAddressFromWKTPayload{WKTLocation: "POINT(-12.34567 12.34567)"}
@@ -759,129 +673,320 @@ func TestGetAddressFromWKTHandler_ErrorDecodingBody(t *testing.T) {
 	r.POST("/location/address", handler.GetAddressFromWKT)
 	r.ServeHTTP(w, req)
 
-	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.Equal(t, http.StatusOK, w.Code)
 
-	var response ErrorResponse
+	var response GetAddressFromWKTResponse
 	err = json.Unmarshal(w.Body.Bytes(), &response)
 	if err != nil {
 		t.Errorf("Error unmarshaling response: %v", err)
 	}
 
-	assert.Equal(t, "Failed to get geocode location", response.Error)
+	assert.Equal(t, "New York, NY", response.Address)
 }
 
-func TestGetAddressFromWKTHandler_EmptyGeocodeResponse(t *testing.T) {
-	os.Setenv("GEOCODE_API_KEY", "some-key")
-	os.Setenv("GEOCODE_URL", "http://geocode.url")
-
-	r := gin.New()
-
+func TestGetWKTLocationHandler_MissingGeocoder(t *testing.T) {
 	logger, err := zap.NewProduction()
 	if err != nil {
 		t.Fatal(err)
 	}
 
-	geocodeResp := GetAddressFromWKTResponse{
-		Address: "",
+	handler := &Handler{
+		Logger: logger,
 	}
 
-	geocodeDataJSON, err := json.Marshal(geocodeResp)
+	for _, test := range []struct {
+		path    string
+		payload string
+		handle  gin.HandlerFunc
+	}{
+		{"/location/wkt", `{"user_location": "Košice"}`, handler.GetWKTLocation},
+		{"/location/address", `{"wkt_location": "POINT(21.2611 48.7164)"}`, handler.GetAddressFromWKT},
+	} {
+		req, _ := http.NewRequest("POST", test.path, strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST(test.path, test.handle)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusInternalServerError, w.Code)
+
+		var response ErrorResponse
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			t.Errorf("Error unmarshaling response: %v", err)
+		}
+
+		assert.Equal(t, "Something went wrong, please try again later", response.Error)
+	}
+}
+
+func TestGetWKTLocationHandler_OfflineGazetteer(t *testing.T) {
+	logger, err := zap.NewProduction()
 	if err != nil {
 		t.Fatal(err)
 	}
 
+	gazetteer := geocoding.NewGazetteer()
+	gazetteer.SetMunicipalities([]*types.Place{{Lat: 48.7543, Lon: 21.9195, DisplayName: "Michalovce, Slovensko"}})
+
 	handler := &Handler{
-		Logger: logger,
-		Get: func(url string) (*http.Response, error) {
-			return &http.Response{
-				StatusCode: http.StatusOK,
-				Body:       io.NopCloser(bytes.NewReader(geocodeDataJSON)),
-			}, nil
-		},
+		Logger:   logger,
+		Geocoder: gazetteer,
 	}
 
-	payload := GetAddressFromWKTPayload{WKTLocation: "POINT(-12.34567 12.34567)"}
+	req, _ := http.NewRequest("POST", "/location/wkt", strings.NewReader(`{"user_location": "michalovce"}`))
+	req.Header.Set("Content-Type", "application/json")
 
-	payloadJSON, err := json.Marshal(payload)
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/location/wkt", handler.GetWKTLocation)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response GetWKTLocationResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
 	if err != nil {
-		t.Fatal(err)
+		t.Errorf("Error unmarshaling response: %v", err)
 	}
-	req, err := http.NewRequest("POST", "/location/address", bytes.NewBuffer(payloadJSON))
-	if err != nil {
-		t.Fatal(err)
+
+	assert.Equal(t, "POINT(21.9195 48.7543)", response.WKTLocation)
+}
+
+func TestGetAddressFromWKTHandler_GeoJSON(t *testing.T) {
+	gazetteer := geocoding.NewGazetteer()
+	gazetteer.SetMunicipalities([]*types.Place{{Lat: 48.7543, Lon: 21.9195, DisplayName: "Michalovce, Slovensko"}})
+
+	handler := &Handler{
+		Logger:   zap.NewNop(),
+		Geocoder: gazetteer,
 	}
+
+	req, _ := http.NewRequest("POST", "/location/address", strings.NewReader(`{"wkt_location": {"type": "Point", "coordinates": [21.92, 48.75]}}`))
 	req.Header.Set("Content-Type", "application/json")
 
 	w := httptest.NewRecorder()
-
+	r := gin.New()
 	r.POST("/location/address", handler.GetAddressFromWKT)
 	r.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"display_name": "Michalovce, Slovensko"}`, w.Body.String())
+}
+
+func TestGetAddressFromWKTHandler_SwappedAxes(t *testing.T) {
+	handler := &Handler{
+		Logger:   zap.NewNop(),
+		Geocoder: geocoding.NewGazetteer(),
+	}
+
+	req, _ := http.NewRequest("POST", "/location/address", strings.NewReader(`{"wkt_location": "POINT(48.75 21.92)"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/location/address", handler.GetAddressFromWKT)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
 
 	var response ErrorResponse
-	err = json.Unmarshal(w.Body.Bytes(), &response)
+	err := json.Unmarshal(w.Body.Bytes(), &response)
 	if err != nil {
 		t.Errorf("Error unmarshaling response: %v", err)
 	}
 
-	assert.Equal(t, "No geocode data found for the location provided", response.Error)
+	assert.Contains(t, response.Error, "the coordinates look swapped")
 }
 
-func TestGetAddressFromWKTHandler_Success(t *testing.T) {
-	os.Setenv("GEOCODE_API_KEY", "some-key")
-	os.Setenv("GEOCODE_URL", "http://geocode.url")
-
-	r := gin.New()
+func TestGetWKTLocationHandler_Candidates(t *testing.T) {
+	gazetteer := geocoding.NewGazetteer()
+	gazetteer.SetMunicipalities([]*types.Place{
+		{Lat: 48.7543, Lon: 21.9195, DisplayName: "Michalovce, Slovensko", Type: types.PlaceTypeCity},
+		{Lat: 48.9931, Lon: 21.6022, DisplayName: "Michaľany, Slovensko", Type: types.PlaceTypeCity},
+		{Lat: 48.7164, Lon: 21.2611, DisplayName: "Košice, Slovensko", Type: types.PlaceTypeCity},
+	})
 
-	logger, err := zap.NewProduction()
-	if err != nil {
-		t.Fatal(err)
+	handler := &Handler{
+		Logger:   zap.NewNop(),
+		Geocoder: gazetteer,
+	}
+
+	tests := []struct {
+		payload    string
+		candidates []string
+		ambiguous  bool
+	}{
+		{`{"user_location": "michal"}`, []string{"Michalovce, Slovensko", "Michaľany, Slovensko"}, true},
+		{`{"user_location": "michal", "limit": 1}`, []string{"Michalovce, Slovensko"}, false},
+		{`{"user_location": "kosice"}`, []string{"Košice, Slovensko"}, false},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", "/location/wkt", strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/location/wkt", handler.GetWKTLocation)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, test.payload)
+
+		var response GetWKTLocationResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			t.Errorf("Error unmarshaling response: %v", err)
+		}
+
+		names := []string{}
+		for _, candidate := range response.Candidates {
+			names = append(names, candidate.DisplayName)
+			assert.Equal(t, types.PlaceTypeCity, candidate.Type)
+		}
+		assert.Equal(t, test.candidates, names, test.payload)
+		assert.Equal(t, test.ambiguous, response.Ambiguous, test.payload)
+		assert.Equal(t, response.Candidates[0].WKTLocation, response.WKTLocation)
 	}
+}
 
-	geocodeResp := GetAddressFromWKTResponse{
-		Address: "New York, NY",
+func TestGetWKTLocationHandler_InvalidLimit(t *testing.T) {
+	handler := &Handler{
+		Logger:   zap.NewNop(),
+		Geocoder: geocoding.NewGazetteer(),
 	}
 
-	geocodeDataJSON, err := json.Marshal(geocodeResp)
-	if err != nil {
-		t.Fatal(err)
+	for _, payload := range []string{`{"user_location": "Košice", "limit": -1}`, `{"user_location": "Košice", "limit": 11}`} {
+		req, _ := http.NewRequest("POST", "/location/wkt", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/location/wkt", handler.GetWKTLocation)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, payload)
+		assert.JSONEq(t, `{"error":"Invalid payload: limit must be between 1 and 10","code":"invalid_payload","fields":[{"field":"limit","message":"must be between 1 and 10"}]}`, w.Body.String())
 	}
+}
+
+func TestGetWKTLocationBatchHandler(t *testing.T) {
+	gazetteer := geocoding.NewGazetteer()
+	gazetteer.SetMunicipalities([]*types.Place{
+		{Lat: 48.7543, Lon: 21.9195, DisplayName: "Michalovce, Slovensko", Type: types.PlaceTypeCity},
+		{Lat: 48.7164, Lon: 21.2611, DisplayName: "Košice, Slovensko", Type: types.PlaceTypeCity},
+	})
 
 	handler := &Handler{
-		Logger: logger,
-		Get: func(url string) (*http.Response, error) {
-			return &http.Response{
-				StatusCode: http.StatusOK,
-				Body:       io.NopCloser(bytes.NewReader(geocodeDataJSON)),
-			}, nil
-		},
+		Logger:   zap.NewNop(),
+		Geocoder: gazetteer,
 	}
 
-	payload := GetAddressFromWKTPayload{WKTLocation: "POINT(-12.34567 12.34567)"}
+	req, _ := http.NewRequest("POST", "/location/wkt/batch", strings.NewReader(`{"queries": ["Košice", "Atlantída", "kosice", " ", "Michalovce"], "limit": 1}`))
+	req.Header.Set("Content-Type", "application/json")
 
-	payloadJSON, err := json.Marshal(payload)
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/location/wkt/batch", handler.GetWKTLocationBatch)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response GetWKTLocationBatchResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
 	if err != nil {
-		t.Fatal(err)
+		t.Errorf("Error unmarshaling response: %v", err)
 	}
-	req, err := http.NewRequest("POST", "/location/address", bytes.NewBuffer(payloadJSON))
-	if err != nil {
-		t.Fatal(err)
+
+	assert.Equal(t, 3, response.Succeeded)
+	assert.Equal(t, 2, response.Failed)
+	assert.Len(t, response.Results, 5)
+
+	expected := []struct {
+		query  string
+		status int
+		wkt    string
+		error  string
+	}{
+		{"Košice", http.StatusOK, "POINT(21.2611 48.7164)", ""},
+		{"Atlantída", http.StatusNotFound, "", "No geocode data found for the location provided"},
+		{"kosice", http.StatusOK, "POINT(21.2611 48.7164)", ""},
+		{" ", http.StatusBadRequest, "", "Invalid payload: empty query"},
+		{"Michalovce", http.StatusOK, "POINT(21.9195 48.7543)", ""},
+	}
+	for i, want := range expected {
+		got := response.Results[i]
+		assert.Equal(t, want.query, got.Query)
+		assert.Equal(t, want.status, got.Status, want.query)
+		assert.Equal(t, want.error, got.Error, want.query)
+		if want.wkt != "" {
+			assert.Equal(t, want.wkt, got.Result.WKTLocation, want.query)
+		} else {
+			assert.Nil(t, got.Result, want.query)
+		}
+	}
+}
+
+func TestGetWKTLocationBatchHandler_RateLimited(t *testing.T) {
+	calls := 0
+	handler := &Handler{
+		Logger: zap.NewNop(),
+		Geocoder: geocoding.NewMapsCo("http://geocode.url", "some-key", func(req *http.Request) (*http.Response, error) {
+			calls++
+			return &http.Response{StatusCode: http.StatusTooManyRequests, Header: http.Header{"Retry-After": {"3600"}}}, nil
+		}),
 	}
+
+	req, _ := http.NewRequest("POST", "/location/wkt/batch", strings.NewReader(`{"queries": ["Košice"]}`))
 	req.Header.Set("Content-Type", "application/json")
 
 	w := httptest.NewRecorder()
-
-	r.POST("/location/address", handler.GetAddressFromWKT)
+	r := gin.New()
+	r.POST("/location/wkt/batch", handler.GetWKTLocationBatch)
 	r.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, 1, calls)
+	assert.JSONEq(t, `{"succeeded": 0, "failed": 1, "results": [{"query": "Košice", "status": 429, "error": "Geocoding rate limit exceeded, please try again later", "code": "rate_limited"}]}`, w.Body.String())
+}
 
-	var response GetAddressFromWKTResponse
-	err = json.Unmarshal(w.Body.Bytes(), &response)
-	if err != nil {
-		t.Errorf("Error unmarshaling response: %v", err)
+func TestGetWKTLocationBatchHandler_InvalidPayload(t *testing.T) {
+	handler := &Handler{
+		Logger:   zap.NewNop(),
+		Geocoder: geocoding.NewGazetteer(),
 	}
 
-	assert.Equal(t, "New York, NY", response.Address)
+	tooMany := `{"queries": [` + strings.TrimSuffix(strings.Repeat(`"Košice",`, 501), ",") + `]}`
+
+	tests := []struct {
+		payload  string
+		expected string
+	}{
+		{"{invalid_json}", "Invalid JSON payload"},
+		{`{"queries": []}`, "Invalid payload: missing queries field"},
+		{tooMany, "Invalid payload: at most 500 queries are allowed"},
+		{`{"queries": ["Košice"], "limit": 11}`, "Invalid payload: limit must be between 1 and 10"},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", "/location/wkt/batch", strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/location/wkt/batch", handler.GetWKTLocationBatch)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response ErrorResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			t.Errorf("Error unmarshaling response: %v", err)
+		}
+
+		assert.Equal(t, test.expected, response.Error)
+	}
 }
@@ -3,6 +3,7 @@ package handlers
 import (
 	"net/http"
 
+	"github.com/acornak/healthcare-poc/types"
 	"github.com/gin-gonic/gin"
 	"go.uber.org/zap"
 )
@@ -26,18 +27,15 @@ type AddPayload struct {
 // @Router		/math/add [post]
 func (h *Handler) Add(c *gin.Context) {
 	var payload AddPayload
-	var errResp ErrorResponse
 	var successResp SuccessResponse
 
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		errResp.Error = "Invalid JSON payload"
-		c.JSON(http.StatusBadRequest, errResp)
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
 		return
 	}
 
 	if len(payload.Numbers) == 0 {
-		errResp.Error = "Invalid payload: missing numbers field"
-		c.JSON(http.StatusBadRequest, errResp)
+		h.respondError(c, types.NewMissingFieldError("numbers"))
 		return
 	}
 
@@ -73,12 +71,10 @@ type SubtractPayload struct {
 //	@Router			/math/subtract [post]
 func (h *Handler) Subtract(c *gin.Context) {
 	var payload SubtractPayload
-	var errResp ErrorResponse
 	var successResp SuccessResponse
 
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		errResp.Error = "Invalid JSON payload"
-		c.JSON(http.StatusBadRequest, errResp)
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
 		return
 	}
 
@@ -88,8 +84,7 @@ func (h *Handler) Subtract(c *gin.Context) {
 	}
 
 	if len(payload.Subtract) == 0 {
-		errResp.Error = "Invalid payload: missing subtract field"
-		c.JSON(http.StatusBadRequest, errResp)
+		h.respondError(c, types.NewMissingFieldError("subtract"))
 		return
 	}
 
@@ -120,18 +115,15 @@ type ComputePayload struct {
 //	@Router			/math/compute [post]
 func (h *Handler) Compute(c *gin.Context) {
 	var payload ComputePayload
-	var errResp ErrorResponse
 	var successResp SuccessResponse
 
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		errResp.Error = "Invalid JSON payload"
-		c.JSON(http.StatusBadRequest, errResp)
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
 		return
 	}
 
 	if len(payload.Add) == 0 && len(payload.Subtract) == 0 {
-		errResp.Error = "Invalid payload: missing add and subtract fields"
-		c.JSON(http.StatusBadRequest, errResp)
+		h.respondError(c, types.NewValidationError("Invalid payload: missing add and subtract fields"))
 		return
 	}
 
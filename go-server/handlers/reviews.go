@@ -0,0 +1,183 @@
+package handlers
+
+import (
+	"net/http"
+	"strings"
+
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
+)
+
+const (
+	reviewMinRating = 1
+	reviewMaxRating = 5
+)
+
+type SaveReviewPayload struct {
+	ID           int     `json:"id,omitempty"`
+	SpecialistID int     `json:"specialist_id"`
+	Url          string  `json:"url"`
+	Rating       float64 `json:"rating"`
+	Comment      string  `json:"comment"`
+}
+
+type DeleteReviewPayload struct {
+	ID int `json:"id"`
+}
+
+// @Summary		Save review
+// @Description	Create a review of a specialist, or update the one with the id, the url links to the source of the review
+// @ID			admin-review-save
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		X-Admin-User	header		string				false	"Member of staff making the change"
+// @Param		payload			body		SaveReviewPayload	true	"Review with a rating from 1 to 5, with id for an update"
+// @Success		200				{object}	types.Review
+// @Failure		400				{object}	ErrorResponse
+// @Failure		401				{object}	ErrorResponse
+// @Failure		404				{object}	ErrorResponse
+// @Failure		500				{object}	ErrorResponse
+// @Router		/admin/review [post]
+func (h *Handler) SaveReview(c *gin.Context) {
+	var payload SaveReviewPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	review := types.Review{
+		ID:           payload.ID,
+		SpecialistId: payload.SpecialistID,
+		Url:          strings.TrimSpace(payload.Url),
+		Rating:       payload.Rating,
+		Comment:      strings.TrimSpace(payload.Comment),
+	}
+
+	missingParams := []string{}
+	if review.SpecialistId == 0 {
+		missingParams = append(missingParams, "specialist_id")
+	}
+	if review.Url == "" {
+		missingParams = append(missingParams, "url")
+	}
+
+	if len(missingParams) > 0 {
+		h.respondError(c, types.NewMissingFieldsError(missingParams))
+		return
+	}
+
+	if !isWebURL(review.Url) {
+		h.respondError(c, types.NewInvalidFieldError("url", "must be an http or https URL"))
+		return
+	}
+
+	if review.Rating < reviewMinRating || review.Rating > reviewMaxRating {
+		h.respondError(c, types.NewInvalidFieldError("rating", "must be between 1 and 5"))
+		return
+	}
+
+	specialist, err := h.Models.Specialists.GetSpecialistByID(c.Request.Context(), review.SpecialistId)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if specialist == nil {
+		h.respondError(c, types.NewInvalidFieldError("specialist_id", "does not exist"))
+		return
+	}
+
+	if review.ID == 0 {
+		ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+			created := review
+			created.ID = id
+			return []auditChange{{types.AuditActionCreate, types.AuditEntityReview, id, nil, created}}
+		})
+
+		review.ID, err = h.Models.Reviews.InsertReview(ctx, review)
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+		logChange()
+
+		c.JSON(http.StatusOK, review)
+		return
+	}
+
+	before, err := h.Models.Reviews.GetReviewByID(c.Request.Context(), review.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if before == nil {
+		h.respondError(c, types.NewNotFoundError("Review not found"))
+		return
+	}
+
+	ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+		return []auditChange{{types.AuditActionUpdate, types.AuditEntityReview, id, before, review}}
+	})
+
+	if err := h.Models.Reviews.UpdateReview(ctx, review); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	logChange()
+
+	c.JSON(http.StatusOK, review)
+}
+
+// @Summary		Delete review
+// @Description	Delete a review of a specialist
+// @ID			admin-review-delete
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		X-Admin-User	header		string				false	"Member of staff making the change"
+// @Param		payload			body		DeleteReviewPayload	true	"Review id"
+// @Success		200				{object}	DeleteReviewPayload
+// @Failure		400				{object}	ErrorResponse
+// @Failure		401				{object}	ErrorResponse
+// @Failure		404				{object}	ErrorResponse
+// @Failure		500				{object}	ErrorResponse
+// @Router		/admin/review/delete [post]
+func (h *Handler) DeleteReview(c *gin.Context) {
+	var payload DeleteReviewPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	if payload.ID == 0 {
+		h.respondError(c, types.NewMissingFieldError("id"))
+		return
+	}
+
+	before, err := h.Models.Reviews.GetReviewByID(c.Request.Context(), payload.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if before == nil {
+		h.respondError(c, types.NewNotFoundError("Review not found"))
+		return
+	}
+
+	ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+		return []auditChange{{types.AuditActionDelete, types.AuditEntityReview, id, before, nil}}
+	})
+
+	if err := h.Models.Reviews.DeleteReview(ctx, payload.ID); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	logChange()
+
+	c.JSON(http.StatusOK, payload)
+}
@@ -0,0 +1,88 @@
+package handlers
+
+import (
+	"context"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSaveReviewHandler_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	r := gin.New()
+	r.POST("/admin/review", handler.SaveReview)
+
+	tests := []struct {
+		payload  string
+		expected int
+		body     string
+	}{
+		{`{"rating": 4}`, http.StatusBadRequest, "Invalid payload: missing specialist_id, url"},
+		{`{"specialist_id": 1, "url": "example.com/review", "rating": 4}`, http.StatusBadRequest, "Invalid payload: url must be an http or https URL"},
+		{`{"specialist_id": 1, "url": "https://example.com/review", "rating": 6}`, http.StatusBadRequest, "Invalid payload: rating must be between 1 and 5"},
+		{`{"specialist_id": 42, "url": "https://example.com/review", "rating": 4}`, http.StatusBadRequest, "Invalid payload: specialist_id does not exist"},
+		{`{"specialist_id": 1, "url": "https://example.com/review", "rating": 4.5, "comment": " milý personál "}`, http.StatusOK, `{"id":1,"specialist_id":1,"url":"https://example.com/review","rating":4.5,"comment":"milý personál"}`},
+		{`{"id": 1, "specialist_id": 2, "url": "https://example.com/review", "rating": 3}`, http.StatusOK, `{"id":1,"specialist_id":2,"url":"https://example.com/review","rating":3}`},
+		{`{"id": 42, "specialist_id": 2, "url": "https://example.com/review", "rating": 3}`, http.StatusNotFound, "Review not found"},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", "/admin/review", strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, test.expected, w.Code, test.payload)
+		assert.Contains(t, w.Body.String(), test.body, test.payload)
+	}
+
+	review, err := handler.Models.Reviews.GetReviewByID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, &types.Review{ID: 1, SpecialistId: 2, Url: "https://example.com/review", Rating: 3}, review)
+
+	entries, err := handler.Models.Audit.GetAuditLog(context.Background(), types.AuditEntityReview, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+}
+
+func TestDeleteReviewHandler_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	if _, err := handler.Models.Reviews.InsertReview(context.Background(), types.Review{SpecialistId: 1, Url: "https://example.com", Rating: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.POST("/admin/review/delete", handler.DeleteReview)
+
+	tests := []struct {
+		payload  string
+		expected int
+	}{
+		{`{}`, http.StatusBadRequest},
+		{`{"id": 1}`, http.StatusOK},
+		{`{"id": 1}`, http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", "/admin/review/delete", strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+		req.Header.Set(AdminUserHeader, "peter")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, test.expected, w.Code, test.payload)
+	}
+
+	entries, err := handler.Models.Audit.GetAuditLog(context.Background(), types.AuditEntityReview, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "peter", entries[0].Actor)
+	assert.JSONEq(t, `{"id":1,"specialist_id":1,"url":"https://example.com","rating":5}`, string(entries[0].Before))
+}
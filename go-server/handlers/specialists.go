@@ -1,25 +1,79 @@
 package handlers
 
 import (
+	"database/sql"
+	"errors"
+	"fmt"
+	"maps"
 	"net/http"
+	"slices"
+	"strconv"
 	"strings"
 
+	"github.com/acornak/healthcare-poc/textutil"
 	"github.com/acornak/healthcare-poc/types"
 	"github.com/gin-gonic/gin"
 )
 
 type FindSpecialistPayload struct {
-	SpecialtyId  int    `json:"specialty_id"`
-	Radius       int    `json:"radius"`
-	UserLocation string `json:"user_location"`
+	SpecialtyId  int                 `json:"specialty_id"`
+	Radius       int                 `json:"radius"`
+	UserLocation types.LocationInput `json:"user_location" swaggertype:"string" example:"POINT(21.2496774 48.7172272)"`
 }
 
 type FindSpecialistResponse struct {
 	Specialists []*types.Specialist `json:"specialists"`
 }
 
+type SaveSpecialistPayload struct {
+	ID          int                 `json:"id,omitempty"`
+	Version     int                 `json:"version,omitempty"`
+	Name        string              `json:"name"`
+	SpecialtyID int                 `json:"specialty_id"`
+	Location    types.LocationInput `json:"location" swaggertype:"string" example:"POINT(21.2496774 48.7172272)"`
+	Address     string              `json:"address"`
+	Url         string              `json:"url"`
+	Telephone   string              `json:"telephone"`
+	Email       string              `json:"email"`
+	Monday      string              `json:"monday"`
+	Tuesday     string              `json:"tuesday"`
+	Wednesday   string              `json:"wednesday"`
+	Thursday    string              `json:"thursday"`
+	Friday      string              `json:"friday"`
+	Saturday    string              `json:"saturday"`
+	Sunday      string              `json:"sunday"`
+	Staff       string              `json:"staff"`
+	Insurers    []string            `json:"insurers"`
+}
+
+type DeleteSpecialistPayload struct {
+	ID      int `json:"id"`
+	Version int `json:"version"`
+}
+
+type GetSpecialistOverridesPayload struct {
+	ID int `json:"id"`
+}
+
+type GetSpecialistOverridesResponse struct {
+	Overrides []*types.SpecialistOverride `json:"overrides"`
+}
+
+type SetSpecialistOverridesPayload struct {
+	ID      int               `json:"id"`
+	Version int               `json:"version"`
+	Fields  map[string]string `json:"fields" example:"name:Kardiológia Košice"`
+}
+
+type DeleteSpecialistOverridesPayload struct {
+	ID      int      `json:"id"`
+	Version int      `json:"version"`
+	Fields  []string `json:"fields"`
+}
+
 // @Summary		Find specialist
 // @Description	Find a specialist based on the user's location, specialty, and radius
+// @Description	The location is given as WKT, EWKT, GeoJSON Point or {lat, lon} object
 // @ID			find-specialist
 // @Accept		json
 // @Produce		json
@@ -29,11 +83,9 @@ type FindSpecialistResponse struct {
 // @Router		/specialist/find [post]
 func (h *Handler) FindSpecialist(c *gin.Context) {
 	var payload FindSpecialistPayload
-	var errResp ErrorResponse
 
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		errResp.Error = "Invalid JSON payload"
-		c.JSON(http.StatusBadRequest, errResp)
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
 		return
 	}
 
@@ -49,18 +101,654 @@ func (h *Handler) FindSpecialist(c *gin.Context) {
 	}
 
 	if len(missingParams) > 0 {
-		errResp.Error = "Invalid payload: missing " + strings.Join(missingParams, ", ")
-		c.JSON(http.StatusBadRequest, errResp)
+		h.respondError(c, types.NewMissingFieldsError(missingParams))
 		return
 	}
 
-	specialists, err := h.Models.DB.GetSpecialistBySpecialtyAndLocation(payload.SpecialtyId, payload.Radius, payload.UserLocation)
+	location, err := parseLocation(payload.UserLocation)
 	if err != nil {
-		errResp.Error = err.Error()
-		c.JSON(http.StatusInternalServerError, errResp)
+		h.respondError(c, types.NewInvalidFieldError("user_location", "is not a valid location: "+err.Error()))
 		return
 	}
 
+	specialists, err := h.Models.Specialists.GetSpecialistBySpecialtyAndLocation(c.Request.Context(), payload.SpecialtyId, payload.Radius, location.WKT())
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	addNavigationLinks(specialists, location.WKT())
+
 	c.JSON(http.StatusOK, FindSpecialistResponse{Specialists: specialists})
 
 }
+
+const (
+	searchDefaultLimit = 20
+	searchMaxLimit     = 100
+	searchMinRank      = 0.3
+)
+
+type SearchSpecialistPayload struct {
+	Query  string              `json:"query"`
+	Limit  int                 `json:"limit,omitempty"`
+	Origin types.LocationInput `json:"origin,omitempty" swaggertype:"string" example:"POINT(21.2496774 48.7172272)"`
+}
+
+type SearchSpecialistResponse struct {
+	Results []*types.SpecialistSearchResult `json:"results"`
+}
+
+// @Summary		Search specialists
+// @Description	Fuzzy, diacritics-insensitive search across clinic name, staff, address and specialty
+// @ID			search-specialist
+// @Accept		json
+// @Produce		json
+// @Param		payload	body		SearchSpecialistPayload	true	"Search query and optional result limit"
+// @Success		200		{object}	SearchSpecialistResponse
+// @Failure		400		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/specialist/search [post]
+func (h *Handler) SearchSpecialist(c *gin.Context) {
+	var payload SearchSpecialistPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	terms := textutil.Terms(payload.Query)
+	if len(terms) == 0 {
+		h.respondError(c, types.NewMissingFieldError("query"))
+		return
+	}
+
+	if payload.Limit < 0 || payload.Limit > searchMaxLimit {
+		h.respondError(c, types.NewInvalidFieldError("limit", fmt.Sprintf("must be between 1 and %d", searchMaxLimit)))
+		return
+	}
+
+	origin, err := parseOrigin(payload.Origin)
+	if err != nil {
+		h.respondError(c, types.NewInvalidFieldError("origin", "is not a valid location: "+err.Error()))
+		return
+	}
+
+	limit := payload.Limit
+	if limit == 0 {
+		limit = searchDefaultLimit
+	}
+
+	results, err := h.Models.Specialists.SearchSpecialists(c.Request.Context(), terms, searchMinRank, limit)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	for _, result := range results {
+		result.Highlights = highlightSearchResult(result, terms)
+		addNavigationLinks([]*types.Specialist{result.Specialist}, origin)
+	}
+
+	c.JSON(http.StatusOK, SearchSpecialistResponse{Results: results})
+}
+
+// @Summary		Get specialist
+// @Description	Get the full profile of a specialist: specialty name, parsed opening hours, whether it is open now, staff, insurers, review summary, navigation links and last update time
+// @Description	On public holidays and closures the specialist keeps its Sunday hours
+// @ID			get-specialist
+// @Produce		json
+// @Param		id	path		int	true	"Specialist id"
+// @Param		origin	query		string	false	"Location the navigation directions start at, in any format accepted for locations"
+// @Success		200	{object}	types.SpecialistProfile
+// @Failure		400	{object}	ErrorResponse
+// @Failure		404	{object}	ErrorResponse
+// @Failure		500	{object}	ErrorResponse
+// @Router		/specialist/{id} [get]
+func (h *Handler) GetSpecialist(c *gin.Context) {
+	id, err := strconv.Atoi(c.Param("id"))
+	if err != nil || id <= 0 {
+		h.respondError(c, types.NewValidationError("Invalid specialist id"))
+		return
+	}
+
+	origin, err := parseOrigin(types.LocationInput(c.Query("origin")))
+	if err != nil {
+		h.respondError(c, types.NewValidationError("Param 'origin' is not a valid location: "+err.Error()))
+		return
+	}
+
+	profile, err := h.Models.Specialists.GetSpecialistProfile(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if profile == nil {
+		h.respondError(c, types.NewNotFoundError("Specialist not found"))
+		return
+	}
+
+	now := clinicTime(h.now())
+	calendar, err := h.holidayCalendar(c.Request.Context(), now.Year())
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	profile.OpeningHours = profile.Specialist.OpeningHours()
+	profile.Holiday = calendar.Holiday(now, profile.Specialist.Address)
+	profile.OpenNow = profile.Specialist.OpenAt(now, profile.Holiday != nil)
+	addNavigationLinks([]*types.Specialist{profile.Specialist}, origin)
+
+	c.JSON(http.StatusOK, profile)
+}
+
+// @Summary		Save specialist
+// @Description	Create a specialist, or update the one with the id, an update names the version it is based on and fails with a conflict when the specialist was changed since
+// @Description	The opening hours are in the format of the geoportal, e.g. "7:00 - 12:00, 13:00 - 15:00", the change is recorded in the audit log under the X-Admin-User header
+// @ID			admin-specialist-save
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		X-Admin-User	header		string					false	"Member of staff making the change"
+// @Param		payload			body		SaveSpecialistPayload	true	"Specialist, with id and version for an update"
+// @Success		200				{object}	types.Specialist
+// @Failure		400				{object}	ErrorResponse
+// @Failure		401				{object}	ErrorResponse
+// @Failure		404				{object}	ErrorResponse
+// @Failure		409				{object}	ErrorResponse
+// @Failure		500				{object}	ErrorResponse
+// @Router		/admin/specialist [post]
+func (h *Handler) SaveSpecialist(c *gin.Context) {
+	var payload SaveSpecialistPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	missingParams := []string{}
+	if strings.TrimSpace(payload.Name) == "" {
+		missingParams = append(missingParams, "name")
+	}
+	if payload.SpecialtyID == 0 {
+		missingParams = append(missingParams, "specialty_id")
+	}
+	if payload.Location == "" {
+		missingParams = append(missingParams, "location")
+	}
+	if payload.ID != 0 && payload.Version == 0 {
+		missingParams = append(missingParams, "version")
+	}
+
+	if len(missingParams) > 0 {
+		h.respondError(c, types.NewMissingFieldsError(missingParams))
+		return
+	}
+
+	location, err := parseLocation(payload.Location)
+	if err != nil {
+		h.respondError(c, types.NewInvalidFieldError("location", "is not a valid location: "+err.Error()))
+		return
+	}
+
+	specialist := types.Specialist{
+		ID:          payload.ID,
+		Version:     payload.Version,
+		Name:        strings.TrimSpace(payload.Name),
+		SpecialtyID: payload.SpecialtyID,
+		Location:    location.WKT(),
+		Address:     strings.TrimSpace(payload.Address),
+		Url:         strings.TrimSpace(payload.Url),
+		Telephone:   strings.TrimSpace(payload.Telephone),
+		Email:       strings.TrimSpace(payload.Email),
+		Monday:      strings.TrimSpace(payload.Monday),
+		Tuesday:     strings.TrimSpace(payload.Tuesday),
+		Wednesday:   strings.TrimSpace(payload.Wednesday),
+		Thursday:    strings.TrimSpace(payload.Thursday),
+		Friday:      strings.TrimSpace(payload.Friday),
+		Saturday:    strings.TrimSpace(payload.Saturday),
+		Sunday:      strings.TrimSpace(payload.Sunday),
+		Staff:       strings.TrimSpace(payload.Staff),
+		Insurers:    []string{},
+	}
+
+	if specialist.Url != "" && !isWebURL(specialist.Url) {
+		h.respondError(c, types.NewInvalidFieldError("url", "must be an http or https URL"))
+		return
+	}
+
+	if specialist.Email != "" && !isEmail(specialist.Email) {
+		h.respondError(c, types.NewInvalidFieldError("email", "is not a valid email address"))
+		return
+	}
+
+	for _, insurer := range payload.Insurers {
+		if insurer = strings.TrimSpace(insurer); insurer != "" && !slices.Contains(specialist.Insurers, insurer) {
+			specialist.Insurers = append(specialist.Insurers, insurer)
+		}
+	}
+
+	specialty, err := h.Models.Specialties.GetSpecialtyByID(c.Request.Context(), specialist.SpecialtyID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if specialty == nil {
+		h.respondError(c, types.NewInvalidFieldError("specialty_id", "does not exist"))
+		return
+	}
+
+	if specialist.ID == 0 {
+		specialist.Version = 1
+		specialist.FieldSources = types.NewFieldSources(nil)
+
+		ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+			created := specialist
+			created.ID = id
+			return []auditChange{{types.AuditActionCreate, types.AuditEntitySpecialist, id, nil, created}}
+		})
+
+		specialist.ID, err = h.Models.Specialists.InsertSpecialist(ctx, specialist)
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+		logChange()
+
+		c.JSON(http.StatusOK, specialist)
+		return
+	}
+
+	// the profile holds the insurers as well, the audit log keeps the whole record
+	before, err := h.Models.Specialists.GetSpecialistProfile(c.Request.Context(), specialist.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if before == nil {
+		h.respondError(c, types.NewNotFoundError("Specialist not found"))
+		return
+	}
+
+	// the overridden fields stay overridden with the new values
+	specialist.FieldSources = before.Specialist.FieldSources
+
+	// the update only succeeds on the version read, it stores the next one
+	ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+		updated := specialist
+		updated.Version++
+		return []auditChange{{types.AuditActionUpdate, types.AuditEntitySpecialist, id, before.Specialist, updated}}
+	})
+
+	specialist.Version, err = h.Models.Specialists.UpdateSpecialist(ctx, specialist)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+	logChange()
+
+	c.JSON(http.StatusOK, specialist)
+}
+
+// @Summary		Delete specialist
+// @Description	Delete a specialist, the delete names the version it is based on and fails with a conflict when the specialist was changed since or still has reviews
+// @Description	The scraper does not insert a deleted specialist again, its name in the geoportal is kept as a tombstone
+// @ID			admin-specialist-delete
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		X-Admin-User	header		string					false	"Member of staff making the change"
+// @Param		payload			body		DeleteSpecialistPayload	true	"Specialist id and version"
+// @Success		200				{object}	DeleteSpecialistPayload
+// @Failure		400				{object}	ErrorResponse
+// @Failure		401				{object}	ErrorResponse
+// @Failure		404				{object}	ErrorResponse
+// @Failure		409				{object}	ErrorResponse
+// @Failure		500				{object}	ErrorResponse
+// @Router		/admin/specialist/delete [post]
+func (h *Handler) DeleteSpecialist(c *gin.Context) {
+	var payload DeleteSpecialistPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	missingParams := []string{}
+	if payload.ID == 0 {
+		missingParams = append(missingParams, "id")
+	}
+	if payload.Version == 0 {
+		missingParams = append(missingParams, "version")
+	}
+
+	if len(missingParams) > 0 {
+		h.respondError(c, types.NewMissingFieldsError(missingParams))
+		return
+	}
+
+	before, err := h.Models.Specialists.GetSpecialistProfile(c.Request.Context(), payload.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if before == nil {
+		h.respondError(c, types.NewNotFoundError("Specialist not found"))
+		return
+	}
+
+	// the reviews reference the specialist, they have to be deleted first
+	_, err = h.Models.Reviews.GetReviewBySpecialistId(c.Request.Context(), payload.ID)
+	if err == nil {
+		h.respondError(c, types.NewConflictError("The specialist still has reviews, delete them first", nil))
+		return
+	}
+
+	if !errors.Is(err, sql.ErrNoRows) {
+		h.respondError(c, err)
+		return
+	}
+
+	ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+		return []auditChange{{types.AuditActionDelete, types.AuditEntitySpecialist, id, before.Specialist, nil}}
+	})
+
+	if err := h.Models.Specialists.DeleteSpecialist(ctx, payload.ID, payload.Version); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	logChange()
+
+	c.JSON(http.StatusOK, payload)
+}
+
+// @Summary		Specialist overrides
+// @Description	Get the fields of a specialist overridden by an admin, with their values and the latest values of the source
+// @ID			admin-specialist-overrides
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		payload	body		GetSpecialistOverridesPayload	true	"Specialist id"
+// @Success		200		{object}	GetSpecialistOverridesResponse
+// @Failure		400		{object}	ErrorResponse
+// @Failure		401		{object}	ErrorResponse
+// @Failure		404		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/admin/specialist/overrides [post]
+func (h *Handler) GetSpecialistOverrides(c *gin.Context) {
+	var payload GetSpecialistOverridesPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	if payload.ID == 0 {
+		h.respondError(c, types.NewMissingFieldError("id"))
+		return
+	}
+
+	overrides, err := h.Models.Specialists.GetSpecialistOverrides(c.Request.Context(), payload.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if overrides == nil {
+		h.respondError(c, types.NewNotFoundError("Specialist not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, GetSpecialistOverridesResponse{Overrides: overrides})
+}
+
+// @Summary		Override specialist fields
+// @Description	Correct fields of a specialist, overridden fields keep their values when the specialist is updated from the geoportal
+// @Description	The fields are name, location, address, url, telephone, email, the opening hours (monday to sunday) and staff, the override names the version it is based on
+// @ID			admin-specialist-override-set
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		X-Admin-User	header		string							false	"Member of staff making the change"
+// @Param		payload			body		SetSpecialistOverridesPayload	true	"Specialist id, version and the new values of the fields"
+// @Success		200				{object}	types.Specialist
+// @Failure		400				{object}	ErrorResponse
+// @Failure		401				{object}	ErrorResponse
+// @Failure		404				{object}	ErrorResponse
+// @Failure		409				{object}	ErrorResponse
+// @Failure		500				{object}	ErrorResponse
+// @Router		/admin/specialist/override [post]
+func (h *Handler) SetSpecialistOverrides(c *gin.Context) {
+	var payload SetSpecialistOverridesPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	missingParams := []string{}
+	if payload.ID == 0 {
+		missingParams = append(missingParams, "id")
+	}
+	if payload.Version == 0 {
+		missingParams = append(missingParams, "version")
+	}
+	if len(payload.Fields) == 0 {
+		missingParams = append(missingParams, "fields")
+	}
+
+	if len(missingParams) > 0 {
+		h.respondError(c, types.NewMissingFieldsError(missingParams))
+		return
+	}
+
+	values, err := overrideValues(payload.Fields)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	before, err := h.Models.Specialists.GetSpecialistProfile(c.Request.Context(), payload.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if before == nil {
+		h.respondError(c, types.NewNotFoundError("Specialist not found"))
+		return
+	}
+
+	ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+		after := overriddenSpecialist(before.Specialist, values, types.FieldSourceOverride)
+		return []auditChange{{types.AuditActionUpdate, types.AuditEntitySpecialist, id, before.Specialist, after}}
+	})
+
+	if _, err := h.Models.Specialists.SetSpecialistOverrides(ctx, payload.ID, payload.Version, values); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	logChange()
+
+	h.respondOverridden(c, payload.ID)
+}
+
+// @Summary		Remove specialist overrides
+// @Description	Remove overrides of a specialist, the fields get the latest values of the geoportal back, fields that are not overridden are skipped
+// @ID			admin-specialist-override-delete
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		X-Admin-User	header		string								false	"Member of staff making the change"
+// @Param		payload			body		DeleteSpecialistOverridesPayload	true	"Specialist id, version and the fields"
+// @Success		200				{object}	types.Specialist
+// @Failure		400				{object}	ErrorResponse
+// @Failure		401				{object}	ErrorResponse
+// @Failure		404				{object}	ErrorResponse
+// @Failure		409				{object}	ErrorResponse
+// @Failure		500				{object}	ErrorResponse
+// @Router		/admin/specialist/override/delete [post]
+func (h *Handler) DeleteSpecialistOverrides(c *gin.Context) {
+	var payload DeleteSpecialistOverridesPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	missingParams := []string{}
+	if payload.ID == 0 {
+		missingParams = append(missingParams, "id")
+	}
+	if payload.Version == 0 {
+		missingParams = append(missingParams, "version")
+	}
+	if len(payload.Fields) == 0 {
+		missingParams = append(missingParams, "fields")
+	}
+
+	if len(missingParams) > 0 {
+		h.respondError(c, types.NewMissingFieldsError(missingParams))
+		return
+	}
+
+	for _, field := range payload.Fields {
+		if !slices.Contains(types.SpecialistOverrideFields, field) {
+			h.respondError(c, types.NewInvalidFieldError("fields", fmt.Sprintf("%q cannot be overridden", field)))
+			return
+		}
+	}
+
+	before, err := h.Models.Specialists.GetSpecialistProfile(c.Request.Context(), payload.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if before == nil {
+		h.respondError(c, types.NewNotFoundError("Specialist not found"))
+		return
+	}
+
+	overrides, err := h.Models.Specialists.GetSpecialistOverrides(c.Request.Context(), payload.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	// the removed overrides restore the source values
+	restored := map[string]string{}
+	for _, override := range overrides {
+		if slices.Contains(payload.Fields, override.Field) {
+			restored[override.Field] = override.SourceValue
+		}
+	}
+
+	ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+		after := overriddenSpecialist(before.Specialist, restored, types.FieldSourceSource)
+		return []auditChange{{types.AuditActionUpdate, types.AuditEntitySpecialist, id, before.Specialist, after}}
+	})
+
+	version, err := h.Models.Specialists.DeleteSpecialistOverrides(ctx, payload.ID, payload.Version, payload.Fields)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	// none of the fields was overridden, nothing changed
+	if version == payload.Version {
+		c.JSON(http.StatusOK, before.Specialist)
+		return
+	}
+	logChange()
+
+	h.respondOverridden(c, payload.ID)
+}
+
+// respondOverridden responds with the specialist after a change of its overrides, merged with the overrides
+func (h *Handler) respondOverridden(c *gin.Context, id int) {
+	after, err := h.Models.Specialists.GetSpecialistProfile(c.Request.Context(), id)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if after == nil {
+		h.respondError(c, types.NewNotFoundError("Specialist not found"))
+		return
+	}
+
+	c.JSON(http.StatusOK, after.Specialist)
+}
+
+/*
+overriddenSpecialist returns the specialist as a change of its overrides stores it, with the next version
+values maps the changed fields to their new values, source is where the fields come from after the change
+*/
+func overriddenSpecialist(s *types.Specialist, values map[string]string, source string) *types.Specialist {
+	after := *s
+	after.FieldSources = maps.Clone(s.FieldSources)
+	if after.FieldSources == nil {
+		after.FieldSources = types.NewFieldSources(nil)
+	}
+
+	for field, value := range values {
+		*after.OverridableField(field) = value
+		after.FieldSources[field] = source
+	}
+	after.Version++
+
+	return &after
+}
+
+/*
+overrideValues validates the values of overridden fields the way SaveSpecialist validates them
+The values are trimmed, the location is returned in the WKT format
+The function returns a validation error for a field that cannot be overridden or an invalid value
+*/
+func overrideValues(fields map[string]string) (map[string]string, error) {
+	names := make([]string, 0, len(fields))
+	for field := range fields {
+		names = append(names, field)
+	}
+	slices.Sort(names)
+
+	values := make(map[string]string, len(fields))
+	for _, field := range names {
+		if !slices.Contains(types.SpecialistOverrideFields, field) {
+			return nil, types.NewInvalidFieldError("fields", fmt.Sprintf("%q cannot be overridden", field))
+		}
+
+		value := strings.TrimSpace(fields[field])
+
+		switch field {
+		case "name":
+			if value == "" {
+				return nil, types.NewInvalidFieldError("name", "must not be empty")
+			}
+		case "location":
+			location, err := parseLocation(types.LocationInput(value))
+			if err != nil {
+				return nil, types.NewInvalidFieldError("location", "is not a valid location: "+err.Error())
+			}
+			value = location.WKT()
+		case "url":
+			if value != "" && !isWebURL(value) {
+				return nil, types.NewInvalidFieldError("url", "must be an http or https URL")
+			}
+		case "email":
+			if value != "" && !isEmail(value) {
+				return nil, types.NewInvalidFieldError("email", "is not a valid email address")
+			}
+		}
+
+		values[field] = value
+	}
+
+	return values, nil
+}
@@ -2,17 +2,20 @@ package handlers
 
 import (
 	"bytes"
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
 	"strings"
 	"testing"
+	"time"
 
 	"github.com/DATA-DOG/go-sqlmock"
 	"github.com/acornak/healthcare-poc/models"
 	"github.com/acornak/healthcare-poc/types"
 	"github.com/gin-gonic/gin"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
 )
@@ -134,7 +137,8 @@ func TestFindSpecialistHandler_SqlError(t *testing.T) {
 		t.Errorf("Error unmarshaling response: %v", err)
 	}
 
-	assert.Equal(t, "mocked error", response.Error)
+	assert.Equal(t, "Something went wrong, please try again later", response.Error)
+	assert.Equal(t, types.ErrorCodeInternal, response.Code)
 }
 
 func TestFindSpecialistHandler_EmptyResponse(t *testing.T) {
@@ -215,8 +219,8 @@ func TestFindSpecialistHandler_Success(t *testing.T) {
 		Sunday:      "",
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}).
-		AddRow(specialist.ID, specialist.Name, specialist.SpecialtyID, specialist.Location, specialist.Address, specialist.Url, specialist.Telephone, specialist.Email, specialist.Monday, specialist.Tuesday, specialist.Wednesday, specialist.Thursday, specialist.Friday, specialist.Saturday, specialist.Sunday)
+	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"}).
+		AddRow(specialist.ID, specialist.Name, specialist.SpecialtyID, specialist.Location, specialist.Address, specialist.Url, specialist.Telephone, specialist.Email, specialist.Monday, specialist.Tuesday, specialist.Wednesday, specialist.Thursday, specialist.Friday, specialist.Saturday, specialist.Sunday, specialist.Staff, 1, nil)
 
 	mock.ExpectQuery("SELECT (.+) FROM specialist").WithArgs(1, "POINT(-71.060316 48.432044)", 10).WillReturnRows(rows)
 
@@ -276,3 +280,805 @@ func TestFindSpecialistHandler_Success(t *testing.T) {
 		assert.Equal(t, field.expected, field.got)
 	}
 }
+
+func TestSearchSpecialistHandler_InvalidJson(t *testing.T) {
+	r := gin.New()
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := &Handler{
+		Logger: logger,
+	}
+
+	req, _ := http.NewRequest("POST", "/specialist/search", strings.NewReader("{invalid_json}"))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+
+	r.POST("/specialist/search", handler.SearchSpecialist)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+
+	var response ErrorResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Equal(t, "Invalid JSON payload", response.Error)
+}
+
+func TestSearchSpecialistHandler_InvalidPayload(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := &Handler{
+		Logger: logger,
+	}
+
+	tests := []struct {
+		payload  SearchSpecialistPayload
+		expected string
+	}{
+		{SearchSpecialistPayload{Query: " , "}, "Invalid payload: missing query field"},
+		{SearchSpecialistPayload{Query: "kosice", Limit: 500}, "Invalid payload: limit must be between 1 and 100"},
+		{SearchSpecialistPayload{Query: "kosice", Limit: -1}, "Invalid payload: limit must be between 1 and 100"},
+		{SearchSpecialistPayload{Query: "kosice", Origin: "Košice"}, `Invalid payload: origin is not a valid location: invalid location "Košice", expected a WKT point, e.g. POINT(21.2496774 48.7172272)`},
+		{SearchSpecialistPayload{Query: "kosice", Origin: "POINT(48.72 21.25)"}, "Invalid payload: origin is not a valid location: POINT(48.72 21.25) lies outside the service area but POINT(21.25 48.72) lies inside it, " + types.ErrAxisOrder.Error()},
+	}
+
+	for _, test := range tests {
+		payloadJSON, err := json.Marshal(test.payload)
+		if err != nil {
+			t.Fatal(err)
+		}
+		req, err := http.NewRequest("POST", "/specialist/search", bytes.NewBuffer(payloadJSON))
+		if err != nil {
+			t.Fatal(err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/specialist/search", handler.SearchSpecialist)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response ErrorResponse
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			t.Errorf("Error unmarshaling response: %v", err)
+		}
+
+		assert.Equal(t, test.expected, response.Error)
+	}
+}
+
+func TestSearchSpecialistHandler_SqlError(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM specialist s JOIN specialty sp").WithArgs(pq.Array([]string{"ocny", "lekar"}), searchMinRank, searchDefaultLimit).WillReturnError(errors.New("mocked error"))
+
+	r := gin.New()
+	handler := &Handler{
+		Logger: logger,
+		Models: models.NewModels(db),
+	}
+
+	payloadJSON, err := json.Marshal(SearchSpecialistPayload{Query: "Očný lekár"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest("POST", "/specialist/search", bytes.NewBuffer(payloadJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r.POST("/specialist/search", handler.SearchSpecialist)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchSpecialistHandler_Success(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden", "name", "rank"}).
+		AddRow(1, "Ambulancia očného lekárstva", 2, "POINT(21.9 48.7)", "Hlavná 1, 07101 Michalovce", "", "", "", "", "", "", "", "", "", "", "MUDr. Ján Novák", 1, nil, "oftalmológia", 0.72)
+
+	mock.ExpectQuery("SELECT (.+) FROM specialist s JOIN specialty sp").WithArgs(pq.Array([]string{"ocny", "lekar", "michalovce"}), searchMinRank, 5).WillReturnRows(rows)
+
+	r := gin.New()
+	handler := &Handler{
+		Logger: logger,
+		Models: models.NewModels(db),
+	}
+
+	payloadJSON := `{"query": "ocny lekar michalovce", "limit": 5, "origin": {"lat": 48.72, "lon": 21.25}}`
+	req, err := http.NewRequest("POST", "/specialist/search", bytes.NewBufferString(payloadJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r.POST("/specialist/search", handler.SearchSpecialist)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response SearchSpecialistResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Len(t, response.Results, 1)
+	assert.Equal(t, 1, response.Results[0].Specialist.ID)
+	assert.Equal(t, 0.72, response.Results[0].Rank)
+	assert.Equal(t, map[string]string{
+		"name":    "Ambulancia <mark>očného</mark> <mark>lekárstva</mark>",
+		"address": "Hlavná 1, 07101 <mark>Michalovce</mark>",
+	}, response.Results[0].Highlights)
+	assert.Equal(t, "https://www.google.com/maps/dir/?api=1&destination=48.7%2C21.9&origin=48.72%2C21.25", response.Results[0].Specialist.Navigation.GoogleMapsDirections)
+	assert.Equal(t, "https://waze.com/ul?ll=48.7%2C21.9&navigate=yes", response.Results[0].Specialist.Navigation.WazeDirections)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistHandler_InvalidRequest(t *testing.T) {
+	handler := &Handler{}
+
+	for _, id := range []string{"abc", "0", "-1", "7?origin=Ko%C5%A1ice", "7?origin=POINT(48.72%2021.25)"} {
+		req, _ := http.NewRequest("GET", "/specialist/"+id, nil)
+
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.GET("/specialist/:id", handler.GetSpecialist)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, id)
+	}
+}
+
+func TestGetSpecialistHandler_SqlError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM specialist s").WithArgs(7).WillReturnError(errors.New("mocked error"))
+
+	handler := &Handler{
+		Logger: zap.NewNop(),
+		Models: models.NewModels(db),
+	}
+
+	req, _ := http.NewRequest("GET", "/specialist/7", nil)
+
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.GET("/specialist/:id", handler.GetSpecialist)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistHandler_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM specialist s").WithArgs(7).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	handler := &Handler{
+		Models: models.NewModels(db),
+	}
+
+	req, _ := http.NewRequest("GET", "/specialist/7", nil)
+
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.GET("/specialist/:id", handler.GetSpecialist)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	var response ErrorResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Equal(t, "Specialist not found", response.Error)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistHandler_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden", "insurers", "specialty_name", "updated_at", "count", "average_rating"}).
+		AddRow(7, "Ambulancia očného lekárstva", 2, "POINT(21.9 48.7)", "Hlavná 1, 07101 Michalovce", "", "", "", "7:00 - 12:00, 13:00 - 15:00", "", "", "", "", "", "", "MUDr. Ján Novák", 1, nil, "{VšZP}", "oftalmológia", updatedAt, 2, 4.25)
+
+	mock.ExpectQuery("SELECT (.+) FROM specialist s").WithArgs(7).WillReturnRows(rows)
+	mock.ExpectQuery("SELECT (.+) FROM holiday_override").WithArgs(2024).WillReturnRows(sqlmock.NewRows([]string{"date", "name", "region", "closed"}))
+
+	handler := &Handler{
+		Models: models.NewModels(db),
+		// Monday 8:30 in Bratislava
+		Now: func() time.Time { return time.Date(2024, 3, 4, 7, 30, 0, 0, time.UTC) },
+	}
+
+	req, _ := http.NewRequest("GET", "/specialist/7?origin=SRID%3D4326%3BPOINT(21.25%2048.72)", nil)
+
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.GET("/specialist/:id", handler.GetSpecialist)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response types.SpecialistProfile
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Equal(t, 7, response.Specialist.ID)
+	assert.Equal(t, "oftalmológia", response.SpecialtyName)
+	assert.Equal(t, "MUDr. Ján Novák", response.Specialist.Staff)
+	assert.Equal(t, []string{"VšZP"}, response.Specialist.Insurers)
+	assert.Equal(t, types.ReviewSummary{Count: 2, AverageRating: 4.25}, response.Reviews)
+	assert.Len(t, response.OpeningHours, 7)
+	assert.Equal(t, []types.TimeRange{{Open: "07:00", Close: "12:00"}, {Open: "13:00", Close: "15:00"}}, response.OpeningHours[0].Ranges)
+	assert.Equal(t, "https://www.openstreetmap.org/?mlat=48.7&mlon=21.9#map=17/48.7/21.9", response.Specialist.Navigation.OpenStreetMap)
+	assert.Equal(t, "https://www.openstreetmap.org/directions?route=48.72%2C21.25%3B48.7%2C21.9", response.Specialist.Navigation.OpenStreetMapDirections)
+	assert.True(t, updatedAt.Equal(response.UpdatedAt))
+	assert.True(t, response.OpenNow)
+	assert.Nil(t, response.Holiday)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistHandler_Holiday(t *testing.T) {
+	tests := []struct {
+		overrides *sqlmock.Rows
+		openNow   bool
+		holiday   string
+	}{
+		{sqlmock.NewRows([]string{"date", "name", "region", "closed"}), false, "Veľkonočný pondelok"},
+		{sqlmock.NewRows([]string{"date", "name", "region", "closed"}).AddRow("2024-04-01", "Veľkonočný pondelok", "Michalovce", false), true, ""},
+		{sqlmock.NewRows([]string{"date", "name", "region", "closed"}).AddRow("2024-04-01", "Veľkonočný pondelok", "Košice", false), false, "Veľkonočný pondelok"},
+	}
+
+	for _, test := range tests {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to open sqlmock database: %s", err)
+		}
+
+		rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden", "insurers", "specialty_name", "updated_at", "count", "average_rating"}).
+			AddRow(7, "Ambulancia očného lekárstva", 2, "POINT(21.9 48.7)", "Hlavná 1, 07101 Michalovce", "", "", "", "7:00 - 12:00, 13:00 - 15:00", "", "", "", "", "", "", "", 1, nil, "{}", "oftalmológia", time.Now(), 0, 0.0)
+
+		mock.ExpectQuery("SELECT (.+) FROM specialist s").WithArgs(7).WillReturnRows(rows)
+		mock.ExpectQuery("SELECT (.+) FROM holiday_override").WithArgs(2024).WillReturnRows(test.overrides)
+
+		handler := &Handler{
+			Models: models.NewModels(db),
+			// Easter Monday 8:30 in Bratislava
+			Now: func() time.Time { return time.Date(2024, 4, 1, 6, 30, 0, 0, time.UTC) },
+		}
+
+		req, _ := http.NewRequest("GET", "/specialist/7", nil)
+
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.GET("/specialist/:id", handler.GetSpecialist)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var response types.SpecialistProfile
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			t.Errorf("Error unmarshaling response: %v", err)
+		}
+
+		assert.Equal(t, test.openNow, response.OpenNow)
+		if test.holiday == "" {
+			assert.Nil(t, response.Holiday)
+		} else {
+			assert.Equal(t, test.holiday, response.Holiday.Name)
+		}
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	}
+}
+
+func TestFindSpecialistHandler_LocationFormats(t *testing.T) {
+	for _, location := range []string{
+		`"SRID=4326;POINT (21.2496774 48.7172272)"`,
+		`{"type": "Point", "coordinates": [21.2496774, 48.7172272]}`,
+		`{"lat": 48.7172272, "lon": 21.2496774}`,
+	} {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to open sqlmock database: %s", err)
+		}
+
+		rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"})
+		mock.ExpectQuery("SELECT (.+) FROM specialist").WithArgs(1, "POINT(21.2496774 48.7172272)", 10).WillReturnRows(rows)
+
+		handler := &Handler{
+			Logger: zap.NewNop(),
+			Models: models.NewModels(db),
+		}
+
+		req, _ := http.NewRequest("POST", "/specialist", strings.NewReader(`{"specialty_id": 1, "radius": 10, "user_location": `+location+`}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/specialist", handler.FindSpecialist)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, location)
+		assert.NoError(t, mock.ExpectationsWereMet(), location)
+		db.Close()
+	}
+}
+
+func TestFindSpecialistHandler_InvalidLocation(t *testing.T) {
+	handler := &Handler{
+		Logger: zap.NewNop(),
+	}
+
+	tests := []struct {
+		location string
+		expected string
+	}{
+		{`"POINT(48.7172272 21.2496774)"`, "Invalid payload: user_location is not a valid location: POINT(48.7172272 21.2496774) lies outside the service area but POINT(21.2496774 48.7172272) lies inside it, " + types.ErrAxisOrder.Error()},
+		{`{"lat": 148.7172272, "lon": 21.2496774}`, "Invalid payload: user_location is not a valid location: latitude 148.7172272 is out of range [-90, 90], " + types.ErrAxisOrder.Error()},
+		{`"Košice"`, `Invalid payload: user_location is not a valid location: invalid location "Košice", expected a WKT point, e.g. POINT(21.2496774 48.7172272)`},
+		{`[21.2, 48.7]`, "Invalid JSON payload"},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", "/specialist", strings.NewReader(`{"specialty_id": 1, "radius": 10, "user_location": `+test.location+`}`))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/specialist", handler.FindSpecialist)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, test.location)
+
+		var response ErrorResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			t.Errorf("Error unmarshaling response: %v", err)
+		}
+
+		assert.Equal(t, test.expected, response.Error)
+	}
+}
+
+// newMemoryHandler returns a handler backed by an in-memory store with two cardiologists, one of them in Košice
+func newMemoryHandler(t *testing.T) *Handler {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	memory := models.NewMemoryModel()
+	if err := memory.InsertMultipleSpecialties(context.Background(), []types.Specialty{{Name: "kardiológia"}, {Name: "kardiologia detska"}}); err != nil {
+		t.Fatal(err)
+	}
+	for _, s := range []types.Specialist{
+		{Name: "Kardio Košice", SpecialtyID: 1, Location: "POINT(21.2496774 48.7172272)", Address: "Hlavná 1, Košice"},
+		{Name: "Kardio Prešov", SpecialtyID: 1, Location: "POINT(21.2393 48.9984)", Address: "Hlavná 2, Prešov"},
+	} {
+		if _, err := memory.InsertSpecialist(context.Background(), s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return NewHandler(logger, models.NewMemoryModels(memory))
+}
+
+func TestFindSpecialistHandler_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	req, _ := http.NewRequest("POST", "/specialist", strings.NewReader(`{"specialty_id": 1, "radius": 5000, "user_location": {"lat": 48.72, "lon": 21.25}}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/specialist", handler.FindSpecialist)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response FindSpecialistResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Len(t, response.Specialists, 1)
+	assert.Equal(t, "Kardio Košice", response.Specialists[0].Name)
+	assert.NotNil(t, response.Specialists[0].Navigation)
+}
+
+func TestGetSpecialistHandler_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+	// Wednesday 10:00 in Košice
+	handler.Now = func() time.Time { return time.Date(2024, 3, 6, 9, 0, 0, 0, time.UTC) }
+
+	specialist, _ := handler.Models.Specialists.GetSpecialistByID(context.Background(), 1)
+	specialist.Wednesday = "7:00 - 15:00"
+	if _, err := handler.Models.Specialists.UpdateSpecialist(context.Background(), *specialist); err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.GET("/specialist/:id", handler.GetSpecialist)
+
+	get := func() *types.SpecialistProfile {
+		req, _ := http.NewRequest("GET", "/specialist/1", nil)
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code)
+
+		var profile types.SpecialistProfile
+		if err := json.Unmarshal(w.Body.Bytes(), &profile); err != nil {
+			t.Errorf("Error unmarshaling response: %v", err)
+		}
+
+		return &profile
+	}
+
+	profile := get()
+	assert.Equal(t, "Kardio Košice", profile.Specialist.Name)
+	assert.Equal(t, "kardiológia", profile.SpecialtyName)
+	assert.True(t, profile.OpenNow)
+	assert.Nil(t, profile.Holiday)
+
+	// a closure kept in memory applies Sunday hours
+	if err := handler.Models.Holidays.SetHolidayOverride(context.Background(), types.Holiday{Date: "2024-03-06", Name: "Zatvorené", Closed: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	profile = get()
+	assert.False(t, profile.OpenNow)
+	assert.Equal(t, "Zatvorené", profile.Holiday.Name)
+}
+
+func TestSearchSpecialistHandler_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	req, _ := http.NewRequest("POST", "/specialist/search", strings.NewReader(`{"query": "kardio presov"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/specialist/search", handler.SearchSpecialist)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response SearchSpecialistResponse
+	err := json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Len(t, response.Results, 2)
+	assert.Equal(t, "Kardio Prešov", response.Results[0].Specialist.Name)
+	assert.Equal(t, "<mark>Kardio</mark> <mark>Prešov</mark>", response.Results[0].Highlights["name"])
+}
+
+func TestSearchSpecialistHandler_ClientAbort(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	handler := &Handler{
+		Logger: logger,
+		Models: models.NewModels(db),
+	}
+
+	// the client is gone before the query starts, so it is never sent
+	ctx, cancel := context.WithCancel(context.Background())
+	cancel()
+
+	req, _ := http.NewRequestWithContext(ctx, "POST", "/specialist/search", strings.NewReader(`{"query": "kardio"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/specialist/search", handler.SearchSpecialist)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusServiceUnavailable, w.Code)
+	assert.JSONEq(t, `{"error": "The request was cancelled", "code": "unavailable"}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveSpecialistHandler_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	r := gin.New()
+	r.POST("/admin/specialist", handler.SaveSpecialist)
+
+	post := func(payload string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", "/admin/specialist", strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post(`{"name": " Kardio Michalovce ", "specialty_id": 1, "location": {"lat": 48.75, "lon": 21.92}, "url": "https://kardio-mi.sk", "insurers": ["VšZP", "VšZP", " Dôvera "]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id": 3, "name": "Kardio Michalovce", "specialty_id": 1, "location": "POINT(21.92 48.75)", "url": "https://kardio-mi.sk", "insurers": ["VšZP", "Dôvera"], "version": 1,
+		"field_sources": {"name": "source", "location": "source", "address": "source", "url": "source", "telephone": "source", "email": "source", "monday": "source", "tuesday": "source", "wednesday": "source", "thursday": "source", "friday": "source", "saturday": "source", "sunday": "source", "staff": "source"}}`, w.Body.String())
+
+	w = post(`{"id": 3, "version": 1, "name": "Kardio Michalovce", "specialty_id": 2, "location": "POINT(21.92 48.75)", "monday": "7:00 - 12:00"}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"version":2`)
+
+	// the update above bumped the version, an update based on the old one is rejected
+	w = post(`{"id": 3, "version": 1, "name": "Kardio Michalovce II", "specialty_id": 2, "location": "POINT(21.92 48.75)"}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+	assert.Contains(t, w.Body.String(), `"code":"conflict"`)
+
+	specialist, err := handler.Models.Specialists.GetSpecialistByID(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, "Kardio Michalovce", specialist.Name)
+	assert.Equal(t, "7:00 - 12:00", specialist.Monday)
+
+	entries, err := handler.Models.Audit.GetAuditLog(context.Background(), types.AuditEntitySpecialist, 3, 10)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, types.AuditActionUpdate, entries[0].Action)
+	assert.Contains(t, string(entries[0].Before), `"specialty_id":1`)
+	assert.Contains(t, string(entries[0].After), `"specialty_id":2`)
+
+	w = post(`{"id": 42, "version": 1, "name": "Neexistuje", "specialty_id": 1, "location": "POINT(21.92 48.75)"}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSaveSpecialistHandler_Validation(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	r := gin.New()
+	r.POST("/admin/specialist", handler.SaveSpecialist)
+
+	tests := []struct {
+		payload string
+		message string
+	}{
+		{`{"name": "Kardio"`, "Invalid JSON payload"},
+		{`{"name": " "}`, "Invalid payload: missing name, specialty_id, location"},
+		{`{"id": 1, "name": "Kardio", "specialty_id": 1, "location": "POINT(21.92 48.75)"}`, "Invalid payload: missing version"},
+		{`{"name": "Kardio", "specialty_id": 1, "location": "somewhere"}`, "Invalid payload: location is not a valid location"},
+		{`{"name": "Kardio", "specialty_id": 1, "location": "POINT(21.92 48.75)", "url": "kardio.sk"}`, "Invalid payload: url must be an http or https URL"},
+		{`{"name": "Kardio", "specialty_id": 1, "location": "POINT(21.92 48.75)", "email": "kardio"}`, "Invalid payload: email is not a valid email address"},
+		{`{"name": "Kardio", "specialty_id": 9, "location": "POINT(21.92 48.75)"}`, "Invalid payload: specialty_id does not exist"},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", "/admin/specialist", strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, test.payload)
+		assert.Contains(t, w.Body.String(), test.message, test.payload)
+	}
+
+	entries, err := handler.Models.Audit.GetAuditLog(context.Background(), "", 0, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestSaveSpecialistHandler_AuditError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.id=\$1`).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "kardiológia", ""))
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`INSERT INTO specialist (.+) RETURNING id`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectExec(`INSERT INTO admin_audit_log`).WithArgs("admin", "", "create", "specialist", 7, nil, sqlmock.AnyArg()).
+		WillReturnError(errors.New("pq: relation \"admin_audit_log\" does not exist"))
+	mock.ExpectRollback()
+
+	handler := &Handler{Logger: zap.NewNop(), Models: models.NewModels(db)}
+
+	req, _ := http.NewRequest("POST", "/admin/specialist", strings.NewReader(`{"name": "Kardio", "specialty_id": 1, "location": "POINT(21.92 48.75)"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/admin/specialist", handler.SaveSpecialist)
+	r.ServeHTTP(w, req)
+
+	// the insert is rolled back with the audit entry, so no change goes unaudited
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.JSONEq(t, `{"error": "Something went wrong, please try again later", "code": "internal_error"}`, w.Body.String())
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteSpecialistHandler_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	if _, err := handler.Models.Reviews.InsertReview(context.Background(), types.Review{SpecialistId: 1, Url: "https://example.com", Rating: 5}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.POST("/admin/specialist/delete", handler.DeleteSpecialist)
+
+	tests := []struct {
+		payload  string
+		expected int
+	}{
+		{`{"id": 2}`, http.StatusBadRequest},
+		{`{"id": 42, "version": 1}`, http.StatusNotFound},
+		{`{"id": 1, "version": 1}`, http.StatusConflict},
+		{`{"id": 2, "version": 3}`, http.StatusConflict},
+		{`{"id": 2, "version": 1}`, http.StatusOK},
+		{`{"id": 2, "version": 1}`, http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", "/admin/specialist/delete", strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, test.expected, w.Code, test.payload)
+	}
+
+	entries, err := handler.Models.Audit.GetAuditLog(context.Background(), types.AuditEntitySpecialist, 2, 10)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, types.AuditActionDelete, entries[0].Action)
+	assert.Contains(t, string(entries[0].Before), `"name":"Kardio Prešov"`)
+	assert.Nil(t, entries[0].After)
+}
+
+func TestSpecialistOverridesHandler_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	r := gin.New()
+	r.POST("/admin/specialist/overrides", handler.GetSpecialistOverrides)
+	r.POST("/admin/specialist/override", handler.SetSpecialistOverrides)
+	r.POST("/admin/specialist/override/delete", handler.DeleteSpecialistOverrides)
+
+	post := func(path, payload string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", path, strings.NewReader(payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("/admin/specialist/override", `{"id": 1, "version": 1, "fields": {"name": " Kardiológia Košice ", "location": "POINT(21.25 48.72)"}}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var specialist types.Specialist
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &specialist))
+	assert.Equal(t, "Kardiológia Košice", specialist.Name)
+	assert.Equal(t, "POINT(21.25 48.72)", specialist.Location)
+	assert.Equal(t, 2, specialist.Version)
+	assert.Equal(t, types.FieldSourceOverride, specialist.FieldSources["name"])
+	assert.Equal(t, types.FieldSourceOverride, specialist.FieldSources["location"])
+	assert.Equal(t, types.FieldSourceSource, specialist.FieldSources["address"])
+
+	w = post("/admin/specialist/overrides", `{"id": 1}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"field":"name","value":"Kardiológia Košice","source_value":"Kardio Košice"`)
+
+	// the override was based on the first version
+	w = post("/admin/specialist/override/delete", `{"id": 1, "version": 1, "fields": ["name"]}`)
+	assert.Equal(t, http.StatusConflict, w.Code)
+
+	w = post("/admin/specialist/override/delete", `{"id": 1, "version": 2, "fields": ["name"]}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.NoError(t, json.Unmarshal(w.Body.Bytes(), &specialist))
+	assert.Equal(t, "Kardio Košice", specialist.Name)
+	assert.Equal(t, types.FieldSourceSource, specialist.FieldSources["name"])
+	assert.Equal(t, 3, specialist.Version)
+
+	entries, err := handler.Models.Audit.GetAuditLog(context.Background(), types.AuditEntitySpecialist, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Contains(t, string(entries[1].Before), `"name":"Kardio Košice"`)
+	assert.Contains(t, string(entries[1].After), `"name":"Kardiológia Košice"`)
+
+	w = post("/admin/specialist/overrides", `{"id": 42}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+}
+
+func TestSetSpecialistOverridesHandler_Validation(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	r := gin.New()
+	r.POST("/admin/specialist/override", handler.SetSpecialistOverrides)
+	r.POST("/admin/specialist/override/delete", handler.DeleteSpecialistOverrides)
+
+	tests := []struct {
+		path    string
+		payload string
+		code    int
+		message string
+	}{
+		{"/admin/specialist/override", `{"id": 1}`, http.StatusBadRequest, "Invalid payload: missing version, fields"},
+		{"/admin/specialist/override", `{"id": 1, "version": 1, "fields": {"specialty_id": "2"}}`, http.StatusBadRequest, `Invalid payload: fields \"specialty_id\" cannot be overridden`},
+		{"/admin/specialist/override", `{"id": 1, "version": 1, "fields": {"name": " "}}`, http.StatusBadRequest, "Invalid payload: name must not be empty"},
+		{"/admin/specialist/override", `{"id": 1, "version": 1, "fields": {"location": "somewhere"}}`, http.StatusBadRequest, "Invalid payload: location is not a valid location"},
+		{"/admin/specialist/override", `{"id": 1, "version": 1, "fields": {"url": "kardio.sk"}}`, http.StatusBadRequest, "Invalid payload: url must be an http or https URL"},
+		{"/admin/specialist/override", `{"id": 42, "version": 1, "fields": {"telephone": "055/123"}}`, http.StatusNotFound, "Specialist not found"},
+		{"/admin/specialist/override/delete", `{"id": 1, "version": 1, "fields": ["insurers"]}`, http.StatusBadRequest, `Invalid payload: fields \"insurers\" cannot be overridden`},
+		{"/admin/specialist/override/delete", `{"id": 1, "version": 1, "fields": ["email"]}`, http.StatusOK, `"version":1`},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", test.path, strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, test.code, w.Code, test.payload)
+		assert.Contains(t, w.Body.String(), test.message, test.payload)
+	}
+}
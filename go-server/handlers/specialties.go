@@ -2,6 +2,7 @@ package handlers
 
 import (
 	"net/http"
+	"strings"
 
 	"github.com/acornak/healthcare-poc/types"
 	"github.com/gin-gonic/gin"
@@ -11,23 +12,188 @@ type GetSpecialtiesResponse struct {
 	Specialties []*types.Specialty `json:"specialties"`
 }
 
+type SaveSpecialtyPayload struct {
+	ID          int    `json:"id,omitempty"`
+	Name        string `json:"name"`
+	Description string `json:"description"`
+}
+
+type DeleteSpecialtyPayload struct {
+	ID int `json:"id"`
+}
+
+// supported languages of the specialty taxonomy, the first one is the default
+var specialtyLanguages = []string{"sk", "en", "hu"}
+
 // @Summary		Get specialties
-// @Description	Get all specialties
+// @Description	Get all specialties with descriptions, categories and synonyms in the language chosen by Accept-Language
 // @ID			specialties
 // @Accept		json
 // @Produce		json
+// @Param		Accept-Language	header		string	false	"Preferred language: sk, en or hu"
 // @Success		200		{object}	GetSpecialtiesResponse
 // @Failure		500		{object}	ErrorResponse
 // @Router		/specialty/all [post]
 func (h *Handler) GetSpecialties(c *gin.Context) {
-	var errResp ErrorResponse
+	language := preferredLanguage(c.GetHeader("Accept-Language"), specialtyLanguages)
 
-	specialties, err := h.Models.DB.GetAllSpecialties()
+	specialties, err := h.Models.Specialties.GetAllSpecialtiesLocalized(c.Request.Context(), language)
 	if err != nil {
-		errResp.Error = err.Error()
-		c.JSON(http.StatusInternalServerError, errResp)
+		h.respondError(c, err)
 		return
 	}
 
+	c.Header("Content-Language", language)
 	c.JSON(http.StatusOK, GetSpecialtiesResponse{Specialties: specialties})
 }
+
+// @Summary		Save specialty
+// @Description	Create a specialty, or rename and describe the one with the id, names are unique regardless of case and diacritics
+// @ID			admin-specialty-save
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		X-Admin-User	header		string					false	"Member of staff making the change"
+// @Param		payload			body		SaveSpecialtyPayload	true	"Specialty, with id for an update"
+// @Success		200				{object}	types.Specialty
+// @Failure		400				{object}	ErrorResponse
+// @Failure		401				{object}	ErrorResponse
+// @Failure		404				{object}	ErrorResponse
+// @Failure		409				{object}	ErrorResponse
+// @Failure		500				{object}	ErrorResponse
+// @Router		/admin/specialty [post]
+func (h *Handler) SaveSpecialty(c *gin.Context) {
+	var payload SaveSpecialtyPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	specialty := types.Specialty{
+		ID:          payload.ID,
+		Name:        strings.TrimSpace(payload.Name),
+		Description: strings.TrimSpace(payload.Description),
+	}
+
+	if specialty.Name == "" {
+		h.respondError(c, types.NewMissingFieldError("name"))
+		return
+	}
+
+	// the lookup follows the aliases, a name of a merged specialty is taken by the canonical one
+	existing, err := h.Models.Specialties.GetSpecialtyByNormalizedName(c.Request.Context(), specialty.Name)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if existing != nil && existing.ID != specialty.ID {
+		h.respondError(c, types.NewConflictError("A specialty with the same name already exists", nil))
+		return
+	}
+
+	if specialty.ID == 0 {
+		ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+			created := specialty
+			created.ID = id
+			return []auditChange{{types.AuditActionCreate, types.AuditEntitySpecialty, id, nil, created}}
+		})
+
+		specialty.ID, err = h.Models.Specialties.InsertSpecialty(ctx, specialty)
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+		logChange()
+
+		c.JSON(http.StatusOK, specialty)
+		return
+	}
+
+	before, err := h.Models.Specialties.GetSpecialtyByID(c.Request.Context(), specialty.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if before == nil {
+		h.respondError(c, types.NewNotFoundError("Specialty not found"))
+		return
+	}
+
+	ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+		return []auditChange{{types.AuditActionUpdate, types.AuditEntitySpecialty, id, before, specialty}}
+	})
+
+	if err := h.Models.Specialties.UpdateSpecialty(ctx, specialty); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	logChange()
+
+	c.JSON(http.StatusOK, specialty)
+}
+
+// @Summary		Delete specialty
+// @Description	Delete a specialty with its translations, aliases and symptom mappings, a specialty still assigned to specialists cannot be deleted, merge it instead
+// @ID			admin-specialty-delete
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		X-Admin-User	header		string					false	"Member of staff making the change"
+// @Param		payload			body		DeleteSpecialtyPayload	true	"Specialty id"
+// @Success		200				{object}	DeleteSpecialtyPayload
+// @Failure		400				{object}	ErrorResponse
+// @Failure		401				{object}	ErrorResponse
+// @Failure		404				{object}	ErrorResponse
+// @Failure		409				{object}	ErrorResponse
+// @Failure		500				{object}	ErrorResponse
+// @Router		/admin/specialty/delete [post]
+func (h *Handler) DeleteSpecialty(c *gin.Context) {
+	var payload DeleteSpecialtyPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	if payload.ID == 0 {
+		h.respondError(c, types.NewMissingFieldError("id"))
+		return
+	}
+
+	before, err := h.Models.Specialties.GetSpecialtyByID(c.Request.Context(), payload.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if before == nil {
+		h.respondError(c, types.NewNotFoundError("Specialty not found"))
+		return
+	}
+
+	specialists, err := h.Models.Specialists.GetSpecialistBySpecialty(c.Request.Context(), payload.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if len(specialists) > 0 {
+		h.respondError(c, types.NewConflictError("The specialty is still assigned to specialists, merge it instead", nil))
+		return
+	}
+
+	ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+		return []auditChange{{types.AuditActionDelete, types.AuditEntitySpecialty, id, before, nil}}
+	})
+
+	if err := h.Models.Specialties.DeleteSpecialty(ctx, payload.ID); err != nil {
+		h.respondError(c, err)
+		return
+	}
+	logChange()
+
+	c.JSON(http.StatusOK, payload)
+}
@@ -1,10 +1,12 @@
 package handlers
 
 import (
+	"context"
 	"encoding/json"
 	"errors"
 	"net/http"
 	"net/http/httptest"
+	"strings"
 	"testing"
 
 	"github.com/DATA-DOG/go-sqlmock"
@@ -26,7 +28,7 @@ func TestGetSpecialtiesHandler_SqlError(t *testing.T) {
 	}
 	defer db.Close()
 
-	mock.ExpectQuery("SELECT (.+) FROM specialty").WithoutArgs().WillReturnError(errors.New("mocked error"))
+	mock.ExpectQuery("SELECT (.+) FROM specialty").WithArgs("sk").WillReturnError(errors.New("mocked error"))
 
 	modelsDB := models.NewModels(db)
 
@@ -54,7 +56,8 @@ func TestGetSpecialtiesHandler_SqlError(t *testing.T) {
 		t.Errorf("Error unmarshaling response: %v", err)
 	}
 
-	assert.Equal(t, "mocked error", response.Error)
+	assert.Equal(t, "Something went wrong, please try again later", response.Error)
+	assert.Equal(t, types.ErrorCodeInternal, response.Code)
 }
 
 func TestGetSpecialtiesHandler_EmptyResponse(t *testing.T) {
@@ -68,9 +71,9 @@ func TestGetSpecialtiesHandler_EmptyResponse(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "name", "description"})
+	rows := sqlmock.NewRows([]string{"id", "name", "description", "synonyms", "category_id", "category_code", "category_name", "parent_code"})
 
-	mock.ExpectQuery("SELECT (.+) FROM specialty").WithoutArgs().WillReturnRows(rows)
+	mock.ExpectQuery("SELECT (.+) FROM specialty").WithArgs("sk").WillReturnRows(rows)
 
 	modelsDB := models.NewModels(db)
 
@@ -114,13 +117,21 @@ func TestGetSpecialtiesHandler_Success(t *testing.T) {
 
 	specialty := types.Specialty{
 		ID:          1,
-		Name:        "Dr. John Doe",
+		Name:        "Orthodontics",
 		Description: "Specialty description",
+		Synonyms:    []string{"orthodontist", "braces"},
+		Category: &types.SpecialtyCategory{
+			ID:         2,
+			Code:       "orthodontics",
+			Name:       "Orthodontics",
+			ParentCode: "dentistry",
+		},
 	}
 
-	rows := sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(specialty.ID, specialty.Name, specialty.Description)
+	rows := sqlmock.NewRows([]string{"id", "name", "description", "synonyms", "category_id", "category_code", "category_name", "parent_code"}).
+		AddRow(specialty.ID, specialty.Name, specialty.Description, "{orthodontist,braces}", 2, "orthodontics", "Orthodontics", "dentistry")
 
-	mock.ExpectQuery("SELECT (.+) FROM specialty").WithoutArgs().WillReturnRows(rows)
+	mock.ExpectQuery("SELECT (.+) FROM specialty").WithArgs("en").WillReturnRows(rows)
 
 	modelsDB := models.NewModels(db)
 
@@ -134,12 +145,14 @@ func TestGetSpecialtiesHandler_Success(t *testing.T) {
 		t.Fatal(err)
 	}
 	req.Header.Set("Content-Type", "application/json")
+	req.Header.Set("Accept-Language", "en-US,en;q=0.9,sk;q=0.8")
 
 	w := httptest.NewRecorder()
 	r.POST("/specialties", handler.GetSpecialties)
 	r.ServeHTTP(w, req)
 
 	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
 
 	var response GetSpecialtiesResponse
 	err = json.Unmarshal(w.Body.Bytes(), &response)
@@ -154,6 +167,8 @@ func TestGetSpecialtiesHandler_Success(t *testing.T) {
 		{specialty.ID, response.Specialties[0].ID, "ID"},
 		{specialty.Name, response.Specialties[0].Name, "Name"},
 		{specialty.Description, response.Specialties[0].Description, "SpecialtyID"},
+		{specialty.Synonyms, response.Specialties[0].Synonyms, "Synonyms"},
+		{specialty.Category, response.Specialties[0].Category, "Category"},
 	}
 
 	for _, field := range fields {
@@ -161,3 +176,94 @@ func TestGetSpecialtiesHandler_Success(t *testing.T) {
 	}
 
 }
+
+func TestGetSpecialtiesHandler_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	req, _ := http.NewRequest("POST", "/specialty/all", nil)
+	req.Header.Set("Accept-Language", "en")
+
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/specialty/all", handler.GetSpecialties)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Equal(t, "en", w.Header().Get("Content-Language"))
+
+	var response GetSpecialtiesResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Len(t, response.Specialties, 2)
+	assert.Equal(t, "kardiológia", response.Specialties[0].Name)
+}
+
+func TestSaveSpecialtyHandler_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	r := gin.New()
+	r.POST("/admin/specialty", handler.SaveSpecialty)
+
+	tests := []struct {
+		payload  string
+		expected int
+		body     string
+	}{
+		{`{"name": " "}`, http.StatusBadRequest, `"fields":[{"field":"name","message":"is required"}]`},
+		{`{"name": "dermatológia", "description": " koža "}`, http.StatusOK, `{"id":3,"name":"dermatológia","description":"koža"}`},
+		{`{"name": "Dermatologia"}`, http.StatusConflict, `"code":"conflict"`},
+		{`{"id": 3, "name": "Dermatológia", "description": "koža a vlasy"}`, http.StatusOK, `{"id":3,"name":"Dermatológia","description":"koža a vlasy"}`},
+		{`{"id": 3, "name": "Kardiologia"}`, http.StatusConflict, `"code":"conflict"`},
+		{`{"id": 42, "name": "neurológia"}`, http.StatusNotFound, `"code":"not_found"`},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", "/admin/specialty", strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, test.expected, w.Code, test.payload)
+		assert.Contains(t, w.Body.String(), test.body, test.payload)
+	}
+
+	entries, err := handler.Models.Audit.GetAuditLog(context.Background(), types.AuditEntitySpecialty, 3, 10)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.JSONEq(t, `{"id":3,"name":"dermatológia","description":"koža"}`, string(entries[0].Before))
+}
+
+func TestDeleteSpecialtyHandler_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	r := gin.New()
+	r.POST("/admin/specialty/delete", handler.DeleteSpecialty)
+
+	tests := []struct {
+		payload  string
+		expected int
+	}{
+		{`{}`, http.StatusBadRequest},
+		{`{"id": 42}`, http.StatusNotFound},
+		{`{"id": 1}`, http.StatusConflict},
+		{`{"id": 2}`, http.StatusOK},
+		{`{"id": 2}`, http.StatusNotFound},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", "/admin/specialty/delete", strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, test.expected, w.Code, test.payload)
+	}
+
+	entries, err := handler.Models.Audit.GetAuditLog(context.Background(), "", 0, 10)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, types.AuditActionDelete, entries[0].Action)
+	assert.Equal(t, 2, entries[0].EntityID)
+}
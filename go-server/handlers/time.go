@@ -1,52 +1,173 @@
 package handlers
 
 import (
+	"context"
 	"net/http"
+	"strconv"
 	"time"
 
+	"github.com/acornak/healthcare-poc/holidays"
+	"github.com/acornak/healthcare-poc/types"
 	"github.com/gin-gonic/gin"
 )
 
 type GetCurrentTimePayload struct {
-	Timezone string `json:"timezone"`
+	Timezone string              `json:"timezone" example:"Europe/Bratislava"`
+	Location types.LocationInput `json:"location" swaggertype:"string" example:"POINT(21.2496774 48.7172272)"`
 }
 
+// the specialists are all in Slovakia, their opening hours are in its local time
+const clinicTimezone = "Europe/Bratislava"
+
 type GetCurrentTimeResponse struct {
-	Time string `json:"time"`
+	Time      string `json:"time"`
+	Timestamp string `json:"timestamp"`
+	Timezone  string `json:"timezone"`
+	UTCOffset string `json:"utc_offset"`
+	DST       bool   `json:"dst"`
+	Weekday   string `json:"weekday"`
+	// the location is outside the known timezone boundaries, the time is that of the nautical timezone of its longitude
+	Approximate bool `json:"approximate,omitempty"`
 }
 
 // @Summary		Get current time
-// @Description	Get the current time in a specific timezone
+// @Description	Get the current time at a location or in a specific IANA timezone, the location takes precedence
+// @Description	The timezone of a location is resolved offline, locations outside Slovakia and its neighbours get the nautical timezone of their longitude,
+// @Description	which ignores daylight saving time and may be hours off the local time, such results are flagged approximate
 // @ID			current-time
 // @Accept		json
 // @Produce		json
+// @Param		payload	body		GetCurrentTimePayload	true	"WKT point or IANA timezone, e.g. Europe/Bratislava"
 // @Success		200		{object}	GetCurrentTimeResponse
+// @Failure		400		{object}	ErrorResponse
 // @Failure		500		{object}	ErrorResponse
 // @Router		/time/current [post]
 func (h *Handler) GetCurrentTime(c *gin.Context) {
 	var payload GetCurrentTimePayload
-	var errResp ErrorResponse
 
 	if err := c.ShouldBindJSON(&payload); err != nil {
-		errResp.Error = "Invalid JSON payload"
-		c.JSON(http.StatusBadRequest, errResp)
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
 		return
 	}
 
-	if payload.Timezone == "" {
-		errResp.Error = "Invalid payload: missing timezone field"
-		c.JSON(http.StatusBadRequest, errResp)
+	var loc *time.Location
+	var approximate bool
+	switch {
+	case payload.Location != "":
+		location, err := payload.Location.Location()
+		if err != nil {
+			h.respondError(c, types.NewInvalidFieldError("location", "is not a valid location: "+err.Error()))
+			return
+		}
+
+		if h.Timezones == nil {
+			h.respondError(c, types.NewError(types.ErrorCodeUnavailable, "Timezone lookup is not configured"))
+			return
+		}
+
+		loc, approximate, err = h.Timezones.Location(location)
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+	case payload.Timezone != "":
+		var err error
+		loc, err = time.LoadLocation(payload.Timezone)
+		if err != nil {
+			h.respondError(c, types.NewValidationError("Invalid timezone"))
+			return
+		}
+	default:
+		h.respondError(c, types.NewValidationError("Invalid payload: missing location or timezone field"))
 		return
 	}
 
-	loc, err := time.LoadLocation(payload.Timezone)
+	resp := currentTime(h.now().In(loc))
+	resp.Approximate = approximate
+
+	c.JSON(http.StatusOK, resp)
+}
+
+/*
+currentTime describes the time for the LLM, the weekday and the offset spelled out so it does not have to work them out
+*/
+func currentTime(now time.Time) GetCurrentTimeResponse {
+	return GetCurrentTimeResponse{
+		Time:      now.Format("2006-01-02 15:04:05"),
+		Timestamp: now.Format(time.RFC3339),
+		Timezone:  now.Location().String(),
+		UTCOffset: now.Format("-07:00"),
+		DST:       now.IsDST(),
+		Weekday:   now.Weekday().String(),
+	}
+}
+
+type GetHolidaysResponse struct {
+	Year     int              `json:"year"`
+	Region   string           `json:"region,omitempty"`
+	Holidays []*types.Holiday `json:"holidays"`
+}
+
+// @Summary		Get holidays
+// @Description	Get the Slovak public holidays of a year together with the closures and cancellations edited by an admin
+// @Description	The clinics keep their Sunday hours on these days
+// @ID			time-holidays
+// @Produce		json
+// @Param		year	query		int		false	"Year, the current one when omitted"
+// @Param		region	query		string	false	"Municipality or district, only its regional overrides and those of the district of the municipality are applied"
+// @Success		200		{object}	GetHolidaysResponse
+// @Failure		400		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/time/holidays [get]
+func (h *Handler) GetHolidays(c *gin.Context) {
+	year := clinicTime(h.now()).Year()
+	if raw := c.Query("year"); raw != "" {
+		parsed, err := strconv.Atoi(raw)
+		if err != nil || parsed < 1900 || parsed > 2100 {
+			h.respondError(c, types.NewValidationError("Invalid year: must be between 1900 and 2100"))
+			return
+		}
+		year = parsed
+	}
+
+	calendar, err := h.holidayCalendar(c.Request.Context(), year)
 	if err != nil {
-		errResp.Error = "Invalid timezone"
-		c.JSON(http.StatusBadRequest, errResp)
+		h.respondError(c, err)
 		return
 	}
 
-	currentTime := time.Now().In(loc).Format("2006-01-02 15:04:05")
+	region := c.Query("region")
+
+	c.JSON(http.StatusOK, GetHolidaysResponse{Year: year, Region: region, Holidays: calendar.Year(year, region)})
+}
+
+// holidayCalendar returns the public holidays with the overrides of the years, each year is loaded once
+func (h *Handler) holidayCalendar(ctx context.Context, years ...int) (holidays.Calendar, error) {
+	calendar := holidays.Calendar{Overrides: []*types.Holiday{}}
+	loaded := make(map[int]bool)
+
+	for _, year := range years {
+		if loaded[year] {
+			continue
+		}
+		loaded[year] = true
+
+		overrides, err := h.Models.Holidays.GetHolidayOverrides(ctx, year)
+		if err != nil {
+			return holidays.Calendar{}, err
+		}
+		calendar.Overrides = append(calendar.Overrides, overrides...)
+	}
+
+	return calendar, nil
+}
+
+// clinicTime returns t in the local time of the specialists
+func clinicTime(t time.Time) time.Time {
+	loc, err := time.LoadLocation(clinicTimezone)
+	if err != nil {
+		return t
+	}
 
-	c.JSON(http.StatusOK, GetCurrentTimeResponse{Time: currentTime})
+	return t.In(loc)
 }
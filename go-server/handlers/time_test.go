@@ -9,6 +9,10 @@ import (
 	"testing"
 	"time"
 
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/models"
+	"github.com/acornak/healthcare-poc/timezone"
+	"github.com/acornak/healthcare-poc/types"
 	"github.com/gin-gonic/gin"
 	"github.com/stretchr/testify/assert"
 	"go.uber.org/zap"
@@ -86,7 +90,7 @@ func TestGetCurrentTime_IncompletePayload(t *testing.T) {
 		t.Errorf("Error unmarshaling response: %v", err)
 	}
 
-	assert.Equal(t, "Invalid payload: missing timezone field", response.Error)
+	assert.Equal(t, "Invalid payload: missing location or timezone field", response.Error)
 }
 
 func TestGetCurrentTime_InvalidTimezone(t *testing.T) {
@@ -178,3 +182,161 @@ func TestGetCurrentTime_Success(t *testing.T) {
 	_, parseErr := time.Parse(expectedFormat, response.Time)
 	assert.Nil(t, parseErr, "The time should be in the expected format")
 }
+
+func TestGetCurrentTime_Location(t *testing.T) {
+	finder, err := timezone.NewDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := []struct {
+		payload     string
+		timezone    string
+		approximate bool
+	}{
+		{`{"location": "POINT(21.2496774 48.7172272)"}`, "Europe/Bratislava", false},
+		{`{"location": {"lat": 48.6208, "lon": 22.2879}}`, "Europe/Kyiv", false},
+		{`{"location": "POINT(121.4737 31.2304)", "timezone": "Shanghai/China"}`, "Etc/GMT-8", true},
+	}
+
+	for _, test := range tests {
+		handler := &Handler{Logger: zap.NewNop(), Timezones: finder}
+
+		req, _ := http.NewRequest("POST", "/time/current", strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/time/current", handler.GetCurrentTime)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusOK, w.Code, test.payload)
+
+		var response GetCurrentTimeResponse
+		err := json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			t.Errorf("Error unmarshaling response: %v", err)
+		}
+
+		assert.Equal(t, test.timezone, response.Timezone, test.payload)
+		assert.Equal(t, test.approximate, response.Approximate, test.payload)
+
+		timestamp, err := time.Parse(time.RFC3339, response.Timestamp)
+		assert.NoError(t, err, test.payload)
+		assert.Equal(t, timestamp.Weekday().String(), response.Weekday, test.payload)
+		assert.Equal(t, timestamp.Format("-07:00"), response.UTCOffset, test.payload)
+	}
+}
+
+func TestGetCurrentTime_InvalidLocation(t *testing.T) {
+	handler := &Handler{Logger: zap.NewNop()}
+
+	req, _ := http.NewRequest("POST", "/time/current", strings.NewReader(`{"location": "POINT(21.2)"}`))
+	req.Header.Set("Content-Type", "application/json")
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/time/current", handler.GetCurrentTime)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusBadRequest, w.Code)
+	assert.JSONEq(t, `{"error":"Invalid payload: location is not a valid location: WKT point must have 2 coordinates, got 1","code":"invalid_payload","fields":[{"field":"location","message":"is not a valid location: WKT point must have 2 coordinates, got 1"}]}`, w.Body.String())
+}
+
+func TestCurrentTime(t *testing.T) {
+	bratislava, err := time.LoadLocation("Europe/Bratislava")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	summer := currentTime(time.Date(2024, 7, 15, 9, 30, 0, 0, bratislava))
+	assert.Equal(t, GetCurrentTimeResponse{
+		Time:      "2024-07-15 09:30:00",
+		Timestamp: "2024-07-15T09:30:00+02:00",
+		Timezone:  "Europe/Bratislava",
+		UTCOffset: "+02:00",
+		DST:       true,
+		Weekday:   "Monday",
+	}, summer)
+
+	winter := currentTime(time.Date(2024, 12, 24, 18, 0, 0, 0, bratislava))
+	assert.Equal(t, "+01:00", winter.UTCOffset)
+	assert.False(t, winter.DST)
+	assert.Equal(t, "Tuesday", winter.Weekday)
+}
+
+func TestGetHolidaysHandler(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM holiday_override`).WithArgs(2024).
+		WillReturnRows(sqlmock.NewRows([]string{"date", "name", "region", "closed"}).
+			AddRow("2024-05-08", "Deň víťazstva nad fašizmom", "", false).
+			AddRow("2024-12-31", "Silvester", "", true))
+
+	handler := &Handler{Logger: zap.NewNop(), Models: models.NewModels(db)}
+
+	req, _ := http.NewRequest("GET", "/time/holidays?year=2024", nil)
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.GET("/time/holidays", handler.GetHolidays)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response GetHolidaysResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Equal(t, 2024, response.Year)
+	assert.Len(t, response.Holidays, 15)
+	assert.Equal(t, "2024-03-29", response.Holidays[2].Date)
+	assert.Equal(t, types.HolidaySourcePublic, response.Holidays[2].Source)
+	assert.Equal(t, &types.Holiday{Date: "2024-12-31", Name: "Silvester", Closed: true, Source: types.HolidaySourceOverride}, response.Holidays[14])
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetHolidaysHandler_CurrentYear(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	// already 2025 in Bratislava
+	mock.ExpectQuery(`SELECT (.+) FROM holiday_override`).WithArgs(2025).WillReturnRows(sqlmock.NewRows([]string{"date", "name", "region", "closed"}))
+
+	handler := &Handler{
+		Logger: zap.NewNop(),
+		Models: models.NewModels(db),
+		Now:    func() time.Time { return time.Date(2024, 12, 31, 23, 30, 0, 0, time.UTC) },
+	}
+
+	req, _ := http.NewRequest("GET", "/time/holidays", nil)
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.GET("/time/holidays", handler.GetHolidays)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.Contains(t, w.Body.String(), `"year":2025`)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetHolidaysHandler_InvalidYear(t *testing.T) {
+	handler := &Handler{Logger: zap.NewNop()}
+
+	for _, year := range []string{"abc", "1899", "2101"} {
+		req, _ := http.NewRequest("GET", "/time/holidays?year="+year, nil)
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.GET("/time/holidays", handler.GetHolidays)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code, year)
+		assert.JSONEq(t, `{"error":"Invalid year: must be between 1900 and 2100","code":"invalid_payload"}`, w.Body.String())
+	}
+}
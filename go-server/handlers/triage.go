@@ -0,0 +1,333 @@
+package handlers
+
+import (
+	"errors"
+	"math"
+	"net/http"
+	"sort"
+	"strings"
+	"unicode/utf8"
+
+	"github.com/acornak/healthcare-poc/models"
+	"github.com/acornak/healthcare-poc/textutil"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
+)
+
+type TriageSpecialtyPayload struct {
+	Text string `json:"text"`
+}
+
+type TriageSpecialtyResponse struct {
+	Candidates []*types.TriageCandidate `json:"candidates"`
+}
+
+type SaveSymptomMappingPayload struct {
+	ID          int      `json:"id"`
+	Keyword     string   `json:"keyword"`
+	SpecialtyID int      `json:"specialty_id"`
+	Weight      *float64 `json:"weight"`
+	Enabled     *bool    `json:"enabled"`
+}
+
+type DeleteSymptomMappingPayload struct {
+	ID int `json:"id"`
+}
+
+// @Summary		Triage specialty
+// @Description	Suggest specialties for a free text complaint using the curated symptom mapping
+// @ID			triage-specialty
+// @Accept		json
+// @Produce		json
+// @Param		payload	body		TriageSpecialtyPayload	true	"Free text complaint"
+// @Success		200		{object}	TriageSpecialtyResponse
+// @Failure		400		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/specialty/triage [post]
+func (h *Handler) TriageSpecialty(c *gin.Context) {
+	var payload TriageSpecialtyPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	if strings.TrimSpace(payload.Text) == "" {
+		h.respondError(c, types.NewMissingFieldError("text"))
+		return
+	}
+
+	mappings, err := h.Models.Symptoms.GetAllSymptomMappings(c.Request.Context())
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, TriageSpecialtyResponse{Candidates: matchSymptoms(payload.Text, mappings)})
+}
+
+// @Summary		Symptom mappings
+// @Description	Get all curated symptom mappings used by the triage, the seeded ones included
+// @ID			admin-specialty-symptoms
+// @Produce		json
+// @Security	BearerAuth
+// @Success		200		{array}		types.SymptomMapping
+// @Failure		401		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/admin/specialty/symptoms [post]
+func (h *Handler) GetSymptomMappings(c *gin.Context) {
+	mappings, err := h.Models.Symptoms.GetAllSymptomMappings(c.Request.Context())
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	c.JSON(http.StatusOK, mappings)
+}
+
+// @Summary		Save symptom mapping
+// @Description	Add a keyword to the curated symptom mapping, or update the keyword, specialty, weight or enabled flag of the mapping with the id
+// @Description	Seeded mappings keep the change until the seed file version changes
+// @ID			admin-specialty-symptom-save
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		payload	body		SaveSymptomMappingPayload	true	"Keyword, specialty id, weight between 0 and 1 and enabled, true by default, with id for an update"
+// @Success		200		{object}	types.SymptomMapping
+// @Failure		400		{object}	ErrorResponse
+// @Failure		401		{object}	ErrorResponse
+// @Failure		404		{object}	ErrorResponse
+// @Failure		409		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/admin/specialty/symptom [post]
+func (h *Handler) SaveSymptomMapping(c *gin.Context) {
+	var payload SaveSymptomMappingPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	payload.Keyword = strings.TrimSpace(payload.Keyword)
+
+	var missingParams []string
+	if payload.Keyword == "" {
+		missingParams = append(missingParams, "keyword")
+	}
+	if payload.SpecialtyID == 0 {
+		missingParams = append(missingParams, "specialty_id")
+	}
+	if payload.Weight == nil {
+		missingParams = append(missingParams, "weight")
+	}
+
+	if len(missingParams) > 0 {
+		h.respondError(c, types.NewMissingFieldsError(missingParams))
+		return
+	}
+
+	if *payload.Weight <= 0 || *payload.Weight > 1 {
+		h.respondError(c, types.NewInvalidFieldError("weight", "must be greater than 0 and at most 1"))
+		return
+	}
+
+	specialty, err := h.Models.Specialties.GetSpecialtyByID(c.Request.Context(), payload.SpecialtyID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if specialty == nil {
+		h.respondError(c, types.NewNotFoundError("Specialty not found"))
+		return
+	}
+
+	mapping := types.SymptomMapping{
+		ID:            payload.ID,
+		Keyword:       payload.Keyword,
+		SpecialtyID:   payload.SpecialtyID,
+		SpecialtyName: specialty.Name,
+		Weight:        *payload.Weight,
+		Enabled:       payload.Enabled == nil || *payload.Enabled,
+	}
+
+	if mapping.ID == 0 {
+		ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+			created := mapping
+			created.ID = id
+			return []auditChange{{types.AuditActionCreate, types.AuditEntitySymptomMapping, id, nil, created}}
+		})
+
+		mapping.ID, err = h.Models.Symptoms.CreateSymptomMapping(ctx, mapping)
+		if err != nil {
+			h.respondError(c, err)
+			return
+		}
+		logChange()
+
+		c.JSON(http.StatusOK, mapping)
+		return
+	}
+
+	before, err := h.Models.Symptoms.GetSymptomMappingByID(c.Request.Context(), mapping.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if before == nil {
+		h.respondError(c, types.NewNotFoundError("Symptom mapping not found"))
+		return
+	}
+
+	ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+		return []auditChange{{types.AuditActionUpdate, types.AuditEntitySymptomMapping, id, before, mapping}}
+	})
+
+	if err := h.Models.Symptoms.UpdateSymptomMapping(ctx, mapping); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			err = types.NewNotFoundError("Symptom mapping not found")
+		}
+		h.respondError(c, err)
+		return
+	}
+	logChange()
+
+	c.JSON(http.StatusOK, mapping)
+}
+
+// @Summary		Delete symptom mapping
+// @Description	Delete a symptom mapping, a seeded mapping is not seeded again until the seed file version changes, disable it to keep it listed
+// @ID			admin-specialty-symptom-delete
+// @Accept		json
+// @Produce		json
+// @Security	BearerAuth
+// @Param		payload	body		DeleteSymptomMappingPayload	true	"Mapping id"
+// @Success		200		{object}	DeleteSymptomMappingPayload
+// @Failure		400		{object}	ErrorResponse
+// @Failure		401		{object}	ErrorResponse
+// @Failure		404		{object}	ErrorResponse
+// @Failure		500		{object}	ErrorResponse
+// @Router		/admin/specialty/symptom/delete [post]
+func (h *Handler) DeleteSymptomMapping(c *gin.Context) {
+	var payload DeleteSymptomMappingPayload
+
+	if err := c.ShouldBindJSON(&payload); err != nil {
+		h.respondError(c, types.NewValidationError("Invalid JSON payload"))
+		return
+	}
+
+	if payload.ID == 0 {
+		h.respondError(c, types.NewMissingFieldError("id"))
+		return
+	}
+
+	before, err := h.Models.Symptoms.GetSymptomMappingByID(c.Request.Context(), payload.ID)
+	if err != nil {
+		h.respondError(c, err)
+		return
+	}
+
+	if before == nil {
+		h.respondError(c, types.NewNotFoundError("Symptom mapping not found"))
+		return
+	}
+
+	ctx, logChange := h.auditContext(c, func(id int) []auditChange {
+		return []auditChange{{types.AuditActionDelete, types.AuditEntitySymptomMapping, id, before, nil}}
+	})
+
+	if err := h.Models.Symptoms.DeleteSymptomMapping(ctx, payload.ID); err != nil {
+		if errors.Is(err, models.ErrNotFound) {
+			err = types.NewNotFoundError("Symptom mapping not found")
+		}
+		h.respondError(c, err)
+		return
+	}
+	logChange()
+
+	c.JSON(http.StatusOK, payload)
+}
+
+/*
+matchSymptoms returns the specialties whose enabled keywords occur in text
+Keywords are matched without diacritics as word prefixes, so "koleno" matches "kolenom" and "kolenách",
+words of keywords shorter than minPrefixLength have to match whole words, so "oko" does not match "okolo",
+their inflected forms are listed as keywords of their own instead
+Multi-word keywords have to match consecutive words
+The confidence of a specialty combines the weights of its matched keywords as 1 - (1-w1)(1-w2)...
+Candidates are ordered by confidence, ties by specialty id, so the result is deterministic
+*/
+func matchSymptoms(text string, mappings []*types.SymptomMapping) []*types.TriageCandidate {
+	words := textutil.Words(text)
+	candidates := make(map[int]*types.TriageCandidate)
+	misses := make(map[int]float64)
+
+	for _, mapping := range mappings {
+		if !mapping.Enabled || !containsPhrase(words, textutil.Words(mapping.Keyword)) {
+			continue
+		}
+
+		candidate, ok := candidates[mapping.SpecialtyID]
+		if !ok {
+			candidate = &types.TriageCandidate{
+				SpecialtyID:     mapping.SpecialtyID,
+				SpecialtyName:   mapping.SpecialtyName,
+				MatchedKeywords: []string{},
+			}
+			candidates[mapping.SpecialtyID] = candidate
+			misses[mapping.SpecialtyID] = 1
+		}
+
+		candidate.MatchedKeywords = append(candidate.MatchedKeywords, mapping.Keyword)
+		misses[mapping.SpecialtyID] *= 1 - mapping.Weight
+	}
+
+	result := []*types.TriageCandidate{}
+	for id, candidate := range candidates {
+		candidate.Confidence = math.Round((1-misses[id])*1000) / 1000
+		result = append(result, candidate)
+	}
+
+	sort.Slice(result, func(i, j int) bool {
+		if result[i].Confidence != result[j].Confidence {
+			return result[i].Confidence > result[j].Confidence
+		}
+		return result[i].SpecialtyID < result[j].SpecialtyID
+	})
+
+	return result
+}
+
+// minPrefixLength is the number of letters a keyword word needs to match longer words as their prefix
+const minPrefixLength = 4
+
+func containsPhrase(words, phrase []string) bool {
+	if len(phrase) == 0 {
+		return false
+	}
+
+	for i := 0; i+len(phrase) <= len(words); i++ {
+		matched := true
+		for j, part := range phrase {
+			if !matchesWord(words[i+j], part) {
+				matched = false
+				break
+			}
+		}
+		if matched {
+			return true
+		}
+	}
+
+	return false
+}
+
+// matchesWord tells if the keyword part matches the word, short parts only match the whole word
+func matchesWord(word, part string) bool {
+	if utf8.RuneCountInString(part) < minPrefixLength {
+		return word == part
+	}
+
+	return strings.HasPrefix(word, part)
+}
@@ -0,0 +1,325 @@
+package handlers
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"net/http"
+	"net/http/httptest"
+	"strings"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/models"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/gin-gonic/gin"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestMatchSymptoms(t *testing.T) {
+	mappings := []*types.SymptomMapping{
+		{Keyword: "zuby", SpecialtyID: 2, SpecialtyName: "zubár", Weight: 0.9, Enabled: true},
+		{Keyword: "ďasno", SpecialtyID: 2, SpecialtyName: "zubár", Weight: 0.5, Enabled: true},
+		{Keyword: "hlava", SpecialtyID: 3, SpecialtyName: "neurológia", Weight: 0.5, Enabled: true},
+		{Keyword: "pálenie záhy", SpecialtyID: 4, SpecialtyName: "gastroenterológia", Weight: 0.8, Enabled: true},
+		{Keyword: "tlak", SpecialtyID: 5, SpecialtyName: "kardiológia", Weight: 0.5, Enabled: false},
+	}
+
+	actual := matchSymptoms("Bolia ma zuby, krváca mi ďasno a trápi ma pálenie záhy aj tlak", mappings)
+
+	expected := []*types.TriageCandidate{
+		{SpecialtyID: 2, SpecialtyName: "zubár", Confidence: 0.95, MatchedKeywords: []string{"zuby", "ďasno"}},
+		{SpecialtyID: 4, SpecialtyName: "gastroenterológia", Confidence: 0.8, MatchedKeywords: []string{"pálenie záhy"}},
+	}
+
+	assert.Equal(t, expected, actual)
+}
+
+func TestMatchSymptoms_NoMatch(t *testing.T) {
+	mappings := []*types.SymptomMapping{
+		{Keyword: "pálenie záhy", SpecialtyID: 4, SpecialtyName: "gastroenterológia", Weight: 0.8, Enabled: true},
+	}
+
+	assert.Empty(t, matchSymptoms("záhy ma pálenie netrápi", mappings))
+	assert.Empty(t, matchSymptoms("", mappings))
+}
+
+func TestMatchSymptoms_ShortKeywords(t *testing.T) {
+	mappings := []*types.SymptomMapping{
+		{Keyword: "oko", SpecialtyID: 3, SpecialtyName: "oftalmológia", Weight: 0.9, Enabled: true},
+		{Keyword: "nos", SpecialtyID: 4, SpecialtyName: "otorinolaryngológia", Weight: 0.9, Enabled: true},
+		{Keyword: "uši", SpecialtyID: 4, SpecialtyName: "otorinolaryngológia", Weight: 0.9, Enabled: true},
+		{Keyword: "koleno", SpecialtyID: 5, SpecialtyName: "ortopédia", Weight: 0.8, Enabled: true},
+	}
+
+	// short keywords are not prefixes of unrelated words
+	assert.Empty(t, matchSymptoms("bolí ma okolo srdca", mappings))
+	assert.Empty(t, matchSymptoms("nosím okuliare a musím nosiť ťažké tašky", mappings))
+	assert.Empty(t, matchSymptoms("usilujem sa schudnúť", mappings))
+
+	// they still match the whole word, longer keywords match as prefixes
+	assert.Equal(t, []*types.TriageCandidate{
+		{SpecialtyID: 3, SpecialtyName: "oftalmológia", Confidence: 0.9, MatchedKeywords: []string{"oko"}},
+		{SpecialtyID: 4, SpecialtyName: "otorinolaryngológia", Confidence: 0.9, MatchedKeywords: []string{"uši"}},
+		{SpecialtyID: 5, SpecialtyName: "ortopédia", Confidence: 0.8, MatchedKeywords: []string{"koleno"}},
+	}, matchSymptoms("Páli ma oko, bolia ma uši a opuch pod kolenom", mappings))
+}
+
+func TestTriageSpecialtyHandler_InvalidPayload(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	handler := &Handler{
+		Logger: logger,
+	}
+
+	tests := []struct {
+		payload  string
+		expected string
+	}{
+		{"{invalid_json}", "Invalid JSON payload"},
+		{`{"text": "  "}`, "Invalid payload: missing text field"},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", "/specialty/triage", strings.NewReader(test.payload))
+		req.Header.Set("Content-Type", "application/json")
+
+		w := httptest.NewRecorder()
+		r := gin.New()
+		r.POST("/specialty/triage", handler.TriageSpecialty)
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, http.StatusBadRequest, w.Code)
+
+		var response ErrorResponse
+		err = json.Unmarshal(w.Body.Bytes(), &response)
+		if err != nil {
+			t.Errorf("Error unmarshaling response: %v", err)
+		}
+
+		assert.Equal(t, test.expected, response.Error)
+	}
+}
+
+func TestTriageSpecialtyHandler_SqlError(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM symptom_mapping").WillReturnError(errors.New("mocked error"))
+
+	r := gin.New()
+	handler := &Handler{
+		Logger: logger,
+		Models: models.NewModels(db),
+	}
+
+	payloadJSON, err := json.Marshal(TriageSpecialtyPayload{Text: "bolí ma zub"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest("POST", "/specialty/triage", bytes.NewBuffer(payloadJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r.POST("/specialty/triage", handler.TriageSpecialty)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusInternalServerError, w.Code)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTriageSpecialtyHandler_Success(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "keyword", "specialty_id", "name", "weight", "enabled"}).
+		AddRow(1, "zub", 2, "ambulancia zubného lekárstva", 0.95, true).
+		AddRow(2, "oko", 3, "ambulancia oftalmológie", 0.9, true)
+
+	mock.ExpectQuery("SELECT (.+) FROM symptom_mapping").WillReturnRows(rows)
+
+	r := gin.New()
+	handler := &Handler{
+		Logger: logger,
+		Models: models.NewModels(db),
+	}
+
+	payloadJSON, err := json.Marshal(TriageSpecialtyPayload{Text: "Bolí ma zub"})
+	if err != nil {
+		t.Fatal(err)
+	}
+	req, err := http.NewRequest("POST", "/specialty/triage", bytes.NewBuffer(payloadJSON))
+	if err != nil {
+		t.Fatal(err)
+	}
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r.POST("/specialty/triage", handler.TriageSpecialty)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response TriageSpecialtyResponse
+	err = json.Unmarshal(w.Body.Bytes(), &response)
+	if err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Equal(t, []*types.TriageCandidate{
+		{SpecialtyID: 2, SpecialtyName: "ambulancia zubného lekárstva", Confidence: 0.95, MatchedKeywords: []string{"zub"}},
+	}, response.Candidates)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestTriageSpecialtyHandler_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	if err := handler.Models.Symptoms.InsertSymptomMapping(context.Background(), types.SymptomMapping{Keyword: "srdce", SpecialtyID: 1, Weight: 0.9, Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	req, _ := http.NewRequest("POST", "/specialty/triage", strings.NewReader(`{"text": "pichá ma srdce"}`))
+	req.Header.Set("Content-Type", "application/json")
+
+	w := httptest.NewRecorder()
+	r := gin.New()
+	r.POST("/specialty/triage", handler.TriageSpecialty)
+	r.ServeHTTP(w, req)
+
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var response TriageSpecialtyResponse
+	if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Len(t, response.Candidates, 1)
+	assert.Equal(t, 1, response.Candidates[0].SpecialtyID)
+}
+
+func TestSymptomMappingHandlers_Memory(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	if err := handler.Models.Symptoms.InsertSymptomMapping(context.Background(), types.SymptomMapping{Keyword: "srdce", SpecialtyID: 1, Weight: 0.9, Enabled: true}); err != nil {
+		t.Fatal(err)
+	}
+
+	r := gin.New()
+	r.POST("/admin/specialty/symptoms", handler.GetSymptomMappings)
+	r.POST("/admin/specialty/symptom", handler.SaveSymptomMapping)
+	r.POST("/admin/specialty/symptom/delete", handler.DeleteSymptomMapping)
+
+	post := func(url, body string) *httptest.ResponseRecorder {
+		req, _ := http.NewRequest("POST", url, strings.NewReader(body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+		return w
+	}
+
+	w := post("/admin/specialty/symptom", `{"id": 1, "keyword": "srdce", "specialty_id": 2, "weight": 0.4, "enabled": false}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id": 1, "keyword": "srdce", "specialty_id": 2, "specialty_name": "kardiologia detska", "weight": 0.4, "enabled": false}`, w.Body.String())
+
+	w = post("/admin/specialty/symptoms", "")
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	var mappings []*types.SymptomMapping
+	if err := json.Unmarshal(w.Body.Bytes(), &mappings); err != nil {
+		t.Errorf("Error unmarshaling response: %v", err)
+	}
+
+	assert.Len(t, mappings, 1)
+	assert.Equal(t, 2, mappings[0].SpecialtyID)
+	assert.False(t, mappings[0].Enabled)
+
+	w = post("/admin/specialty/symptom/delete", `{"id": 1}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+
+	mappings, _ = handler.Models.Symptoms.GetAllSymptomMappings(context.Background())
+	assert.Empty(t, mappings)
+
+	w = post("/admin/specialty/symptom/delete", `{"id": 1}`)
+	assert.Equal(t, http.StatusNotFound, w.Code)
+
+	// without an id the keyword is added
+	w = post("/admin/specialty/symptom", `{"keyword": "búšenie srdca", "specialty_id": 1, "weight": 0.6}`)
+	assert.Equal(t, http.StatusOK, w.Code)
+	assert.JSONEq(t, `{"id": 2, "keyword": "búšenie srdca", "specialty_id": 1, "specialty_name": "kardiológia", "weight": 0.6, "enabled": true}`, w.Body.String())
+
+	mappings, _ = handler.Models.Symptoms.GetAllSymptomMappings(context.Background())
+	assert.Len(t, mappings, 1)
+
+	created, err := handler.Models.Audit.GetAuditLog(context.Background(), types.AuditEntitySymptomMapping, 2, 10)
+	assert.NoError(t, err)
+	assert.Len(t, created, 1)
+	assert.Equal(t, types.AuditActionCreate, created[0].Action)
+
+	entries, err := handler.Models.Audit.GetAuditLog(context.Background(), types.AuditEntitySymptomMapping, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, types.AuditActionDelete, entries[0].Action)
+	assert.Equal(t, types.AuditActionUpdate, entries[1].Action)
+	assert.JSONEq(t, `{"id": 1, "keyword": "srdce", "specialty_id": 1, "specialty_name": "kardiológia", "weight": 0.9, "enabled": true}`, string(entries[1].Before))
+}
+
+func TestSymptomMappingHandlers_Validation(t *testing.T) {
+	handler := newMemoryHandler(t)
+
+	r := gin.New()
+	r.POST("/admin/specialty/symptom", handler.SaveSymptomMapping)
+	r.POST("/admin/specialty/symptom/delete", handler.DeleteSymptomMapping)
+
+	tests := []struct {
+		url      string
+		body     string
+		code     int
+		expected string
+	}{
+		{"/admin/specialty/symptom", `{"id": "a"}`, http.StatusBadRequest, "Invalid JSON payload"},
+		{"/admin/specialty/symptom", `{"id": 1, "keyword": " "}`, http.StatusBadRequest, "Invalid payload: missing keyword, specialty_id, weight"},
+		{"/admin/specialty/symptom", `{"id": 1, "keyword": "srdce", "specialty_id": 1, "weight": 1.5}`, http.StatusBadRequest, "Invalid payload: weight must be greater than 0 and at most 1"},
+		{"/admin/specialty/symptom", `{"id": 1, "keyword": "srdce", "specialty_id": 42, "weight": 0.5}`, http.StatusNotFound, "Specialty not found"},
+		{"/admin/specialty/symptom", `{"id": 42, "keyword": "srdce", "specialty_id": 1, "weight": 0.5}`, http.StatusNotFound, "Symptom mapping not found"},
+		{"/admin/specialty/symptom/delete", `{}`, http.StatusBadRequest, "Invalid payload: missing id field"},
+	}
+
+	for _, test := range tests {
+		req, _ := http.NewRequest("POST", test.url, strings.NewReader(test.body))
+		req.Header.Set("Content-Type", "application/json")
+		w := httptest.NewRecorder()
+		r.ServeHTTP(w, req)
+
+		assert.Equal(t, test.code, w.Code, test.body)
+
+		var response ErrorResponse
+		if err := json.Unmarshal(w.Body.Bytes(), &response); err != nil {
+			t.Errorf("Error unmarshaling response: %v", err)
+		}
+
+		assert.Equal(t, test.expected, response.Error, test.body)
+	}
+}
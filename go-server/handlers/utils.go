@@ -1,17 +1,166 @@
 package handlers
 
 import (
-	"errors"
-	"regexp"
+	"net/mail"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+
+	"github.com/acornak/healthcare-poc/textutil"
+	"github.com/acornak/healthcare-poc/types"
 )
 
-func getLatAndLonFromWKT(wkt string) (string, string, error) {
-	re := regexp.MustCompile(`POINT\(([-+]?[0-9]*\.?[0-9]+) ([-+]?[0-9]*\.?[0-9]+)\)`)
-	matches := re.FindStringSubmatch(wkt)
+/*
+parseLocation parses a location sent by a client, see types.ParseLocation
+Locations outside the service area that would lie inside it with latitude and longitude swapped are rejected with types.ErrAxisOrder,
+the LLM tends to mix up the order and the search would silently return specialists far away
+*/
+func parseLocation(input types.LocationInput) (types.Location, error) {
+	location, err := input.Location()
+	if err != nil {
+		return types.Location{}, err
+	}
+
+	if err := location.CheckAxisOrder(types.ServiceArea); err != nil {
+		return types.Location{}, err
+	}
+
+	return location, nil
+}
+
+// parseOrigin parses the optional start of the navigation directions, an empty input is no origin
+func parseOrigin(input types.LocationInput) (string, error) {
+	if strings.TrimSpace(string(input)) == "" {
+		return "", nil
+	}
+
+	location, err := parseLocation(input)
+	if err != nil {
+		return "", err
+	}
+
+	return location.WKT(), nil
+}
 
-	if len(matches) != 3 {
-		return "", "", errors.New("invalid WKT format")
+/*
+addNavigationLinks fills in the navigation links of the specialists
+The origin is an optional WKT point the directions start at
+Specialists without a valid location are left without links
+*/
+func addNavigationLinks(specialists []*types.Specialist, origin string) {
+	for _, specialist := range specialists {
+		if links, err := types.NewNavigationLinks(specialist.Location, origin); err == nil {
+			specialist.Navigation = links
+		}
 	}
+}
+
+/*
+isAmbiguous reports whether the best geocoded place is not clearly better than the next one
+Places of different types, e.g. a town and a street named after it, are not considered ambiguous
+*/
+func isAmbiguous(places []*types.Place) bool {
+	if len(places) < 2 {
+		return false
+	}
+
+	best, next := places[0], places[1]
+	if best.Type != next.Type {
+		return false
+	}
+
+	return next.Importance >= best.Importance-geocodeAmbiguityMargin
+}
+
+func formatCoordinate(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
+
+func highlightSearchResult(result *types.SpecialistSearchResult, terms []string) map[string]string {
+	highlights := make(map[string]string)
+
+	fields := map[string]string{
+		"specialty": result.SpecialtyName,
+	}
+	if result.Specialist != nil {
+		fields["name"] = result.Specialist.Name
+		fields["staff"] = result.Specialist.Staff
+		fields["address"] = result.Specialist.Address
+	}
+
+	for field, value := range fields {
+		if highlighted, ok := textutil.Highlight(value, terms); ok {
+			highlights[field] = highlighted
+		}
+	}
+
+	return highlights
+}
+
+/*
+preferredLanguage picks the best supported language from an Accept-Language header
+Languages are ranked by their q value, region subtags are ignored ("en-GB" counts as "en")
+The first supported language is returned when nothing in the header is supported
+*/
+func preferredLanguage(header string, supported []string) string {
+	type candidate struct {
+		language string
+		quality  float64
+	}
+
+	candidates := []candidate{}
+	for _, part := range strings.Split(header, ",") {
+		fields := strings.Split(strings.TrimSpace(part), ";")
+		language := strings.ToLower(strings.TrimSpace(fields[0]))
+		if language == "" {
+			continue
+		}
+		language, _, _ = strings.Cut(language, "-")
+
+		quality := 1.0
+		for _, param := range fields[1:] {
+			if value, ok := strings.CutPrefix(strings.TrimSpace(param), "q="); ok {
+				if q, err := strconv.ParseFloat(value, 64); err == nil {
+					quality = q
+				}
+			}
+		}
+
+		candidates = append(candidates, candidate{language: language, quality: quality})
+	}
+
+	sort.SliceStable(candidates, func(i, j int) bool {
+		return candidates[i].quality > candidates[j].quality
+	})
+
+	for _, c := range candidates {
+		if c.quality <= 0 {
+			continue
+		}
+		for _, language := range supported {
+			if c.language == language {
+				return language
+			}
+		}
+	}
+
+	return supported[0]
+}
+
+// isWebURL reports whether the value is an absolute http or https URL
+func isWebURL(value string) bool {
+	u, err := url.Parse(value)
+	if err != nil {
+		return false
+	}
+
+	return (u.Scheme == "http" || u.Scheme == "https") && u.Host != ""
+}
+
+// isEmail reports whether the value is a bare email address, without a display name
+func isEmail(value string) bool {
+	address, err := mail.ParseAddress(value)
 
-	return matches[1], matches[2], nil
+	return err == nil && address.Address == value
 }
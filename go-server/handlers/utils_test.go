@@ -3,58 +3,108 @@ package handlers
 import (
 	"testing"
 
+	"github.com/acornak/healthcare-poc/types"
 	"github.com/stretchr/testify/assert"
 )
 
-func TestGetLatAndLonFromWKT(t *testing.T) {
+func TestParseLocation(t *testing.T) {
 	tests := []struct {
 		name        string
-		wkt         string
-		wantLat     string
-		wantLon     string
-		expectError bool
+		input       types.LocationInput
+		want        types.Location
+		expectError error
 	}{
 		{
-			name:        "Valid POINT positive",
-			wkt:         "POINT(12.34536 34.56789)",
-			wantLat:     "12.34536",
-			wantLon:     "34.56789",
-			expectError: false,
+			name:  "Valid POINT positive",
+			input: "POINT(12.34536 34.56789)",
+			want:  types.Location{Lat: 34.56789, Lon: 12.34536},
 		},
 		{
-			name:        "Valid POINT negative",
-			wkt:         "POINT(-12.34536 -34.56789)",
-			wantLat:     "-12.34536",
-			wantLon:     "-34.56789",
-			expectError: false,
+			name:  "Valid POINT negative",
+			input: "POINT(-12.34536 -34.56789)",
+			want:  types.Location{Lat: -34.56789, Lon: -12.34536},
 		},
 		{
-			name:        "Invalid format",
-			wkt:         "INVALID(-12.34536 -34.56789)",
-			wantLat:     "",
-			wantLon:     "",
-			expectError: true,
+			name:  "Valid lat lon object",
+			input: `{"lat": 48.7172272, "lon": 21.2496774}`,
+			want:  types.Location{Lat: 48.7172272, Lon: 21.2496774},
 		},
 		{
-			name:        "Missing coordinates",
-			wkt:         "POINT()",
-			wantLat:     "",
-			wantLon:     "",
-			expectError: true,
+			name:        "Swapped axes inside the service area",
+			input:       "POINT(48.7172272 21.2496774)",
+			expectError: types.ErrAxisOrder,
+		},
+		{
+			name:        "Swapped axes out of range",
+			input:       `{"type": "Point", "coordinates": [48.7172272, 121.2496774]}`,
+			expectError: types.ErrAxisOrder,
 		},
 	}
 
 	for _, tt := range tests {
 		t.Run(tt.name, func(t *testing.T) {
-			gotLat, gotLon, err := getLatAndLonFromWKT(tt.wkt)
+			got, err := parseLocation(tt.input)
 
-			if tt.expectError {
-				assert.Error(t, err)
+			if tt.expectError != nil {
+				assert.ErrorIs(t, err, tt.expectError)
 			} else {
 				assert.NoError(t, err)
-				assert.Equal(t, tt.wantLat, gotLat, "Latitude does not match")
-				assert.Equal(t, tt.wantLon, gotLon, "Longitude does not match")
+				assert.Equal(t, tt.want, got)
 			}
 		})
 	}
+
+	for _, input := range []types.LocationInput{"INVALID(-12.34536 -34.56789)", "POINT()"} {
+		_, err := parseLocation(input)
+		assert.Error(t, err, input)
+	}
+}
+
+func TestPreferredLanguage(t *testing.T) {
+	supported := []string{"sk", "en", "hu"}
+
+	tests := []struct {
+		header   string
+		expected string
+	}{
+		{"", "sk"},
+		{"en", "en"},
+		{"en-GB,en;q=0.9", "en"},
+		{"de-DE,de;q=0.9,hu;q=0.8,en;q=0.7", "hu"},
+		{"en;q=0.5, hu", "hu"},
+		{"hu;q=0, en;q=0.1", "en"},
+		{"fr, de", "sk"},
+		{"*", "sk"},
+	}
+
+	for _, test := range tests {
+		assert.Equal(t, test.expected, preferredLanguage(test.header, supported), test.header)
+	}
+}
+
+func TestIsAmbiguous(t *testing.T) {
+	town := &types.Place{Type: types.PlaceTypeCity, Importance: 0.6}
+	village := &types.Place{Type: types.PlaceTypeCity, Importance: 0.3}
+	street := &types.Place{Type: types.PlaceTypeStreet, Importance: 0.55}
+
+	assert.False(t, isAmbiguous(nil))
+	assert.False(t, isAmbiguous([]*types.Place{town}))
+	assert.False(t, isAmbiguous([]*types.Place{town, village}))
+	assert.False(t, isAmbiguous([]*types.Place{town, street}))
+	assert.True(t, isAmbiguous([]*types.Place{village, town}))
+	assert.True(t, isAmbiguous([]*types.Place{{}, {}}))
+}
+
+func TestIsWebURL(t *testing.T) {
+	assert.True(t, isWebURL("https://www.kardio-kosice.sk/kontakt"))
+	assert.True(t, isWebURL("http://example.com"))
+	assert.False(t, isWebURL("www.kardio-kosice.sk"))
+	assert.False(t, isWebURL("ftp://example.com"))
+	assert.False(t, isWebURL("javascript:alert(1)"))
+}
+
+func TestIsEmail(t *testing.T) {
+	assert.True(t, isEmail("ambulancia@kardio-kosice.sk"))
+	assert.False(t, isEmail("Ambulancia <ambulancia@kardio-kosice.sk>"))
+	assert.False(t, isEmail("ambulancia"))
 }
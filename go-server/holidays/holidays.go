@@ -0,0 +1,251 @@
+package holidays
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/acornak/healthcare-poc/seeds"
+	"github.com/acornak/healthcare-poc/textutil"
+	"github.com/acornak/healthcare-poc/types"
+)
+
+// DateFormat is the format of holiday dates
+const DateFormat = "2006-01-02"
+
+// a statutory day of rest, either on a fixed date or relative to Easter Sunday
+type publicHoliday struct {
+	name     string
+	month    time.Month
+	day      int
+	easter   int
+	relative bool
+	until    int
+}
+
+/*
+the days of rest under Act 241/1993 Coll., Easter Sunday is left out as it falls on a Sunday anyway
+changes of the act are recorded with until, the last year the day was a day of rest
+*/
+var publicHolidays = []publicHoliday{
+	{name: "Deň vzniku Slovenskej republiky", month: time.January, day: 1},
+	{name: "Zjavenie Pána (Traja králi)", month: time.January, day: 6},
+	{name: "Veľký piatok", easter: -2, relative: true},
+	{name: "Veľkonočný pondelok", easter: 1, relative: true},
+	{name: "Sviatok práce", month: time.May, day: 1},
+	{name: "Deň víťazstva nad fašizmom", month: time.May, day: 8},
+	{name: "Sviatok svätého Cyrila a svätého Metoda", month: time.July, day: 5},
+	{name: "Výročie Slovenského národného povstania", month: time.August, day: 29},
+	{name: "Deň Ústavy Slovenskej republiky", month: time.September, day: 1, until: 2024},
+	{name: "Sedembolestná Panna Mária", month: time.September, day: 15},
+	{name: "Sviatok Všetkých svätých", month: time.November, day: 1},
+	{name: "Deň boja za slobodu a demokraciu", month: time.November, day: 17},
+	{name: "Štedrý deň", month: time.December, day: 24},
+	{name: "Prvý sviatok vianočný", month: time.December, day: 25},
+	{name: "Druhý sviatok vianočný", month: time.December, day: 26},
+}
+
+/*
+Easter returns the date of Easter Sunday in the Gregorian calendar, using the anonymous Gregorian algorithm
+*/
+func Easter(year int) time.Time {
+	a := year % 19
+	b := year / 100
+	c := year % 100
+	d := b / 4
+	e := b % 4
+	f := (b + 8) / 25
+	g := (b - f + 1) / 3
+	h := (19*a + b - d - g + 15) % 30
+	i := c / 4
+	k := c % 4
+	l := (32 + 2*e + 2*i - h - k) % 7
+	m := (a + 11*h + 22*l) / 451
+	month := (h + l - 7*m + 114) / 31
+	day := (h+l-7*m+114)%31 + 1
+
+	return time.Date(year, time.Month(month), day, 0, 0, 0, 0, time.UTC)
+}
+
+/*
+Public returns the Slovak public holidays of the year in chronological order
+*/
+func Public(year int) []*types.Holiday {
+	easter := Easter(year)
+
+	holidays := []*types.Holiday{}
+	for _, p := range publicHolidays {
+		if p.until != 0 && year > p.until {
+			continue
+		}
+
+		date := time.Date(year, p.month, p.day, 0, 0, 0, 0, time.UTC)
+		if p.relative {
+			date = easter.AddDate(0, 0, p.easter)
+		}
+
+		holidays = append(holidays, &types.Holiday{
+			Date:   date.Format(DateFormat),
+			Name:   p.name,
+			Closed: true,
+			Source: types.HolidaySourcePublic,
+		})
+	}
+
+	sortHolidays(holidays)
+
+	return holidays
+}
+
+/*
+Calendar combines the public holidays with the overrides edited by an admin
+An override closes the clinics on another day, or with Closed false cancels a public holiday, in the whole country or in one region
+*/
+type Calendar struct {
+	Overrides []*types.Holiday
+}
+
+/*
+Year returns the holidays of the year in chronological order
+With a region, a municipality or district, only the overrides of that region, of the district of the municipality
+and of the whole country apply, without one all overrides are listed
+Cancelled public holidays are left out, so are the cancelling overrides themselves
+*/
+func (cal Calendar) Year(year int, region string) []*types.Holiday {
+	prefix := fmt.Sprintf("%04d-", year)
+
+	overrides := []*types.Holiday{}
+	for _, o := range cal.Overrides {
+		if !strings.HasPrefix(o.Date, prefix) {
+			continue
+		}
+		if region != "" && o.Region != "" && !inRegion(region, o.Region) {
+			continue
+		}
+		overrides = append(overrides, o)
+	}
+
+	holidays := []*types.Holiday{}
+	for _, h := range Public(year) {
+		if !cancelled(h.Date, overrides, region) {
+			holidays = append(holidays, h)
+		}
+	}
+	for _, o := range overrides {
+		if o.Closed {
+			holidays = append(holidays, o)
+		}
+	}
+
+	sortHolidays(holidays)
+
+	return holidays
+}
+
+/*
+Holiday returns the holiday on the date of t at a specialist with the address, nil on a regular day
+Overrides of the municipality of the address or of its district take precedence over those of the whole country,
+which take precedence over the public holidays
+*/
+func (cal Calendar) Holiday(t time.Time, address string) *types.Holiday {
+	date := t.Format(DateFormat)
+	municipality := types.AddressMunicipality(address)
+
+	var nationwide *types.Holiday
+	for _, o := range cal.Overrides {
+		if o.Date != date {
+			continue
+		}
+		if o.Region == "" {
+			nationwide = o
+			continue
+		}
+		if municipality != "" && inRegion(municipality, o.Region) {
+			return closedOrNil(o)
+		}
+	}
+	if nationwide != nil {
+		return closedOrNil(nationwide)
+	}
+
+	for _, h := range Public(t.Year()) {
+		if h.Date == date {
+			return h
+		}
+	}
+
+	return nil
+}
+
+// cancelled reports whether an override cancels the public holiday on the date, regional ones only when listing their region
+func cancelled(date string, overrides []*types.Holiday, region string) bool {
+	for _, o := range overrides {
+		if o.Date == date && !o.Closed && (o.Region == "" || region != "") {
+			return true
+		}
+	}
+
+	return false
+}
+
+func closedOrNil(h *types.Holiday) *types.Holiday {
+	if !h.Closed {
+		return nil
+	}
+
+	return h
+}
+
+/*
+inRegion reports whether the municipality lies in the region of an override, ignoring case and diacritics
+The region is either the municipality, the city a part of it belongs to ("Košice" for "Košice-Západ") or its district (okres)
+*/
+func inRegion(municipality, region string) bool {
+	city := strings.TrimSpace(strings.SplitN(municipality, "-", 2)[0])
+	if sameRegion(municipality, region) || sameRegion(city, region) {
+		return true
+	}
+
+	districts := municipalityDistricts()
+	for _, name := range []string{municipality, city} {
+		if district, ok := districts[textutil.Normalize(name)]; ok && sameRegion(district, region) {
+			return true
+		}
+	}
+
+	return false
+}
+
+var (
+	districtsOnce sync.Once
+	districts     map[string]string
+)
+
+// municipalityDistricts returns the districts of the municipalities known to the gazetteer, keyed by the normalized municipality
+func municipalityDistricts() map[string]string {
+	districtsOnce.Do(func() {
+		districts = make(map[string]string)
+
+		seed, err := seeds.Municipalities()
+		if err != nil {
+			return
+		}
+		for _, municipality := range seed.Municipalities {
+			districts[textutil.Normalize(municipality.Name)] = municipality.District
+		}
+	})
+
+	return districts
+}
+
+func sameRegion(a, b string) bool {
+	return textutil.Normalize(a) == textutil.Normalize(b)
+}
+
+func sortHolidays(holidays []*types.Holiday) {
+	sort.SliceStable(holidays, func(i, j int) bool {
+		return holidays[i].Date < holidays[j].Date
+	})
+}
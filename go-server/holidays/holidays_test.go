@@ -0,0 +1,137 @@
+package holidays
+
+import (
+	"testing"
+	"time"
+
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestEaster(t *testing.T) {
+	tests := map[int]string{
+		2019: "2019-04-21",
+		2024: "2024-03-31",
+		2025: "2025-04-20",
+		2026: "2026-04-05",
+		2038: "2038-04-25",
+	}
+
+	for year, expected := range tests {
+		assert.Equal(t, expected, Easter(year).Format(DateFormat), year)
+	}
+}
+
+func TestPublic(t *testing.T) {
+	holidays := Public(2024)
+
+	assert.Len(t, holidays, 15)
+	assert.Equal(t, &types.Holiday{Date: "2024-01-01", Name: "Deň vzniku Slovenskej republiky", Closed: true, Source: types.HolidaySourcePublic}, holidays[0])
+	assert.Equal(t, "2024-03-29", holidays[2].Date)
+	assert.Equal(t, "Veľký piatok", holidays[2].Name)
+	assert.Equal(t, "2024-04-01", holidays[3].Date)
+	assert.Equal(t, "Veľkonočný pondelok", holidays[3].Name)
+	assert.Equal(t, "2024-09-01", holidays[8].Date)
+
+	// Constitution Day is no longer a day of rest since 2025
+	holidays = Public(2025)
+	assert.Len(t, holidays, 14)
+	for _, h := range holidays {
+		assert.NotEqual(t, "2025-09-01", h.Date)
+	}
+}
+
+func TestCalendar_Year(t *testing.T) {
+	cal := Calendar{Overrides: []*types.Holiday{
+		{Date: "2024-05-08", Name: "Deň víťazstva nad fašizmom", Closed: false, Source: types.HolidaySourceOverride},
+		{Date: "2024-07-05", Name: "Sviatok svätého Cyrila a svätého Metoda", Region: "Košice", Closed: false, Source: types.HolidaySourceOverride},
+		{Date: "2024-06-14", Name: "Dezinfekcia budovy", Region: "Michalovce", Closed: true, Source: types.HolidaySourceOverride},
+		{Date: "2024-12-31", Name: "Silvester", Closed: true, Source: types.HolidaySourceOverride},
+		{Date: "2025-12-31", Name: "Silvester", Closed: true, Source: types.HolidaySourceOverride},
+	}}
+
+	dates := func(holidays []*types.Holiday) []string {
+		result := []string{}
+		for _, h := range holidays {
+			result = append(result, h.Date)
+		}
+		return result
+	}
+
+	all := dates(cal.Year(2024, ""))
+	assert.NotContains(t, all, "2024-05-08")
+	assert.Contains(t, all, "2024-07-05")
+	assert.Contains(t, all, "2024-06-14")
+	assert.Equal(t, "2024-12-31", all[len(all)-1])
+	assert.Len(t, all, 16)
+
+	kosice := dates(cal.Year(2024, "kosice"))
+	assert.NotContains(t, kosice, "2024-07-05")
+	assert.NotContains(t, kosice, "2024-06-14")
+	assert.Len(t, kosice, 14)
+
+	michalovce := dates(cal.Year(2024, "Michalovce"))
+	assert.Contains(t, michalovce, "2024-07-05")
+	assert.Contains(t, michalovce, "2024-06-14")
+}
+
+func TestCalendar_Holiday(t *testing.T) {
+	cal := Calendar{Overrides: []*types.Holiday{
+		{Date: "2024-05-08", Name: "Deň víťazstva nad fašizmom", Closed: false, Source: types.HolidaySourceOverride},
+		{Date: "2024-07-05", Name: "Sviatok svätého Cyrila a svätého Metoda", Region: "Košice", Closed: false, Source: types.HolidaySourceOverride},
+		{Date: "2024-06-14", Name: "Dezinfekcia budovy", Region: "Michalovce", Closed: true, Source: types.HolidaySourceOverride},
+	}}
+
+	day := func(date string) time.Time {
+		parsed, _ := time.Parse(DateFormat, date)
+		return parsed.Add(10 * time.Hour)
+	}
+
+	kosice := "Hlavná 1, 04001 Košice, Slovenská republika"
+	michalovce := "Nám. osloboditeľov 1, 07101 Michalovce, Slovenská republika"
+
+	assert.Equal(t, "Štedrý deň", cal.Holiday(day("2024-12-24"), kosice).Name)
+	assert.Nil(t, cal.Holiday(day("2024-12-23"), kosice))
+	assert.Nil(t, cal.Holiday(day("2024-05-08"), kosice))
+	assert.Nil(t, cal.Holiday(day("2024-07-05"), kosice))
+	assert.Equal(t, "Sviatok svätého Cyrila a svätého Metoda", cal.Holiday(day("2024-07-05"), michalovce).Name)
+	assert.Equal(t, "Dezinfekcia budovy", cal.Holiday(day("2024-06-14"), michalovce).Name)
+	assert.Nil(t, cal.Holiday(day("2024-06-14"), kosice))
+}
+
+func TestCalendar_HolidayRegion(t *testing.T) {
+	cal := Calendar{Overrides: []*types.Holiday{
+		{Date: "2024-06-14", Name: "Výpadok vody", Region: "Košice-okolie", Closed: true, Source: types.HolidaySourceOverride},
+		{Date: "2024-06-17", Name: "Hody", Region: "Nová Ves", Closed: true, Source: types.HolidaySourceOverride},
+		{Date: "2024-06-18", Name: "Dezinfekcia budovy", Region: "Košice", Closed: true, Source: types.HolidaySourceOverride},
+	}}
+
+	day := func(date string) time.Time {
+		parsed, _ := time.Parse(DateFormat, date)
+		return parsed.Add(10 * time.Hour)
+	}
+
+	moldava := "Školská 3, 045 01 Moldava nad Bodvou, Slovenská republika"
+	spisska := "Letná 10, 052 01 Spišská Nová Ves, Slovenská republika"
+	zapad := "Trieda SNP 1, 040 11 Košice-Západ, Slovenská republika"
+	michalovce := "Košická 2, 071 01 Michalovce, Slovenská republika"
+
+	// the override of the district applies to its municipalities
+	assert.Equal(t, "Výpadok vody", cal.Holiday(day("2024-06-14"), moldava).Name)
+	assert.Nil(t, cal.Holiday(day("2024-06-14"), zapad))
+
+	// the region has to be the whole municipality, not a part of its name or of the street
+	assert.Nil(t, cal.Holiday(day("2024-06-17"), spisska))
+	assert.Nil(t, cal.Holiday(day("2024-06-18"), michalovce))
+
+	// a part of a city belongs to the city
+	assert.Equal(t, "Dezinfekcia budovy", cal.Holiday(day("2024-06-18"), zapad).Name)
+
+	dates := []string{}
+	for _, h := range cal.Year(2024, "Moldava nad Bodvou") {
+		dates = append(dates, h.Date)
+	}
+	assert.Contains(t, dates, "2024-06-14")
+	assert.NotContains(t, dates, "2024-06-17")
+	assert.NotContains(t, dates, "2024-06-18")
+}
@@ -0,0 +1,107 @@
+package migrations
+
+import (
+	"database/sql"
+	"fmt"
+	"os"
+	"testing"
+	"time"
+
+	_ "github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+// testdata/init.sql is the schema databases were created with before the migrations, the first migration must not change
+func TestNewDefault_InitialIsBaseline(t *testing.T) {
+	baseline, err := os.ReadFile("testdata/init.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewDefault(nil, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	assert.Equal(t, "initial", m.Migrations[0].Name)
+	assert.Equal(t, string(baseline), m.Migrations[0].Up)
+}
+
+// TestUp_BaselineDatabase migrates a database created by the former init.sql,
+// it needs a PostGIS database in MIGRATIONS_TEST_DATABASE_URL and runs in a schema of its own
+func TestUp_BaselineDatabase(t *testing.T) {
+	url := os.Getenv("MIGRATIONS_TEST_DATABASE_URL")
+	if url == "" {
+		t.Skip("MIGRATIONS_TEST_DATABASE_URL is not set")
+	}
+
+	db, err := sql.Open("postgres", url)
+	if err != nil {
+		t.Fatal(err)
+	}
+	defer db.Close()
+
+	// every statement runs on the one connection with the search path of the test schema
+	db.SetMaxOpenConns(1)
+
+	schema := fmt.Sprintf("migrations_test_%d", time.Now().UnixNano())
+	if _, err := db.Exec(fmt.Sprintf(`CREATE SCHEMA %s; SET search_path TO %s, public`, schema, schema)); err != nil {
+		t.Fatal(err)
+	}
+	defer db.Exec(fmt.Sprintf(`DROP SCHEMA %s CASCADE`, schema))
+
+	baseline, err := os.ReadFile("testdata/init.sql")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	if _, err := db.Exec(string(baseline)); err != nil {
+		t.Fatal(err)
+	}
+
+	// variants of one specialty inserted by the former scraper, the specialist points to the newer one
+	_, err = db.Exec(`
+	INSERT INTO specialty (id, name, description) VALUES (1, 'Kardiológia', ''), (2, 'kardiologia ', ''), (3, 'Neurológia', '');
+	INSERT INTO specialist (id, name, specialty_id, location) VALUES (1, 'Kardio Košice', 2, 'POINT(21.2581 48.7164)');
+	`)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	m, err := NewDefault(db, zap.NewNop())
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := m.Up(0)
+	if err != nil {
+		t.Fatal(err)
+	}
+	assert.Equal(t, len(m.Migrations), applied)
+
+	var normalized string
+	var specialties, specialtyID, version int
+	var updatedAt time.Time
+
+	assert.NoError(t, db.QueryRow(`SELECT normalized_name FROM specialty WHERE id=1`).Scan(&normalized))
+	assert.Equal(t, "kardiologia", normalized)
+	assert.NoError(t, db.QueryRow(`SELECT count(*) FROM specialty`).Scan(&specialties))
+	assert.Equal(t, 2, specialties)
+	assert.NoError(t, db.QueryRow(`SELECT specialty_id, version, updated_at FROM specialist WHERE id=1`).Scan(&specialtyID, &version, &updatedAt))
+	assert.Equal(t, 1, specialtyID)
+	assert.Equal(t, 1, version)
+
+	// the history of the existing specialist starts before any as-of query
+	var changedAt time.Time
+	assert.NoError(t, db.QueryRow(`SELECT changed_at FROM specialist_history WHERE specialist_id=1 ORDER BY id LIMIT 1`).Scan(&changedAt))
+	assert.True(t, changedAt.Equal(time.Unix(0, 0)))
+
+	applied, err = m.Up(0)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, applied)
+
+	reverted, err := m.Down(len(m.Migrations))
+	assert.NoError(t, err)
+	assert.Equal(t, len(m.Migrations), reverted)
+}
@@ -0,0 +1,295 @@
+package migrations
+
+import (
+	"database/sql"
+	"embed"
+	"fmt"
+	"io/fs"
+	"path"
+	"regexp"
+	"sort"
+	"strconv"
+
+	"go.uber.org/zap"
+)
+
+//go:embed sql/*.sql
+var files embed.FS
+
+// migration files are named <version>_<name>.up.sql and <version>_<name>.down.sql
+var fileNameRegexp = regexp.MustCompile(`^(\d+)_(\w+)\.(up|down)\.sql$`)
+
+// arbitrary key of the advisory lock serializing migrations of concurrently starting servers
+const lockKey = 72656701
+
+/*
+Migration represents one versioned schema change
+The struct contains the following fields:
+- Version: the version, migrations are applied in ascending order
+- Name: the name describing the change
+- Up: the SQL applying the change
+- Down: the SQL reverting the change, empty when the change cannot be reverted
+*/
+type Migration struct {
+	Version int64
+	Name    string
+	Up      string
+	Down    string
+}
+
+/*
+Status represents the state of a migration in the database
+The struct contains the following fields:
+- Migration: the migration
+- Applied: whether the migration is applied
+*/
+type Status struct {
+	Migration Migration
+	Applied   bool
+}
+
+/*
+Migrator applies the migrations to a database and records them in the schema_migrations table
+Every migration runs in its own transaction, a failing migration leaves the database at the previous version
+*/
+type Migrator struct {
+	DB         *sql.DB
+	Logger     *zap.Logger
+	Migrations []Migration
+}
+
+/*
+New returns a migrator for the migrations in fsys, files named <version>_<name>.up.sql and <version>_<name>.down.sql
+The function returns an error if a file name is not valid, a version is used twice or a down migration has no up migration
+*/
+func New(db *sql.DB, logger *zap.Logger, fsys fs.FS) (*Migrator, error) {
+	names, err := fs.Glob(fsys, "*.sql")
+	if err != nil {
+		return nil, err
+	}
+
+	byVersion := map[int64]*Migration{}
+	for _, name := range names {
+		matches := fileNameRegexp.FindStringSubmatch(path.Base(name))
+		if matches == nil {
+			return nil, fmt.Errorf("invalid migration file name %s, expected <version>_<name>.up.sql or <version>_<name>.down.sql", name)
+		}
+
+		version, err := strconv.ParseInt(matches[1], 10, 64)
+		if err != nil || version <= 0 {
+			return nil, fmt.Errorf("invalid migration version in %s", name)
+		}
+
+		content, err := fs.ReadFile(fsys, name)
+		if err != nil {
+			return nil, err
+		}
+
+		m, ok := byVersion[version]
+		if !ok {
+			m = &Migration{Version: version, Name: matches[2]}
+			byVersion[version] = m
+		}
+		if m.Name != matches[2] {
+			return nil, fmt.Errorf("migration version %d is used by %s and %s", version, m.Name, matches[2])
+		}
+
+		if matches[3] == "up" {
+			m.Up = string(content)
+		} else {
+			m.Down = string(content)
+		}
+	}
+
+	migrations := []Migration{}
+	for _, m := range byVersion {
+		if m.Up == "" {
+			return nil, fmt.Errorf("migration %d_%s has no up migration", m.Version, m.Name)
+		}
+		migrations = append(migrations, *m)
+	}
+
+	sort.Slice(migrations, func(i, j int) bool {
+		return migrations[i].Version < migrations[j].Version
+	})
+
+	return &Migrator{DB: db, Logger: logger, Migrations: migrations}, nil
+}
+
+/*
+NewDefault returns a migrator for the migrations embedded in the server
+*/
+func NewDefault(db *sql.DB, logger *zap.Logger) (*Migrator, error) {
+	fsys, err := fs.Sub(files, "sql")
+	if err != nil {
+		return nil, err
+	}
+
+	return New(db, logger, fsys)
+}
+
+/*
+Up applies the pending migrations up to the target version, all of them when the target is 0
+The function returns the number of applied migrations
+The function returns an error if there was an issue with the database or a migration failed
+*/
+func (m *Migrator) Up(target int64) (int, error) {
+	if err := m.ensureTable(); err != nil {
+		return 0, err
+	}
+
+	applied := 0
+	for _, migration := range m.Migrations {
+		if target != 0 && migration.Version > target {
+			break
+		}
+
+		ok, err := m.run(migration, true)
+		if err != nil {
+			return applied, err
+		}
+		if ok {
+			applied++
+		}
+	}
+
+	return applied, nil
+}
+
+/*
+Down reverts the given number of the most recently applied migrations
+The function returns the number of reverted migrations
+The function returns an error if there was an issue with the database, a migration failed or cannot be reverted
+*/
+func (m *Migrator) Down(steps int) (int, error) {
+	if err := m.ensureTable(); err != nil {
+		return 0, err
+	}
+
+	statuses, err := m.Status()
+	if err != nil {
+		return 0, err
+	}
+
+	reverted := 0
+	for i := len(statuses) - 1; i >= 0 && reverted < steps; i-- {
+		if !statuses[i].Applied {
+			continue
+		}
+
+		migration := statuses[i].Migration
+		if migration.Down == "" {
+			return reverted, fmt.Errorf("migration %d_%s cannot be reverted", migration.Version, migration.Name)
+		}
+
+		ok, err := m.run(migration, false)
+		if err != nil {
+			return reverted, err
+		}
+		if ok {
+			reverted++
+		}
+	}
+
+	return reverted, nil
+}
+
+/*
+Status returns every known migration and whether it is applied, in ascending order
+The function returns an error if there was an issue with the database
+*/
+func (m *Migrator) Status() ([]Status, error) {
+	if err := m.ensureTable(); err != nil {
+		return nil, err
+	}
+
+	rows, err := m.DB.Query(`SELECT version FROM schema_migrations`)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	applied := map[int64]bool{}
+	for rows.Next() {
+		var version int64
+		if err := rows.Scan(&version); err != nil {
+			return nil, err
+		}
+		applied[version] = true
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	statuses := make([]Status, 0, len(m.Migrations))
+	for _, migration := range m.Migrations {
+		statuses = append(statuses, Status{Migration: migration, Applied: applied[migration.Version]})
+	}
+
+	return statuses, nil
+}
+
+func (m *Migrator) ensureTable() error {
+	_, err := m.DB.Exec(`
+	CREATE TABLE IF NOT EXISTS schema_migrations (
+		version BIGINT PRIMARY KEY,
+		name VARCHAR(255) NOT NULL,
+		applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+	)
+	`)
+
+	return err
+}
+
+/*
+run applies or reverts the migration in a transaction holding the advisory lock
+The applied state is checked again under the lock, so a migration applied meanwhile by another server is skipped
+The function returns false if there was nothing to do
+*/
+func (m *Migrator) run(migration Migration, up bool) (bool, error) {
+	tx, err := m.DB.Begin()
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if _, err := tx.Exec(`SELECT pg_advisory_xact_lock($1)`, lockKey); err != nil {
+		return false, err
+	}
+
+	var applied bool
+	err = tx.QueryRow(`SELECT EXISTS (SELECT 1 FROM schema_migrations WHERE version=$1)`, migration.Version).Scan(&applied)
+	if err != nil {
+		return false, err
+	}
+	if applied == up {
+		return false, nil
+	}
+
+	script, direction := migration.Up, "up"
+	if !up {
+		script, direction = migration.Down, "down"
+	}
+
+	if _, err := tx.Exec(script); err != nil {
+		return false, fmt.Errorf("migration %d_%s %s failed: %w", migration.Version, migration.Name, direction, err)
+	}
+
+	if up {
+		_, err = tx.Exec(`INSERT INTO schema_migrations (version, name) VALUES ($1, $2)`, migration.Version, migration.Name)
+	} else {
+		_, err = tx.Exec(`DELETE FROM schema_migrations WHERE version=$1`, migration.Version)
+	}
+	if err != nil {
+		return false, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return false, err
+	}
+
+	m.Logger.Info("migration "+direction, zap.Int64("version", migration.Version), zap.String("name", migration.Name))
+
+	return true, nil
+}
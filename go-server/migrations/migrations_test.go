@@ -0,0 +1,203 @@
+package migrations
+
+import (
+	"errors"
+	"testing"
+	"testing/fstest"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+var testFiles = fstest.MapFS{
+	"0002_reviews.up.sql":      {Data: []byte("CREATE TABLE review (id INT);")},
+	"0002_reviews.down.sql":    {Data: []byte("DROP TABLE review;")},
+	"0001_initial.up.sql":      {Data: []byte("CREATE TABLE specialist (id INT);")},
+	"0003_irreversible.up.sql": {Data: []byte("UPDATE specialist SET id = id;")},
+}
+
+func TestNewDefault(t *testing.T) {
+	m, err := NewDefault(nil, zap.NewNop())
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, m.Migrations)
+	for i, migration := range m.Migrations {
+		assert.Equal(t, int64(i+1), migration.Version, migration.Name)
+		assert.NotEmpty(t, migration.Up, migration.Name)
+		assert.NotEmpty(t, migration.Down, migration.Name)
+	}
+}
+
+func TestNew(t *testing.T) {
+	m, err := New(nil, zap.NewNop(), testFiles)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []Migration{
+		{Version: 1, Name: "initial", Up: "CREATE TABLE specialist (id INT);"},
+		{Version: 2, Name: "reviews", Up: "CREATE TABLE review (id INT);", Down: "DROP TABLE review;"},
+		{Version: 3, Name: "irreversible", Up: "UPDATE specialist SET id = id;"},
+	}, m.Migrations)
+}
+
+func TestNew_InvalidFiles(t *testing.T) {
+	tests := map[string]fstest.MapFS{
+		"invalid migration file name initial.sql, expected <version>_<name>.up.sql or <version>_<name>.down.sql": {
+			"initial.sql": {Data: []byte("")},
+		},
+		"migration version 1 is used by initial and other": {
+			"0001_initial.up.sql": {Data: []byte("SELECT 1;")},
+			"0001_other.up.sql":   {Data: []byte("SELECT 1;")},
+		},
+		"migration 1_initial has no up migration": {
+			"0001_initial.down.sql": {Data: []byte("SELECT 1;")},
+		},
+		"invalid migration version in 0000_initial.up.sql": {
+			"0000_initial.up.sql": {Data: []byte("SELECT 1;")},
+		},
+	}
+
+	for expected, fsys := range tests {
+		m, err := New(nil, zap.NewNop(), fsys)
+		assert.EqualError(t, err, expected)
+		assert.Nil(t, m)
+	}
+}
+
+func TestUp(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	// the first migration is already applied
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT EXISTS`).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectRollback()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WithArgs(lockKey).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT EXISTS`).WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(`CREATE TABLE review`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO schema_migrations`).WithArgs(2, "reviews").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	m, err := New(db, zap.NewNop(), testFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := m.Up(2)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUp_MigrationFails(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT EXISTS`).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectExec(`CREATE TABLE specialist`).WillReturnError(errors.New("relation already exists"))
+	mock.ExpectRollback()
+
+	m, err := New(db, zap.NewNop(), testFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	applied, err := m.Up(0)
+
+	assert.EqualError(t, err, "migration 1_initial up failed: relation already exists")
+	assert.Equal(t, 0, applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDown(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version FROM schema_migrations`).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1).AddRow(2))
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT pg_advisory_xact_lock`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT EXISTS`).WithArgs(2).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectExec(`DROP TABLE review`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM schema_migrations`).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	m, err := New(db, zap.NewNop(), testFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// the first migration has no down migration, it is not reached
+	reverted, err := m.Down(1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, reverted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDown_Irreversible(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version FROM schema_migrations`).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1).AddRow(2).AddRow(3))
+
+	m, err := New(db, zap.NewNop(), testFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	reverted, err := m.Down(2)
+
+	assert.EqualError(t, err, "migration 3_irreversible cannot be reverted")
+	assert.Equal(t, 0, reverted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestStatus(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`CREATE TABLE IF NOT EXISTS schema_migrations`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT version FROM schema_migrations`).WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(1))
+
+	m, err := New(db, zap.NewNop(), testFiles)
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	statuses, err := m.Status()
+
+	assert.NoError(t, err)
+	assert.Len(t, statuses, 3)
+	assert.True(t, statuses[0].Applied)
+	assert.False(t, statuses[1].Applied)
+	assert.False(t, statuses[2].Applied)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,4 @@
+-- the postgis extension is kept, other databases of the cluster may use it
+DROP TABLE IF EXISTS review;
+DROP TABLE IF EXISTS specialist;
+DROP TABLE IF EXISTS specialty;
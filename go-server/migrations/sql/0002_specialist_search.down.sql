@@ -0,0 +1,2 @@
+-- the extensions are kept, other databases of the cluster may use them
+ALTER TABLE specialist DROP COLUMN IF EXISTS staff;
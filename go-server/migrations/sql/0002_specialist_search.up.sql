@@ -0,0 +1,5 @@
+-- diacritics-insensitive fuzzy search of specialists by name, address and staff
+CREATE EXTENSION IF NOT EXISTS unaccent;
+CREATE EXTENSION IF NOT EXISTS pg_trgm;
+
+ALTER TABLE specialist ADD COLUMN IF NOT EXISTS staff TEXT;
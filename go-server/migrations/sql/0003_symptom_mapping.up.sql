@@ -0,0 +1,10 @@
+-- curated keywords of symptoms pointing to the specialties treating them
+CREATE TABLE IF NOT EXISTS symptom_mapping (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    keyword VARCHAR(255) NOT NULL,
+    specialty_id INT NOT NULL,
+    weight DECIMAL(3, 2) NOT NULL DEFAULT 0.5 CHECK (weight > 0 AND weight <= 1),
+    enabled BOOLEAN NOT NULL DEFAULT TRUE,
+    UNIQUE (keyword, specialty_id),
+    FOREIGN KEY (specialty_id) REFERENCES specialty(id)
+);
@@ -0,0 +1,5 @@
+DROP TABLE IF EXISTS seed_version;
+DROP TABLE IF EXISTS specialty_translation;
+ALTER TABLE specialty DROP COLUMN IF EXISTS category_id;
+DROP TABLE IF EXISTS specialty_category_translation;
+DROP TABLE IF EXISTS specialty_category;
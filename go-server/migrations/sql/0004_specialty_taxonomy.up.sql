@@ -0,0 +1,33 @@
+CREATE TABLE IF NOT EXISTS specialty_category (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    code VARCHAR(64) NOT NULL UNIQUE,
+    parent_id INT,
+    FOREIGN KEY (parent_id) REFERENCES specialty_category(id)
+);
+
+CREATE TABLE IF NOT EXISTS specialty_category_translation (
+    category_id INT NOT NULL,
+    language CHAR(2) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    PRIMARY KEY (category_id, language),
+    FOREIGN KEY (category_id) REFERENCES specialty_category(id)
+);
+
+ALTER TABLE specialty ADD COLUMN IF NOT EXISTS category_id INT REFERENCES specialty_category(id);
+
+CREATE TABLE IF NOT EXISTS specialty_translation (
+    specialty_id INT NOT NULL,
+    language CHAR(2) NOT NULL,
+    name VARCHAR(255) NOT NULL,
+    description VARCHAR(255) NOT NULL,
+    synonyms TEXT[] NOT NULL DEFAULT '{}',
+    PRIMARY KEY (specialty_id, language),
+    FOREIGN KEY (specialty_id) REFERENCES specialty(id)
+);
+
+-- the versions of the seeds applied by the server
+CREATE TABLE IF NOT EXISTS seed_version (
+    name VARCHAR(64) PRIMARY KEY,
+    version INT NOT NULL,
+    applied_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS specialty_alias;
+ALTER TABLE specialty DROP COLUMN IF EXISTS normalized_name;
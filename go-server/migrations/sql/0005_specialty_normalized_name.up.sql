@@ -0,0 +1,46 @@
+-- specialty names differing only in case, spacing or diacritics share the normalized name (textutil.Normalize)
+ALTER TABLE specialty ADD COLUMN IF NOT EXISTS normalized_name VARCHAR(255);
+
+UPDATE specialty
+SET normalized_name = trim(regexp_replace(lower(unaccent(name)), '[^[:alnum:]]+', ' ', 'g'))
+WHERE normalized_name IS NULL;
+
+-- variants of the same specialty inserted before are merged into the oldest one
+CREATE TEMPORARY TABLE specialty_duplicate ON COMMIT DROP AS
+SELECT s.id, c.id AS canonical_id
+FROM specialty s
+JOIN LATERAL (
+    SELECT min(id) AS id FROM specialty WHERE normalized_name = s.normalized_name
+) c ON c.id <> s.id;
+
+UPDATE specialist sp SET specialty_id = d.canonical_id FROM specialty_duplicate d WHERE sp.specialty_id = d.id;
+
+INSERT INTO symptom_mapping (keyword, specialty_id, weight, enabled)
+SELECT m.keyword, d.canonical_id, m.weight, m.enabled
+FROM symptom_mapping m JOIN specialty_duplicate d ON m.specialty_id = d.id
+ON CONFLICT (keyword, specialty_id) DO NOTHING;
+DELETE FROM symptom_mapping m USING specialty_duplicate d WHERE m.specialty_id = d.id;
+
+INSERT INTO specialty_translation (specialty_id, language, name, description, synonyms)
+SELECT d.canonical_id, t.language, t.name, t.description, t.synonyms
+FROM specialty_translation t JOIN specialty_duplicate d ON t.specialty_id = d.id
+ON CONFLICT (specialty_id, language) DO NOTHING;
+DELETE FROM specialty_translation t USING specialty_duplicate d WHERE t.specialty_id = d.id;
+
+DELETE FROM specialty s USING specialty_duplicate d WHERE s.id = d.id;
+
+ALTER TABLE specialty ALTER COLUMN normalized_name SET NOT NULL;
+
+DO $$
+BEGIN
+    IF NOT EXISTS (SELECT 1 FROM pg_constraint WHERE conname = 'specialty_normalized_name_key') THEN
+        ALTER TABLE specialty ADD CONSTRAINT specialty_normalized_name_key UNIQUE (normalized_name);
+    END IF;
+END $$;
+
+-- normalized names of merged duplicates, so the scraper keeps resolving them to the surviving specialty
+CREATE TABLE IF NOT EXISTS specialty_alias (
+    normalized_name VARCHAR(255) PRIMARY KEY,
+    specialty_id INT NOT NULL,
+    FOREIGN KEY (specialty_id) REFERENCES specialty(id)
+);
@@ -0,0 +1,2 @@
+ALTER TABLE specialist DROP COLUMN IF EXISTS updated_at;
+ALTER TABLE specialist DROP COLUMN IF EXISTS insurers;
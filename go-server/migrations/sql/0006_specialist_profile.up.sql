@@ -0,0 +1,2 @@
+ALTER TABLE specialist ADD COLUMN IF NOT EXISTS insurers TEXT[] NOT NULL DEFAULT '{}';
+ALTER TABLE specialist ADD COLUMN IF NOT EXISTS updated_at TIMESTAMPTZ NOT NULL DEFAULT now();
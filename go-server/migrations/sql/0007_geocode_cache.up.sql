@@ -0,0 +1,7 @@
+-- geocoding results keyed on the normalized query or rounded coordinates, an empty array caches "not found"
+CREATE TABLE IF NOT EXISTS geocode_cache (
+    key VARCHAR(255) PRIMARY KEY,
+    places JSONB NOT NULL,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    expires_at TIMESTAMPTZ NOT NULL
+);
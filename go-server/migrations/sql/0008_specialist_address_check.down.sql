@@ -0,0 +1 @@
+DROP TABLE IF EXISTS specialist_address_check;
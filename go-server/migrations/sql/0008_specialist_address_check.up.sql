@@ -0,0 +1,10 @@
+-- the last verification of each specialist address against the reverse geocoded coordinates
+CREATE TABLE IF NOT EXISTS specialist_address_check (
+    specialist_id INT PRIMARY KEY,
+    status VARCHAR(16) NOT NULL,
+    stated_address VARCHAR(255) NOT NULL DEFAULT '',
+    geocoded_address VARCHAR(255) NOT NULL DEFAULT '',
+    distance_meters DOUBLE PRECISION NOT NULL DEFAULT 0,
+    checked_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    FOREIGN KEY (specialist_id) REFERENCES specialist(id) ON DELETE CASCADE
+);
@@ -0,0 +1,9 @@
+-- closures and cancelled public holidays edited by an admin, an empty region applies to the whole country
+CREATE TABLE IF NOT EXISTS holiday_override (
+    date DATE NOT NULL,
+    region VARCHAR(255) NOT NULL DEFAULT '',
+    name VARCHAR(255) NOT NULL,
+    closed BOOLEAN NOT NULL DEFAULT TRUE,
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (date, region)
+);
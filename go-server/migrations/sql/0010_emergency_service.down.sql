@@ -0,0 +1,2 @@
+DROP TABLE IF EXISTS emergency_shift;
+DROP TABLE IF EXISTS emergency_service;
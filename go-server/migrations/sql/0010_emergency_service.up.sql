@@ -0,0 +1,29 @@
+-- emergency and on-call services: out-of-hours practices (APS), hospital emergency departments and pharmacies on duty
+CREATE TABLE IF NOT EXISTS emergency_service (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    name VARCHAR(255) NOT NULL,
+    kind VARCHAR(16) NOT NULL CHECK (kind IN ('aps', 'er', 'pharmacy')),
+    location GEOGRAPHY NOT NULL,
+    address VARCHAR(255) NOT NULL DEFAULT '',
+    telephone VARCHAR(255) NOT NULL DEFAULT '',
+    url VARCHAR(255) NOT NULL DEFAULT '',
+    note TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS emergency_service_location_idx ON emergency_service USING GIST (location);
+
+-- the rota of an emergency service: weekly shifts (0 = Monday, 6 = Sunday, 7 = holidays) or one-off duties on a date,
+-- a shift closing at or before its opening ends the next day
+CREATE TABLE IF NOT EXISTS emergency_shift (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    service_id INT NOT NULL,
+    weekday SMALLINT CHECK (weekday BETWEEN 0 AND 7),
+    date DATE,
+    opens TIME NOT NULL,
+    closes TIME NOT NULL,
+    CHECK ((weekday IS NULL) <> (date IS NULL)),
+    FOREIGN KEY (service_id) REFERENCES emergency_service(id) ON DELETE CASCADE
+);
+
+CREATE INDEX IF NOT EXISTS emergency_shift_service_idx ON emergency_shift (service_id);
@@ -0,0 +1 @@
+ALTER TABLE specialist DROP COLUMN IF EXISTS version;
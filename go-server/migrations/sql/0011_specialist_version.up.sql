@@ -0,0 +1,2 @@
+-- the version of a specialist is bumped by every update, an update or delete naming an older version is rejected
+ALTER TABLE specialist ADD COLUMN IF NOT EXISTS version INT NOT NULL DEFAULT 1;
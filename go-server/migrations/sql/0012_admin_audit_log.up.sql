@@ -0,0 +1,14 @@
+-- every create, update and delete made through the admin API, before and after hold the record as JSON
+CREATE TABLE IF NOT EXISTS admin_audit_log (
+    id INT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    actor VARCHAR(255) NOT NULL,
+    request_id VARCHAR(64) NOT NULL DEFAULT '',
+    action VARCHAR(16) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    entity VARCHAR(32) NOT NULL CHECK (entity IN ('specialist', 'specialty', 'review')),
+    entity_id INT NOT NULL,
+    before JSONB,
+    after JSONB,
+    created_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
+
+CREATE INDEX IF NOT EXISTS admin_audit_log_entity_idx ON admin_audit_log (entity, entity_id, created_at);
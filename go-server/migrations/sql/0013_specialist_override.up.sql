@@ -0,0 +1,11 @@
+-- fields of specialists corrected by an admin, the corrected value is stored in specialist and kept by the sync from the geoportal,
+-- source_value keeps the latest value of the source and is restored when the override is removed
+CREATE TABLE IF NOT EXISTS specialist_override (
+    specialist_id INT NOT NULL,
+    field VARCHAR(32) NOT NULL CHECK (field IN ('name', 'location', 'address', 'url', 'telephone', 'email',
+        'monday', 'tuesday', 'wednesday', 'thursday', 'friday', 'saturday', 'sunday', 'staff')),
+    source_value TEXT NOT NULL DEFAULT '',
+    updated_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (specialist_id, field),
+    FOREIGN KEY (specialist_id) REFERENCES specialist(id) ON DELETE CASCADE
+);
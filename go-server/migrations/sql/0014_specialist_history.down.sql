@@ -0,0 +1,4 @@
+DROP TRIGGER IF EXISTS specialist_history ON specialist;
+DROP FUNCTION IF EXISTS record_specialist_history();
+DROP FUNCTION IF EXISTS specialist_history_record(specialist);
+DROP TABLE IF EXISTS specialist_history;
@@ -0,0 +1,76 @@
+-- every change of a specialist, whether by the scraper, the address check or the admin API, recorded by a trigger
+-- before and after hold the changed columns only, record the whole specialist after the change (before it for a delete)
+-- the actor is the transaction setting app.actor, system when it is not set
+CREATE TABLE IF NOT EXISTS specialist_history (
+    id BIGINT GENERATED BY DEFAULT AS IDENTITY PRIMARY KEY,
+    specialist_id INT NOT NULL,
+    action VARCHAR(16) NOT NULL CHECK (action IN ('create', 'update', 'delete')),
+    actor VARCHAR(255) NOT NULL,
+    before JSONB,
+    after JSONB,
+    record JSONB NOT NULL,
+    changed_at TIMESTAMPTZ NOT NULL DEFAULT clock_timestamp()
+);
+
+CREATE INDEX IF NOT EXISTS specialist_history_specialist_idx ON specialist_history (specialist_id, changed_at);
+
+-- the specialist as JSON keyed by column, the location in the WKT format as the API returns it
+CREATE OR REPLACE FUNCTION specialist_history_record(s specialist) RETURNS JSONB AS $$
+    SELECT to_jsonb(s) - 'location' - 'updated_at' || jsonb_build_object('location', ST_AsText(s.location))
+$$ LANGUAGE SQL STABLE;
+
+CREATE OR REPLACE FUNCTION record_specialist_history() RETURNS TRIGGER AS $$
+DECLARE
+    old_record JSONB;
+    new_record JSONB;
+    changed_before JSONB;
+    changed_after JSONB;
+BEGIN
+    IF TG_OP <> 'INSERT' THEN
+        old_record := specialist_history_record(OLD);
+    END IF;
+    IF TG_OP <> 'DELETE' THEN
+        new_record := specialist_history_record(NEW);
+    END IF;
+
+    IF TG_OP = 'UPDATE' THEN
+        SELECT jsonb_object_agg(o.key, o.value), jsonb_object_agg(o.key, n.value)
+        INTO changed_before, changed_after
+        FROM jsonb_each(old_record) o
+        JOIN jsonb_each(new_record) n ON n.key = o.key
+        WHERE o.value IS DISTINCT FROM n.value AND o.key <> 'version';
+
+        -- only the version was bumped, nothing presented by the API changed
+        IF changed_before IS NULL THEN
+            RETURN NULL;
+        END IF;
+    ELSE
+        changed_before := old_record;
+        changed_after := new_record;
+    END IF;
+
+    INSERT INTO specialist_history (specialist_id, action, actor, before, after, record)
+    VALUES (
+        coalesce(NEW.id, OLD.id),
+        CASE TG_OP WHEN 'INSERT' THEN 'create' WHEN 'UPDATE' THEN 'update' ELSE 'delete' END,
+        coalesce(nullif(current_setting('app.actor', true), ''), 'system'),
+        changed_before,
+        changed_after,
+        coalesce(new_record, old_record)
+    );
+
+    RETURN NULL;
+END;
+$$ LANGUAGE plpgsql;
+
+DROP TRIGGER IF EXISTS specialist_history ON specialist;
+CREATE TRIGGER specialist_history
+AFTER INSERT OR UPDATE OR DELETE ON specialist
+FOR EACH ROW EXECUTE FUNCTION record_specialist_history();
+
+-- the existing specialists start their history with their current state, their earlier changes are unknown
+-- it is stamped with the epoch, so as-of queries before the history was introduced return that state rather than nothing
+INSERT INTO specialist_history (specialist_id, action, actor, after, record, changed_at)
+SELECT s.id, 'create', 'system', specialist_history_record(s), specialist_history_record(s), 'epoch'::timestamptz
+FROM specialist s
+WHERE NOT EXISTS (SELECT 1 FROM specialist_history h WHERE h.specialist_id = s.id);
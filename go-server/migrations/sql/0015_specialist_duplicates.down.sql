@@ -0,0 +1,4 @@
+DROP TABLE IF EXISTS specialist_duplicate_dismissal;
+DROP TABLE IF EXISTS specialist_alias;
+DROP INDEX IF EXISTS specialist_kpzs_idx;
+ALTER TABLE specialist DROP COLUMN IF EXISTS kpzs;
@@ -0,0 +1,22 @@
+-- the code of the provider in the geoportal, filled in by the next scrape, duplicates usually share it
+ALTER TABLE specialist ADD COLUMN IF NOT EXISTS kpzs VARCHAR(32);
+
+CREATE INDEX IF NOT EXISTS specialist_kpzs_idx ON specialist (kpzs);
+
+-- source names of specialists merged into another one, the scraper skips them instead of inserting the duplicate again
+CREATE TABLE IF NOT EXISTS specialist_alias (
+    name VARCHAR(255) PRIMARY KEY,
+    specialist_id INT NOT NULL,
+    FOREIGN KEY (specialist_id) REFERENCES specialist(id) ON DELETE CASCADE
+);
+
+-- candidate pairs reviewed by an admin as distinct specialists, the lower id first
+CREATE TABLE IF NOT EXISTS specialist_duplicate_dismissal (
+    specialist_id INT NOT NULL,
+    duplicate_id INT NOT NULL CHECK (specialist_id < duplicate_id),
+    actor VARCHAR(255) NOT NULL,
+    dismissed_at TIMESTAMPTZ NOT NULL DEFAULT now(),
+    PRIMARY KEY (specialist_id, duplicate_id),
+    FOREIGN KEY (specialist_id) REFERENCES specialist(id) ON DELETE CASCADE,
+    FOREIGN KEY (duplicate_id) REFERENCES specialist(id) ON DELETE CASCADE
+);
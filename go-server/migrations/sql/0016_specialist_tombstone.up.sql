@@ -0,0 +1,7 @@
+-- source names of specialists deleted by an admin, the scraper skips them instead of inserting the specialist again
+CREATE TABLE IF NOT EXISTS specialist_tombstone (
+    name VARCHAR(255) PRIMARY KEY,
+    specialist_id INT NOT NULL,
+    actor VARCHAR(255) NOT NULL,
+    deleted_at TIMESTAMPTZ NOT NULL DEFAULT now()
+);
@@ -0,0 +1,3 @@
+DELETE FROM admin_audit_log WHERE entity NOT IN ('specialist', 'specialty', 'review');
+ALTER TABLE admin_audit_log DROP CONSTRAINT IF EXISTS admin_audit_log_entity_check;
+ALTER TABLE admin_audit_log ADD CONSTRAINT admin_audit_log_entity_check CHECK (entity IN ('specialist', 'specialty', 'review'));
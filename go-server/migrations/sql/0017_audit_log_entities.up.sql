@@ -0,0 +1,4 @@
+-- symptom mappings, holiday overrides and emergency services edited through the admin API are audited too
+ALTER TABLE admin_audit_log DROP CONSTRAINT IF EXISTS admin_audit_log_entity_check;
+ALTER TABLE admin_audit_log ADD CONSTRAINT admin_audit_log_entity_check
+    CHECK (entity IN ('specialist', 'specialty', 'review', 'symptom_mapping', 'holiday_override', 'emergency_service'));
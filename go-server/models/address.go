@@ -0,0 +1,144 @@
+package models
+
+import (
+	"context"
+	"time"
+
+	"github.com/acornak/healthcare-poc/types"
+)
+
+/*
+GetSpecialistsForAddressCheck returns the specialists with a location whose address was never checked or was checked before the interval
+The specialists checked the longest time ago come first
+The limit is the maximum number of specialists returned
+The function returns a slice of pointers to Specialist structs with the id, name, location and address filled in
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetSpecialistsForAddressCheck(ctx context.Context, interval time.Duration, limit int) ([]*types.Specialist, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT s.id, s.name, ST_AsText(s.location), coalesce(s.address, '')
+	FROM specialist s
+	LEFT JOIN specialist_address_check c ON c.specialist_id = s.id
+	WHERE s.location IS NOT NULL AND (c.checked_at IS NULL OR c.checked_at < now() - make_interval(secs => $1))
+	ORDER BY c.checked_at NULLS FIRST, s.id
+	LIMIT $2
+	`
+
+	rows, err := m.DB.QueryContext(ctx, stmt, interval.Seconds(), limit)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var specialists []*types.Specialist
+
+	for rows.Next() {
+		var s types.Specialist
+		err := rows.Scan(&s.ID, &s.Name, &s.Location, &s.Address)
+		if err != nil {
+			return nil, err
+		}
+		specialists = append(specialists, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return specialists, nil
+}
+
+/*
+SaveAddressCheck stores the result of an address check, replacing the previous check of the specialist
+A check with the filled status also writes the geocoded address to the specialist,
+unless the address of the specialist changed since it was read or is overridden by an admin
+Everything runs in a single transaction
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) SaveAddressCheck(ctx context.Context, check types.AddressCheck) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return err
+	}
+	defer tx.Rollback()
+
+	if err := setActor(ctx, tx); err != nil {
+		return err
+	}
+
+	if check.Status == types.AddressCheckFilled {
+		_, err := tx.ExecContext(ctx, `
+		UPDATE specialist
+		SET address=$1, version=version+1, updated_at=now()
+		WHERE id=$2 AND coalesce(address, '')=$3
+		AND NOT EXISTS (SELECT 1 FROM specialist_override WHERE specialist_id=$2 AND field='address')
+		`, check.GeocodedAddress, check.SpecialistID, check.StatedAddress)
+		if err != nil {
+			return err
+		}
+	}
+
+	_, err = tx.ExecContext(ctx, `
+	INSERT INTO specialist_address_check (specialist_id, status, stated_address, geocoded_address, distance_meters, checked_at)
+	VALUES ($1, $2, $3, $4, $5, now())
+	ON CONFLICT (specialist_id) DO UPDATE SET
+		status=EXCLUDED.status,
+		stated_address=EXCLUDED.stated_address,
+		geocoded_address=EXCLUDED.geocoded_address,
+		distance_meters=EXCLUDED.distance_meters,
+		checked_at=EXCLUDED.checked_at
+	`, check.SpecialistID, check.Status, check.StatedAddress, check.GeocodedAddress, check.DistanceMeters)
+	if err != nil {
+		return err
+	}
+
+	return tx.Commit()
+}
+
+/*
+GetAddressChecks returns the address checks with a specific status, all checks when the status is empty
+The most recent checks come first
+The function returns a slice of pointers to AddressCheck structs
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetAddressChecks(ctx context.Context, status string) ([]*types.AddressCheck, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT c.specialist_id, s.name, c.status, c.stated_address, c.geocoded_address, c.distance_meters, c.checked_at
+	FROM specialist_address_check c
+	JOIN specialist s ON s.id = c.specialist_id
+	WHERE $1 = '' OR c.status = $1
+	ORDER BY c.checked_at DESC, c.specialist_id
+	`
+
+	rows, err := m.DB.QueryContext(ctx, stmt, status)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	checks := []*types.AddressCheck{}
+
+	for rows.Next() {
+		var c types.AddressCheck
+		err := rows.Scan(&c.SpecialistID, &c.SpecialistName, &c.Status, &c.StatedAddress, &c.GeocodedAddress, &c.DistanceMeters, &c.CheckedAt)
+		if err != nil {
+			return nil, err
+		}
+		checks = append(checks, &c)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return checks, nil
+}
@@ -0,0 +1,119 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSpecialistsForAddressCheck_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "location", "address"}).
+		AddRow(7, "Ambulancia", "POINT(21.258 48.72)", "Hlavná 1, 04001 Košice, Slovenská republika").
+		AddRow(8, "Poliklinika", "POINT(21.9 48.75)", "")
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s LEFT JOIN specialist_address_check`).WithArgs(float64(86400), 50).WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	specialists, err := modelsDB.DB.GetSpecialistsForAddressCheck(context.Background(), 24*time.Hour, 50)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Specialist{
+		{ID: 7, Name: "Ambulancia", Location: "POINT(21.258 48.72)", Address: "Hlavná 1, 04001 Košice, Slovenská republika"},
+		{ID: 8, Name: "Poliklinika", Location: "POINT(21.9 48.75)"},
+	}, specialists)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistsForAddressCheck_SqlError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s`).WillReturnError(errors.New("mocked error"))
+
+	modelsDB := NewModels(db)
+	specialists, err := modelsDB.DB.GetSpecialistsForAddressCheck(context.Background(), time.Hour, 10)
+
+	assert.EqualError(t, err, "mocked error")
+	assert.Nil(t, specialists)
+}
+
+func TestSaveAddressCheck_Filled(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	check := types.AddressCheck{SpecialistID: 8, Status: types.AddressCheckFilled, GeocodedAddress: "Hlavná 1, 04001 Košice, Slovenská republika", DistanceMeters: 12.5}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE specialist SET address=\$1, version=version\+1, updated_at=now\(\) WHERE id=\$2 AND coalesce\(address, ''\)=\$3`).
+		WithArgs(check.GeocodedAddress, 8, "").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO specialist_address_check`).
+		WithArgs(8, "filled", "", check.GeocodedAddress, 12.5).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	modelsDB := NewModels(db)
+	err = modelsDB.DB.SaveAddressCheck(context.Background(), check)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveAddressCheck_Mismatch(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	check := types.AddressCheck{SpecialistID: 7, Status: types.AddressCheckMismatch, StatedAddress: "Hlavná 1, 04001 Košice", GeocodedAddress: "Michalovce"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO specialist_address_check`).WithArgs(7, "mismatch", check.StatedAddress, "Michalovce", float64(0)).WillReturnError(errors.New("mocked error"))
+	mock.ExpectRollback()
+
+	modelsDB := NewModels(db)
+	err = modelsDB.DB.SaveAddressCheck(context.Background(), check)
+
+	assert.EqualError(t, err, "mocked error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAddressChecks_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	checkedAt := time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows([]string{"specialist_id", "name", "status", "stated_address", "geocoded_address", "distance_meters", "checked_at"}).
+		AddRow(7, "Ambulancia", "mismatch", "Hlavná 1, 04001 Košice", "Michalovce", 3.5, checkedAt)
+	mock.ExpectQuery(`SELECT (.+) FROM specialist_address_check c JOIN specialist s`).WithArgs("mismatch").WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	checks, err := modelsDB.DB.GetAddressChecks(context.Background(), "mismatch")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.AddressCheck{{
+		SpecialistID: 7, SpecialistName: "Ambulancia", Status: "mismatch", StatedAddress: "Hlavná 1, 04001 Košice",
+		GeocodedAddress: "Michalovce", DistanceMeters: 3.5, CheckedAt: checkedAt,
+	}}, checks)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,166 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+
+	"github.com/acornak/healthcare-poc/types"
+)
+
+const insertAuditEntryStmt = `
+INSERT INTO admin_audit_log (actor, request_id, action, entity, entity_id, before, after)
+VALUES ($1, $2, $3, $4, $5, $6, $7)
+RETURNING id
+`
+
+/*
+AuditFunc returns the audit log entries of a change made through the admin API
+The id is the id of the changed record, for an insert the id assigned by the storage
+*/
+type AuditFunc func(id int) ([]types.AuditEntry, error)
+
+type auditKey struct{}
+
+/*
+WithAudit returns a copy of ctx whose changes are recorded in the admin audit log
+The repositories insert the entries in the transaction of the change, so a change is never stored without its entries
+*/
+func WithAudit(ctx context.Context, audit AuditFunc) context.Context {
+	return context.WithValue(ctx, auditKey{}, audit)
+}
+
+// auditEntries returns the audit log entries of the change made with ctx, none without an AuditFunc in ctx
+func auditEntries(ctx context.Context, id int) ([]types.AuditEntry, error) {
+	audit, ok := ctx.Value(auditKey{}).(AuditFunc)
+	if !ok {
+		return nil, nil
+	}
+
+	return audit(id)
+}
+
+// insertAudit records the change made with ctx in the admin audit log within the transaction of the change
+func insertAudit(ctx context.Context, tx *sql.Tx, id int) error {
+	entries, err := auditEntries(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for _, e := range entries {
+		_, err := tx.ExecContext(ctx, insertAuditEntryStmt, e.Actor, e.RequestID, e.Action, e.Entity, e.EntityID, auditJSON(e.Before), auditJSON(e.After))
+		if err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+// dbExecutor runs statements on the database or within a transaction
+type dbExecutor interface {
+	ExecContext(ctx context.Context, query string, args ...any) (sql.Result, error)
+	QueryRowContext(ctx context.Context, query string, args ...any) *sql.Row
+}
+
+/*
+audited runs a change made by a single statement, in a transaction with its audit log entries when ctx records them
+change returns the id of the changed record
+*/
+func (m *DBModel) audited(ctx context.Context, change func(db dbExecutor) (int, error)) (int, error) {
+	if _, ok := ctx.Value(auditKey{}).(AuditFunc); !ok {
+		return change(m.DB)
+	}
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	id, err := change(tx)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := insertAudit(ctx, tx, id); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
+}
+
+/*
+InsertAuditEntry records a change made through the admin API
+The created_at of the entry is set by the database
+The function returns the id of the new entry
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) InsertAuditEntry(ctx context.Context, e types.AuditEntry) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	var id int
+	err := m.DB.QueryRowContext(ctx, insertAuditEntryStmt, e.Actor, e.RequestID, e.Action, e.Entity, e.EntityID, auditJSON(e.Before), auditJSON(e.After)).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+/*
+GetAuditLog returns the newest entries of the admin audit log first
+An empty entity returns the entries of all entities, entityID 0 the entries of all records of the entity
+The limit is the maximum number of entries
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetAuditLog(ctx context.Context, entity string, entityID, limit int) ([]*types.AuditEntry, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT id, actor, request_id, action, entity, entity_id, before, after, created_at
+	FROM admin_audit_log
+	WHERE ($1 = '' OR entity = $1) AND ($2 = 0 OR entity_id = $2)
+	ORDER BY created_at DESC, id DESC
+	LIMIT $3
+	`
+
+	rows, err := m.DB.QueryContext(ctx, stmt, entity, entityID, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	entries, err := scanAll(rows, func(row rowScanner) (*types.AuditEntry, error) {
+		var e types.AuditEntry
+		var before, after []byte
+
+		if err := row.Scan(&e.ID, &e.Actor, &e.RequestID, &e.Action, &e.Entity, &e.EntityID, &before, &after, &e.CreatedAt); err != nil {
+			return nil, err
+		}
+
+		e.Before = before
+		e.After = after
+
+		return &e, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if entries == nil {
+		entries = []*types.AuditEntry{}
+	}
+
+	return entries, nil
+}
+
+// auditJSON passes a missing record as NULL, JSONB parameters are sent as text
+func auditJSON(raw json.RawMessage) any {
+	if len(raw) == 0 {
+		return nil
+	}
+
+	return string(raw)
+}
@@ -0,0 +1,143 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestInsertAuditEntry(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	e := types.AuditEntry{Actor: "jana", RequestID: "abc-123", Action: types.AuditActionCreate, Entity: types.AuditEntitySpecialist, EntityID: 4, After: json.RawMessage(`{"id":4}`)}
+
+	mock.ExpectQuery(`INSERT INTO admin_audit_log (.+) RETURNING id`).
+		WithArgs("jana", "abc-123", "create", "specialist", 4, nil, `{"id":4}`).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+
+	modelsDB := NewModels(db)
+	id, err := modelsDB.DB.InsertAuditEntry(context.Background(), e)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 9, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertAuditEntry_SqlError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`INSERT INTO admin_audit_log`).WillReturnError(errors.New("mocked error"))
+
+	modelsDB := NewModels(db)
+	_, err = modelsDB.DB.InsertAuditEntry(context.Background(), types.AuditEntry{Actor: "admin", Action: types.AuditActionDelete, Entity: types.AuditEntityReview, EntityID: 1})
+
+	assert.EqualError(t, err, "mocked error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAuditLog(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "actor", "request_id", "action", "entity", "entity_id", "before", "after", "created_at"}).
+		AddRow(2, "jana", "abc-123", "update", "specialty", 3, []byte(`{"id":3,"name":"kardiologia"}`), []byte(`{"id":3,"name":"kardiológia"}`), memoryUpdatedAt).
+		AddRow(1, "admin", "", "create", "specialty", 3, nil, []byte(`{"id":3,"name":"kardiologia"}`), memoryUpdatedAt)
+
+	mock.ExpectQuery(`SELECT (.+) FROM admin_audit_log WHERE (.+) ORDER BY created_at DESC, id DESC LIMIT \$3`).WithArgs("specialty", 3, 50).WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetAuditLog(context.Background(), "specialty", 3, 50)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.AuditEntry{
+		{ID: 2, Actor: "jana", RequestID: "abc-123", Action: "update", Entity: "specialty", EntityID: 3, Before: json.RawMessage(`{"id":3,"name":"kardiologia"}`), After: json.RawMessage(`{"id":3,"name":"kardiológia"}`), CreatedAt: memoryUpdatedAt},
+		{ID: 1, Actor: "admin", Action: "create", Entity: "specialty", EntityID: 3, After: json.RawMessage(`{"id":3,"name":"kardiologia"}`), CreatedAt: memoryUpdatedAt},
+	}, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAuditLog_Empty(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM admin_audit_log`).WithArgs("", 0, 10).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "actor", "request_id", "action", "entity", "entity_id", "before", "after", "created_at"}))
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetAuditLog(context.Background(), "", 0, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.AuditEntry{}, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertReview_Audited(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	ctx := WithAudit(context.Background(), func(id int) ([]types.AuditEntry, error) {
+		return []types.AuditEntry{{Actor: "jana", Action: types.AuditActionCreate, Entity: types.AuditEntityReview, EntityID: id, After: json.RawMessage(`{"id":3}`)}}, nil
+	})
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO review (.+) RETURNING id`).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
+	mock.ExpectExec(`INSERT INTO admin_audit_log`).WithArgs("jana", "", "create", "review", 3, nil, `{"id":3}`).
+		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectCommit()
+
+	modelsDB := NewModels(db)
+	id, err := modelsDB.DB.InsertReview(ctx, types.Review{SpecialistId: 1, Url: "https://example.com", Rating: 4})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteSpecialty_AuditError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	ctx := WithAudit(context.Background(), func(id int) ([]types.AuditEntry, error) {
+		return []types.AuditEntry{{Actor: "jana", Action: types.AuditActionDelete, Entity: types.AuditEntitySpecialty, EntityID: id}}, nil
+	})
+
+	mock.ExpectBegin()
+	for _, table := range []string{"specialty_translation", "specialty_alias", "symptom_mapping"} {
+		mock.ExpectExec(`DELETE FROM ` + table + ` WHERE specialty_id=\$1`).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 0))
+	}
+	mock.ExpectExec(`DELETE FROM specialty WHERE id=\$1`).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO admin_audit_log`).WillReturnError(errors.New("mocked error"))
+	mock.ExpectRollback()
+
+	modelsDB := NewModels(db)
+	err = modelsDB.DB.DeleteSpecialty(ctx, 2)
+
+	// the delete is rolled back with the failed audit entry
+	assert.EqualError(t, err, "mocked error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,21 +1,80 @@
 package models
 
 import (
+	"context"
 	"database/sql"
+	"time"
 )
 
+// DefaultQueryTimeout bounds every query unless configured otherwise
+const DefaultQueryTimeout = 10 * time.Second
+
+/*
+DBModel is the Postgres storage
+Every method takes the context of the caller, a cancelled context (e.g. a disconnected client) cancels the running query
+QueryTimeout bounds every query on top of the context, a transaction is bounded as a whole, 0 disables the timeout
+*/
 type DBModel struct {
-	DB *sql.DB
+	DB           *sql.DB
+	QueryTimeout time.Duration
 }
 
-// wrapper for the database
+/*
+Models is the wrapper for the storage
+Specialists, specialties, reviews, the admin audit log, symptom mappings, holiday overrides, seed versions and address checks
+are accessed through their repositories, so they can be kept in memory, the rest of the data is only available in the database through DB
+*/
 type Models struct {
-	DB DBModel
+	DB          *DBModel
+	Specialists SpecialistRepository
+	Specialties SpecialtyRepository
+	Reviews     ReviewRepository
+	Audit       AuditRepository
+	Symptoms    SymptomRepository
+	Holidays    HolidayRepository
+	Seeds       SeedRepository
+	Addresses   AddressCheckRepository
 }
 
 // models with db pool
 func NewModels(db *sql.DB) Models {
+	dbModel := &DBModel{DB: db, QueryTimeout: DefaultQueryTimeout}
+
+	return Models{
+		DB:          dbModel,
+		Specialists: dbModel,
+		Specialties: dbModel,
+		Reviews:     dbModel,
+		Audit:       dbModel,
+		Symptoms:    dbModel,
+		Holidays:    dbModel,
+		Seeds:       dbModel,
+		Addresses:   dbModel,
+	}
+}
+
+/*
+NewMemoryModels returns models keeping all the data of the repositories in memory
+It is meant for tests and demos without a database, DB is nil and the features needing it are unavailable
+*/
+func NewMemoryModels(memory *MemoryModel) Models {
 	return Models{
-		DB: DBModel{DB: db},
+		Specialists: memory,
+		Specialties: memory,
+		Reviews:     memory,
+		Audit:       memory,
+		Symptoms:    memory,
+		Holidays:    memory,
+		Seeds:       memory,
+		Addresses:   memory,
 	}
 }
+
+// withTimeout derives the context of a single query, cancel must be called once the rows are read
+func (m *DBModel) withTimeout(ctx context.Context) (context.Context, context.CancelFunc) {
+	if m.QueryTimeout <= 0 {
+		return context.WithCancel(ctx)
+	}
+
+	return context.WithTimeout(ctx, m.QueryTimeout)
+}
@@ -1,7 +1,9 @@
 package models
 
 import (
+	"context"
 	"testing"
+	"time"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	"github.com/stretchr/testify/assert"
@@ -18,3 +20,79 @@ func TestNewModels(t *testing.T) {
 
 	assert.NotNil(t, testModels.DB)
 }
+
+func TestNewModels_Repositories(t *testing.T) {
+	db, _, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	testModels := NewModels(db)
+
+	assert.Same(t, testModels.DB, testModels.Specialists)
+	assert.Same(t, testModels.DB, testModels.Specialties)
+	assert.Same(t, testModels.DB, testModels.Reviews)
+	assert.Same(t, testModels.DB, testModels.Audit)
+	assert.Equal(t, DefaultQueryTimeout, testModels.DB.QueryTimeout)
+}
+
+func TestDBModel_QueryTimeout(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialty`).WillDelayFor(time.Second).WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}))
+
+	modelsDB := NewModels(db)
+	modelsDB.DB.QueryTimeout = 10 * time.Millisecond
+
+	start := time.Now()
+	specialties, err := modelsDB.DB.GetAllSpecialties(context.Background())
+
+	assert.Error(t, err)
+	assert.Nil(t, specialties)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestDBModel_CancelledContext(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`DELETE FROM review`).WillDelayFor(time.Second).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	// a client disconnecting cancels the context of its request
+	ctx, cancel := context.WithCancel(context.Background())
+	time.AfterFunc(10*time.Millisecond, cancel)
+
+	modelsDB := NewModels(db)
+	start := time.Now()
+	err = modelsDB.DB.DeleteReview(ctx, 1)
+
+	assert.Error(t, err)
+	assert.Less(t, time.Since(start), time.Second)
+}
+
+func TestDBModel_WithTimeout(t *testing.T) {
+	m := &DBModel{QueryTimeout: time.Minute}
+
+	ctx, cancel := m.withTimeout(context.Background())
+	deadline, ok := ctx.Deadline()
+	cancel()
+
+	assert.True(t, ok)
+	assert.WithinDuration(t, time.Now().Add(time.Minute), deadline, time.Second)
+	assert.Error(t, ctx.Err())
+
+	m.QueryTimeout = 0
+	ctx, cancel = m.withTimeout(context.Background())
+	defer cancel()
+
+	_, ok = ctx.Deadline()
+	assert.False(t, ok)
+}
@@ -0,0 +1,453 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"sort"
+	"strings"
+	"unicode"
+
+	"github.com/acornak/healthcare-poc/textutil"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/lib/pq"
+)
+
+const (
+	// duplicateRadiusMeters is the distance up to which specialists of the same specialty are compared
+	duplicateRadiusMeters = 500
+	// duplicateSamePlaceMeters is the distance up to which two locations are the same place, e.g. geocoded slightly differently
+	duplicateSamePlaceMeters = 25
+)
+
+// duplicateWeights are the weights of the signals in the score of a duplicate candidate, they sum up to 1
+var duplicateWeights = types.DuplicateSignals{Name: 0.3, Address: 0.15, Location: 0.2, Telephone: 0.15, KPZS: 0.2}
+
+// duplicatePair is a pair of specialists to compare, the lower id first, distance is negative when a location is unknown
+type duplicatePair struct {
+	specialistID int
+	duplicateID  int
+	distance     float64
+}
+
+/*
+GetDuplicateSpecialists returns pairs of specialists that may be the same clinic, the most likely first
+Specialists are compared when they share the KPZS code, or have the same specialty and are within 500 meters or have the same phone numbers
+Pairs dismissed by DismissDuplicateSpecialists are skipped
+The minScore is the minimum score of a returned pair, the limit is the maximum number of pairs
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetDuplicateSpecialists(ctx context.Context, minScore float64, limit int) ([]*types.DuplicateCandidate, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT a.id, b.id, coalesce(ST_Distance(a.location, b.location), -1)
+	FROM specialist a
+	JOIN specialist b ON b.id > a.id
+	WHERE (
+		(a.kpzs <> '' AND b.kpzs = a.kpzs)
+		OR (b.specialty_id = a.specialty_id AND (
+			ST_DWithin(a.location, b.location, $1)
+			OR nullif(regexp_replace(a.telephone, '\D', '', 'g'), '') = regexp_replace(b.telephone, '\D', '', 'g')
+		))
+	)
+	AND NOT EXISTS (SELECT 1 FROM specialist_duplicate_dismissal d WHERE d.specialist_id = a.id AND d.duplicate_id = b.id)
+	`
+
+	rows, err := m.DB.QueryContext(ctx, stmt, duplicateRadiusMeters)
+	if err != nil {
+		return nil, err
+	}
+
+	pairs, err := scanAll(rows, func(row rowScanner) (*duplicatePair, error) {
+		var p duplicatePair
+		if err := row.Scan(&p.specialistID, &p.duplicateID, &p.distance); err != nil {
+			return nil, err
+		}
+
+		return &p, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if len(pairs) == 0 {
+		return []*types.DuplicateCandidate{}, nil
+	}
+
+	ids := []int64{}
+	for _, p := range pairs {
+		ids = append(ids, int64(p.specialistID), int64(p.duplicateID))
+	}
+
+	rows, err = m.DB.QueryContext(ctx, `SELECT `+specialistColumns+`, s.kpzs FROM specialist s WHERE s.id = ANY($1)`, pq.Array(ids))
+	if err != nil {
+		return nil, err
+	}
+
+	specialists, err := scanAll(rows, func(row rowScanner) (*types.Specialist, error) {
+		var kpzs sql.NullString
+		s, err := scanSpecialist(row, &kpzs)
+		if err != nil {
+			return nil, err
+		}
+
+		s.KPZS = kpzs.String
+
+		return s, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	byID := make(map[int]*types.Specialist, len(specialists))
+	for _, s := range specialists {
+		byID[s.ID] = s
+	}
+
+	candidates := []*types.DuplicateCandidate{}
+	for _, p := range pairs {
+		// deleted in the meantime
+		a, b := byID[p.specialistID], byID[p.duplicateID]
+		if a == nil || b == nil {
+			continue
+		}
+
+		candidates = append(candidates, scoreDuplicate(a, b, p.distance))
+	}
+
+	return rankDuplicates(candidates, minScore, limit), nil
+}
+
+/*
+DismissDuplicateSpecialists records that two specialists are distinct, GetDuplicateSpecialists skips the pair from then on
+The dismissal is recorded with the actor named by WithActor
+The function returns an error if there was an issue with the database, e.g. one of the specialists does not exist
+*/
+func (m *DBModel) DismissDuplicateSpecialists(ctx context.Context, id, otherID int) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	INSERT INTO specialist_duplicate_dismissal (specialist_id, duplicate_id, actor)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (specialist_id, duplicate_id) DO NOTHING
+	`
+
+	_, err := m.DB.ExecContext(ctx, stmt, min(id, otherID), max(id, otherID), ActorFromContext(ctx))
+
+	return err
+}
+
+/*
+MergeSpecialists merges a duplicate specialist into the surviving one
+The reviews of the duplicate are moved to the survivor, the staff of the duplicate missing at the survivor is added to its staff,
+which is kept as an override so the next sync from the source does not drop it
+The source name of the duplicate is kept as an alias, GetSpecialistAlias resolves it to the survivor, and the duplicate is deleted
+Both versions are the versions the caller read
+The function returns the new version of the survivor, unchanged when no staff was added, and the number of moved reviews
+The function returns ErrNotFound if a specialist does not exist and ErrStaleVersion if it was changed since
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) MergeSpecialists(ctx context.Context, duplicateID, duplicateVersion, survivorID, survivorVersion int) (int, int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, 0, err
+	}
+	defer tx.Rollback()
+
+	if err := setActor(ctx, tx); err != nil {
+		return 0, 0, err
+	}
+
+	// the rows are locked in the order of their ids, so two merges of the same pair cannot deadlock
+	locked := map[int]*types.Specialist{}
+	versions := map[int]int{duplicateID: duplicateVersion, survivorID: survivorVersion}
+	for _, id := range []int{min(duplicateID, survivorID), max(duplicateID, survivorID)} {
+		if locked[id], err = lockedSpecialist(ctx, tx, id, versions[id]); err != nil {
+			return 0, 0, err
+		}
+	}
+	survivor, duplicate := locked[survivorID], locked[duplicateID]
+
+	res, err := tx.ExecContext(ctx, `UPDATE review SET specialist_id=$1 WHERE specialist_id=$2`, survivorID, duplicateID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	moved, err := res.RowsAffected()
+	if err != nil {
+		return 0, 0, err
+	}
+
+	version := survivor.Version
+	if staff := MergeStaff(survivor.Staff, duplicate.Staff); staff != survivor.Staff {
+		_, err := tx.ExecContext(ctx, `
+		INSERT INTO specialist_override (specialist_id, field, source_value)
+		VALUES ($1, 'staff', $2)
+		ON CONFLICT (specialist_id, field) DO UPDATE SET updated_at=now()
+		`, survivorID, survivor.Staff)
+		if err != nil {
+			return 0, 0, err
+		}
+
+		version, err = updateSpecialistFields(ctx, tx, survivorID, survivor.Version, []string{"staff"}, map[string]string{"staff": staff}, nil)
+		if err != nil {
+			return 0, 0, err
+		}
+	}
+
+	stmts := []struct {
+		query string
+		args  []any
+	}{
+		{`UPDATE specialist_alias SET specialist_id=$1 WHERE specialist_id=$2`, []any{survivorID, duplicateID}},
+		{`INSERT INTO specialist_alias (name, specialist_id)
+		SELECT coalesce(o.source_value, s.name), $1
+		FROM specialist s
+		LEFT JOIN specialist_override o ON o.specialist_id = s.id AND o.field = 'name'
+		WHERE s.id=$2
+		ON CONFLICT (name) DO UPDATE SET specialist_id=EXCLUDED.specialist_id`, []any{survivorID, duplicateID}},
+		{`DELETE FROM specialist WHERE id=$1`, []any{duplicateID}},
+	}
+
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			return 0, 0, err
+		}
+	}
+
+	if err := insertAudit(ctx, tx, survivorID); err != nil {
+		return 0, 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, 0, err
+	}
+
+	return version, int(moved), nil
+}
+
+/*
+GetSpecialistAlias returns the id of the specialist a specialist with the source name was merged into
+The function returns 0 if no specialist with the name was merged
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetSpecialistAlias(ctx context.Context, name string) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	var id int
+	err := m.DB.QueryRowContext(ctx, `SELECT specialist_id FROM specialist_alias WHERE name=$1`, name).Scan(&id)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return id, nil
+}
+
+// isDuplicatePair tells whether two specialists are compared, as the blocking of GetDuplicateSpecialists does
+func isDuplicatePair(a, b *types.Specialist, distance float64) bool {
+	if a.KPZS != "" && a.KPZS == b.KPZS {
+		return true
+	}
+
+	if a.SpecialtyID != b.SpecialtyID {
+		return false
+	}
+
+	if distance >= 0 && distance <= duplicateRadiusMeters {
+		return true
+	}
+
+	phone := digits(a.Telephone)
+
+	return phone != "" && phone == digits(b.Telephone)
+}
+
+// scoreDuplicate compares two specialists, distance is the distance of their locations in meters, negative when unknown
+func scoreDuplicate(a, b *types.Specialist, distance float64) *types.DuplicateCandidate {
+	candidate := &types.DuplicateCandidate{Specialist: a, Duplicate: b}
+	signals := &candidate.Signals
+
+	signals.Name = textSimilarity(a.Name, b.Name)
+
+	if a.Address != "" && b.Address != "" {
+		if textutil.Normalize(a.Address) == textutil.Normalize(b.Address) {
+			signals.Address = 1
+		} else {
+			signals.Address = textSimilarity(a.Address, b.Address)
+		}
+	}
+
+	if distance >= 0 {
+		candidate.DistanceMeters = &distance
+		signals.Location = max(0, min(1, (duplicateRadiusMeters-distance)/(duplicateRadiusMeters-duplicateSamePlaceMeters)))
+	}
+
+	numbers := phoneNumbers(a.Telephone)
+	for number := range phoneNumbers(b.Telephone) {
+		if numbers[number] {
+			signals.Telephone = 1
+		}
+	}
+
+	if a.KPZS != "" && a.KPZS == b.KPZS {
+		signals.KPZS = 1
+	}
+
+	candidate.Score = signals.Name*duplicateWeights.Name +
+		signals.Address*duplicateWeights.Address +
+		signals.Location*duplicateWeights.Location +
+		signals.Telephone*duplicateWeights.Telephone +
+		signals.KPZS*duplicateWeights.KPZS
+
+	return candidate
+}
+
+// rankDuplicates returns the candidates scoring at least minScore, the highest score first, at most limit of them
+func rankDuplicates(candidates []*types.DuplicateCandidate, minScore float64, limit int) []*types.DuplicateCandidate {
+	ranked := []*types.DuplicateCandidate{}
+	for _, c := range candidates {
+		if c.Score >= minScore {
+			ranked = append(ranked, c)
+		}
+	}
+
+	sort.SliceStable(ranked, func(i, j int) bool {
+		if ranked[i].Score != ranked[j].Score {
+			return ranked[i].Score > ranked[j].Score
+		}
+		if ranked[i].Specialist.ID != ranked[j].Specialist.ID {
+			return ranked[i].Specialist.ID < ranked[j].Specialist.ID
+		}
+		return ranked[i].Duplicate.ID < ranked[j].Duplicate.ID
+	})
+
+	if len(ranked) > limit {
+		ranked = ranked[:limit]
+	}
+
+	return ranked
+}
+
+/*
+textSimilarity returns the share of the trigrams of the words of a and b found in both, as similarity of pg_trgm
+Texts differing only in case, diacritics or punctuation are the same
+*/
+func textSimilarity(a, b string) float64 {
+	aTrigrams, bTrigrams := wordTrigrams(a), wordTrigrams(b)
+
+	shared := 0
+	for trigram := range aTrigrams {
+		if bTrigrams[trigram] {
+			shared++
+		}
+	}
+
+	all := len(aTrigrams) + len(bTrigrams) - shared
+	if all == 0 {
+		return 0
+	}
+
+	return float64(shared) / float64(all)
+}
+
+func wordTrigrams(text string) map[string]bool {
+	set := make(map[string]bool)
+	for _, word := range textutil.Words(text) {
+		for trigram := range trigrams(word) {
+			set[trigram] = true
+		}
+	}
+
+	return set
+}
+
+/*
+phoneNumbers returns the numbers in a list of phone numbers separated by commas
+The numbers are kept without the Slovak country code and the trunk prefix, so +421 55 123 456 and 055/123 456 are the same
+*/
+func phoneNumbers(telephone string) map[string]bool {
+	numbers := make(map[string]bool)
+	for _, number := range strings.Split(telephone, ",") {
+		number = strings.TrimPrefix(digits(number), "00")
+		if len(number) > 9 {
+			number = strings.TrimPrefix(number, "421")
+		}
+		number = strings.TrimPrefix(number, "0")
+
+		if len(number) >= 6 {
+			numbers[number] = true
+		}
+	}
+
+	return numbers
+}
+
+// digits returns the digits of s
+func digits(s string) string {
+	return strings.Map(func(r rune) rune {
+		if unicode.IsDigit(r) {
+			return r
+		}
+		return -1
+	}, s)
+}
+
+/*
+MergeStaff adds the names of the other staff missing in staff, both are names separated by commas
+Names differing only in case, diacritics or punctuation are the same
+*/
+func MergeStaff(staff, other string) string {
+	seen := make(map[string]bool)
+	for _, name := range strings.Split(staff, ",") {
+		seen[textutil.Normalize(name)] = true
+	}
+
+	added := []string{}
+	for _, name := range strings.Split(other, ",") {
+		name = strings.TrimSpace(name)
+		key := textutil.Normalize(name)
+		if key == "" || seen[key] {
+			continue
+		}
+
+		seen[key] = true
+		added = append(added, name)
+	}
+
+	if len(added) == 0 {
+		return staff
+	}
+
+	if strings.TrimSpace(staff) == "" {
+		return strings.Join(added, ", ")
+	}
+
+	return staff + ", " + strings.Join(added, ", ")
+}
+
+/*
+IsSpecialistDeleted returns whether a specialist with the source name was deleted by an admin
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) IsSpecialistDeleted(ctx context.Context, name string) (bool, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	var deleted bool
+	err := m.DB.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM specialist_tombstone WHERE name=$1)`, name).Scan(&deleted)
+	if err != nil {
+		return false, err
+	}
+
+	return deleted, nil
+}
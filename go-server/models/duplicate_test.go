@@ -0,0 +1,287 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetDuplicateSpecialists_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	pairs := sqlmock.NewRows([]string{"a", "b", "distance"}).
+		AddRow(1, 2, 10.0).
+		AddRow(1, 3, 450.0)
+	columns := append(append([]string{}, specialistColumnNames...), "kpzs")
+	specialists := sqlmock.NewRows(columns).
+		AddRow(1, "Kardiológia Košice, MUDr. Ján Novák", 1, "POINT(21.25 48.72)", "Hlavná 1, Košice", nil, "055/123 456, ", nil, nil, nil, nil, nil, nil, nil, nil, "MUDr. Ján Novák", 1, nil, "P27489001201").
+		AddRow(2, "Kardiologia Kosice - MUDr. Jan Novak", 1, "POINT(21.2501 48.72)", "Hlavna 1, Kosice", nil, "+421 55 123 456", nil, nil, nil, nil, nil, nil, nil, nil, nil, 1, nil, "P27489001201").
+		AddRow(3, "Očná ambulancia", 1, "POINT(21.256 48.72)", "Mlynská 5, Košice", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 1, nil, nil)
+
+	mock.ExpectQuery(`SELECT a.id, b.id, coalesce\(ST_Distance\(a.location, b.location\), -1\) FROM specialist a JOIN specialist b ON b.id > a.id (.+) specialist_duplicate_dismissal`).
+		WithArgs(500).
+		WillReturnRows(pairs)
+	mock.ExpectQuery(`SELECT (.+), s.kpzs FROM specialist s WHERE s.id = ANY\(\$1\)`).
+		WithArgs("{1,2,1,3}").
+		WillReturnRows(specialists)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetDuplicateSpecialists(context.Background(), 0.5, 10)
+
+	assert.NoError(t, err)
+	assert.Len(t, res, 1)
+	assert.Equal(t, 1, res[0].Specialist.ID)
+	assert.Equal(t, "P27489001201", res[0].Specialist.KPZS)
+	assert.Equal(t, 2, res[0].Duplicate.ID)
+	assert.Equal(t, 10.0, *res[0].DistanceMeters)
+	assert.Equal(t, 1.0, res[0].Signals.Address)
+	assert.Equal(t, 1.0, res[0].Signals.Location)
+	assert.Equal(t, 1.0, res[0].Signals.Telephone)
+	assert.Equal(t, 1.0, res[0].Signals.KPZS)
+	assert.Equal(t, 1.0, res[0].Signals.Name)
+	assert.InDelta(t, 1.0, res[0].Score, 0.0001)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDuplicateSpecialists_NoPairs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT a.id, b.id, (.+) FROM specialist a`).WithArgs(500).WillReturnRows(sqlmock.NewRows([]string{"a", "b", "distance"}))
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetDuplicateSpecialists(context.Background(), 0.5, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.DuplicateCandidate{}, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetDuplicateSpecialists_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT a.id, b.id, (.+) FROM specialist a`).WillReturnError(errors.New("some error"))
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetDuplicateSpecialists(context.Background(), 0.5, 10)
+
+	assert.EqualError(t, err, "some error")
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDismissDuplicateSpecialists(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	// the lower id comes first whatever the order of the arguments
+	mock.ExpectExec(`INSERT INTO specialist_duplicate_dismissal \(specialist_id, duplicate_id, actor\) VALUES \(\$1, \$2, \$3\) ON CONFLICT \(specialist_id, duplicate_id\) DO NOTHING`).
+		WithArgs(2, 5, "jana").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+
+	modelsDB := NewModels(db)
+	err = modelsDB.DB.DismissDuplicateSpecialists(WithActor(context.Background(), "jana"), 5, 2)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMergeSpecialists_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	survivor := sqlmock.NewRows(specialistColumnNames).
+		AddRow(2, "Kardio Košice", 1, "POINT(21.25 48.72)", "Hlavná 1", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "MUDr. Ján Novák", 3, nil)
+	duplicate := sqlmock.NewRows(specialistColumnNames).
+		AddRow(1, "Kardio Kosice", 1, "POINT(21.25 48.72)", "Hlavná 1", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "MUDr. Jan Novak, Eva Malá", 1, nil)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("jana").WillReturnResult(sqlmock.NewResult(0, 0))
+	// the lower id is locked first
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=\$1 FOR UPDATE OF s`).WithArgs(1).WillReturnRows(duplicate)
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=\$1 FOR UPDATE OF s`).WithArgs(2).WillReturnRows(survivor)
+	mock.ExpectExec(`UPDATE review SET specialist_id=\$1 WHERE specialist_id=\$2`).WithArgs(2, 1).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec(`INSERT INTO specialist_override \(specialist_id, field, source_value\) VALUES \(\$1, 'staff', \$2\)`).
+		WithArgs(2, "MUDr. Ján Novák").
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`UPDATE specialist SET staff=NULLIF\(\$1, ''\), version=version\+1, updated_at=now\(\) WHERE id=\$2 AND version=\$3 RETURNING version`).
+		WithArgs("MUDr. Ján Novák, Eva Malá", 2, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+	mock.ExpectExec(`UPDATE specialist_alias SET specialist_id=\$1 WHERE specialist_id=\$2`).WithArgs(2, 1).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO specialist_alias \(name, specialist_id\) SELECT coalesce\(o.source_value, s.name\), \$1 (.+) ON CONFLICT \(name\)`).
+		WithArgs(2, 1).
+		WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM specialist WHERE id=\$1`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	modelsDB := NewModels(db)
+	version, moved, err := modelsDB.DB.MergeSpecialists(WithActor(context.Background(), "jana"), 1, 1, 2, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, version)
+	assert.Equal(t, 2, moved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMergeSpecialists_NoStaffAdded(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	survivor := sqlmock.NewRows(specialistColumnNames).
+		AddRow(1, "Kardio Košice", 1, "POINT(21.25 48.72)", "Hlavná 1", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, "MUDr. Ján Novák", 3, nil)
+	duplicate := sqlmock.NewRows(specialistColumnNames).
+		AddRow(2, "Kardio Kosice", 1, "POINT(21.25 48.72)", "Hlavná 1", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 1, nil)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=\$1 FOR UPDATE OF s`).WithArgs(1).WillReturnRows(survivor)
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=\$1 FOR UPDATE OF s`).WithArgs(2).WillReturnRows(duplicate)
+	mock.ExpectExec(`UPDATE review SET specialist_id=\$1 WHERE specialist_id=\$2`).WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE specialist_alias`).WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO specialist_alias`).WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM specialist WHERE id=\$1`).WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	modelsDB := NewModels(db)
+	version, moved, err := modelsDB.DB.MergeSpecialists(context.Background(), 2, 1, 1, 3)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, version)
+	assert.Equal(t, 0, moved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMergeSpecialists_Stale(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	survivor := sqlmock.NewRows(specialistColumnNames).
+		AddRow(1, "Kardio Košice", 1, "POINT(21.25 48.72)", "Hlavná 1", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 4, nil)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=\$1 FOR UPDATE OF s`).WithArgs(1).WillReturnRows(survivor)
+	mock.ExpectRollback()
+
+	modelsDB := NewModels(db)
+	_, _, err = modelsDB.DB.MergeSpecialists(context.Background(), 2, 1, 1, 3)
+
+	assert.ErrorIs(t, err, ErrStaleVersion)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistAlias(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT specialist_id FROM specialist_alias WHERE name=\$1`).WithArgs("Kardio Kosice").
+		WillReturnRows(sqlmock.NewRows([]string{"specialist_id"}).AddRow(2))
+	mock.ExpectQuery(`SELECT specialist_id FROM specialist_alias WHERE name=\$1`).WithArgs("Kardio Prešov").
+		WillReturnRows(sqlmock.NewRows([]string{"specialist_id"}))
+	mock.ExpectQuery(`SELECT specialist_id FROM specialist_alias WHERE name=\$1`).WithArgs("Kardio Bardejov").
+		WillReturnError(errors.New("some error"))
+
+	modelsDB := NewModels(db)
+
+	id, err := modelsDB.DB.GetSpecialistAlias(context.Background(), "Kardio Kosice")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, id)
+
+	id, err = modelsDB.DB.GetSpecialistAlias(context.Background(), "Kardio Prešov")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, id)
+
+	_, err = modelsDB.DB.GetSpecialistAlias(context.Background(), "Kardio Bardejov")
+	assert.EqualError(t, err, "some error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestIsSpecialistDeleted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM specialist_tombstone WHERE name=\$1\)`).WithArgs("Kardio Kosice").
+		WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM specialist_tombstone WHERE name=\$1\)`).WithArgs("Kardio Bardejov").
+		WillReturnError(errors.New("some error"))
+
+	modelsDB := NewModels(db)
+
+	deleted, err := modelsDB.DB.IsSpecialistDeleted(context.Background(), "Kardio Kosice")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+
+	_, err = modelsDB.DB.IsSpecialistDeleted(context.Background(), "Kardio Bardejov")
+	assert.EqualError(t, err, "some error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScoreDuplicate(t *testing.T) {
+	a := &types.Specialist{ID: 1, Name: "Kardio Košice", Address: "Hlavná 1, Košice", Telephone: "055/123 456, 0905 111 222"}
+	b := &types.Specialist{ID: 2, Name: "Kardio Prešov", Address: "Hlavná 2, Prešov", Telephone: "+421 905 111 222"}
+
+	candidate := scoreDuplicate(a, b, 262.5)
+
+	assert.Equal(t, 0.5, candidate.Signals.Location)
+	assert.Equal(t, 1.0, candidate.Signals.Telephone)
+	assert.Equal(t, 0.0, candidate.Signals.KPZS)
+	assert.Greater(t, candidate.Signals.Name, 0.3)
+	assert.Less(t, candidate.Signals.Name, 1.0)
+	assert.InDelta(t, 0.3*candidate.Signals.Name+0.15*candidate.Signals.Address+0.2*0.5+0.15, candidate.Score, 0.0001)
+
+	// an unknown location has no distance
+	candidate = scoreDuplicate(a, &types.Specialist{ID: 3, Name: "Očná ambulancia"}, -1)
+	assert.Nil(t, candidate.DistanceMeters)
+	assert.Equal(t, types.DuplicateSignals{}, candidate.Signals)
+}
+
+func TestIsDuplicatePair(t *testing.T) {
+	a := &types.Specialist{SpecialtyID: 1, Telephone: "055/123 456, ", KPZS: "P1"}
+
+	assert.True(t, isDuplicatePair(a, &types.Specialist{SpecialtyID: 2, KPZS: "P1"}, -1))
+	assert.True(t, isDuplicatePair(a, &types.Specialist{SpecialtyID: 1}, 499))
+	assert.True(t, isDuplicatePair(a, &types.Specialist{SpecialtyID: 1, Telephone: "055 123456"}, 5000))
+	assert.False(t, isDuplicatePair(a, &types.Specialist{SpecialtyID: 1}, 501))
+	assert.False(t, isDuplicatePair(a, &types.Specialist{SpecialtyID: 2}, 10))
+	assert.False(t, isDuplicatePair(&types.Specialist{SpecialtyID: 1, Telephone: ", "}, &types.Specialist{SpecialtyID: 1, Telephone: ", "}, -1))
+}
+
+func TestMergeStaff(t *testing.T) {
+	assert.Equal(t, "MUDr. Ján Novák, Eva Malá", MergeStaff("MUDr. Ján Novák", "MUDr. Jan Novak, Eva Malá"))
+	assert.Equal(t, "MUDr. Ján Novák", MergeStaff("MUDr. Ján Novák", "mudr. jan novak"))
+	assert.Equal(t, "Eva Malá", MergeStaff("", "Eva Malá"))
+	assert.Equal(t, "", MergeStaff("", ""))
+}
@@ -0,0 +1,228 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/lib/pq"
+)
+
+/*
+GetEmergencyServicesNear returns the emergency services within a certain radius of a location, the nearest first
+The location is in the WKT format and the radius in meters
+The kind limits the services to one kind, all kinds are returned when it is empty
+Every service is returned with its rota, whether it is open is left for the caller to evaluate
+The function returns a slice of pointers to NearbyEmergencyService structs
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetEmergencyServicesNear(ctx context.Context, location string, radius int, kind string) ([]*types.NearbyEmergencyService, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT id, name, kind, ST_AsText(location), address, telephone, url, note, ST_Distance(location, ST_GeogFromText($1))
+	FROM emergency_service
+	WHERE ST_DWithin(location, ST_GeogFromText($1), $2) AND ($3 = '' OR kind = $3)
+	ORDER BY 9, id
+	`
+
+	rows, err := m.DB.QueryContext(ctx, stmt, location, radius, kind)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	nearby := []*types.NearbyEmergencyService{}
+	services := map[int]*types.EmergencyService{}
+	ids := []int64{}
+
+	for rows.Next() {
+		s := types.EmergencyService{Shifts: []types.EmergencyShift{}}
+		n := types.NearbyEmergencyService{Service: &s}
+		err := rows.Scan(&s.ID, &s.Name, &s.Kind, &s.Location, &s.Address, &s.Telephone, &s.Url, &s.Note, &n.DistanceMeters)
+		if err != nil {
+			return nil, err
+		}
+		nearby = append(nearby, &n)
+		services[s.ID] = &s
+		ids = append(ids, int64(s.ID))
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	if len(ids) == 0 {
+		return nearby, nil
+	}
+
+	if err := m.loadEmergencyShifts(ctx, services, ids); err != nil {
+		return nil, err
+	}
+
+	return nearby, nil
+}
+
+/*
+GetEmergencyServiceByID returns the emergency service with a specific id together with its rota
+The function returns nil if there is no service with the id
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetEmergencyServiceByID(ctx context.Context, id int) (*types.EmergencyService, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT id, name, kind, ST_AsText(location), address, telephone, url, note
+	FROM emergency_service
+	WHERE id=$1
+	`
+
+	s := types.EmergencyService{Shifts: []types.EmergencyShift{}}
+	err := m.DB.QueryRowContext(ctx, stmt, id).Scan(&s.ID, &s.Name, &s.Kind, &s.Location, &s.Address, &s.Telephone, &s.Url, &s.Note)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if err := m.loadEmergencyShifts(ctx, map[int]*types.EmergencyService{s.ID: &s}, []int64{int64(s.ID)}); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// loadEmergencyShifts appends the rota of the services with the ids to the services
+func (m *DBModel) loadEmergencyShifts(ctx context.Context, services map[int]*types.EmergencyService, ids []int64) error {
+	shifts, err := m.DB.QueryContext(ctx, `
+	SELECT service_id, weekday, coalesce(to_char(date, 'YYYY-MM-DD'), ''), to_char(opens, 'HH24:MI'), to_char(closes, 'HH24:MI')
+	FROM emergency_shift
+	WHERE service_id = ANY($1)
+	ORDER BY service_id, weekday NULLS LAST, date, opens
+	`, pq.Array(ids))
+	if err != nil {
+		return err
+	}
+	defer shifts.Close()
+
+	for shifts.Next() {
+		var serviceID int
+		var weekday sql.NullInt64
+		var shift types.EmergencyShift
+		err := shifts.Scan(&serviceID, &weekday, &shift.Date, &shift.Opens, &shift.Closes)
+		if err != nil {
+			return err
+		}
+		if weekday.Valid {
+			day := int(weekday.Int64)
+			shift.Weekday = &day
+		}
+		if s, ok := services[serviceID]; ok {
+			s.Shifts = append(s.Shifts, shift)
+		}
+	}
+
+	return shifts.Err()
+}
+
+/*
+SaveEmergencyService inserts the emergency service when its id is 0, otherwise it updates it, the rota is replaced in both cases
+The function returns the id of the service, 0 if there is no service with the id to update
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) SaveEmergencyService(ctx context.Context, s types.EmergencyService) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	id := s.ID
+	if id == 0 {
+		err := tx.QueryRowContext(ctx, `
+		INSERT INTO emergency_service (name, kind, location, address, telephone, url, note)
+		VALUES ($1, $2, ST_GeogFromText($3), $4, $5, $6, $7)
+		RETURNING id
+		`, s.Name, s.Kind, s.Location, s.Address, s.Telephone, s.Url, s.Note).Scan(&id)
+		if err != nil {
+			return 0, err
+		}
+	} else {
+		result, err := tx.ExecContext(ctx, `
+		UPDATE emergency_service
+		SET name=$1, kind=$2, location=ST_GeogFromText($3), address=$4, telephone=$5, url=$6, note=$7, updated_at=now()
+		WHERE id=$8
+		`, s.Name, s.Kind, s.Location, s.Address, s.Telephone, s.Url, s.Note, id)
+		if err != nil {
+			return 0, err
+		}
+
+		updated, err := result.RowsAffected()
+		if err != nil {
+			return 0, err
+		}
+		if updated == 0 {
+			return 0, nil
+		}
+
+		if _, err := tx.ExecContext(ctx, `DELETE FROM emergency_shift WHERE service_id=$1`, id); err != nil {
+			return 0, err
+		}
+	}
+
+	for _, shift := range s.Shifts {
+		var date *string
+		if shift.Date != "" {
+			date = &shift.Date
+		}
+
+		_, err := tx.ExecContext(ctx, `
+		INSERT INTO emergency_shift (service_id, weekday, date, opens, closes)
+		VALUES ($1, $2, $3, $4, $5)
+		`, id, shift.Weekday, date, shift.Opens, shift.Closes)
+		if err != nil {
+			return 0, err
+		}
+	}
+
+	if err := insertAudit(ctx, tx, id); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+/*
+DeleteEmergencyService deletes the emergency service with a specific id together with its rota
+The function returns false if there was no such service
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) DeleteEmergencyService(ctx context.Context, id int) (bool, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	_, err := m.audited(ctx, func(db dbExecutor) (int, error) {
+		res, err := db.ExecContext(ctx, `DELETE FROM emergency_service WHERE id=$1`, id)
+
+		return id, changedRow(res, err)
+	})
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,183 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetEmergencyServicesNear_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	services := sqlmock.NewRows([]string{"id", "name", "kind", "location", "address", "telephone", "url", "note", "distance"}).
+		AddRow(3, "APS Košice", "aps", "POINT(21.25 48.72)", "Rastislavova 43, Košice", "+421 55 123", "", "", 850.5).
+		AddRow(5, "Lekáreň Pod Hradom", "pharmacy", "POINT(21.26 48.73)", "", "", "", "vchod z dvora", 1200.0)
+	mock.ExpectQuery(`SELECT (.+) FROM emergency_service`).WithArgs("POINT(21.2496774 48.7172272)", 5000, "").WillReturnRows(services)
+
+	shifts := sqlmock.NewRows([]string{"service_id", "weekday", "date", "opens", "closes"}).
+		AddRow(3, 0, "", "15:30", "07:00").
+		AddRow(5, nil, "2024-03-04", "08:00", "20:00")
+	mock.ExpectQuery(`SELECT (.+) FROM emergency_shift`).WithArgs("{3,5}").WillReturnRows(shifts)
+
+	modelsDB := NewModels(db)
+	nearby, err := modelsDB.DB.GetEmergencyServicesNear(context.Background(), "POINT(21.2496774 48.7172272)", 5000, "")
+
+	monday := 0
+	assert.NoError(t, err)
+	assert.Len(t, nearby, 2)
+	assert.Equal(t, &types.EmergencyService{
+		ID: 3, Name: "APS Košice", Kind: "aps", Location: "POINT(21.25 48.72)", Address: "Rastislavova 43, Košice", Telephone: "+421 55 123",
+		Shifts: []types.EmergencyShift{{Weekday: &monday, Opens: "15:30", Closes: "07:00"}},
+	}, nearby[0].Service)
+	assert.Equal(t, 850.5, nearby[0].DistanceMeters)
+	assert.Equal(t, []types.EmergencyShift{{Date: "2024-03-04", Opens: "08:00", Closes: "20:00"}}, nearby[1].Service.Shifts)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetEmergencyServicesNear_NoneFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM emergency_service`).WithArgs("POINT(21.2496774 48.7172272)", 5000, "er").
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "kind", "location", "address", "telephone", "url", "note", "distance"}))
+
+	modelsDB := NewModels(db)
+	nearby, err := modelsDB.DB.GetEmergencyServicesNear(context.Background(), "POINT(21.2496774 48.7172272)", 5000, "er")
+
+	assert.NoError(t, err)
+	assert.Empty(t, nearby)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetEmergencyServicesNear_SqlError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM emergency_service`).WillReturnError(errors.New("mocked error"))
+
+	modelsDB := NewModels(db)
+	nearby, err := modelsDB.DB.GetEmergencyServicesNear(context.Background(), "POINT(21.2496774 48.7172272)", 5000, "")
+
+	assert.EqualError(t, err, "mocked error")
+	assert.Nil(t, nearby)
+}
+
+func TestSaveEmergencyService_Insert(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	saturday := 5
+	service := types.EmergencyService{
+		Name: "APS Košice", Kind: "aps", Location: "POINT(21.25 48.72)",
+		Shifts: []types.EmergencyShift{{Weekday: &saturday, Opens: "00:00", Closes: "24:00"}, {Date: "2024-03-04", Opens: "08:00", Closes: "20:00"}},
+	}
+
+	mock.ExpectBegin()
+	mock.ExpectQuery(`INSERT INTO emergency_service`).WithArgs("APS Košice", "aps", "POINT(21.25 48.72)", "", "", "", "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(9))
+	mock.ExpectExec(`INSERT INTO emergency_shift`).WithArgs(9, 5, nil, "00:00", "24:00").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`INSERT INTO emergency_shift`).WithArgs(9, nil, "2024-03-04", "08:00", "20:00").WillReturnResult(sqlmock.NewResult(2, 1))
+	mock.ExpectCommit()
+
+	modelsDB := NewModels(db)
+	id, err := modelsDB.DB.SaveEmergencyService(context.Background(), service)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 9, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSaveEmergencyService_Update(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	service := types.EmergencyService{ID: 9, Name: "APS Košice", Kind: "aps", Location: "POINT(21.25 48.72)"}
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE emergency_service`).WithArgs("APS Košice", "aps", "POINT(21.25 48.72)", "", "", "", "", 9).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM emergency_shift`).WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectCommit()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`UPDATE emergency_service`).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	modelsDB := NewModels(db)
+
+	id, err := modelsDB.DB.SaveEmergencyService(context.Background(), service)
+	assert.NoError(t, err)
+	assert.Equal(t, 9, id)
+
+	id, err = modelsDB.DB.SaveEmergencyService(context.Background(), service)
+	assert.NoError(t, err)
+	assert.Equal(t, 0, id)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteEmergencyService(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`DELETE FROM emergency_service`).WithArgs(9).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	modelsDB := NewModels(db)
+	deleted, err := modelsDB.DB.DeleteEmergencyService(context.Background(), 9)
+
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetEmergencyServiceByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM emergency_service WHERE id=\$1`).WithArgs(9).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "kind", "location", "address", "telephone", "url", "note"}).
+			AddRow(9, "APS Košice", "aps", "POINT(21.25 48.72)", "Rastislavova 43, Košice", "", "", ""))
+	mock.ExpectQuery(`SELECT (.+) FROM emergency_shift`).
+		WillReturnRows(sqlmock.NewRows([]string{"service_id", "weekday", "date", "opens", "closes"}).AddRow(9, 0, "", "15:30", "07:00"))
+	mock.ExpectQuery(`SELECT (.+) FROM emergency_service WHERE id=\$1`).WithArgs(10).WillReturnError(sql.ErrNoRows)
+
+	modelsDB := NewModels(db)
+
+	service, err := modelsDB.DB.GetEmergencyServiceByID(context.Background(), 9)
+	assert.NoError(t, err)
+	monday := 0
+	assert.Equal(t, &types.EmergencyService{
+		ID: 9, Name: "APS Košice", Kind: "aps", Location: "POINT(21.25 48.72)", Address: "Rastislavova 43, Košice",
+		Shifts: []types.EmergencyShift{{Weekday: &monday, Opens: "15:30", Closes: "07:00"}},
+	}, service)
+
+	service, err = modelsDB.DB.GetEmergencyServiceByID(context.Background(), 10)
+	assert.NoError(t, err)
+	assert.Nil(t, service)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,100 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/acornak/healthcare-poc/types"
+)
+
+/*
+GetGeocodeCache returns the cached places for a key
+Expired entries are treated as missing
+The function returns the places and true if the key is cached, an empty slice is a cached "not found"
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetGeocodeCache(ctx context.Context, key string) ([]*types.Place, bool, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT places
+	FROM geocode_cache
+	WHERE key=$1 AND expires_at > now()
+	`
+
+	var raw []byte
+	err := m.DB.QueryRowContext(ctx, stmt, key).Scan(&raw)
+	if errors.Is(err, sql.ErrNoRows) {
+		return nil, false, nil
+	}
+	if err != nil {
+		return nil, false, err
+	}
+
+	places := []*types.Place{}
+	if err := json.Unmarshal(raw, &places); err != nil {
+		return nil, false, err
+	}
+
+	return places, true, nil
+}
+
+/*
+SetGeocodeCache stores the places for a key until expiresAt, replacing any previous entry
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) SetGeocodeCache(ctx context.Context, key string, places []*types.Place, expiresAt time.Time) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	INSERT INTO geocode_cache (key, places, expires_at)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (key) DO UPDATE SET places=EXCLUDED.places, created_at=now(), expires_at=EXCLUDED.expires_at
+	`
+
+	if places == nil {
+		places = []*types.Place{}
+	}
+
+	raw, err := json.Marshal(places)
+	if err != nil {
+		return err
+	}
+
+	_, err = m.DB.ExecContext(ctx, stmt, key, raw, expiresAt)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/*
+PurgeGeocodeCache deletes cached geocoding results
+When expiredOnly is set only the expired entries are deleted
+The function returns the number of deleted entries
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) PurgeGeocodeCache(ctx context.Context, expiredOnly bool) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `DELETE FROM geocode_cache WHERE NOT $1 OR expires_at <= now()`
+
+	res, err := m.DB.ExecContext(ctx, stmt, expiredOnly)
+	if err != nil {
+		return 0, err
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	return int(deleted), nil
+}
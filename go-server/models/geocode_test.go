@@ -0,0 +1,113 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetGeocodeCache_SqlError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT places FROM geocode_cache WHERE key=\$1`).WithArgs("search:kosice").WillReturnError(errors.New("mocked error"))
+
+	modelsDB := NewModels(db)
+	places, found, err := modelsDB.DB.GetGeocodeCache(context.Background(), "search:kosice")
+
+	assert.EqualError(t, err, "mocked error")
+	assert.False(t, found)
+	assert.Nil(t, places)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetGeocodeCache_Missing(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT places FROM geocode_cache WHERE key=\$1`).WithArgs("search:kosice").WillReturnRows(sqlmock.NewRows([]string{"places"}))
+
+	modelsDB := NewModels(db)
+	places, found, err := modelsDB.DB.GetGeocodeCache(context.Background(), "search:kosice")
+
+	assert.NoError(t, err)
+	assert.False(t, found)
+	assert.Nil(t, places)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetGeocodeCache_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"places"}).AddRow([]byte(`[{"lat": 48.7164, "lon": 21.2611, "display_name": "Košice"}]`))
+	mock.ExpectQuery(`SELECT places FROM geocode_cache WHERE key=\$1`).WithArgs("search:kosice").WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	places, found, err := modelsDB.DB.GetGeocodeCache(context.Background(), "search:kosice")
+
+	assert.NoError(t, err)
+	assert.True(t, found)
+	assert.Equal(t, []*types.Place{{Lat: 48.7164, Lon: 21.2611, DisplayName: "Košice"}}, places)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetGeocodeCache(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	expiresAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	mock.ExpectExec("INSERT INTO geocode_cache").WithArgs("search:praha", []byte("[]"), expiresAt).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO geocode_cache").WithArgs("search:kosice", []byte(`[{"lat":48.7,"lon":21.2,"display_name":"Košice"}]`), expiresAt).WillReturnError(errors.New("mocked error"))
+
+	modelsDB := NewModels(db)
+
+	err = modelsDB.DB.SetGeocodeCache(context.Background(), "search:praha", nil, expiresAt)
+	assert.NoError(t, err)
+
+	err = modelsDB.DB.SetGeocodeCache(context.Background(), "search:kosice", []*types.Place{{Lat: 48.7, Lon: 21.2, DisplayName: "Košice"}}, expiresAt)
+	assert.EqualError(t, err, "mocked error")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestPurgeGeocodeCache(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("DELETE FROM geocode_cache").WithArgs(true).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("DELETE FROM geocode_cache").WithArgs(false).WillReturnError(errors.New("mocked error"))
+
+	modelsDB := NewModels(db)
+
+	deleted, err := modelsDB.DB.PurgeGeocodeCache(context.Background(), true)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, deleted)
+
+	deleted, err = modelsDB.DB.PurgeGeocodeCache(context.Background(), false)
+	assert.EqualError(t, err, "mocked error")
+	assert.Equal(t, 0, deleted)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
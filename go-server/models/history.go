@@ -0,0 +1,138 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"time"
+
+	"github.com/acornak/healthcare-poc/types"
+)
+
+// SystemActor is recorded in the history of specialists for changes made without an actor in the context
+const SystemActor = "system"
+
+type actorKey struct{}
+
+// WithActor returns a copy of ctx naming who makes the changes, the history of specialists records it
+func WithActor(ctx context.Context, actor string) context.Context {
+	return context.WithValue(ctx, actorKey{}, actor)
+}
+
+// ActorFromContext returns the actor named by WithActor, SystemActor when there is none
+func ActorFromContext(ctx context.Context) string {
+	if actor, ok := ctx.Value(actorKey{}).(string); ok && actor != "" {
+		return actor
+	}
+
+	return SystemActor
+}
+
+/*
+setActor names the actor of the changes made in the transaction
+The setting is local to the transaction, the history trigger of the specialist table reads it
+*/
+func setActor(ctx context.Context, tx *sql.Tx) error {
+	_, err := tx.ExecContext(ctx, `SELECT set_config('app.actor', $1, true)`, ActorFromContext(ctx))
+
+	return err
+}
+
+/*
+GetSpecialistHistory returns the changes of a specialist, the newest first
+The until is the time of the latest change returned, the zero time returns all changes
+The limit is the maximum number of changes
+The history is kept when the specialist is deleted, the function returns an empty slice for a specialist without changes
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetSpecialistHistory(ctx context.Context, id int, until time.Time, limit int) ([]*types.SpecialistChange, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT id, specialist_id, action, actor, before, after, changed_at
+	FROM specialist_history
+	WHERE specialist_id=$1 AND ($2::timestamptz IS NULL OR changed_at <= $2)
+	ORDER BY changed_at DESC, id DESC
+	LIMIT $3
+	`
+
+	rows, err := m.DB.QueryContext(ctx, stmt, id, historyTime(until), limit)
+	if err != nil {
+		return nil, err
+	}
+
+	changes, err := scanAll(rows, func(row rowScanner) (*types.SpecialistChange, error) {
+		var c types.SpecialistChange
+		var before, after []byte
+
+		if err := row.Scan(&c.ID, &c.SpecialistID, &c.Action, &c.Actor, &before, &after, &c.ChangedAt); err != nil {
+			return nil, err
+		}
+
+		c.Before = before
+		c.After = after
+
+		return &c, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if changes == nil {
+		changes = []*types.SpecialistChange{}
+	}
+
+	return changes, nil
+}
+
+/*
+GetSpecialistAsOf returns a specialist as it was at a point in time, after the latest change made until then
+The function returns nil if the specialist did not exist at that time
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetSpecialistAsOf(ctx context.Context, id int, at time.Time) (*types.SpecialistSnapshot, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT action, actor, record, changed_at
+	FROM specialist_history
+	WHERE specialist_id=$1 AND changed_at <= $2
+	ORDER BY changed_at DESC, id DESC
+	LIMIT 1
+	`
+
+	var action string
+	var record []byte
+	var snapshot types.SpecialistSnapshot
+
+	err := m.DB.QueryRowContext(ctx, stmt, id, at).Scan(&action, &snapshot.Actor, &record, &snapshot.ChangedAt)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	if action == types.AuditActionDelete {
+		return nil, nil
+	}
+
+	// the record is keyed by the columns of the specialist, the JSON names of its fields
+	if err := json.Unmarshal(record, &snapshot.Specialist); err != nil {
+		return nil, err
+	}
+
+	return &snapshot, nil
+}
+
+// historyTime passes the zero time as NULL
+func historyTime(t time.Time) any {
+	if t.IsZero() {
+		return nil
+	}
+
+	return t
+}
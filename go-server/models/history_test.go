@@ -0,0 +1,168 @@
+package models
+
+import (
+	"context"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var historyColumnNames = []string{"id", "specialist_id", "action", "actor", "before", "after", "changed_at"}
+
+func TestActorFromContext(t *testing.T) {
+	assert.Equal(t, SystemActor, ActorFromContext(context.Background()))
+	assert.Equal(t, SystemActor, ActorFromContext(WithActor(context.Background(), "")))
+	assert.Equal(t, "jana", ActorFromContext(WithActor(context.Background(), "jana")))
+}
+
+func TestGetSpecialistHistory_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	changedAt := time.Date(2024, 3, 2, 9, 0, 0, 0, time.UTC)
+	rows := sqlmock.NewRows(historyColumnNames).
+		AddRow(2, 1, "update", "jana", []byte(`{"telephone": null}`), []byte(`{"telephone": "055/123"}`), changedAt).
+		AddRow(1, 1, "create", "scraper", nil, []byte(`{"id": 1, "name": "Kardio Košice"}`), memoryUpdatedAt)
+
+	mock.ExpectQuery(`SELECT id, specialist_id, action, actor, before, after, changed_at FROM specialist_history WHERE specialist_id=\$1 AND \(\$2::timestamptz IS NULL OR changed_at <= \$2\) ORDER BY changed_at DESC, id DESC LIMIT \$3`).
+		WithArgs(1, nil, 50).
+		WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialistHistory(context.Background(), 1, time.Time{}, 50)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.SpecialistChange{
+		{ID: 2, SpecialistID: 1, Action: "update", Actor: "jana", Before: json.RawMessage(`{"telephone": null}`), After: json.RawMessage(`{"telephone": "055/123"}`), ChangedAt: changedAt},
+		{ID: 1, SpecialistID: 1, Action: "create", Actor: "scraper", After: json.RawMessage(`{"id": 1, "name": "Kardio Košice"}`), ChangedAt: memoryUpdatedAt},
+	}, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistHistory_Until(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialist_history`).
+		WithArgs(42, memoryUpdatedAt, 10).
+		WillReturnRows(sqlmock.NewRows(historyColumnNames))
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialistHistory(context.Background(), 42, memoryUpdatedAt, 10)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.SpecialistChange{}, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistHistory_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialist_history`).WillReturnError(errors.New("some error"))
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialistHistory(context.Background(), 1, time.Time{}, 50)
+
+	assert.EqualError(t, err, "some error")
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistAsOf_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	record := `{"id": 1, "name": "Kardio Košice", "specialty_id": 1, "location": "POINT(21.25 48.72)", "address": "Hlavná 1", "url": null, "telephone": "055/123", "insurers": ["VšZP"], "version": 2}`
+	rows := sqlmock.NewRows([]string{"action", "actor", "record", "changed_at"}).
+		AddRow("update", "jana", []byte(record), memoryUpdatedAt)
+
+	at := memoryUpdatedAt.Add(time.Hour)
+	mock.ExpectQuery(`SELECT action, actor, record, changed_at FROM specialist_history WHERE specialist_id=\$1 AND changed_at <= \$2 ORDER BY changed_at DESC, id DESC LIMIT 1`).
+		WithArgs(1, at).
+		WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialistAsOf(context.Background(), 1, at)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &types.SpecialistSnapshot{
+		Specialist: &types.Specialist{ID: 1, Name: "Kardio Košice", SpecialtyID: 1, Location: "POINT(21.25 48.72)", Address: "Hlavná 1", Telephone: "055/123", Insurers: []string{"VšZP"}, Version: 2},
+		Actor:      "jana",
+		ChangedAt:  memoryUpdatedAt,
+	}, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistAsOf_Deleted(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"action", "actor", "record", "changed_at"}).
+		AddRow("delete", "jana", []byte(`{"id": 1, "name": "Kardio Košice"}`), memoryUpdatedAt)
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialist_history`).WithArgs(1, memoryUpdatedAt).WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialistAsOf(context.Background(), 1, memoryUpdatedAt)
+
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistAsOf_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialist_history`).
+		WithArgs(1, memoryUpdatedAt).
+		WillReturnRows(sqlmock.NewRows([]string{"action", "actor", "record", "changed_at"}))
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialistAsOf(context.Background(), 1, memoryUpdatedAt)
+
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistAsOf_Error(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialist_history`).WillReturnError(errors.New("some error"))
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialistAsOf(context.Background(), 1, memoryUpdatedAt)
+
+	assert.EqualError(t, err, "some error")
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,101 @@
+package models
+
+import (
+	"context"
+	"errors"
+
+	"github.com/acornak/healthcare-poc/types"
+)
+
+/*
+GetHolidayOverrides returns the holiday overrides of a year, ordered by date
+The function returns a slice of pointers to Holiday structs
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetHolidayOverrides(ctx context.Context, year int) ([]*types.Holiday, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT to_char(date, 'YYYY-MM-DD'), name, region, closed
+	FROM holiday_override
+	WHERE EXTRACT(YEAR FROM date) = $1
+	ORDER BY date, region
+	`
+
+	rows, err := m.DB.QueryContext(ctx, stmt, year)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	overrides := []*types.Holiday{}
+
+	for rows.Next() {
+		h := types.Holiday{Source: types.HolidaySourceOverride}
+		err := rows.Scan(&h.Date, &h.Name, &h.Region, &h.Closed)
+		if err != nil {
+			return nil, err
+		}
+		overrides = append(overrides, &h)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return overrides, nil
+}
+
+/*
+SetHolidayOverride inserts the holiday override, replacing the one on the same date and region
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) SetHolidayOverride(ctx context.Context, h types.Holiday) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	INSERT INTO holiday_override (date, region, name, closed)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (date, region) DO UPDATE SET name=EXCLUDED.name, closed=EXCLUDED.closed, updated_at=now()
+	`
+
+	// a holiday override has no id, the audit log identifies it by the date and region of the record
+	_, err := m.audited(ctx, func(db dbExecutor) (int, error) {
+		_, err := db.ExecContext(ctx, stmt, h.Date, h.Region, h.Name, h.Closed)
+
+		return 0, err
+	})
+
+	return err
+}
+
+/*
+DeleteHolidayOverride deletes the holiday override on the date and region
+The function returns false if there was no such override
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) DeleteHolidayOverride(ctx context.Context, date, region string) (bool, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	DELETE FROM holiday_override
+	WHERE date=$1 AND region=$2
+	`
+
+	_, err := m.audited(ctx, func(db dbExecutor) (int, error) {
+		res, err := db.ExecContext(ctx, stmt, date, region)
+
+		return 0, changedRow(res, err)
+	})
+	if errors.Is(err, ErrNotFound) {
+		return false, nil
+	}
+	if err != nil {
+		return false, err
+	}
+
+	return true, nil
+}
@@ -0,0 +1,89 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetHolidayOverrides_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"date", "name", "region", "closed"}).
+		AddRow("2024-05-08", "Deň víťazstva nad fašizmom", "", false).
+		AddRow("2024-06-14", "Dezinfekcia budovy", "Michalovce", true)
+	mock.ExpectQuery(`SELECT (.+) FROM holiday_override`).WithArgs(2024).WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	overrides, err := modelsDB.DB.GetHolidayOverrides(context.Background(), 2024)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Holiday{
+		{Date: "2024-05-08", Name: "Deň víťazstva nad fašizmom", Closed: false, Source: types.HolidaySourceOverride},
+		{Date: "2024-06-14", Name: "Dezinfekcia budovy", Region: "Michalovce", Closed: true, Source: types.HolidaySourceOverride},
+	}, overrides)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetHolidayOverrides_SqlError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM holiday_override`).WillReturnError(errors.New("mocked error"))
+
+	modelsDB := NewModels(db)
+	overrides, err := modelsDB.DB.GetHolidayOverrides(context.Background(), 2024)
+
+	assert.EqualError(t, err, "mocked error")
+	assert.Nil(t, overrides)
+}
+
+func TestSetHolidayOverride(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`INSERT INTO holiday_override`).WithArgs("2024-06-14", "Michalovce", "Dezinfekcia budovy", true).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	modelsDB := NewModels(db)
+	err = modelsDB.DB.SetHolidayOverride(context.Background(), types.Holiday{Date: "2024-06-14", Name: "Dezinfekcia budovy", Region: "Michalovce", Closed: true})
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteHolidayOverride(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`DELETE FROM holiday_override`).WithArgs("2024-06-14", "").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM holiday_override`).WithArgs("2024-06-14", "Michalovce").WillReturnResult(sqlmock.NewResult(0, 1))
+
+	modelsDB := NewModels(db)
+
+	deleted, err := modelsDB.DB.DeleteHolidayOverride(context.Background(), "2024-06-14", "")
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+
+	deleted, err = modelsDB.DB.DeleteHolidayOverride(context.Background(), "2024-06-14", "Michalovce")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,1940 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"fmt"
+	"maps"
+	"slices"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+
+	"github.com/acornak/healthcare-poc/geocoding"
+	"github.com/acornak/healthcare-poc/textutil"
+	"github.com/acornak/healthcare-poc/types"
+)
+
+/*
+MemoryModel keeps specialists, specialties with their taxonomy, reviews, the admin audit log, symptom mappings, holiday overrides,
+seed versions and address checks in memory
+It implements the same repositories as DBModel and mirrors its behaviour, including the foreign keys:
+a specialty with specialists or a specialist with reviews cannot be deleted
+Distances are great-circle distances, the search approximates the trigram word similarity of pg_trgm
+MemoryModel is safe for concurrent use, the zero value is not, use NewMemoryModel
+*/
+type MemoryModel struct {
+	// Now returns the time stored as the last update of a specialist, time.Now when nil
+	Now func() time.Time
+
+	mu          sync.RWMutex
+	specialists map[int]*memorySpecialist
+	specialties map[int]*memorySpecialty
+	aliases     map[string]int
+	reviews     map[int]*types.Review
+	audit       []types.AuditEntry
+	history     []memoryChange
+	symptoms    map[int]*types.SymptomMapping
+	holidays    map[[2]string]types.Holiday
+	categories  map[int]*memoryCategory
+	seeds       map[string]int
+	checks      map[int]types.AddressCheck
+	lastID      struct{ specialist, specialty, review, symptom, category int }
+
+	// specialistAliases maps the source names of merged specialists to the surviving ones
+	specialistAliases map[string]int
+	// dismissed holds the pairs of specialists reviewed as distinct, the lower id first
+	dismissed map[[2]int]bool
+	// tombstones holds the source names of specialists deleted by an admin
+	tombstones map[string]bool
+}
+
+type memorySpecialist struct {
+	specialist types.Specialist
+	updatedAt  time.Time
+	// overrides maps the overridden fields to their source values, the values are those of the specialist
+	overrides map[string]types.SpecialistOverride
+}
+
+// memoryChange is a change of a specialist with the whole specialist after it, before it for a delete
+type memoryChange struct {
+	change types.SpecialistChange
+	record *types.Specialist
+}
+
+type memorySpecialty struct {
+	specialty      types.Specialty
+	normalizedName string
+	categoryID     int
+	translations   map[string]types.SpecialtyTranslation
+}
+
+type memoryCategory struct {
+	code     string
+	parentID int
+	// names maps the languages to the translated names
+	names map[string]string
+}
+
+// NewMemoryModel returns an empty in-memory store, ids are assigned from 1 in the order of insertion
+func NewMemoryModel() *MemoryModel {
+	return &MemoryModel{
+		specialists: make(map[int]*memorySpecialist),
+		specialties: make(map[int]*memorySpecialty),
+		aliases:     make(map[string]int),
+		reviews:     make(map[int]*types.Review),
+		symptoms:    make(map[int]*types.SymptomMapping),
+		holidays:    make(map[[2]string]types.Holiday),
+		categories:  make(map[int]*memoryCategory),
+		seeds:       make(map[string]int),
+		checks:      make(map[int]types.AddressCheck),
+
+		specialistAliases: make(map[string]int),
+		dismissed:         make(map[[2]int]bool),
+		tombstones:        make(map[string]bool),
+	}
+}
+
+func (m *MemoryModel) now() time.Time {
+	if m.Now != nil {
+		return m.Now()
+	}
+
+	return time.Now()
+}
+
+/*
+GetAllSpecialists returns all specialists ordered by id
+The function returns a slice of pointers to Specialist structs
+*/
+func (m *MemoryModel) GetAllSpecialists(ctx context.Context) ([]*types.Specialist, error) {
+	return m.filterSpecialists(func(*types.Specialist) bool { return true }), nil
+}
+
+/*
+GetSpecialistBySpecialty returns all specialists with a specific specialty ordered by id
+The specialtyID is the id of the specialty
+The function returns a slice of pointers to Specialist structs
+*/
+func (m *MemoryModel) GetSpecialistBySpecialty(ctx context.Context, specialtyID int) ([]*types.Specialist, error) {
+	return m.filterSpecialists(func(s *types.Specialist) bool { return s.SpecialtyID == specialtyID }), nil
+}
+
+/*
+GetSpecialistByID returns a specialist with a specific id
+The function returns a pointer to a Specialist struct, nil if the specialist does not exist
+*/
+func (m *MemoryModel) GetSpecialistByID(ctx context.Context, id int) (*types.Specialist, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.specialists[id]
+	if !ok {
+		return nil, nil
+	}
+
+	return cloneSpecialist(&stored.specialist), nil
+}
+
+/*
+GetSpecialistProfile returns the full profile of a specialist with a specific id
+The profile contains the specialist with its insurers, the specialty name, the review summary and the last update time
+The function returns a pointer to a SpecialistProfile struct, nil if the specialist does not exist
+*/
+func (m *MemoryModel) GetSpecialistProfile(ctx context.Context, id int) (*types.SpecialistProfile, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.specialists[id]
+	if !ok {
+		return nil, nil
+	}
+
+	profile := types.SpecialistProfile{
+		Specialist: cloneSpecialist(&stored.specialist),
+		UpdatedAt:  stored.updatedAt,
+	}
+
+	if specialty, ok := m.specialties[stored.specialist.SpecialtyID]; ok {
+		profile.SpecialtyName = specialty.specialty.Name
+	}
+
+	total := 0.0
+	for _, review := range m.reviews {
+		if review.SpecialistId == id {
+			profile.Reviews.Count++
+			total += review.Rating
+		}
+	}
+	if profile.Reviews.Count > 0 {
+		profile.Reviews.AverageRating = total / float64(profile.Reviews.Count)
+	}
+
+	return &profile, nil
+}
+
+/*
+GetSpecialistByName returns the specialist with the lowest id with a specific name
+An overridden name is matched by its source value
+The function returns a pointer to a Specialist struct, nil if the specialist does not exist
+*/
+func (m *MemoryModel) GetSpecialistByName(ctx context.Context, name string) (*types.Specialist, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, id := range sortedKeys(m.specialists) {
+		stored := m.specialists[id]
+
+		sourceName := stored.specialist.Name
+		if override, ok := stored.overrides["name"]; ok {
+			sourceName = override.SourceValue
+		}
+
+		if sourceName == name {
+			return cloneSpecialist(&stored.specialist), nil
+		}
+	}
+
+	return nil, nil
+}
+
+/*
+GetSpecialistBySpecialtyAndLocation returns all specialists with a specific specialty within a certain radius of a location
+The radius is the radius in meters, the userLocation is the location in WKT format
+The function returns a slice of pointers to Specialist structs ordered by id
+The function returns an error if the userLocation is not a valid WKT point
+*/
+func (m *MemoryModel) GetSpecialistBySpecialtyAndLocation(ctx context.Context, specialtyID, radius int, userLocation string) ([]*types.Specialist, error) {
+	origin, err := types.ParseLocation(userLocation)
+	if err != nil {
+		return nil, err
+	}
+
+	return m.filterSpecialists(func(s *types.Specialist) bool {
+		if s.SpecialtyID != specialtyID || s.Location == "" {
+			return false
+		}
+
+		location, err := types.ParseLocation(s.Location)
+		if err != nil {
+			return false
+		}
+
+		return geocoding.Distance(origin.Lat, origin.Lon, location.Lat, location.Lon) <= float64(radius)
+	}), nil
+}
+
+/*
+InsertSpecialist inserts a new specialist with the next free id
+The function returns the id of the new specialist
+The function returns an error if the specialty of the specialist does not exist
+*/
+func (m *MemoryModel) InsertSpecialist(ctx context.Context, s types.Specialist) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkSpecialty(s.SpecialtyID); err != nil {
+		return 0, err
+	}
+
+	entries, err := auditEntries(ctx, m.lastID.specialist+1)
+	if err != nil {
+		return 0, err
+	}
+
+	m.lastID.specialist++
+	s.ID = m.lastID.specialist
+	s.Version = 1
+	s.FieldSources = types.NewFieldSources(nil)
+	m.specialists[s.ID] = &memorySpecialist{specialist: *storedSpecialist(&s), updatedAt: m.now()}
+	m.recordChange(ctx, nil, &m.specialists[s.ID].specialist)
+	m.appendAudit(entries)
+
+	return s.ID, nil
+}
+
+/*
+DeleteSpecialist deletes a specialist with a specific id and version
+The source name of the specialist and the names merged into it are kept as tombstones
+The function returns ErrNotFound if the specialist does not exist and ErrStaleVersion if it was changed since
+The function returns an error if the specialist still has reviews
+*/
+func (m *MemoryModel) DeleteSpecialist(ctx context.Context, id, version int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkVersion(id, version); err != nil {
+		return err
+	}
+
+	for _, reviewID := range sortedKeys(m.reviews) {
+		if review := m.reviews[reviewID]; review.SpecialistId == id {
+			return fmt.Errorf("specialist %d is still referenced by review %d", id, review.ID)
+		}
+	}
+
+	entries, err := auditEntries(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	stored := m.specialists[id]
+	if override, ok := stored.overrides["name"]; ok {
+		m.tombstones[override.SourceValue] = true
+	} else {
+		m.tombstones[stored.specialist.Name] = true
+	}
+	for name, specialistID := range m.specialistAliases {
+		if specialistID == id {
+			m.tombstones[name] = true
+		}
+	}
+
+	m.removeSpecialist(ctx, id)
+	m.appendAudit(entries)
+
+	return nil
+}
+
+// removeSpecialist deletes a specialist with its aliases, dismissed duplicate pairs and address check, as the foreign keys cascade
+func (m *MemoryModel) removeSpecialist(ctx context.Context, id int) {
+	m.recordChange(ctx, &m.specialists[id].specialist, nil)
+	delete(m.specialists, id)
+	delete(m.checks, id)
+
+	for name, specialistID := range m.specialistAliases {
+		if specialistID == id {
+			delete(m.specialistAliases, name)
+		}
+	}
+
+	for pair := range m.dismissed {
+		if pair[0] == id || pair[1] == id {
+			delete(m.dismissed, pair)
+		}
+	}
+}
+
+/*
+UpdateSpecialist updates the specialist with the id of s, the Version of s is the version the caller read
+The function returns the new version of the specialist
+The KPZS code comes from the source and is kept
+The function returns ErrNotFound if the specialist does not exist and ErrStaleVersion if it was changed since
+The function returns an error if the specialty of the specialist does not exist
+*/
+func (m *MemoryModel) UpdateSpecialist(ctx context.Context, s types.Specialist) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkVersion(s.ID, s.Version); err != nil {
+		return 0, err
+	}
+
+	if err := m.checkSpecialty(s.SpecialtyID); err != nil {
+		return 0, err
+	}
+
+	entries, err := auditEntries(ctx, s.ID)
+	if err != nil {
+		return 0, err
+	}
+
+	s.KPZS = m.specialists[s.ID].specialist.KPZS
+	version := m.storeVersion(ctx, &s, m.specialists[s.ID].overrides)
+	m.appendAudit(entries)
+
+	return version, nil
+}
+
+// checkVersion mirrors the optimistic locking of the specialist updates and deletes
+func (m *MemoryModel) checkVersion(id, version int) error {
+	stored, ok := m.specialists[id]
+	if !ok {
+		return ErrNotFound
+	}
+
+	if stored.specialist.Version != version {
+		return ErrStaleVersion
+	}
+
+	return nil
+}
+
+/*
+SearchSpecialists returns specialists matching the search terms ordered by relevance
+The terms are folded search words, every term is compared against the words of the clinic name, staff, address and specialty
+The similarity of a term and a word is the share of the trigrams of the term found in the word, as word_similarity of pg_trgm
+The rank of a specialist is the average similarity of all terms, specialists below minRank or without a specialty are skipped
+The limit is the maximum number of results
+The function returns a slice of pointers to SpecialistSearchResult structs
+*/
+func (m *MemoryModel) SearchSpecialists(ctx context.Context, terms []string, minRank float64, limit int) ([]*types.SpecialistSearchResult, error) {
+	if len(terms) == 0 {
+		return nil, nil
+	}
+
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var results []*types.SpecialistSearchResult
+
+	for _, stored := range m.specialists {
+		s := &stored.specialist
+
+		specialty, ok := m.specialties[s.SpecialtyID]
+		if !ok {
+			continue
+		}
+
+		words := textutil.Words(s.Name + " " + s.Staff + " " + s.Address + " " + specialty.specialty.Name)
+
+		total := 0.0
+		for _, term := range terms {
+			total += wordSimilarity(term, words)
+		}
+
+		rank := total / float64(len(terms))
+		if rank < minRank {
+			continue
+		}
+
+		results = append(results, &types.SpecialistSearchResult{
+			Specialist:    cloneSpecialist(s),
+			SpecialtyName: specialty.specialty.Name,
+			Rank:          rank,
+		})
+	}
+
+	sort.Slice(results, func(i, j int) bool {
+		if results[i].Rank != results[j].Rank {
+			return results[i].Rank > results[j].Rank
+		}
+		return results[i].Specialist.ID < results[j].Specialist.ID
+	})
+
+	if len(results) > limit {
+		results = results[:limit]
+	}
+
+	return results, nil
+}
+
+/*
+GetSpecialistPlaces returns the addresses and coordinates of all specialists with a known location
+The function returns a slice of pointers to Place structs ordered by specialist id
+*/
+func (m *MemoryModel) GetSpecialistPlaces(ctx context.Context) ([]*types.Place, error) {
+	var places []*types.Place
+
+	for _, s := range m.filterSpecialists(func(s *types.Specialist) bool { return s.Location != "" && s.Address != "" }) {
+		location, err := types.ParseLocation(s.Location)
+		if err != nil {
+			continue
+		}
+
+		places = append(places, &types.Place{Type: types.PlaceTypeHouse, DisplayName: s.Address, Lat: location.Lat, Lon: location.Lon})
+	}
+
+	return places, nil
+}
+
+/*
+GetSpecialistHistory returns the changes of a specialist made until a point in time, the newest first
+The zero until returns all changes, the limit is the maximum number of changes
+The function returns an empty slice for a specialist without changes
+*/
+func (m *MemoryModel) GetSpecialistHistory(ctx context.Context, id int, until time.Time, limit int) ([]*types.SpecialistChange, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	changes := []*types.SpecialistChange{}
+	for i := len(m.history) - 1; i >= 0 && len(changes) < limit; i-- {
+		change := m.history[i].change
+		if change.SpecialistID == id && (until.IsZero() || !change.ChangedAt.After(until)) {
+			changes = append(changes, &change)
+		}
+	}
+
+	return changes, nil
+}
+
+/*
+GetSpecialistAsOf returns a specialist as it was at a point in time, after the latest change made until then
+The function returns nil if the specialist did not exist at that time
+*/
+func (m *MemoryModel) GetSpecialistAsOf(ctx context.Context, id int, at time.Time) (*types.SpecialistSnapshot, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for i := len(m.history) - 1; i >= 0; i-- {
+		stored := m.history[i]
+		if stored.change.SpecialistID != id || stored.change.ChangedAt.After(at) {
+			continue
+		}
+
+		if stored.change.Action == types.AuditActionDelete {
+			return nil, nil
+		}
+
+		specialist := cloneSpecialist(stored.record)
+		specialist.FieldSources = nil
+
+		return &types.SpecialistSnapshot{Specialist: specialist, Actor: stored.change.Actor, ChangedAt: stored.change.ChangedAt}, nil
+	}
+
+	return nil, nil
+}
+
+/*
+GetSpecialistOverrides returns the overridden fields of a specialist ordered by field
+The function returns nil if the specialist does not exist
+*/
+func (m *MemoryModel) GetSpecialistOverrides(ctx context.Context, id int) ([]*types.SpecialistOverride, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.specialists[id]
+	if !ok {
+		return nil, nil
+	}
+
+	overrides := []*types.SpecialistOverride{}
+	for _, field := range fieldNames(stored.overrides) {
+		override := stored.overrides[field]
+		override.Value = *stored.specialist.OverridableField(field)
+		overrides = append(overrides, &override)
+	}
+
+	return overrides, nil
+}
+
+/*
+SetSpecialistOverrides overrides fields of a specialist, values maps the fields to their new values
+A field overridden for the first time keeps its current value as the source value
+The function returns the new version of the specialist
+The function returns ErrNotFound if the specialist does not exist and ErrStaleVersion if it was changed since
+The function returns an error if a field cannot be overridden
+*/
+func (m *MemoryModel) SetSpecialistOverrides(ctx context.Context, id, version int, values map[string]string) (int, error) {
+	fields, err := overrideFields(fieldNames(values))
+	if err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkVersion(id, version); err != nil {
+		return 0, err
+	}
+
+	stored := m.specialists[id]
+	s := cloneSpecialist(&stored.specialist)
+	overrides := maps.Clone(stored.overrides)
+	if overrides == nil {
+		overrides = map[string]types.SpecialistOverride{}
+	}
+
+	now := m.now()
+	for _, field := range fields {
+		override, ok := overrides[field]
+		if !ok {
+			override = types.SpecialistOverride{Field: field, SourceValue: *s.OverridableField(field)}
+		}
+		override.UpdatedAt = now
+		overrides[field] = override
+
+		*s.OverridableField(field) = values[field]
+	}
+
+	entries, err := auditEntries(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	newVersion := m.storeVersion(ctx, s, overrides)
+	m.appendAudit(entries)
+
+	return newVersion, nil
+}
+
+/*
+DeleteSpecialistOverrides removes overrides of a specialist, the fields get their latest source values back
+Fields that are not overridden are skipped, the version is kept when none of the fields was overridden
+The function returns the new version of the specialist
+The function returns ErrNotFound if the specialist does not exist and ErrStaleVersion if it was changed since
+The function returns an error if a field cannot be overridden
+*/
+func (m *MemoryModel) DeleteSpecialistOverrides(ctx context.Context, id, version int, fields []string) (int, error) {
+	fields, err := overrideFields(fields)
+	if err != nil {
+		return 0, err
+	}
+
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkVersion(id, version); err != nil {
+		return 0, err
+	}
+
+	stored := m.specialists[id]
+	s := cloneSpecialist(&stored.specialist)
+	overrides := maps.Clone(stored.overrides)
+
+	restored := false
+	for _, field := range fields {
+		if override, ok := overrides[field]; ok {
+			*s.OverridableField(field) = override.SourceValue
+			delete(overrides, field)
+			restored = true
+		}
+	}
+
+	if !restored {
+		return version, nil
+	}
+
+	entries, err := auditEntries(ctx, id)
+	if err != nil {
+		return 0, err
+	}
+
+	newVersion := m.storeVersion(ctx, s, overrides)
+	m.appendAudit(entries)
+
+	return newVersion, nil
+}
+
+/*
+SyncSpecialist updates a specialist with its data from the source
+Overridden fields keep their values, only their source values are updated
+The specialist is updated and its version bumped only when a field presented by the API changed
+The function returns whether the specialist changed
+The function returns ErrNotFound if the specialist does not exist
+The function returns an error if the specialty of the source does not exist
+*/
+func (m *MemoryModel) SyncSpecialist(ctx context.Context, id int, source types.Specialist) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.specialists[id]
+	if !ok {
+		return false, ErrNotFound
+	}
+
+	s := cloneSpecialist(&stored.specialist)
+	overrides := maps.Clone(stored.overrides)
+
+	changed := false
+	for _, field := range types.SpecialistOverrideFields {
+		value := *source.OverridableField(field)
+
+		if override, ok := overrides[field]; ok {
+			override.SourceValue = value
+			overrides[field] = override
+			continue
+		}
+
+		if !sameFieldValue(field, *s.OverridableField(field), value) {
+			*s.OverridableField(field) = value
+			changed = true
+		}
+	}
+
+	if source.SpecialtyID != s.SpecialtyID {
+		if err := m.checkSpecialty(source.SpecialtyID); err != nil {
+			return false, err
+		}
+		s.SpecialtyID = source.SpecialtyID
+		changed = true
+	}
+
+	insurers := source.Insurers
+	if insurers == nil {
+		insurers = []string{}
+	}
+	if !slices.Equal(insurers, s.Insurers) {
+		s.Insurers = slices.Clone(insurers)
+		changed = true
+	}
+
+	if source.KPZS != s.KPZS {
+		s.KPZS = source.KPZS
+		changed = true
+	}
+
+	if !changed {
+		stored.overrides = overrides
+		return false, nil
+	}
+
+	m.storeVersion(ctx, s, overrides)
+
+	return true, nil
+}
+
+// storeVersion stores the specialist with its overrides as its next version, the function returns the new version
+func (m *MemoryModel) storeVersion(ctx context.Context, s *types.Specialist, overrides map[string]types.SpecialistOverride) int {
+	before := m.specialists[s.ID].specialist
+
+	s.Version++
+	s.FieldSources = types.NewFieldSources(fieldNames(overrides))
+	m.specialists[s.ID] = &memorySpecialist{specialist: *storedSpecialist(s), updatedAt: m.now(), overrides: overrides}
+	m.recordChange(ctx, &before, &m.specialists[s.ID].specialist)
+
+	return s.Version
+}
+
+/*
+GetDuplicateSpecialists returns pairs of specialists that may be the same clinic, the most likely first
+Specialists are compared when they share the KPZS code, or have the same specialty and are within 500 meters or have the same phone numbers
+Pairs dismissed by DismissDuplicateSpecialists are skipped
+The minScore is the minimum score of a returned pair, the limit is the maximum number of pairs
+*/
+func (m *MemoryModel) GetDuplicateSpecialists(ctx context.Context, minScore float64, limit int) ([]*types.DuplicateCandidate, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := sortedKeys(m.specialists)
+
+	candidates := []*types.DuplicateCandidate{}
+	for i, id := range ids {
+		for _, otherID := range ids[i+1:] {
+			if m.dismissed[[2]int{id, otherID}] {
+				continue
+			}
+
+			a, b := &m.specialists[id].specialist, &m.specialists[otherID].specialist
+			distance := specialistDistance(a, b)
+			if isDuplicatePair(a, b, distance) {
+				candidates = append(candidates, scoreDuplicate(cloneSpecialist(a), cloneSpecialist(b), distance))
+			}
+		}
+	}
+
+	return rankDuplicates(candidates, minScore, limit), nil
+}
+
+// specialistDistance returns the great-circle distance of two specialists in meters, -1 when a location is unknown
+func specialistDistance(a, b *types.Specialist) float64 {
+	from, err := types.ParseLocation(a.Location)
+	if err != nil {
+		return -1
+	}
+
+	to, err := types.ParseLocation(b.Location)
+	if err != nil {
+		return -1
+	}
+
+	return geocoding.Distance(from.Lat, from.Lon, to.Lat, to.Lon)
+}
+
+/*
+DismissDuplicateSpecialists records that two specialists are distinct, GetDuplicateSpecialists skips the pair from then on
+The function returns an error if one of the specialists does not exist
+*/
+func (m *MemoryModel) DismissDuplicateSpecialists(ctx context.Context, id, otherID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, specialistID := range []int{id, otherID} {
+		if _, ok := m.specialists[specialistID]; !ok {
+			return fmt.Errorf("specialist %d does not exist", specialistID)
+		}
+	}
+
+	m.dismissed[[2]int{min(id, otherID), max(id, otherID)}] = true
+
+	return nil
+}
+
+/*
+MergeSpecialists merges a duplicate specialist into the surviving one
+The reviews of the duplicate are moved to the survivor, the staff of the duplicate missing at the survivor is added to its staff,
+which is kept as an override so the next sync from the source does not drop it
+The source name of the duplicate is kept as an alias, GetSpecialistAlias resolves it to the survivor, and the duplicate is deleted
+The function returns the new version of the survivor, unchanged when no staff was added, and the number of moved reviews
+The function returns ErrNotFound if a specialist does not exist and ErrStaleVersion if it was changed since
+*/
+func (m *MemoryModel) MergeSpecialists(ctx context.Context, duplicateID, duplicateVersion, survivorID, survivorVersion int) (int, int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkVersion(survivorID, survivorVersion); err != nil {
+		return 0, 0, err
+	}
+
+	if err := m.checkVersion(duplicateID, duplicateVersion); err != nil {
+		return 0, 0, err
+	}
+
+	entries, err := auditEntries(ctx, survivorID)
+	if err != nil {
+		return 0, 0, err
+	}
+
+	moved := 0
+	for _, id := range sortedKeys(m.reviews) {
+		if review := m.reviews[id]; review.SpecialistId == duplicateID {
+			review.SpecialistId = survivorID
+			moved++
+		}
+	}
+
+	stored, duplicate := m.specialists[survivorID], m.specialists[duplicateID]
+
+	version := survivorVersion
+	if staff := MergeStaff(stored.specialist.Staff, duplicate.specialist.Staff); staff != stored.specialist.Staff {
+		s := cloneSpecialist(&stored.specialist)
+		overrides := maps.Clone(stored.overrides)
+		if overrides == nil {
+			overrides = map[string]types.SpecialistOverride{}
+		}
+
+		override, ok := overrides["staff"]
+		if !ok {
+			override = types.SpecialistOverride{Field: "staff", SourceValue: s.Staff}
+		}
+		override.UpdatedAt = m.now()
+		overrides["staff"] = override
+
+		s.Staff = staff
+		version = m.storeVersion(ctx, s, overrides)
+	}
+
+	for name, id := range m.specialistAliases {
+		if id == duplicateID {
+			m.specialistAliases[name] = survivorID
+		}
+	}
+
+	sourceName := duplicate.specialist.Name
+	if override, ok := duplicate.overrides["name"]; ok {
+		sourceName = override.SourceValue
+	}
+
+	m.removeSpecialist(ctx, duplicateID)
+	m.specialistAliases[sourceName] = survivorID
+	m.appendAudit(entries)
+
+	return version, moved, nil
+}
+
+/*
+GetSpecialistAlias returns the id of the specialist a specialist with the source name was merged into
+The function returns 0 if no specialist with the name was merged
+*/
+func (m *MemoryModel) GetSpecialistAlias(ctx context.Context, name string) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.specialistAliases[name], nil
+}
+
+/*
+IsSpecialistDeleted returns whether a specialist with the source name was deleted by an admin
+*/
+func (m *MemoryModel) IsSpecialistDeleted(ctx context.Context, name string) (bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.tombstones[name], nil
+}
+
+/*
+GetAllSpecialties returns all specialties ordered by id
+The function returns a slice of pointers to Specialty structs
+*/
+func (m *MemoryModel) GetAllSpecialties(ctx context.Context) ([]*types.Specialty, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var specialties []*types.Specialty
+	for _, id := range sortedKeys(m.specialties) {
+		specialty := m.specialties[id].specialty
+		specialties = append(specialties, &specialty)
+	}
+
+	return specialties, nil
+}
+
+/*
+GetSpecialtyByID returns a specialty with a specific id
+The function returns a pointer to a Specialty struct, nil if the specialty does not exist
+*/
+func (m *MemoryModel) GetSpecialtyByID(ctx context.Context, id int) (*types.Specialty, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.specialties[id]
+	if !ok {
+		return nil, nil
+	}
+
+	specialty := stored.specialty
+
+	return &specialty, nil
+}
+
+/*
+GetSpecialtyByName returns the specialty with the lowest id with a specific name
+The function returns a pointer to a Specialty struct, nil if the specialty does not exist
+*/
+func (m *MemoryModel) GetSpecialtyByName(ctx context.Context, name string) (*types.Specialty, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, id := range sortedKeys(m.specialties) {
+		if specialty := m.specialties[id].specialty; specialty.Name == name {
+			return &specialty, nil
+		}
+	}
+
+	return nil, nil
+}
+
+/*
+GetSpecialtyByNormalizedName returns a specialty with a specific normalized name
+The name is normalized before the lookup, aliases left behind by merged duplicates are resolved to the surviving specialty
+The function returns a pointer to a Specialty struct, nil if the specialty does not exist
+*/
+func (m *MemoryModel) GetSpecialtyByNormalizedName(ctx context.Context, name string) (*types.Specialty, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	normalized := textutil.Normalize(name)
+
+	for _, stored := range m.specialties {
+		if stored.normalizedName == normalized {
+			specialty := stored.specialty
+			return &specialty, nil
+		}
+	}
+
+	if stored, ok := m.specialties[m.aliases[normalized]]; ok {
+		specialty := stored.specialty
+		return &specialty, nil
+	}
+
+	return nil, nil
+}
+
+/*
+InsertSpecialty inserts a specialty with the next free id
+The function returns the id of the new specialty
+The function returns an error if a specialty with the same normalized name exists
+*/
+func (m *MemoryModel) InsertSpecialty(ctx context.Context, s types.Specialty) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries, err := auditEntries(ctx, m.lastID.specialty+1)
+	if err != nil {
+		return 0, err
+	}
+
+	id, err := m.insertSpecialty(s)
+	if err != nil {
+		return 0, err
+	}
+	m.appendAudit(entries)
+
+	return id, nil
+}
+
+/*
+InsertMultipleSpecialties inserts multiple specialties, the ones before a failing specialty stay inserted
+The function returns an error if a specialty with the same normalized name exists
+*/
+func (m *MemoryModel) InsertMultipleSpecialties(ctx context.Context, s []types.Specialty) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	for _, specialty := range s {
+		if _, err := m.insertSpecialty(specialty); err != nil {
+			return err
+		}
+	}
+
+	return nil
+}
+
+/*
+DeleteSpecialty deletes a specialty with a specific id together with its aliases, translations and symptom mappings
+The function returns ErrNotFound if the specialty does not exist
+The function returns an error if the specialty still has specialists
+*/
+func (m *MemoryModel) DeleteSpecialty(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.specialties[id]; !ok {
+		return ErrNotFound
+	}
+
+	for _, specialistID := range sortedKeys(m.specialists) {
+		if stored := m.specialists[specialistID]; stored.specialist.SpecialtyID == id {
+			return fmt.Errorf("specialty %d is still referenced by specialist %d", id, stored.specialist.ID)
+		}
+	}
+
+	entries, err := auditEntries(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	for alias, specialtyID := range m.aliases {
+		if specialtyID == id {
+			delete(m.aliases, alias)
+		}
+	}
+	for symptomID, sm := range m.symptoms {
+		if sm.SpecialtyID == id {
+			delete(m.symptoms, symptomID)
+		}
+	}
+	delete(m.specialties, id)
+	m.appendAudit(entries)
+
+	return nil
+}
+
+/*
+UpdateSpecialty updates the name and description of the specialty with the id of s
+The function returns ErrNotFound if the specialty does not exist
+The function returns an error if another specialty has the same normalized name
+*/
+func (m *MemoryModel) UpdateSpecialty(ctx context.Context, s types.Specialty) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.specialties[s.ID]
+	if !ok {
+		return ErrNotFound
+	}
+
+	normalized := textutil.Normalize(s.Name)
+	for id, other := range m.specialties {
+		if id != s.ID && other.normalizedName == normalized {
+			return fmt.Errorf("specialty %q already exists", normalized)
+		}
+	}
+
+	entries, err := auditEntries(ctx, s.ID)
+	if err != nil {
+		return err
+	}
+
+	stored.specialty.Name = s.Name
+	stored.specialty.Description = s.Description
+	stored.normalizedName = normalized
+	m.appendAudit(entries)
+
+	return nil
+}
+
+/*
+MergeSpecialties merges a duplicate specialty into the canonical one
+Specialists, aliases, translations and symptom mappings of the duplicate are moved to the canonical specialty,
+the normalized name of the duplicate is kept as an alias and the duplicate is deleted
+The function returns the number of specialists moved to the canonical specialty
+The function returns an error if the canonical specialty does not exist
+*/
+func (m *MemoryModel) MergeSpecialties(ctx context.Context, duplicateID, canonicalID int) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.specialties[canonicalID]; !ok {
+		return 0, fmt.Errorf("specialty %d does not exist", canonicalID)
+	}
+
+	entries, err := auditEntries(ctx, canonicalID)
+	if err != nil {
+		return 0, err
+	}
+
+	moved := 0
+	for _, id := range sortedKeys(m.specialists) {
+		if stored := m.specialists[id]; stored.specialist.SpecialtyID == duplicateID {
+			before := *cloneSpecialist(&stored.specialist)
+			stored.specialist.SpecialtyID = canonicalID
+			stored.specialist.Version++
+			stored.updatedAt = m.now()
+			m.recordChange(ctx, &before, &stored.specialist)
+			moved++
+		}
+	}
+
+	for alias, specialtyID := range m.aliases {
+		if specialtyID == duplicateID {
+			m.aliases[alias] = canonicalID
+		}
+	}
+
+	// the mappings of the duplicate move unless the canonical specialty has the same keyword
+	for _, symptomID := range sortedKeys(m.symptoms) {
+		sm := m.symptoms[symptomID]
+		if sm.SpecialtyID != duplicateID {
+			continue
+		}
+
+		if m.findSymptomMapping(sm.Keyword, canonicalID) == 0 {
+			sm.SpecialtyID = canonicalID
+		} else {
+			delete(m.symptoms, symptomID)
+		}
+	}
+
+	if duplicate, ok := m.specialties[duplicateID]; ok {
+		// the translations of the duplicate move unless the canonical specialty has the same language
+		canonical := m.specialties[canonicalID]
+		for language, t := range duplicate.translations {
+			if _, ok := canonical.translations[language]; !ok {
+				t.SpecialtyID = canonicalID
+				canonical.translations[language] = t
+			}
+		}
+
+		m.aliases[duplicate.normalizedName] = canonicalID
+		delete(m.specialties, duplicateID)
+	}
+	m.appendAudit(entries)
+
+	return moved, nil
+}
+
+/*
+AllReviews returns all reviews ordered by id
+The function returns a slice of pointers to Review structs
+*/
+func (m *MemoryModel) AllReviews(ctx context.Context) ([]*types.Review, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var reviews []*types.Review
+	for _, id := range sortedKeys(m.reviews) {
+		review := *m.reviews[id]
+		reviews = append(reviews, &review)
+	}
+
+	return reviews, nil
+}
+
+/*
+GetReviewBySpecialistId returns the review with the lowest id of a specialist
+The function returns sql.ErrNoRows if the specialist has no review
+*/
+func (m *MemoryModel) GetReviewBySpecialistId(ctx context.Context, id int) (*types.Review, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	for _, reviewID := range sortedKeys(m.reviews) {
+		if review := *m.reviews[reviewID]; review.SpecialistId == id {
+			return &review, nil
+		}
+	}
+
+	return nil, sql.ErrNoRows
+}
+
+/*
+GetReviewByID returns the review with a specific id, nil if there is no such review
+*/
+func (m *MemoryModel) GetReviewByID(ctx context.Context, id int) (*types.Review, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.reviews[id]
+	if !ok {
+		return nil, nil
+	}
+
+	review := *stored
+	return &review, nil
+}
+
+/*
+InsertReview inserts a review with the next free id
+The function returns the id of the new review
+The function returns an error if the reviewed specialist does not exist
+*/
+func (m *MemoryModel) InsertReview(ctx context.Context, r types.Review) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if err := m.checkSpecialist(r.SpecialistId); err != nil {
+		return 0, err
+	}
+
+	entries, err := auditEntries(ctx, m.lastID.review+1)
+	if err != nil {
+		return 0, err
+	}
+
+	m.lastID.review++
+	r.ID = m.lastID.review
+	m.reviews[r.ID] = &r
+	m.appendAudit(entries)
+
+	return r.ID, nil
+}
+
+/*
+DeleteReview deletes a review with a specific id
+The function returns ErrNotFound if the review does not exist
+*/
+func (m *MemoryModel) DeleteReview(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.reviews[id]; !ok {
+		return ErrNotFound
+	}
+
+	entries, err := auditEntries(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	delete(m.reviews, id)
+	m.appendAudit(entries)
+
+	return nil
+}
+
+/*
+UpdateReview updates the review with the id of r
+The function returns ErrNotFound if the review does not exist
+The function returns an error if the reviewed specialist does not exist
+*/
+func (m *MemoryModel) UpdateReview(ctx context.Context, r types.Review) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.reviews[r.ID]; !ok {
+		return ErrNotFound
+	}
+
+	if err := m.checkSpecialist(r.SpecialistId); err != nil {
+		return err
+	}
+
+	entries, err := auditEntries(ctx, r.ID)
+	if err != nil {
+		return err
+	}
+
+	m.reviews[r.ID] = &r
+	m.appendAudit(entries)
+
+	return nil
+}
+
+/*
+InsertAuditEntry appends the entry to the audit log, its id is the next free id and CreatedAt the current time
+The function returns the id of the new entry
+*/
+func (m *MemoryModel) InsertAuditEntry(ctx context.Context, e types.AuditEntry) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.appendAudit([]types.AuditEntry{e})
+
+	return len(m.audit), nil
+}
+
+// appendAudit appends entries to the audit log with the next free ids and the current time, the caller holds the lock
+func (m *MemoryModel) appendAudit(entries []types.AuditEntry) {
+	for _, e := range entries {
+		e.ID = len(m.audit) + 1
+		e.CreatedAt = m.now()
+		m.audit = append(m.audit, e)
+	}
+}
+
+/*
+GetAuditLog returns the newest entries of the audit log first
+An empty entity returns the entries of all entities, entityID 0 the entries of all records of the entity
+The limit is the maximum number of entries
+*/
+func (m *MemoryModel) GetAuditLog(ctx context.Context, entity string, entityID, limit int) ([]*types.AuditEntry, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	entries := []*types.AuditEntry{}
+	for i := len(m.audit) - 1; i >= 0 && len(entries) < limit; i-- {
+		e := m.audit[i]
+		if (entity == "" || e.Entity == entity) && (entityID == 0 || e.EntityID == entityID) {
+			entries = append(entries, &e)
+		}
+	}
+
+	return entries, nil
+}
+
+/*
+GetAllSpecialtiesLocalized returns all specialties ordered by id with their category in a specific language
+Missing translations fall back to the original Slovak data and the category code
+The function returns a slice of pointers to Specialty structs
+*/
+func (m *MemoryModel) GetAllSpecialtiesLocalized(ctx context.Context, language string) ([]*types.Specialty, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var specialties []*types.Specialty
+	for _, id := range sortedKeys(m.specialties) {
+		stored := m.specialties[id]
+		specialty := stored.specialty
+
+		if t, ok := stored.translations[language]; ok {
+			specialty.Name = t.Name
+			specialty.Description = t.Description
+			specialty.Synonyms = append([]string{}, t.Synonyms...)
+		}
+
+		if category, ok := m.categories[stored.categoryID]; ok {
+			specialty.Category = &types.SpecialtyCategory{ID: stored.categoryID, Code: category.code, Name: category.code}
+			if name, ok := category.names[language]; ok {
+				specialty.Category.Name = name
+			}
+			if parent, ok := m.categories[category.parentID]; ok {
+				specialty.Category.ParentCode = parent.code
+			}
+		}
+
+		specialties = append(specialties, &specialty)
+	}
+
+	return specialties, nil
+}
+
+/*
+GetTranslatedSpecialtyIDs returns the ids of all specialties that have at least one translation
+The function returns a set of specialty ids
+*/
+func (m *MemoryModel) GetTranslatedSpecialtyIDs(ctx context.Context) (map[int]bool, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	ids := make(map[int]bool)
+	for id, stored := range m.specialties {
+		if len(stored.translations) > 0 {
+			ids[id] = true
+		}
+	}
+
+	return ids, nil
+}
+
+/*
+UpsertSpecialtyCategory inserts a specialty category or updates the parent of an existing one
+The category is identified by its code, the parent by the code of the parent category, an unknown parent means no parent
+The function returns the id of the category
+*/
+func (m *MemoryModel) UpsertSpecialtyCategory(ctx context.Context, code, parentCode string) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	parentID := m.findCategory(parentCode)
+
+	if id := m.findCategory(code); id != 0 {
+		m.categories[id].parentID = parentID
+		return id, nil
+	}
+
+	m.lastID.category++
+	m.categories[m.lastID.category] = &memoryCategory{code: code, parentID: parentID, names: make(map[string]string)}
+
+	return m.lastID.category, nil
+}
+
+/*
+UpsertSpecialtyCategoryTranslation inserts or updates the name of a specialty category in one language
+The function returns an error if the category does not exist
+*/
+func (m *MemoryModel) UpsertSpecialtyCategoryTranslation(ctx context.Context, categoryID int, language, name string) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	category, ok := m.categories[categoryID]
+	if !ok {
+		return fmt.Errorf("specialty category %d does not exist", categoryID)
+	}
+	category.names[language] = name
+
+	return nil
+}
+
+/*
+UpsertSpecialtyTranslation inserts or updates the translation of a specialty in one language
+The function returns an error if the specialty does not exist
+*/
+func (m *MemoryModel) UpsertSpecialtyTranslation(ctx context.Context, t types.SpecialtyTranslation) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.specialties[t.SpecialtyID]
+	if !ok {
+		return fmt.Errorf("specialty %d does not exist", t.SpecialtyID)
+	}
+
+	t.Synonyms = append([]string{}, t.Synonyms...)
+	stored.translations[t.Language] = t
+
+	return nil
+}
+
+/*
+UpdateSpecialtyTaxonomy sets the description and category of a specialty, a missing specialty is left alone
+The function returns an error if the category does not exist
+*/
+func (m *MemoryModel) UpdateSpecialtyTaxonomy(ctx context.Context, id int, description string, categoryID int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.categories[categoryID]; !ok {
+		return fmt.Errorf("specialty category %d does not exist", categoryID)
+	}
+
+	if stored, ok := m.specialties[id]; ok {
+		stored.specialty.Description = description
+		stored.categoryID = categoryID
+	}
+
+	return nil
+}
+
+/*
+GetSeedVersion returns the version of a seed file last applied
+The function returns 0 if the seed file was never applied
+*/
+func (m *MemoryModel) GetSeedVersion(ctx context.Context, name string) (int, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	return m.seeds[name], nil
+}
+
+// SetSeedVersion records the version of a seed file applied
+func (m *MemoryModel) SetSeedVersion(ctx context.Context, name string, version int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	m.seeds[name] = version
+
+	return nil
+}
+
+/*
+GetSpecialistsForAddressCheck returns the specialists with a location whose address was never checked or was checked before the interval
+The specialists checked the longest time ago come first
+The limit is the maximum number of specialists returned
+The function returns a slice of pointers to Specialist structs with the id, name, location and address filled in
+*/
+func (m *MemoryModel) GetSpecialistsForAddressCheck(ctx context.Context, interval time.Duration, limit int) ([]*types.Specialist, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	due := m.now().Add(-interval)
+
+	var specialists []*types.Specialist
+	for _, id := range sortedKeys(m.specialists) {
+		s := m.specialists[id].specialist
+		if check, ok := m.checks[id]; s.Location == "" || ok && !check.CheckedAt.Before(due) {
+			continue
+		}
+		specialists = append(specialists, &types.Specialist{ID: s.ID, Name: s.Name, Location: s.Location, Address: s.Address})
+	}
+
+	// never checked first, sort.SliceStable keeps the id order among equal check times
+	sort.SliceStable(specialists, func(i, j int) bool {
+		return m.checks[specialists[i].ID].CheckedAt.Before(m.checks[specialists[j].ID].CheckedAt)
+	})
+
+	if len(specialists) > limit {
+		specialists = specialists[:limit]
+	}
+
+	return specialists, nil
+}
+
+/*
+SaveAddressCheck stores the result of an address check with the current time, replacing the previous check of the specialist
+A check with the filled status also writes the geocoded address to the specialist,
+unless the address of the specialist changed since it was read or is overridden by an admin
+The function returns an error if the specialist does not exist
+*/
+func (m *MemoryModel) SaveAddressCheck(ctx context.Context, check types.AddressCheck) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	stored, ok := m.specialists[check.SpecialistID]
+	if !ok {
+		return fmt.Errorf("specialist %d does not exist", check.SpecialistID)
+	}
+
+	_, overridden := stored.overrides["address"]
+	if check.Status == types.AddressCheckFilled && stored.specialist.Address == check.StatedAddress && !overridden {
+		before := *cloneSpecialist(&stored.specialist)
+		stored.specialist.Address = check.GeocodedAddress
+		stored.specialist.Version++
+		stored.updatedAt = m.now()
+		m.recordChange(ctx, &before, &stored.specialist)
+	}
+
+	check.SpecialistName = ""
+	check.CheckedAt = m.now()
+	m.checks[check.SpecialistID] = check
+
+	return nil
+}
+
+/*
+GetAddressChecks returns the address checks with a specific status, all checks when the status is empty
+The most recent checks come first
+The function returns a slice of pointers to AddressCheck structs with the name of the specialist
+*/
+func (m *MemoryModel) GetAddressChecks(ctx context.Context, status string) ([]*types.AddressCheck, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	checks := []*types.AddressCheck{}
+	for _, id := range sortedKeys(m.checks) {
+		if check := m.checks[id]; status == "" || check.Status == status {
+			check.SpecialistName = m.specialists[id].specialist.Name
+			checks = append(checks, &check)
+		}
+	}
+
+	sort.SliceStable(checks, func(i, j int) bool {
+		return checks[i].CheckedAt.After(checks[j].CheckedAt)
+	})
+
+	return checks, nil
+}
+
+/*
+GetAllSymptomMappings returns all symptom mappings ordered by id together with the specialty name
+The function returns a slice of pointers to SymptomMapping structs
+*/
+func (m *MemoryModel) GetAllSymptomMappings(ctx context.Context) ([]*types.SymptomMapping, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var mappings []*types.SymptomMapping
+	for _, id := range sortedKeys(m.symptoms) {
+		sm := *m.symptoms[id]
+		sm.SpecialtyName = m.specialties[sm.SpecialtyID].specialty.Name
+		mappings = append(mappings, &sm)
+	}
+
+	return mappings, nil
+}
+
+/*
+GetSymptomMappingByID returns the symptom mapping with a specific id together with the specialty name
+The function returns nil if there is no mapping with the id
+*/
+func (m *MemoryModel) GetSymptomMappingByID(ctx context.Context, id int) (*types.SymptomMapping, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	stored, ok := m.symptoms[id]
+	if !ok {
+		return nil, nil
+	}
+
+	sm := *stored
+	sm.SpecialtyName = m.specialties[sm.SpecialtyID].specialty.Name
+
+	return &sm, nil
+}
+
+/*
+InsertSymptomMapping inserts a symptom mapping with the next free id, an existing keyword and specialty pair is left untouched
+The function returns an error if the specialty does not exist
+*/
+func (m *MemoryModel) InsertSymptomMapping(ctx context.Context, sm types.SymptomMapping) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.specialties[sm.SpecialtyID]; !ok {
+		return fmt.Errorf("specialty %d does not exist", sm.SpecialtyID)
+	}
+
+	if m.findSymptomMapping(sm.Keyword, sm.SpecialtyID) != 0 {
+		return nil
+	}
+
+	m.lastID.symptom++
+	sm.ID = m.lastID.symptom
+	sm.SpecialtyName = ""
+	m.symptoms[sm.ID] = &sm
+
+	return nil
+}
+
+/*
+CreateSymptomMapping inserts a symptom mapping added by an admin with the next free id
+The function returns the id of the new symptom mapping
+The function returns an error if the specialty does not exist or a mapping has the same keyword and specialty
+*/
+func (m *MemoryModel) CreateSymptomMapping(ctx context.Context, sm types.SymptomMapping) (int, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.specialties[sm.SpecialtyID]; !ok {
+		return 0, fmt.Errorf("specialty %d does not exist", sm.SpecialtyID)
+	}
+
+	if m.findSymptomMapping(sm.Keyword, sm.SpecialtyID) != 0 {
+		return 0, fmt.Errorf("symptom mapping %q of specialty %d already exists", sm.Keyword, sm.SpecialtyID)
+	}
+
+	entries, err := auditEntries(ctx, m.lastID.symptom+1)
+	if err != nil {
+		return 0, err
+	}
+
+	m.lastID.symptom++
+	sm.ID = m.lastID.symptom
+	sm.SpecialtyName = ""
+	m.symptoms[sm.ID] = &sm
+	m.appendAudit(entries)
+
+	return sm.ID, nil
+}
+
+/*
+UpdateSymptomMapping updates the symptom mapping with the id of sm
+The function returns ErrNotFound if the symptom mapping does not exist
+The function returns an error if the specialty does not exist or another mapping has the same keyword and specialty
+*/
+func (m *MemoryModel) UpdateSymptomMapping(ctx context.Context, sm types.SymptomMapping) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.symptoms[sm.ID]; !ok {
+		return ErrNotFound
+	}
+
+	if _, ok := m.specialties[sm.SpecialtyID]; !ok {
+		return fmt.Errorf("specialty %d does not exist", sm.SpecialtyID)
+	}
+
+	if id := m.findSymptomMapping(sm.Keyword, sm.SpecialtyID); id != 0 && id != sm.ID {
+		return fmt.Errorf("symptom mapping %q of specialty %d already exists", sm.Keyword, sm.SpecialtyID)
+	}
+
+	entries, err := auditEntries(ctx, sm.ID)
+	if err != nil {
+		return err
+	}
+
+	sm.SpecialtyName = ""
+	m.symptoms[sm.ID] = &sm
+	m.appendAudit(entries)
+
+	return nil
+}
+
+/*
+DeleteSymptomMapping deletes the symptom mapping with a specific id
+The function returns ErrNotFound if the symptom mapping does not exist
+*/
+func (m *MemoryModel) DeleteSymptomMapping(ctx context.Context, id int) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if _, ok := m.symptoms[id]; !ok {
+		return ErrNotFound
+	}
+
+	entries, err := auditEntries(ctx, id)
+	if err != nil {
+		return err
+	}
+
+	delete(m.symptoms, id)
+	m.appendAudit(entries)
+
+	return nil
+}
+
+/*
+GetHolidayOverrides returns the holiday overrides of a year, ordered by date and region
+The function returns a slice of pointers to Holiday structs
+*/
+func (m *MemoryModel) GetHolidayOverrides(ctx context.Context, year int) ([]*types.Holiday, error) {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	prefix := fmt.Sprintf("%04d-", year)
+	overrides := []*types.Holiday{}
+	for key, h := range m.holidays {
+		if strings.HasPrefix(key[0], prefix) {
+			h := h
+			overrides = append(overrides, &h)
+		}
+	}
+
+	sort.Slice(overrides, func(i, j int) bool {
+		if overrides[i].Date != overrides[j].Date {
+			return overrides[i].Date < overrides[j].Date
+		}
+		return overrides[i].Region < overrides[j].Region
+	})
+
+	return overrides, nil
+}
+
+/*
+SetHolidayOverride stores the holiday override, replacing the one on the same date and region
+*/
+func (m *MemoryModel) SetHolidayOverride(ctx context.Context, h types.Holiday) error {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	entries, err := auditEntries(ctx, 0)
+	if err != nil {
+		return err
+	}
+
+	h.Source = types.HolidaySourceOverride
+	m.holidays[[2]string{h.Date, h.Region}] = h
+	m.appendAudit(entries)
+
+	return nil
+}
+
+/*
+DeleteHolidayOverride deletes the holiday override on the date and region
+The function returns false if there was no such override
+*/
+func (m *MemoryModel) DeleteHolidayOverride(ctx context.Context, date, region string) (bool, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	key := [2]string{date, region}
+	if _, ok := m.holidays[key]; !ok {
+		return false, nil
+	}
+
+	entries, err := auditEntries(ctx, 0)
+	if err != nil {
+		return false, err
+	}
+
+	delete(m.holidays, key)
+	m.appendAudit(entries)
+
+	return true, nil
+}
+
+// findCategory returns the id of the category with the code, 0 if there is none
+func (m *MemoryModel) findCategory(code string) int {
+	for id, category := range m.categories {
+		if category.code == code {
+			return id
+		}
+	}
+
+	return 0
+}
+
+// findSymptomMapping returns the id of the mapping of the keyword to the specialty, 0 if there is none
+func (m *MemoryModel) findSymptomMapping(keyword string, specialtyID int) int {
+	for id, sm := range m.symptoms {
+		if sm.Keyword == keyword && sm.SpecialtyID == specialtyID {
+			return id
+		}
+	}
+
+	return 0
+}
+
+// filterSpecialists returns copies of the specialists matching keep ordered by id
+func (m *MemoryModel) filterSpecialists(keep func(*types.Specialist) bool) []*types.Specialist {
+	m.mu.RLock()
+	defer m.mu.RUnlock()
+
+	var specialists []*types.Specialist
+	for _, id := range sortedKeys(m.specialists) {
+		if s := &m.specialists[id].specialist; keep(s) {
+			specialists = append(specialists, cloneSpecialist(s))
+		}
+	}
+
+	return specialists
+}
+
+func (m *MemoryModel) insertSpecialty(s types.Specialty) (int, error) {
+	normalized := textutil.Normalize(s.Name)
+	for _, stored := range m.specialties {
+		if stored.normalizedName == normalized {
+			return 0, fmt.Errorf("specialty %q already exists", normalized)
+		}
+	}
+
+	m.lastID.specialty++
+	m.specialties[m.lastID.specialty] = &memorySpecialty{
+		specialty:      types.Specialty{ID: m.lastID.specialty, Name: s.Name, Description: s.Description},
+		normalizedName: normalized,
+		translations:   make(map[string]types.SpecialtyTranslation),
+	}
+
+	return m.lastID.specialty, nil
+}
+
+// checkSpecialty mirrors the nullable foreign key of a specialist, 0 means no specialty
+func (m *MemoryModel) checkSpecialty(id int) error {
+	if _, ok := m.specialties[id]; id != 0 && !ok {
+		return fmt.Errorf("specialty %d does not exist", id)
+	}
+
+	return nil
+}
+
+// checkSpecialist mirrors the nullable foreign key of a review, 0 means no specialist
+func (m *MemoryModel) checkSpecialist(id int) error {
+	if _, ok := m.specialists[id]; id != 0 && !ok {
+		return fmt.Errorf("specialist %d does not exist", id)
+	}
+
+	return nil
+}
+
+// storedSpecialist returns a copy of s as the database stores it, without the links filled in by the API
+func storedSpecialist(s *types.Specialist) *types.Specialist {
+	stored := cloneSpecialist(s)
+	stored.Navigation = nil
+	if stored.Insurers == nil {
+		stored.Insurers = []string{}
+	}
+
+	return stored
+}
+
+func cloneSpecialist(s *types.Specialist) *types.Specialist {
+	clone := *s
+	if s.Insurers != nil {
+		clone.Insurers = append([]string{}, s.Insurers...)
+	}
+	clone.FieldSources = maps.Clone(s.FieldSources)
+
+	return &clone
+}
+
+/*
+recordChange records a change of a specialist in its history, before is nil for a create and after nil for a delete
+An update is recorded with the changed fields only, an update changing nothing but the version is skipped
+*/
+func (m *MemoryModel) recordChange(ctx context.Context, before, after *types.Specialist) {
+	change := types.SpecialistChange{ID: len(m.history) + 1, Actor: ActorFromContext(ctx), ChangedAt: m.now()}
+	record := after
+
+	switch {
+	case before == nil:
+		change.Action = types.AuditActionCreate
+		change.SpecialistID = after.ID
+		change.After, _ = json.Marshal(historyFields(after))
+	case after == nil:
+		change.Action = types.AuditActionDelete
+		change.SpecialistID = before.ID
+		change.Before, _ = json.Marshal(historyFields(before))
+		record = before
+	default:
+		change.Action = types.AuditActionUpdate
+		change.SpecialistID = after.ID
+
+		changedBefore, changedAfter := map[string]json.RawMessage{}, map[string]json.RawMessage{}
+		beforeFields, afterFields := historyFields(before), historyFields(after)
+		for _, field := range fieldNames(afterFields) {
+			if field != "version" && string(beforeFields[field]) != string(afterFields[field]) {
+				changedBefore[field] = beforeFields[field]
+				changedAfter[field] = afterFields[field]
+			}
+		}
+
+		if len(changedAfter) == 0 {
+			return
+		}
+
+		change.Before, _ = json.Marshal(changedBefore)
+		change.After, _ = json.Marshal(changedAfter)
+	}
+
+	m.history = append(m.history, memoryChange{change: change, record: cloneSpecialist(record)})
+}
+
+// historyFields returns the fields of the specialist as the database records them, a missing value is null
+func historyFields(s *types.Specialist) map[string]json.RawMessage {
+	record := storedSpecialist(s)
+	record.FieldSources = nil
+
+	data, _ := json.Marshal(record)
+	fields := map[string]json.RawMessage{}
+	_ = json.Unmarshal(data, &fields)
+
+	for _, field := range append([]string{"specialty_id", "kpzs"}, types.SpecialistOverrideFields...) {
+		if _, ok := fields[field]; !ok {
+			fields[field] = json.RawMessage("null")
+		}
+	}
+
+	return fields
+}
+
+func sortedKeys[V any](items map[int]V) []int {
+	keys := make([]int, 0, len(items))
+	for key := range items {
+		keys = append(keys, key)
+	}
+	sort.Ints(keys)
+
+	return keys
+}
+
+/*
+wordSimilarity returns the greatest share of the trigrams of term found in one of the words
+Words are padded like pg_trgm does, with two spaces in front and one at the end, so prefixes weigh more
+*/
+func wordSimilarity(term string, words []string) float64 {
+	termTrigrams := trigrams(term)
+	if len(termTrigrams) == 0 {
+		return 0
+	}
+
+	best := 0.0
+	for _, word := range words {
+		wordTrigrams := trigrams(word)
+
+		shared := 0
+		for trigram := range termTrigrams {
+			if wordTrigrams[trigram] {
+				shared++
+			}
+		}
+
+		if similarity := float64(shared) / float64(len(termTrigrams)); similarity > best {
+			best = similarity
+		}
+	}
+
+	return best
+}
+
+func trigrams(word string) map[string]bool {
+	runes := []rune("  " + word + " ")
+
+	set := make(map[string]bool)
+	for i := 0; i+3 <= len(runes); i++ {
+		set[string(runes[i:i+3])] = true
+	}
+
+	return set
+}
@@ -0,0 +1,750 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"encoding/json"
+	"errors"
+	"testing"
+	"time"
+
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var memoryUpdatedAt = time.Date(2024, 3, 1, 12, 0, 0, 0, time.UTC)
+
+// newTestMemoryModel returns a store with two specialties, three specialists and two reviews
+func newTestMemoryModel(t *testing.T) *MemoryModel {
+	m := NewMemoryModel()
+	m.Now = func() time.Time { return memoryUpdatedAt }
+
+	err := m.InsertMultipleSpecialties(context.Background(), []types.Specialty{
+		{Name: "kardiológia", Description: "srdce"},
+		{Name: "očné lekárstvo", Description: "oči"},
+	})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	specialists := []types.Specialist{
+		{Name: "Kardio Košice", SpecialtyID: 1, Location: "POINT(21.2496774 48.7172272)", Address: "Hlavná 1, Košice", Staff: "MUDr. Ján Novák", Insurers: []string{"VšZP"}},
+		{Name: "Kardio Prešov", SpecialtyID: 1, Location: "POINT(21.2393 48.9984)", Address: "Hlavná 2, Prešov"},
+		{Name: "Očná ambulancia", SpecialtyID: 2, Location: "POINT(21.26 48.72)"},
+	}
+	for _, s := range specialists {
+		if _, err := m.InsertSpecialist(context.Background(), s); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	for _, r := range []types.Review{{SpecialistId: 1, Url: "a", Rating: 4}, {SpecialistId: 1, Url: "b", Rating: 5}} {
+		if _, err := m.InsertReview(context.Background(), r); err != nil {
+			t.Fatal(err)
+		}
+	}
+
+	return m
+}
+
+func TestMemoryModel_Specialists(t *testing.T) {
+	m := newTestMemoryModel(t)
+
+	all, err := m.GetAllSpecialists(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, all, 3)
+	assert.Equal(t, []int{1, 2, 3}, []int{all[0].ID, all[1].ID, all[2].ID})
+	assert.Equal(t, []string{}, all[1].Insurers)
+
+	bySpecialty, err := m.GetSpecialistBySpecialty(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Len(t, bySpecialty, 1)
+	assert.Equal(t, "Očná ambulancia", bySpecialty[0].Name)
+
+	byID, err := m.GetSpecialistByID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, "Kardio Košice", byID.Name)
+
+	// returned specialists are copies
+	byID.Insurers[0] = "Dôvera"
+	byID, _ = m.GetSpecialistByID(context.Background(), 1)
+	assert.Equal(t, []string{"VšZP"}, byID.Insurers)
+
+	byName, err := m.GetSpecialistByName(context.Background(), "Kardio Prešov")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, byName.ID)
+
+	missing, err := m.GetSpecialistByID(context.Background(), 42)
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	missing, err = m.GetSpecialistByName(context.Background(), "Neexistuje")
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+}
+
+func TestMemoryModel_GetSpecialistBySpecialtyAndLocation(t *testing.T) {
+	m := newTestMemoryModel(t)
+
+	// Prešov is about 31 km north of Košice
+	nearby, err := m.GetSpecialistBySpecialtyAndLocation(context.Background(), 1, 5000, "POINT(21.25 48.72)")
+	assert.NoError(t, err)
+	assert.Len(t, nearby, 1)
+	assert.Equal(t, "Kardio Košice", nearby[0].Name)
+
+	nearby, err = m.GetSpecialistBySpecialtyAndLocation(context.Background(), 1, 40000, "POINT(21.25 48.72)")
+	assert.NoError(t, err)
+	assert.Len(t, nearby, 2)
+
+	nearby, err = m.GetSpecialistBySpecialtyAndLocation(context.Background(), 1, 40000, "invalid")
+	assert.Error(t, err)
+	assert.Nil(t, nearby)
+}
+
+func TestMemoryModel_GetSpecialistProfile(t *testing.T) {
+	m := newTestMemoryModel(t)
+
+	profile, err := m.GetSpecialistProfile(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, "Kardio Košice", profile.Specialist.Name)
+	assert.Equal(t, []string{"VšZP"}, profile.Specialist.Insurers)
+	assert.Equal(t, "kardiológia", profile.SpecialtyName)
+	assert.Equal(t, types.ReviewSummary{Count: 2, AverageRating: 4.5}, profile.Reviews)
+	assert.Equal(t, memoryUpdatedAt, profile.UpdatedAt)
+
+	profile, err = m.GetSpecialistProfile(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Equal(t, types.ReviewSummary{}, profile.Reviews)
+
+	profile, err = m.GetSpecialistProfile(context.Background(), 42)
+	assert.NoError(t, err)
+	assert.Nil(t, profile)
+}
+
+func TestMemoryModel_UpdateAndDeleteSpecialist(t *testing.T) {
+	m := newTestMemoryModel(t)
+
+	stored, _ := m.GetSpecialistByID(context.Background(), 2)
+	assert.Equal(t, 1, stored.Version)
+
+	version, err := m.UpdateSpecialist(context.Background(), types.Specialist{ID: 2, Name: "Kardio Prešov II", SpecialtyID: 1, Version: 1})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, version)
+	updated, _ := m.GetSpecialistByID(context.Background(), 2)
+	assert.Equal(t, "Kardio Prešov II", updated.Name)
+	assert.Equal(t, 2, updated.Version)
+	assert.Empty(t, updated.Location)
+
+	// a concurrent edit based on the first version is rejected
+	_, err = m.UpdateSpecialist(context.Background(), types.Specialist{ID: 2, Name: "Kardio Prešov III", SpecialtyID: 1, Version: 1})
+	assert.ErrorIs(t, err, ErrStaleVersion)
+
+	_, err = m.UpdateSpecialist(context.Background(), types.Specialist{ID: 2, Name: "Kardio Prešov II", SpecialtyID: 9, Version: 2})
+	assert.EqualError(t, err, "specialty 9 does not exist")
+
+	_, err = m.UpdateSpecialist(context.Background(), types.Specialist{ID: 42, Name: "Neexistuje", Version: 1})
+	assert.ErrorIs(t, err, ErrNotFound)
+
+	_, err = m.InsertSpecialist(context.Background(), types.Specialist{Name: "Bez odbornosti", SpecialtyID: 9})
+	assert.EqualError(t, err, "specialty 9 does not exist")
+
+	err = m.DeleteSpecialist(context.Background(), 1, 1)
+	assert.EqualError(t, err, "specialist 1 is still referenced by review 1")
+
+	assert.ErrorIs(t, m.DeleteSpecialist(context.Background(), 2, 1), ErrStaleVersion)
+	assert.ErrorIs(t, m.DeleteSpecialist(context.Background(), 42, 1), ErrNotFound)
+
+	tombstone, _ := m.IsSpecialistDeleted(context.Background(), "Kardio Prešov II")
+	assert.False(t, tombstone)
+
+	assert.NoError(t, m.DeleteSpecialist(context.Background(), 2, 2))
+	deleted, _ := m.GetSpecialistByID(context.Background(), 2)
+	assert.Nil(t, deleted)
+
+	tombstone, err = m.IsSpecialistDeleted(context.Background(), "Kardio Prešov II")
+	assert.NoError(t, err)
+	assert.True(t, tombstone)
+}
+
+func TestMemoryModel_SpecialistHistory(t *testing.T) {
+	m := newTestMemoryModel(t)
+	updatedAt := memoryUpdatedAt.Add(24 * time.Hour)
+	m.Now = func() time.Time { return updatedAt }
+
+	_, err := m.UpdateSpecialist(WithActor(context.Background(), "jana"), types.Specialist{ID: 2, Name: "Kardio Prešov", SpecialtyID: 1, Location: "POINT(21.2393 48.9984)", Address: "Hlavná 2, Prešov", Telephone: "051/123", Version: 1})
+	assert.NoError(t, err)
+
+	// a sync without changes bumps nothing and records nothing
+	changed, err := m.SyncSpecialist(context.Background(), 2, types.Specialist{Name: "Kardio Prešov", SpecialtyID: 1, Location: "POINT(21.2393 48.9984)", Address: "Hlavná 2, Prešov", Telephone: "051/123"})
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	changes, err := m.GetSpecialistHistory(context.Background(), 2, time.Time{}, 50)
+	assert.NoError(t, err)
+	assert.Len(t, changes, 2)
+	assert.Equal(t, types.AuditActionUpdate, changes[0].Action)
+	assert.Equal(t, "jana", changes[0].Actor)
+	assert.JSONEq(t, `{"telephone": null}`, string(changes[0].Before))
+	assert.JSONEq(t, `{"telephone": "051/123"}`, string(changes[0].After))
+	assert.Equal(t, types.AuditActionCreate, changes[1].Action)
+	assert.Equal(t, SystemActor, changes[1].Actor)
+	assert.Nil(t, changes[1].Before)
+
+	changes, _ = m.GetSpecialistHistory(context.Background(), 2, memoryUpdatedAt, 50)
+	assert.Len(t, changes, 1)
+	changes, _ = m.GetSpecialistHistory(context.Background(), 2, time.Time{}, 1)
+	assert.Len(t, changes, 1)
+
+	before, err := m.GetSpecialistAsOf(context.Background(), 2, memoryUpdatedAt)
+	assert.NoError(t, err)
+	assert.Empty(t, before.Specialist.Telephone)
+	assert.Nil(t, before.Specialist.FieldSources)
+
+	after, _ := m.GetSpecialistAsOf(context.Background(), 2, updatedAt)
+	assert.Equal(t, "051/123", after.Specialist.Telephone)
+	assert.Equal(t, "jana", after.Actor)
+
+	missing, _ := m.GetSpecialistAsOf(context.Background(), 2, memoryUpdatedAt.Add(-time.Hour))
+	assert.Nil(t, missing)
+
+	// the history outlives the specialist
+	assert.NoError(t, m.DeleteSpecialist(context.Background(), 2, 2))
+	changes, _ = m.GetSpecialistHistory(context.Background(), 2, time.Time{}, 50)
+	assert.Len(t, changes, 3)
+	assert.Equal(t, types.AuditActionDelete, changes[0].Action)
+
+	deleted, _ := m.GetSpecialistAsOf(context.Background(), 2, updatedAt)
+	assert.Nil(t, deleted)
+}
+
+func TestMemoryModel_DuplicateSpecialists(t *testing.T) {
+	m := newTestMemoryModel(t)
+
+	id, err := m.InsertSpecialist(context.Background(), types.Specialist{Name: "Kardio Kosice", SpecialtyID: 1, Location: "POINT(21.2497 48.7172)", Address: "Hlavna 1, Kosice", Staff: "Eva Malá"})
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	candidates, err := m.GetDuplicateSpecialists(context.Background(), 0.5, 10)
+	assert.NoError(t, err)
+	assert.Len(t, candidates, 1)
+	assert.Equal(t, 1, candidates[0].Specialist.ID)
+	assert.Equal(t, id, candidates[0].Duplicate.ID)
+	assert.Equal(t, 1.0, candidates[0].Signals.Address)
+
+	candidates, _ = m.GetDuplicateSpecialists(context.Background(), 0.9, 10)
+	assert.Empty(t, candidates)
+
+	assert.NoError(t, m.DismissDuplicateSpecialists(context.Background(), id, 1))
+	candidates, _ = m.GetDuplicateSpecialists(context.Background(), 0.5, 10)
+	assert.Empty(t, candidates)
+	assert.EqualError(t, m.DismissDuplicateSpecialists(context.Background(), 1, 42), "specialist 42 does not exist")
+
+	_, _, err = m.MergeSpecialists(context.Background(), 1, 2, id, 1)
+	assert.ErrorIs(t, err, ErrStaleVersion)
+
+	// the reviews and the missing staff of the duplicate move to the survivor
+	version, moved, err := m.MergeSpecialists(WithActor(context.Background(), "jana"), 1, 1, id, 1)
+	assert.NoError(t, err)
+	assert.Equal(t, 2, version)
+	assert.Equal(t, 2, moved)
+
+	survivor, _ := m.GetSpecialistProfile(context.Background(), id)
+	assert.Equal(t, "Eva Malá, MUDr. Ján Novák", survivor.Specialist.Staff)
+	assert.Equal(t, types.FieldSourceOverride, survivor.Specialist.FieldSources["staff"])
+	assert.Equal(t, 2, survivor.Reviews.Count)
+
+	duplicate, _ := m.GetSpecialistByID(context.Background(), 1)
+	assert.Nil(t, duplicate)
+
+	// the added staff survives the sync from the source
+	changed, err := m.SyncSpecialist(context.Background(), id, types.Specialist{Name: "Kardio Kosice", SpecialtyID: 1, Location: "POINT(21.2497 48.7172)", Address: "Hlavna 1, Kosice", Staff: "Eva Malá"})
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	survivorID, err := m.GetSpecialistAlias(context.Background(), "Kardio Košice")
+	assert.NoError(t, err)
+	assert.Equal(t, id, survivorID)
+
+	changes, _ := m.GetSpecialistHistory(context.Background(), 1, time.Time{}, 1)
+	assert.Equal(t, types.AuditActionDelete, changes[0].Action)
+	assert.Equal(t, "jana", changes[0].Actor)
+}
+
+func TestMemoryModel_SpecialistOverrides(t *testing.T) {
+	m := newTestMemoryModel(t)
+
+	stored, _ := m.GetSpecialistByID(context.Background(), 1)
+	assert.Equal(t, types.NewFieldSources(nil), stored.FieldSources)
+
+	_, err := m.SetSpecialistOverrides(context.Background(), 1, 1, map[string]string{"specialty_id": "2"})
+	assert.EqualError(t, err, `field "specialty_id" cannot be overridden`)
+
+	version, err := m.SetSpecialistOverrides(context.Background(), 1, 1, map[string]string{"name": "Kardiológia Košice", "telephone": "055/123"})
+	assert.NoError(t, err)
+	assert.Equal(t, 2, version)
+
+	overridden, _ := m.GetSpecialistByID(context.Background(), 1)
+	assert.Equal(t, "Kardiológia Košice", overridden.Name)
+	assert.Equal(t, types.FieldSourceOverride, overridden.FieldSources["name"])
+	assert.Equal(t, types.FieldSourceSource, overridden.FieldSources["address"])
+
+	// the scraper still finds the specialist by its source name
+	byName, _ := m.GetSpecialistByName(context.Background(), "Kardio Košice")
+	assert.Equal(t, 1, byName.ID)
+
+	// the sync keeps the overridden fields and updates the others
+	source := types.Specialist{Name: "Kardio Košice s.r.o.", SpecialtyID: 1, Location: "POINT(21.2496774 48.7172272)", Address: "Hlavná 10, Košice", Telephone: "055/999", Staff: "MUDr. Ján Novák", Insurers: []string{"VšZP"}}
+	changed, err := m.SyncSpecialist(context.Background(), 1, source)
+	assert.NoError(t, err)
+	assert.True(t, changed)
+
+	synced, _ := m.GetSpecialistByID(context.Background(), 1)
+	assert.Equal(t, "Kardiológia Košice", synced.Name)
+	assert.Equal(t, "055/123", synced.Telephone)
+	assert.Equal(t, "Hlavná 10, Košice", synced.Address)
+	assert.Equal(t, 3, synced.Version)
+
+	changed, err = m.SyncSpecialist(context.Background(), 1, source)
+	assert.NoError(t, err)
+	assert.False(t, changed)
+
+	overrides, err := m.GetSpecialistOverrides(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.SpecialistOverride{
+		{Field: "name", Value: "Kardiológia Košice", SourceValue: "Kardio Košice s.r.o.", UpdatedAt: memoryUpdatedAt},
+		{Field: "telephone", Value: "055/123", SourceValue: "055/999", UpdatedAt: memoryUpdatedAt},
+	}, overrides)
+
+	_, err = m.DeleteSpecialistOverrides(context.Background(), 1, 2, []string{"name"})
+	assert.ErrorIs(t, err, ErrStaleVersion)
+
+	// removing an override restores the latest source value
+	version, err = m.DeleteSpecialistOverrides(context.Background(), 1, 3, []string{"name", "email"})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, version)
+
+	restored, _ := m.GetSpecialistByID(context.Background(), 1)
+	assert.Equal(t, "Kardio Košice s.r.o.", restored.Name)
+	assert.Equal(t, types.NewFieldSources([]string{"telephone"}), restored.FieldSources)
+
+	version, err = m.DeleteSpecialistOverrides(context.Background(), 1, 4, []string{"email"})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, version)
+
+	overrides, _ = m.GetSpecialistOverrides(context.Background(), 42)
+	assert.Nil(t, overrides)
+
+	_, err = m.SyncSpecialist(context.Background(), 42, source)
+	assert.ErrorIs(t, err, ErrNotFound)
+}
+
+func TestMemoryModel_SearchSpecialists(t *testing.T) {
+	m := newTestMemoryModel(t)
+
+	results, err := m.SearchSpecialists(context.Background(), []string{"kardio", "kosice"}, 0.3, 10)
+
+	assert.NoError(t, err)
+	assert.Len(t, results, 2)
+	assert.Equal(t, "Kardio Košice", results[0].Specialist.Name)
+	assert.Equal(t, "kardiológia", results[0].SpecialtyName)
+	assert.Equal(t, 1.0, results[0].Rank)
+	assert.Equal(t, "Kardio Prešov", results[1].Specialist.Name)
+	assert.Less(t, results[1].Rank, results[0].Rank)
+
+	results, err = m.SearchSpecialists(context.Background(), []string{"novak"}, 0.3, 10)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+	assert.Equal(t, 1, results[0].Specialist.ID)
+
+	results, err = m.SearchSpecialists(context.Background(), []string{"kardio"}, 0.3, 1)
+	assert.NoError(t, err)
+	assert.Len(t, results, 1)
+
+	results, err = m.SearchSpecialists(context.Background(), []string{"ortopedia"}, 0.3, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, results)
+}
+
+func TestMemoryModel_GetSpecialistPlaces(t *testing.T) {
+	m := newTestMemoryModel(t)
+
+	places, err := m.GetSpecialistPlaces(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Place{
+		{Type: types.PlaceTypeHouse, DisplayName: "Hlavná 1, Košice", Lat: 48.7172272, Lon: 21.2496774},
+		{Type: types.PlaceTypeHouse, DisplayName: "Hlavná 2, Prešov", Lat: 48.9984, Lon: 21.2393},
+	}, places)
+}
+
+func TestMemoryModel_Specialties(t *testing.T) {
+	m := newTestMemoryModel(t)
+
+	all, err := m.GetAllSpecialties(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Specialty{
+		{ID: 1, Name: "kardiológia", Description: "srdce"},
+		{ID: 2, Name: "očné lekárstvo", Description: "oči"},
+	}, all)
+
+	byName, err := m.GetSpecialtyByName(context.Background(), "očné lekárstvo")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, byName.ID)
+
+	byNormalizedName, err := m.GetSpecialtyByNormalizedName(context.Background(), "Očné  lekárstvo")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, byNormalizedName.ID)
+
+	_, err = m.InsertSpecialty(context.Background(), types.Specialty{Name: "Kardiologia"})
+	assert.EqualError(t, err, `specialty "kardiologia" already exists`)
+
+	assert.NoError(t, m.UpdateSpecialty(context.Background(), types.Specialty{ID: 2, Name: "oftalmológia", Description: "zrak"}))
+	updated, _ := m.GetSpecialtyByID(context.Background(), 2)
+	assert.Equal(t, &types.Specialty{ID: 2, Name: "oftalmológia", Description: "zrak"}, updated)
+	renamed, _ := m.GetSpecialtyByNormalizedName(context.Background(), "Oftalmológia")
+	assert.Equal(t, 2, renamed.ID)
+
+	err = m.UpdateSpecialty(context.Background(), types.Specialty{ID: 2, Name: "Kardiológia"})
+	assert.EqualError(t, err, `specialty "kardiologia" already exists`)
+	assert.ErrorIs(t, m.UpdateSpecialty(context.Background(), types.Specialty{ID: 42, Name: "neexistuje"}), ErrNotFound)
+
+	err = m.DeleteSpecialty(context.Background(), 1)
+	assert.EqualError(t, err, "specialty 1 is still referenced by specialist 1")
+
+	id, err := m.InsertSpecialty(context.Background(), types.Specialty{Name: "dermatológia"})
+	assert.NoError(t, err)
+	assert.Equal(t, 3, id)
+	assert.NoError(t, m.DeleteSpecialty(context.Background(), 3))
+	deleted, err := m.GetSpecialtyByID(context.Background(), 3)
+	assert.NoError(t, err)
+	assert.Nil(t, deleted)
+	assert.ErrorIs(t, m.DeleteSpecialty(context.Background(), 3), ErrNotFound)
+}
+
+func TestMemoryModel_MergeSpecialties(t *testing.T) {
+	m := newTestMemoryModel(t)
+
+	moved, err := m.MergeSpecialties(context.Background(), 2, 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, 1, moved)
+
+	specialists, _ := m.GetSpecialistBySpecialty(context.Background(), 1)
+	assert.Len(t, specialists, 3)
+	assert.Equal(t, 2, specialists[2].Version)
+
+	duplicate, _ := m.GetSpecialtyByID(context.Background(), 2)
+	assert.Nil(t, duplicate)
+
+	// the name of the duplicate resolves to the canonical specialty
+	alias, err := m.GetSpecialtyByNormalizedName(context.Background(), "Očné lekárstvo")
+	assert.NoError(t, err)
+	assert.Equal(t, 1, alias.ID)
+
+	_, err = m.MergeSpecialties(context.Background(), 1, 2)
+	assert.EqualError(t, err, "specialty 2 does not exist")
+}
+
+func TestMemoryModel_GetAllSpecialtiesLocalized(t *testing.T) {
+	m := newTestMemoryModel(t)
+
+	specialties, err := m.GetAllSpecialtiesLocalized(context.Background(), "en")
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Specialty{
+		{ID: 1, Name: "kardiológia", Description: "srdce"},
+		{ID: 2, Name: "očné lekárstvo", Description: "oči"},
+	}, specialties)
+}
+
+func TestMemoryModel_SpecialtyTaxonomy(t *testing.T) {
+	m := newTestMemoryModel(t)
+	ctx := context.Background()
+
+	internal, err := m.UpsertSpecialtyCategory(ctx, "internal", "")
+	assert.NoError(t, err)
+	cardiology, err := m.UpsertSpecialtyCategory(ctx, "cardiology", "internal")
+	assert.NoError(t, err)
+	again, err := m.UpsertSpecialtyCategory(ctx, "cardiology", "internal")
+	assert.NoError(t, err)
+	assert.Equal(t, cardiology, again)
+
+	assert.NoError(t, m.UpsertSpecialtyCategoryTranslation(ctx, internal, "en", "Internal medicine"))
+	assert.NoError(t, m.UpsertSpecialtyCategoryTranslation(ctx, cardiology, "en", "Cardiology"))
+	assert.EqualError(t, m.UpsertSpecialtyCategoryTranslation(ctx, 42, "en", "Other"), "specialty category 42 does not exist")
+
+	assert.NoError(t, m.UpdateSpecialtyTaxonomy(ctx, 1, "choroby srdca", cardiology))
+	assert.NoError(t, m.UpdateSpecialtyTaxonomy(ctx, 42, "nič", cardiology))
+	assert.EqualError(t, m.UpdateSpecialtyTaxonomy(ctx, 1, "choroby srdca", 42), "specialty category 42 does not exist")
+
+	assert.NoError(t, m.UpsertSpecialtyTranslation(ctx, types.SpecialtyTranslation{SpecialtyID: 1, Language: "en", Name: "cardiology", Description: "heart", Synonyms: []string{"heart"}}))
+	assert.NoError(t, m.UpsertSpecialtyTranslation(ctx, types.SpecialtyTranslation{SpecialtyID: 2, Language: "de", Name: "Augenheilkunde", Description: "Augen"}))
+	assert.EqualError(t, m.UpsertSpecialtyTranslation(ctx, types.SpecialtyTranslation{SpecialtyID: 42, Language: "en"}), "specialty 42 does not exist")
+
+	translated, err := m.GetTranslatedSpecialtyIDs(ctx)
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]bool{1: true, 2: true}, translated)
+
+	english, err := m.GetAllSpecialtiesLocalized(ctx, "en")
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Specialty{
+		{ID: 1, Name: "cardiology", Description: "heart", Synonyms: []string{"heart"},
+			Category: &types.SpecialtyCategory{ID: cardiology, Code: "cardiology", Name: "Cardiology", ParentCode: "internal"}},
+		{ID: 2, Name: "očné lekárstvo", Description: "oči"},
+	}, english)
+
+	// a language without translations falls back to the Slovak data and the category code
+	slovak, err := m.GetAllSpecialtiesLocalized(ctx, "sk")
+	assert.NoError(t, err)
+	assert.Equal(t, "choroby srdca", slovak[0].Description)
+	assert.Equal(t, "cardiology", slovak[0].Category.Name)
+
+	// the translations of a merged duplicate move to the canonical specialty
+	_, err = m.MergeSpecialties(ctx, 2, 1)
+	assert.NoError(t, err)
+	german, _ := m.GetAllSpecialtiesLocalized(ctx, "de")
+	assert.Equal(t, "Augenheilkunde", german[0].Name)
+}
+
+func TestMemoryModel_SeedVersions(t *testing.T) {
+	m := NewMemoryModel()
+
+	version, err := m.GetSeedVersion(context.Background(), "specialties")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, version)
+
+	assert.NoError(t, m.SetSeedVersion(context.Background(), "specialties", 2))
+	version, err = m.GetSeedVersion(context.Background(), "specialties")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, version)
+}
+
+func TestMemoryModel_AddressChecks(t *testing.T) {
+	m := newTestMemoryModel(t)
+	ctx := WithActor(context.Background(), "address-check")
+
+	due, err := m.GetSpecialistsForAddressCheck(ctx, time.Hour, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Specialist{
+		{ID: 1, Name: "Kardio Košice", Location: "POINT(21.2496774 48.7172272)", Address: "Hlavná 1, Košice"},
+		{ID: 2, Name: "Kardio Prešov", Location: "POINT(21.2393 48.9984)", Address: "Hlavná 2, Prešov"},
+	}, due)
+
+	assert.NoError(t, m.SaveAddressCheck(ctx, types.AddressCheck{SpecialistID: 1, Status: types.AddressCheckMismatch, StatedAddress: "Hlavná 1, Košice", GeocodedAddress: "Hlavná 1, Prešov"}))
+	assert.NoError(t, m.SaveAddressCheck(ctx, types.AddressCheck{SpecialistID: 3, Status: types.AddressCheckFilled, GeocodedAddress: "Hlavná 3, Košice"}))
+	assert.EqualError(t, m.SaveAddressCheck(ctx, types.AddressCheck{SpecialistID: 42, Status: types.AddressCheckVerified}), "specialist 42 does not exist")
+
+	// the filled address is written to the specialist and recorded in its history
+	filled, _ := m.GetSpecialistByID(ctx, 3)
+	assert.Equal(t, "Hlavná 3, Košice", filled.Address)
+	assert.Equal(t, 2, filled.Version)
+	history, _ := m.GetSpecialistHistory(ctx, 3, time.Time{}, 10)
+	assert.Equal(t, "address-check", history[0].Actor)
+
+	// a changed address is not overwritten
+	assert.NoError(t, m.SaveAddressCheck(ctx, types.AddressCheck{SpecialistID: 3, Status: types.AddressCheckFilled, GeocodedAddress: "Hlavná 4, Košice"}))
+	filled, _ = m.GetSpecialistByID(ctx, 3)
+	assert.Equal(t, "Hlavná 3, Košice", filled.Address)
+
+	// checked specialists are due again after the interval
+	due, _ = m.GetSpecialistsForAddressCheck(ctx, time.Hour, 10)
+	assert.Len(t, due, 1)
+	assert.Equal(t, 2, due[0].ID)
+	m.Now = func() time.Time { return memoryUpdatedAt.Add(2 * time.Hour) }
+	due, _ = m.GetSpecialistsForAddressCheck(ctx, time.Hour, 10)
+	assert.Equal(t, []int{2, 1, 3}, []int{due[0].ID, due[1].ID, due[2].ID})
+
+	checks, err := m.GetAddressChecks(ctx, types.AddressCheckMismatch)
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.AddressCheck{{
+		SpecialistID: 1, SpecialistName: "Kardio Košice", Status: types.AddressCheckMismatch,
+		StatedAddress: "Hlavná 1, Košice", GeocodedAddress: "Hlavná 1, Prešov", CheckedAt: memoryUpdatedAt,
+	}}, checks)
+
+	all, _ := m.GetAddressChecks(ctx, "")
+	assert.Len(t, all, 2)
+}
+
+func TestMemoryModel_SymptomMappings(t *testing.T) {
+	m := newTestMemoryModel(t)
+
+	for _, sm := range []types.SymptomMapping{
+		{Keyword: "srdce", SpecialtyID: 1, Weight: 0.9, Enabled: true},
+		{Keyword: "oko", SpecialtyID: 2, Weight: 0.9, Enabled: true},
+		{Keyword: "srdce", SpecialtyID: 1, Weight: 0.1},
+		{Keyword: "zrak", SpecialtyID: 2, Weight: 0.5, Enabled: true},
+	} {
+		assert.NoError(t, m.InsertSymptomMapping(context.Background(), sm))
+	}
+	assert.EqualError(t, m.InsertSymptomMapping(context.Background(), types.SymptomMapping{Keyword: "zub", SpecialtyID: 42, Weight: 0.5}), "specialty 42 does not exist")
+
+	mappings, err := m.GetAllSymptomMappings(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.SymptomMapping{
+		{ID: 1, Keyword: "srdce", SpecialtyID: 1, SpecialtyName: "kardiológia", Weight: 0.9, Enabled: true},
+		{ID: 2, Keyword: "oko", SpecialtyID: 2, SpecialtyName: "očné lekárstvo", Weight: 0.9, Enabled: true},
+		{ID: 3, Keyword: "zrak", SpecialtyID: 2, SpecialtyName: "očné lekárstvo", Weight: 0.5, Enabled: true},
+	}, mappings)
+
+	assert.NoError(t, m.UpdateSymptomMapping(context.Background(), types.SymptomMapping{ID: 3, Keyword: "zrak", SpecialtyID: 2, Weight: 0.7}))
+	assert.EqualError(t, m.UpdateSymptomMapping(context.Background(), types.SymptomMapping{ID: 3, Keyword: "oko", SpecialtyID: 2, Weight: 0.7}), `symptom mapping "oko" of specialty 2 already exists`)
+	assert.ErrorIs(t, m.UpdateSymptomMapping(context.Background(), types.SymptomMapping{ID: 42, Keyword: "zub", SpecialtyID: 1, Weight: 0.5}), ErrNotFound)
+	assert.NoError(t, m.DeleteSymptomMapping(context.Background(), 1))
+	assert.ErrorIs(t, m.DeleteSymptomMapping(context.Background(), 1), ErrNotFound)
+
+	id, err := m.CreateSymptomMapping(context.Background(), types.SymptomMapping{Keyword: "srdce", SpecialtyID: 1, Weight: 0.8, Enabled: true})
+	assert.NoError(t, err)
+	assert.Equal(t, 4, id)
+	_, err = m.CreateSymptomMapping(context.Background(), types.SymptomMapping{Keyword: "srdce", SpecialtyID: 1, Weight: 0.5})
+	assert.EqualError(t, err, `symptom mapping "srdce" of specialty 1 already exists`)
+	assert.NoError(t, m.DeleteSymptomMapping(context.Background(), id))
+
+	// the mappings of a merged specialty move to the canonical one
+	_, err = m.MergeSpecialties(context.Background(), 2, 1)
+	assert.NoError(t, err)
+
+	mappings, _ = m.GetAllSymptomMappings(context.Background())
+	assert.Equal(t, []*types.SymptomMapping{
+		{ID: 2, Keyword: "oko", SpecialtyID: 1, SpecialtyName: "kardiológia", Weight: 0.9, Enabled: true},
+		{ID: 3, Keyword: "zrak", SpecialtyID: 1, SpecialtyName: "kardiológia", Weight: 0.7},
+	}, mappings)
+}
+
+func TestMemoryModel_HolidayOverrides(t *testing.T) {
+	m := NewMemoryModel()
+
+	for _, h := range []types.Holiday{
+		{Date: "2024-12-31", Name: "Silvester", Closed: true},
+		{Date: "2024-05-02", Name: "Mestské dni", Region: "Košice", Closed: true},
+		{Date: "2024-05-02", Name: "Deň", Closed: true},
+		{Date: "2025-01-02", Name: "Zatvorené", Closed: true},
+		{Date: "2024-12-31", Name: "Posledný deň roka", Closed: true},
+	} {
+		assert.NoError(t, m.SetHolidayOverride(context.Background(), h))
+	}
+
+	overrides, err := m.GetHolidayOverrides(context.Background(), 2024)
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Holiday{
+		{Date: "2024-05-02", Name: "Deň", Closed: true, Source: types.HolidaySourceOverride},
+		{Date: "2024-05-02", Name: "Mestské dni", Region: "Košice", Closed: true, Source: types.HolidaySourceOverride},
+		{Date: "2024-12-31", Name: "Posledný deň roka", Closed: true, Source: types.HolidaySourceOverride},
+	}, overrides)
+
+	deleted, err := m.DeleteHolidayOverride(context.Background(), "2024-05-02", "Košice")
+	assert.NoError(t, err)
+	assert.True(t, deleted)
+
+	deleted, err = m.DeleteHolidayOverride(context.Background(), "2024-05-02", "Košice")
+	assert.NoError(t, err)
+	assert.False(t, deleted)
+
+	overrides, _ = m.GetHolidayOverrides(context.Background(), 2025)
+	assert.Len(t, overrides, 1)
+}
+
+func TestMemoryModel_Reviews(t *testing.T) {
+	m := newTestMemoryModel(t)
+
+	review, err := m.GetReviewBySpecialistId(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, &types.Review{ID: 1, SpecialistId: 1, Url: "a", Rating: 4}, review)
+
+	_, err = m.GetReviewBySpecialistId(context.Background(), 3)
+	assert.Equal(t, sql.ErrNoRows, err)
+
+	_, err = m.InsertReview(context.Background(), types.Review{SpecialistId: 42, Url: "c", Rating: 1})
+	assert.EqualError(t, err, "specialist 42 does not exist")
+
+	byID, err := m.GetReviewByID(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, &types.Review{ID: 2, SpecialistId: 1, Url: "b", Rating: 5}, byID)
+
+	assert.NoError(t, m.UpdateReview(context.Background(), types.Review{ID: 1, SpecialistId: 3, Url: "a", Rating: 2, Comment: "dlho sa čaká"}))
+	assert.NoError(t, m.DeleteReview(context.Background(), 2))
+	assert.ErrorIs(t, m.UpdateReview(context.Background(), types.Review{ID: 2, SpecialistId: 1, Url: "b", Rating: 5}), ErrNotFound)
+	assert.ErrorIs(t, m.DeleteReview(context.Background(), 2), ErrNotFound)
+
+	missing, err := m.GetReviewByID(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+
+	reviews, err := m.AllReviews(context.Background())
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Review{{ID: 1, SpecialistId: 3, Url: "a", Rating: 2, Comment: "dlho sa čaká"}}, reviews)
+}
+
+func TestNewMemoryModels(t *testing.T) {
+	memory := NewMemoryModel()
+
+	testModels := NewMemoryModels(memory)
+
+	assert.Same(t, memory, testModels.Specialists)
+	assert.Same(t, memory, testModels.Specialties)
+	assert.Same(t, memory, testModels.Reviews)
+	assert.Same(t, memory, testModels.Audit)
+	assert.Same(t, memory, testModels.Symptoms)
+	assert.Same(t, memory, testModels.Holidays)
+	assert.Same(t, memory, testModels.Seeds)
+	assert.Same(t, memory, testModels.Addresses)
+	assert.Nil(t, testModels.DB)
+}
+
+func TestMemoryModel_AuditLog(t *testing.T) {
+	m := NewMemoryModel()
+	m.Now = func() time.Time { return memoryUpdatedAt }
+
+	for _, e := range []types.AuditEntry{
+		{Actor: "jana", Action: types.AuditActionCreate, Entity: types.AuditEntitySpecialist, EntityID: 1, After: json.RawMessage(`{"id":1}`)},
+		{Actor: "jana", Action: types.AuditActionCreate, Entity: types.AuditEntityReview, EntityID: 1},
+		{Actor: "peter", Action: types.AuditActionDelete, Entity: types.AuditEntitySpecialist, EntityID: 1, Before: json.RawMessage(`{"id":1}`)},
+	} {
+		_, err := m.InsertAuditEntry(context.Background(), e)
+		assert.NoError(t, err)
+	}
+
+	entries, err := m.GetAuditLog(context.Background(), types.AuditEntitySpecialist, 1, 10)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 2)
+	assert.Equal(t, 3, entries[0].ID)
+	assert.Equal(t, "peter", entries[0].Actor)
+	assert.Equal(t, memoryUpdatedAt, entries[0].CreatedAt)
+
+	entries, err = m.GetAuditLog(context.Background(), "", 0, 2)
+	assert.NoError(t, err)
+	assert.Equal(t, 3, entries[0].ID)
+	assert.Equal(t, 2, entries[1].ID)
+
+	entries, err = m.GetAuditLog(context.Background(), types.AuditEntitySpecialty, 0, 10)
+	assert.NoError(t, err)
+	assert.Empty(t, entries)
+}
+
+func TestMemoryModel_Audited(t *testing.T) {
+	m := newTestMemoryModel(t)
+
+	ctx := WithAudit(context.Background(), func(id int) ([]types.AuditEntry, error) {
+		return []types.AuditEntry{{Actor: "jana", Action: types.AuditActionCreate, Entity: types.AuditEntityReview, EntityID: id}}, nil
+	})
+
+	id, err := m.InsertReview(ctx, types.Review{SpecialistId: 1, Url: "c", Rating: 3})
+	assert.NoError(t, err)
+
+	entries, err := m.GetAuditLog(context.Background(), types.AuditEntityReview, id, 10)
+	assert.NoError(t, err)
+	assert.Len(t, entries, 1)
+	assert.Equal(t, "jana", entries[0].Actor)
+
+	// a change whose audit entries cannot be built is not stored
+	failing := WithAudit(context.Background(), func(id int) ([]types.AuditEntry, error) {
+		return nil, errors.New("mocked error")
+	})
+
+	assert.EqualError(t, m.DeleteReview(failing, id), "mocked error")
+
+	review, err := m.GetReviewByID(context.Background(), id)
+	assert.NoError(t, err)
+	assert.NotNil(t, review)
+}
@@ -0,0 +1,422 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"fmt"
+	"math"
+	"slices"
+	"strings"
+
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/lib/pq"
+)
+
+/*
+GetSpecialistOverrides returns the overridden fields of a specialist ordered by field
+The value of an override is the one stored in the specialist
+The function returns nil if the specialist does not exist
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetSpecialistOverrides(ctx context.Context, id int) ([]*types.SpecialistOverride, error) {
+	s, err := m.GetSpecialistByID(ctx, id)
+	if err != nil || s == nil {
+		return nil, err
+	}
+
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT field, source_value, updated_at
+	FROM specialist_override
+	WHERE specialist_id=$1
+	ORDER BY field
+	`
+
+	rows, err := m.DB.QueryContext(ctx, stmt, id)
+	if err != nil {
+		return nil, err
+	}
+
+	overrides, err := scanAll(rows, func(row rowScanner) (*types.SpecialistOverride, error) {
+		var o types.SpecialistOverride
+		if err := row.Scan(&o.Field, &o.SourceValue, &o.UpdatedAt); err != nil {
+			return nil, err
+		}
+
+		if field := s.OverridableField(o.Field); field != nil {
+			o.Value = *field
+		}
+
+		return &o, nil
+	})
+	if err != nil {
+		return nil, err
+	}
+
+	if overrides == nil {
+		overrides = []*types.SpecialistOverride{}
+	}
+
+	return overrides, nil
+}
+
+/*
+SetSpecialistOverrides overrides fields of a specialist, values maps the fields to their new values
+A field overridden for the first time keeps its current value as the source value
+The version is the version of the specialist the caller read, the function returns the new version
+The function returns ErrNotFound if the specialist does not exist and ErrStaleVersion if it was changed since
+The function returns an error if a field cannot be overridden or there was an issue with the database
+*/
+func (m *DBModel) SetSpecialistOverrides(ctx context.Context, id, version int, values map[string]string) (int, error) {
+	fields, err := overrideFields(fieldNames(values))
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := setActor(ctx, tx); err != nil {
+		return 0, err
+	}
+
+	current, err := lockedSpecialist(ctx, tx, id, version)
+	if err != nil {
+		return 0, err
+	}
+
+	assignments := make(map[string]string, len(fields))
+	for _, field := range fields {
+		// the conflict keeps the source value recorded when the field was overridden first
+		_, err := tx.ExecContext(ctx, `
+		INSERT INTO specialist_override (specialist_id, field, source_value)
+		VALUES ($1, $2, $3)
+		ON CONFLICT (specialist_id, field) DO UPDATE SET updated_at=now()
+		`, id, field, *current.OverridableField(field))
+		if err != nil {
+			return 0, err
+		}
+
+		assignments[field] = values[field]
+	}
+
+	newVersion, err := updateSpecialistFields(ctx, tx, id, version, fields, assignments, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := insertAudit(ctx, tx, id); err != nil {
+		return 0, err
+	}
+
+	return newVersion, tx.Commit()
+}
+
+/*
+DeleteSpecialistOverrides removes overrides of a specialist, the fields get their latest source values back
+Fields that are not overridden are skipped
+The version is the version of the specialist the caller read, the function returns the new version,
+the version is kept when none of the fields was overridden
+The function returns ErrNotFound if the specialist does not exist and ErrStaleVersion if it was changed since
+The function returns an error if a field cannot be overridden or there was an issue with the database
+*/
+func (m *DBModel) DeleteSpecialistOverrides(ctx context.Context, id, version int, fields []string) (int, error) {
+	fields, err := overrideFields(fields)
+	if err != nil {
+		return 0, err
+	}
+
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := setActor(ctx, tx); err != nil {
+		return 0, err
+	}
+
+	if _, err := lockedSpecialist(ctx, tx, id, version); err != nil {
+		return 0, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `
+	DELETE FROM specialist_override
+	WHERE specialist_id=$1 AND field = ANY($2)
+	RETURNING field, source_value
+	`, id, pq.Array(fields))
+	if err != nil {
+		return 0, err
+	}
+
+	restored := map[string]string{}
+	for rows.Next() {
+		var field, value string
+		if err := rows.Scan(&field, &value); err != nil {
+			rows.Close()
+			return 0, err
+		}
+		restored[field] = value
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return 0, err
+	}
+
+	if len(restored) == 0 {
+		return version, nil
+	}
+
+	newVersion, err := updateSpecialistFields(ctx, tx, id, version, fieldNames(restored), restored, nil)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := insertAudit(ctx, tx, id); err != nil {
+		return 0, err
+	}
+
+	return newVersion, tx.Commit()
+}
+
+/*
+SyncSpecialist updates a specialist with its data from the source, e.g. the geoportal
+Overridden fields keep their values, only their source values are updated
+The specialist is updated and its version bumped only when a field presented by the API changed
+The function returns whether the specialist changed
+The function returns ErrNotFound if the specialist does not exist, ErrStaleVersion if it was changed during the sync
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) SyncSpecialist(ctx context.Context, id int, source types.Specialist) (bool, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return false, err
+	}
+	defer tx.Rollback()
+
+	if err := setActor(ctx, tx); err != nil {
+		return false, err
+	}
+
+	var insurers pq.StringArray
+	var kpzs sql.NullString
+	current, err := scanSpecialist(tx.QueryRowContext(ctx, `
+	SELECT `+specialistColumns+`, s.insurers, s.kpzs
+	FROM specialist s
+	WHERE s.id=$1
+	FOR UPDATE OF s
+	`, id), &insurers, &kpzs)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return false, ErrNotFound
+		}
+		return false, err
+	}
+
+	rows, err := tx.QueryContext(ctx, `SELECT field, source_value FROM specialist_override WHERE specialist_id=$1`, id)
+	if err != nil {
+		return false, err
+	}
+
+	sourceValues := map[string]string{}
+	for rows.Next() {
+		var field, value string
+		if err := rows.Scan(&field, &value); err != nil {
+			rows.Close()
+			return false, err
+		}
+		sourceValues[field] = value
+	}
+	rows.Close()
+	if err := rows.Err(); err != nil {
+		return false, err
+	}
+
+	changed := []string{}
+	assignments := map[string]string{}
+	for _, field := range types.SpecialistOverrideFields {
+		value := *source.OverridableField(field)
+
+		previous, overridden := sourceValues[field]
+		if !overridden {
+			if !sameFieldValue(field, *current.OverridableField(field), value) {
+				changed = append(changed, field)
+				assignments[field] = value
+			}
+			continue
+		}
+
+		if previous != value {
+			_, err := tx.ExecContext(ctx, `
+			UPDATE specialist_override SET source_value=$3 WHERE specialist_id=$1 AND field=$2
+			`, id, field, value)
+			if err != nil {
+				return false, err
+			}
+		}
+	}
+
+	var extra []specialistAssignment
+	if source.SpecialtyID != current.SpecialtyID {
+		extra = append(extra, specialistAssignment{"specialty_id", source.SpecialtyID})
+	}
+
+	sourceInsurers := source.Insurers
+	if sourceInsurers == nil {
+		sourceInsurers = []string{}
+	}
+	if !slices.Equal(sourceInsurers, []string(insurers)) {
+		extra = append(extra, specialistAssignment{"insurers", pq.Array(sourceInsurers)})
+	}
+
+	if source.KPZS != kpzs.String {
+		extra = append(extra, specialistAssignment{"kpzs", source.KPZS})
+	}
+
+	if len(changed) == 0 && len(extra) == 0 {
+		return false, tx.Commit()
+	}
+
+	if _, err := updateSpecialistFields(ctx, tx, id, current.Version, changed, assignments, extra); err != nil {
+		return false, err
+	}
+
+	return true, tx.Commit()
+}
+
+// specialistAssignment sets a column of a specialist that cannot be overridden
+type specialistAssignment struct {
+	column string
+	value  any
+}
+
+/*
+lockedSpecialist reads the specialist within the transaction and checks its version
+The row stays locked until the end of the transaction
+The function returns ErrNotFound if the specialist does not exist and ErrStaleVersion if it was changed since
+*/
+func lockedSpecialist(ctx context.Context, tx *sql.Tx, id, version int) (*types.Specialist, error) {
+	s, err := scanSpecialist(tx.QueryRowContext(ctx, `
+	SELECT `+specialistColumns+`
+	FROM specialist s
+	WHERE s.id=$1
+	FOR UPDATE OF s
+	`, id))
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, ErrNotFound
+		}
+		return nil, err
+	}
+
+	if s.Version != version {
+		return nil, ErrStaleVersion
+	}
+
+	return s, nil
+}
+
+/*
+updateSpecialistFields writes the values of the overridable fields and the extra columns to the specialist and bumps its version
+The fields are column names checked by overrideFields, empty values are stored as NULL except for the name
+The function returns the new version, ErrStaleVersion if the specialist was changed since it was read
+*/
+func updateSpecialistFields(ctx context.Context, tx *sql.Tx, id, version int, fields []string, values map[string]string, extra []specialistAssignment) (int, error) {
+	set := []string{}
+	args := []any{}
+
+	for _, field := range fields {
+		args = append(args, values[field])
+		if field == "name" {
+			set = append(set, fmt.Sprintf("%s=$%d", field, len(args)))
+		} else {
+			set = append(set, fmt.Sprintf("%s=NULLIF($%d, '')", field, len(args)))
+		}
+	}
+
+	for _, assignment := range extra {
+		args = append(args, assignment.value)
+		set = append(set, fmt.Sprintf("%s=$%d", assignment.column, len(args)))
+	}
+
+	args = append(args, id, version)
+	stmt := fmt.Sprintf(`
+	UPDATE specialist
+	SET %s, version=version+1, updated_at=now()
+	WHERE id=$%d AND version=$%d
+	RETURNING version
+	`, strings.Join(set, ", "), len(args)-1, len(args))
+
+	var newVersion int
+	if err := tx.QueryRowContext(ctx, stmt, args...).Scan(&newVersion); err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, ErrStaleVersion
+		}
+		return 0, err
+	}
+
+	return newVersion, nil
+}
+
+// overrideFields returns the fields sorted, the function returns an error for a field that cannot be overridden
+func overrideFields(fields []string) ([]string, error) {
+	if len(fields) == 0 {
+		return nil, errors.New("no fields to override")
+	}
+
+	for _, field := range fields {
+		if !slices.Contains(types.SpecialistOverrideFields, field) {
+			return nil, fmt.Errorf("field %q cannot be overridden", field)
+		}
+	}
+
+	sorted := slices.Clone(fields)
+	slices.Sort(sorted)
+
+	return slices.Compact(sorted), nil
+}
+
+// sameFieldValue compares two values of a field, locations are compared by their coordinates as the WKT formatting may differ
+func sameFieldValue(field, a, b string) bool {
+	if a == b {
+		return true
+	}
+
+	if field != "location" {
+		return false
+	}
+
+	la, errA := types.ParseLocation(a)
+	lb, errB := types.ParseLocation(b)
+	if errA != nil || errB != nil {
+		return false
+	}
+
+	return math.Abs(la.Lat-lb.Lat) < 1e-9 && math.Abs(la.Lon-lb.Lon) < 1e-9
+}
+
+// fieldNames returns the fields of the values sorted
+func fieldNames[V any](values map[string]V) []string {
+	fields := make([]string, 0, len(values))
+	for field := range values {
+		fields = append(fields, field)
+	}
+	slices.Sort(fields)
+
+	return fields
+}
@@ -0,0 +1,259 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetSpecialistOverrides_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows(specialistColumnNames).
+		AddRow(1, "Kardiológia Košice", 1, "POINT(21.25 48.72)", "Hlavná 1", nil, "055/123", nil, nil, nil, nil, nil, nil, nil, nil, nil, 2, "{name,telephone}")
+	overrides := sqlmock.NewRows([]string{"field", "source_value", "updated_at"}).
+		AddRow("name", "Kardio Košice", memoryUpdatedAt).
+		AddRow("telephone", "", memoryUpdatedAt)
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=\$1`).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT field, source_value, updated_at FROM specialist_override WHERE specialist_id=\$1 ORDER BY field`).WithArgs(1).WillReturnRows(overrides)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialistOverrides(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.SpecialistOverride{
+		{Field: "name", Value: "Kardiológia Košice", SourceValue: "Kardio Košice", UpdatedAt: memoryUpdatedAt},
+		{Field: "telephone", Value: "055/123", SourceValue: "", UpdatedAt: memoryUpdatedAt},
+	}, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistOverrides_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=\$1`).WithArgs(42).WillReturnRows(sqlmock.NewRows(specialistColumnNames))
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialistOverrides(context.Background(), 42)
+
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetSpecialistOverrides_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows(specialistColumnNames).
+		AddRow(1, "Kardio Košice", 1, "POINT(21.25 48.72)", "Hlavná 1", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 2, nil)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=\$1 FOR UPDATE OF s`).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectExec(`INSERT INTO specialist_override \(specialist_id, field, source_value\) VALUES \(\$1, \$2, \$3\) ON CONFLICT`).
+		WithArgs(1, "name", "Kardio Košice").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO specialist_override \(specialist_id, field, source_value\) VALUES \(\$1, \$2, \$3\) ON CONFLICT`).
+		WithArgs(1, "telephone", "").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectQuery(`UPDATE specialist SET name=\$1, telephone=NULLIF\(\$2, ''\), version=version\+1, updated_at=now\(\) WHERE id=\$3 AND version=\$4 RETURNING version`).
+		WithArgs("Kardiológia Košice", "055/123", 1, 2).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(3))
+	mock.ExpectCommit()
+
+	modelsDB := NewModels(db)
+	version, err := modelsDB.DB.SetSpecialistOverrides(context.Background(), 1, 2, map[string]string{"telephone": "055/123", "name": "Kardiológia Košice"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetSpecialistOverrides_StaleVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows(specialistColumnNames).
+		AddRow(1, "Kardio Košice", 1, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 3, nil)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=\$1 FOR UPDATE OF s`).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectRollback()
+
+	modelsDB := NewModels(db)
+	_, err = modelsDB.DB.SetSpecialistOverrides(context.Background(), 1, 2, map[string]string{"name": "Kardiológia Košice"})
+
+	assert.ErrorIs(t, err, ErrStaleVersion)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSetSpecialistOverrides_InvalidField(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	modelsDB := NewModels(db)
+
+	_, err = modelsDB.DB.SetSpecialistOverrides(context.Background(), 1, 2, map[string]string{"insurers": "VšZP"})
+	assert.EqualError(t, err, `field "insurers" cannot be overridden`)
+
+	_, err = modelsDB.DB.SetSpecialistOverrides(context.Background(), 1, 2, nil)
+	assert.EqualError(t, err, "no fields to override")
+
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteSpecialistOverrides_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows(specialistColumnNames).
+		AddRow(1, "Kardiológia Košice", 1, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 3, "{name}")
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=\$1 FOR UPDATE OF s`).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery(`DELETE FROM specialist_override WHERE specialist_id=\$1 AND field = ANY\(\$2\) RETURNING field, source_value`).
+		WithArgs(1, `{"email","name"}`).
+		WillReturnRows(sqlmock.NewRows([]string{"field", "source_value"}).AddRow("name", "Kardio Košice s.r.o."))
+	mock.ExpectQuery(`UPDATE specialist SET name=\$1, version=version\+1, updated_at=now\(\) WHERE id=\$2 AND version=\$3 RETURNING version`).
+		WithArgs("Kardio Košice s.r.o.", 1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+	mock.ExpectCommit()
+
+	modelsDB := NewModels(db)
+	version, err := modelsDB.DB.DeleteSpecialistOverrides(context.Background(), 1, 3, []string{"name", "email", "name"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 4, version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteSpecialistOverrides_NotOverridden(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows(specialistColumnNames).
+		AddRow(1, "Kardio Košice", 1, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 3, nil)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=\$1 FOR UPDATE OF s`).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery(`DELETE FROM specialist_override`).WithArgs(1, `{"email"}`).WillReturnRows(sqlmock.NewRows([]string{"field", "source_value"}))
+	mock.ExpectRollback()
+
+	modelsDB := NewModels(db)
+	version, err := modelsDB.DB.DeleteSpecialistOverrides(context.Background(), 1, 3, []string{"email"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 3, version)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSyncSpecialist_Unchanged(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	columns := append(append([]string{}, specialistColumnNames...), "insurers", "kpzs")
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, "Kardiológia Košice", 1, "POINT(21.25 48.72)", "Hlavná 1", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 3, "{name}", "{VšZP}", nil)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT (.+), s.insurers, s.kpzs FROM specialist s WHERE s.id=\$1 FOR UPDATE OF s`).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT field, source_value FROM specialist_override WHERE specialist_id=\$1`).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"field", "source_value"}).AddRow("name", "Kardio Košice"))
+	// only the source value of the overridden name changes
+	mock.ExpectExec(`UPDATE specialist_override SET source_value=\$3 WHERE specialist_id=\$1 AND field=\$2`).
+		WithArgs(1, "name", "Kardio Košice s.r.o.").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	source := types.Specialist{Name: "Kardio Košice s.r.o.", SpecialtyID: 1, Location: "POINT(21.250000 48.720000)", Address: "Hlavná 1", Insurers: []string{"VšZP"}}
+
+	modelsDB := NewModels(db)
+	changed, err := modelsDB.DB.SyncSpecialist(context.Background(), 1, source)
+
+	assert.NoError(t, err)
+	assert.False(t, changed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSyncSpecialist_Changed(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	columns := append(append([]string{}, specialistColumnNames...), "insurers", "kpzs")
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, "Kardio Košice", 1, "POINT(21.25 48.72)", "Hlavná 1", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 3, nil, "{}", nil)
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT (.+), s.insurers, s.kpzs FROM specialist s WHERE s.id=\$1 FOR UPDATE OF s`).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT field, source_value FROM specialist_override WHERE specialist_id=\$1`).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"field", "source_value"}))
+	mock.ExpectQuery(`UPDATE specialist SET address=NULLIF\(\$1, ''\), specialty_id=\$2, insurers=\$3, kpzs=\$4, version=version\+1, updated_at=now\(\) WHERE id=\$5 AND version=\$6 RETURNING version`).
+		WithArgs("Hlavná 10", 2, `{"VšZP"}`, "P27489001201", 1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+	mock.ExpectCommit()
+
+	source := types.Specialist{Name: "Kardio Košice", SpecialtyID: 2, Location: "POINT(21.25 48.72)", Address: "Hlavná 10", Insurers: []string{"VšZP"}, KPZS: "P27489001201"}
+
+	modelsDB := NewModels(db)
+	changed, err := modelsDB.DB.SyncSpecialist(context.Background(), 1, source)
+
+	assert.NoError(t, err)
+	assert.True(t, changed)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSyncSpecialist_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=\$1 FOR UPDATE OF s`).WithArgs(42).WillReturnError(sql.ErrNoRows)
+	mock.ExpectRollback()
+
+	modelsDB := NewModels(db)
+	_, err = modelsDB.DB.SyncSpecialist(context.Background(), 42, types.Specialist{Name: "Neexistuje"})
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,171 @@
+package models
+
+import (
+	"context"
+	"errors"
+	"time"
+
+	"github.com/acornak/healthcare-poc/types"
+)
+
+var (
+	// ErrNotFound is returned by updates and deletes of a record that does not exist
+	ErrNotFound = errors.New("record not found")
+	// ErrStaleVersion is returned by updates and deletes based on an older version than the stored one
+	ErrStaleVersion = errors.New("record was changed in the meantime")
+)
+
+/*
+SpecialistRepository stores the specialists
+Single specialist lookups return nil without an error when the specialist does not exist
+Locations are passed and returned in the WKT format, radius is in meters
+InsertSpecialist returns the id of the new specialist
+Fields overridden by an admin keep their values when SyncSpecialist updates the specialist from the source,
+GetSpecialistByName matches an overridden name by its source value
+Updates and deletes are optimistically locked: they name the version they are based on and fail with ErrStaleVersion
+when the specialist was changed since, UpdateSpecialist returns the new version
+Every change is recorded in the history of the specialist with the actor named by WithActor
+GetDuplicateSpecialists scores pairs of specialists that may be the same clinic, MergeSpecialists merges a duplicate into the survivor
+and keeps its source name as an alias returned by GetSpecialistAlias
+DeleteSpecialist keeps the source names of the specialist as tombstones reported by IsSpecialistDeleted,
+so the scraper does not insert a specialist deleted by an admin again
+*/
+type SpecialistRepository interface {
+	GetAllSpecialists(ctx context.Context) ([]*types.Specialist, error)
+	GetSpecialistBySpecialty(ctx context.Context, specialtyID int) ([]*types.Specialist, error)
+	GetSpecialistByID(ctx context.Context, id int) (*types.Specialist, error)
+	GetSpecialistProfile(ctx context.Context, id int) (*types.SpecialistProfile, error)
+	GetSpecialistByName(ctx context.Context, name string) (*types.Specialist, error)
+	GetSpecialistBySpecialtyAndLocation(ctx context.Context, specialtyID, radius int, userLocation string) ([]*types.Specialist, error)
+	InsertSpecialist(ctx context.Context, s types.Specialist) (int, error)
+	DeleteSpecialist(ctx context.Context, id, version int) error
+	UpdateSpecialist(ctx context.Context, s types.Specialist) (int, error)
+	SearchSpecialists(ctx context.Context, terms []string, minRank float64, limit int) ([]*types.SpecialistSearchResult, error)
+	GetSpecialistPlaces(ctx context.Context) ([]*types.Place, error)
+	GetSpecialistOverrides(ctx context.Context, id int) ([]*types.SpecialistOverride, error)
+	SetSpecialistOverrides(ctx context.Context, id, version int, values map[string]string) (int, error)
+	DeleteSpecialistOverrides(ctx context.Context, id, version int, fields []string) (int, error)
+	SyncSpecialist(ctx context.Context, id int, source types.Specialist) (bool, error)
+	GetSpecialistHistory(ctx context.Context, id int, until time.Time, limit int) ([]*types.SpecialistChange, error)
+	GetSpecialistAsOf(ctx context.Context, id int, at time.Time) (*types.SpecialistSnapshot, error)
+	GetDuplicateSpecialists(ctx context.Context, minScore float64, limit int) ([]*types.DuplicateCandidate, error)
+	DismissDuplicateSpecialists(ctx context.Context, id, otherID int) error
+	MergeSpecialists(ctx context.Context, duplicateID, duplicateVersion, survivorID, survivorVersion int) (int, int, error)
+	GetSpecialistAlias(ctx context.Context, name string) (int, error)
+	IsSpecialistDeleted(ctx context.Context, name string) (bool, error)
+}
+
+/*
+SpecialtyRepository stores the specialties
+Single specialty lookups return nil without an error when the specialty does not exist, updates and deletes return ErrNotFound
+InsertSpecialty returns the id of the new specialty
+A specialty still assigned to specialists cannot be deleted
+GetAllSpecialtiesLocalized falls back to the Slovak names and descriptions for a language without translations
+The taxonomy of categories and translations is written by the seed, categories are identified by their code
+*/
+type SpecialtyRepository interface {
+	GetAllSpecialties(ctx context.Context) ([]*types.Specialty, error)
+	GetSpecialtyByID(ctx context.Context, id int) (*types.Specialty, error)
+	GetSpecialtyByName(ctx context.Context, name string) (*types.Specialty, error)
+	GetSpecialtyByNormalizedName(ctx context.Context, name string) (*types.Specialty, error)
+	InsertSpecialty(ctx context.Context, s types.Specialty) (int, error)
+	InsertMultipleSpecialties(ctx context.Context, s []types.Specialty) error
+	DeleteSpecialty(ctx context.Context, id int) error
+	UpdateSpecialty(ctx context.Context, s types.Specialty) error
+	MergeSpecialties(ctx context.Context, duplicateID, canonicalID int) (int, error)
+	GetAllSpecialtiesLocalized(ctx context.Context, language string) ([]*types.Specialty, error)
+	GetTranslatedSpecialtyIDs(ctx context.Context) (map[int]bool, error)
+	UpsertSpecialtyCategory(ctx context.Context, code, parentCode string) (int, error)
+	UpsertSpecialtyCategoryTranslation(ctx context.Context, categoryID int, language, name string) error
+	UpsertSpecialtyTranslation(ctx context.Context, t types.SpecialtyTranslation) error
+	UpdateSpecialtyTaxonomy(ctx context.Context, id int, description string, categoryID int) error
+}
+
+/*
+SymptomRepository stores the curated keywords of symptoms pointing to specialties
+The mappings of a specialty are deleted with it and moved to the canonical specialty when it is merged
+GetSymptomMappingByID returns nil without an error when the mapping does not exist
+InsertSymptomMapping leaves an existing keyword and specialty pair untouched, CreateSymptomMapping fails on it,
+updates and deletes return ErrNotFound
+*/
+type SymptomRepository interface {
+	GetAllSymptomMappings(ctx context.Context) ([]*types.SymptomMapping, error)
+	GetSymptomMappingByID(ctx context.Context, id int) (*types.SymptomMapping, error)
+	InsertSymptomMapping(ctx context.Context, sm types.SymptomMapping) error
+	CreateSymptomMapping(ctx context.Context, sm types.SymptomMapping) (int, error)
+	UpdateSymptomMapping(ctx context.Context, sm types.SymptomMapping) error
+	DeleteSymptomMapping(ctx context.Context, id int) error
+}
+
+/*
+HolidayRepository stores the closures and cancelled public holidays edited by an admin
+Dates are in the YYYY-MM-DD format, an empty region applies to the whole country
+*/
+type HolidayRepository interface {
+	GetHolidayOverrides(ctx context.Context, year int) ([]*types.Holiday, error)
+	SetHolidayOverride(ctx context.Context, h types.Holiday) error
+	DeleteHolidayOverride(ctx context.Context, date, region string) (bool, error)
+}
+
+/*
+ReviewRepository stores the reviews of specialists
+GetReviewBySpecialistId returns sql.ErrNoRows when the specialist has no review,
+GetReviewByID returns nil without an error when the review does not exist, updates and deletes return ErrNotFound
+*/
+type ReviewRepository interface {
+	AllReviews(ctx context.Context) ([]*types.Review, error)
+	GetReviewBySpecialistId(ctx context.Context, id int) (*types.Review, error)
+	GetReviewByID(ctx context.Context, id int) (*types.Review, error)
+	InsertReview(ctx context.Context, r types.Review) (int, error)
+	DeleteReview(ctx context.Context, id int) error
+	UpdateReview(ctx context.Context, r types.Review) error
+}
+
+/*
+AuditRepository stores the log of the changes made through the admin API
+The changes of the other repositories made with a WithAudit context are recorded together with the change
+GetAuditLog returns the newest entries first
+*/
+type AuditRepository interface {
+	InsertAuditEntry(ctx context.Context, e types.AuditEntry) (int, error)
+	GetAuditLog(ctx context.Context, entity string, entityID, limit int) ([]*types.AuditEntry, error)
+}
+
+/*
+SeedRepository keeps the versions of the seed files applied to the storage
+GetSeedVersion returns 0 for a seed file that was never applied
+*/
+type SeedRepository interface {
+	GetSeedVersion(ctx context.Context, name string) (int, error)
+	SetSeedVersion(ctx context.Context, name string, version int) error
+}
+
+/*
+AddressCheckRepository stores the last check of every specialist address against its reverse geocoded location
+SaveAddressCheck of a check with the filled status also fills in the address of the specialist,
+unless it changed since it was read or is overridden by an admin
+*/
+type AddressCheckRepository interface {
+	GetSpecialistsForAddressCheck(ctx context.Context, interval time.Duration, limit int) ([]*types.Specialist, error)
+	SaveAddressCheck(ctx context.Context, check types.AddressCheck) error
+	GetAddressChecks(ctx context.Context, status string) ([]*types.AddressCheck, error)
+}
+
+var (
+	_ SpecialistRepository   = (*DBModel)(nil)
+	_ SpecialtyRepository    = (*DBModel)(nil)
+	_ ReviewRepository       = (*DBModel)(nil)
+	_ AuditRepository        = (*DBModel)(nil)
+	_ SymptomRepository      = (*DBModel)(nil)
+	_ HolidayRepository      = (*DBModel)(nil)
+	_ SeedRepository         = (*DBModel)(nil)
+	_ AddressCheckRepository = (*DBModel)(nil)
+	_ SpecialistRepository   = (*MemoryModel)(nil)
+	_ SpecialtyRepository    = (*MemoryModel)(nil)
+	_ ReviewRepository       = (*MemoryModel)(nil)
+	_ AuditRepository        = (*MemoryModel)(nil)
+	_ SymptomRepository      = (*MemoryModel)(nil)
+	_ HolidayRepository      = (*MemoryModel)(nil)
+	_ SeedRepository         = (*MemoryModel)(nil)
+	_ AddressCheckRepository = (*MemoryModel)(nil)
+)
@@ -1,37 +1,32 @@
 package models
 
-import "github.com/acornak/healthcare-poc/types"
+import (
+	"context"
+	"database/sql"
+
+	"github.com/acornak/healthcare-poc/types"
+)
 
 /*
 AllReviews returns all reviews from the database
 The function returns a slice of pointers to Review structs
 The function returns an error if there was an issue with the database
 */
-func (m *DBModel) AllReviews() ([]*types.Review, error) {
+func (m *DBModel) AllReviews(ctx context.Context) ([]*types.Review, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
-	SELECT id, specialist_id, url, rating, comment
-	FROM review
+	SELECT ` + reviewColumns + `
+	FROM review r
 	`
 
-	rows, err := m.DB.Query(stmt)
+	rows, err := m.DB.QueryContext(ctx, stmt)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var reviews []*types.Review
-
-	for rows.Next() {
-		var s types.Review
-		rows.Scan(&s.ID, &s.SpecialistId, &s.Url, &s.Rating, &s.Comment)
-		reviews = append(reviews, &s)
-	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
-
-	return reviews, nil
+	return scanAll(rows, scanReview)
 }
 
 /*
@@ -40,79 +35,115 @@ The id is the id of the review
 The function returns a pointer to a Review struct
 The function returns an error if there was an issue with the database
 */
-func (m *DBModel) GetReviewBySpecialistId(id int) (*types.Review, error) {
+func (m *DBModel) GetReviewBySpecialistId(ctx context.Context, id int) (*types.Review, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
-	SELECT id, specialist_id, url, rating, comment
-	FROM review
-	WHERE specialist_id=$1
+	SELECT ` + reviewColumns + `
+	FROM review r
+	WHERE r.specialist_id=$1
 	`
 
-	row := m.DB.QueryRow(stmt, id)
+	return scanReview(m.DB.QueryRowContext(ctx, stmt, id))
+}
+
+/*
+GetReviewByID returns a review from the database with a specific id
+The id is the id of the review
+The function returns a pointer to a Review struct, nil if there is no review with the id
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetReviewByID(ctx context.Context, id int) (*types.Review, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT ` + reviewColumns + `
+	FROM review r
+	WHERE r.id=$1
+	`
 
-	var r types.Review
-	err := row.Scan(&r.ID, &r.SpecialistId, &r.Url, &r.Rating, &r.Comment)
+	r, err := scanReview(m.DB.QueryRowContext(ctx, stmt, id))
 	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
 		return nil, err
 	}
 
-	return &r, nil
+	return r, nil
 }
 
 /*
-GetReviewByID returns a review from the database with a specific id
-The id is the id of the review
-The function returns a pointer to a Review struct
+InsertReview inserts a review into the database
+The r parameter is a Review struct
+The function returns the id of the new review
 The function returns an error if there was an issue with the database
 */
-func (m *DBModel) InsertReview(r types.Review) error {
+func (m *DBModel) InsertReview(ctx context.Context, r types.Review) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
 	INSERT INTO review (specialist_id, url, rating, comment)
 	VALUES ($1, $2, $3, $4)
+	RETURNING id
 	`
 
-	_, err := m.DB.Exec(stmt, r.SpecialistId, r.Url, r.Rating, r.Comment)
-	if err != nil {
-		return err
-	}
+	return m.audited(ctx, func(db dbExecutor) (int, error) {
+		var id int
+		err := db.QueryRowContext(ctx, stmt, r.SpecialistId, r.Url, r.Rating, r.Comment).Scan(&id)
 
-	return nil
+		return id, err
+	})
 }
 
 /*
 DeleteReview deletes a review from the database with a specific id
 The id is the id of the review
+The function returns ErrNotFound if the review does not exist
 The function returns an error if there was an issue with the database
 */
-func (m *DBModel) DeleteReview(id int) error {
+func (m *DBModel) DeleteReview(ctx context.Context, id int) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
 	DELETE FROM review
 	WHERE id = $1
 	`
 
-	_, err := m.DB.Exec(stmt, id)
-	if err != nil {
-		return err
-	}
+	_, err := m.audited(ctx, func(db dbExecutor) (int, error) {
+		res, err := db.ExecContext(ctx, stmt, id)
+
+		return id, changedRow(res, err)
+	})
 
-	return nil
+	return err
 }
 
 /*
 UpdateReview updates a review in the database
 The r parameter is a Review struct
+The function returns ErrNotFound if the review does not exist
 The function returns an error if there was an issue with the database
 */
-func (m *DBModel) UpdateReview(r types.Review) error {
+func (m *DBModel) UpdateReview(ctx context.Context, r types.Review) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
 	UPDATE review
 	SET specialist_id=$1, url=$2, rating=$3, comment=$4
 	WHERE id=$5
 	`
 
-	_, err := m.DB.Exec(stmt, r.SpecialistId, r.Url, r.Rating, r.Comment, r.ID)
-	if err != nil {
-		return err
-	}
+	_, err := m.audited(ctx, func(db dbExecutor) (int, error) {
+		res, err := db.ExecContext(ctx, stmt, r.SpecialistId, r.Url, r.Rating, r.Comment, r.ID)
+
+		return r.ID, changedRow(res, err)
+	})
 
-	return nil
+	return err
 }
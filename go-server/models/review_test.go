@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -19,7 +20,7 @@ func TestAllReviews_SqlError(t *testing.T) {
 	mock.ExpectQuery(`SELECT (.+) FROM review`).WithoutArgs().WillReturnError(errors.New("mocked error"))
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.AllReviews()
+	res, err := modelsDB.DB.AllReviews(context.Background())
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -40,7 +41,7 @@ func TestAllReviews_RowsScanError(t *testing.T) {
 	mock.ExpectQuery("SELECT (.+) FROM review").WithoutArgs().WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.AllReviews()
+	res, err := modelsDB.DB.AllReviews(context.Background())
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "row scan error")
@@ -60,7 +61,7 @@ func TestAllReviews_Success(t *testing.T) {
 	mock.ExpectQuery("SELECT (.+) FROM review").WithoutArgs().WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.AllReviews()
+	res, err := modelsDB.DB.AllReviews(context.Background())
 
 	expected := []*types.Review{
 		{
@@ -84,10 +85,10 @@ func TestGetReviewBySpecialistId_SqlError(t *testing.T) {
 	}
 	defer db.Close()
 
-	mock.ExpectQuery(`SELECT (.+) FROM review WHERE specialist_id=\$1`).WithArgs(1).WillReturnError(errors.New("mocked error"))
+	mock.ExpectQuery(`SELECT (.+) FROM review r WHERE r.specialist_id=\$1`).WithArgs(1).WillReturnError(errors.New("mocked error"))
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetReviewBySpecialistId(1)
+	res, err := modelsDB.DB.GetReviewBySpecialistId(context.Background(), 1)
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -104,10 +105,10 @@ func TestGetReviewBySpecialistId_Success(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id", "specialist_id", "url", "rating", "comment"}).AddRow(1, 1, "test", 4.5, "test")
 
-	mock.ExpectQuery(`SELECT (.+) FROM review WHERE specialist_id=\$1`).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT (.+) FROM review r WHERE r.specialist_id=\$1`).WithArgs(1).WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetReviewBySpecialistId(1)
+	res, err := modelsDB.DB.GetReviewBySpecialistId(context.Background(), 1)
 
 	expected := &types.Review{
 		ID:           1,
@@ -136,10 +137,10 @@ func TestInsertReview_SqlError(t *testing.T) {
 		Comment:      "test",
 	}
 
-	mock.ExpectExec("INSERT INTO review").WithArgs(r.SpecialistId, r.Url, r.Rating, r.Comment).WillReturnError(errors.New("mocked error"))
+	mock.ExpectQuery("INSERT INTO review (.+) RETURNING id").WithArgs(r.SpecialistId, r.Url, r.Rating, r.Comment).WillReturnError(errors.New("mocked error"))
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.InsertReview(r)
+	_, err = modelsDB.DB.InsertReview(context.Background(), r)
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -160,12 +161,13 @@ func TestInsertReview_Success(t *testing.T) {
 		Comment:      "test",
 	}
 
-	mock.ExpectExec("INSERT INTO review").WithArgs(r.SpecialistId, r.Url, r.Rating, r.Comment).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO review (.+) RETURNING id").WithArgs(r.SpecialistId, r.Url, r.Rating, r.Comment).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(3))
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.InsertReview(r)
+	id, err := modelsDB.DB.InsertReview(context.Background(), r)
 
 	assert.NoError(t, err)
+	assert.Equal(t, 3, id)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -179,7 +181,7 @@ func TestDeleteReview_SqlError(t *testing.T) {
 	mock.ExpectExec("DELETE FROM review").WithArgs(1).WillReturnError(errors.New("mocked error"))
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.DeleteReview(1)
+	err = modelsDB.DB.DeleteReview(context.Background(), 1)
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -196,7 +198,7 @@ func TestDeleteReview_Success(t *testing.T) {
 	mock.ExpectExec("DELETE FROM review").WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.DeleteReview(1)
+	err = modelsDB.DB.DeleteReview(context.Background(), 1)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -219,7 +221,7 @@ func TestUpdateReview_SqlError(t *testing.T) {
 	mock.ExpectExec(`UPDATE review SET specialist_id=\$1, url=\$2, rating=\$3, comment=\$4 WHERE id=\$5`).WithArgs(r.SpecialistId, r.Url, r.Rating, r.Comment, r.ID).WillReturnError(errors.New("mocked error"))
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.UpdateReview(r)
+	err = modelsDB.DB.UpdateReview(context.Background(), r)
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -243,8 +245,66 @@ func TestUpdateReview_Success(t *testing.T) {
 	mock.ExpectExec(`UPDATE review SET specialist_id=\$1, url=\$2, rating=\$3, comment=\$4 WHERE id=\$5`).WithArgs(r.SpecialistId, r.Url, r.Rating, r.Comment, r.ID).WillReturnResult(sqlmock.NewResult(1, 1))
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.UpdateReview(r)
+	err = modelsDB.DB.UpdateReview(context.Background(), r)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestUpdateReview_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	r := types.Review{ID: 42, SpecialistId: 1, Url: "test", Rating: 4.5}
+
+	mock.ExpectExec(`UPDATE review`).WithArgs(r.SpecialistId, r.Url, r.Rating, r.Comment, r.ID).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	modelsDB := NewModels(db)
+	err = modelsDB.DB.UpdateReview(context.Background(), r)
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteReview_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("DELETE FROM review").WithArgs(42).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	modelsDB := NewModels(db)
+	err = modelsDB.DB.DeleteReview(context.Background(), 42)
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetReviewByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM review r WHERE r.id=\$1`).WithArgs(2).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "specialist_id", "url", "rating", "comment"}).AddRow(2, 1, "test", 4.5, nil))
+	mock.ExpectQuery(`SELECT (.+) FROM review r WHERE r.id=\$1`).WithArgs(42).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "specialist_id", "url", "rating", "comment"}))
+
+	modelsDB := NewModels(db)
+
+	res, err := modelsDB.DB.GetReviewByID(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Equal(t, &types.Review{ID: 2, SpecialistId: 1, Url: "test", Rating: 4.5}, res)
+
+	missing, err := modelsDB.DB.GetReviewByID(context.Background(), 42)
+	assert.NoError(t, err)
+	assert.Nil(t, missing)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
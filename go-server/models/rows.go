@@ -0,0 +1,126 @@
+package models
+
+import (
+	"database/sql"
+
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/lib/pq"
+)
+
+// rowScanner is a single result row, either *sql.Row or *sql.Rows
+type rowScanner interface {
+	Scan(dest ...any) error
+}
+
+/*
+The column lists of the row mappers below, every query reading the entity selects them first
+The tables are expected under the aliases s (specialist), sp (specialty) and r (review)
+*/
+const (
+	specialistColumns = `s.id, s.name, s.specialty_id, ST_AsText(s.location), s.address, s.url, s.telephone, s.email,
+		s.monday, s.tuesday, s.wednesday, s.thursday, s.friday, s.saturday, s.sunday, s.staff, s.version,
+		(SELECT array_agg(o.field ORDER BY o.field) FROM specialist_override o WHERE o.specialist_id = s.id)`
+	specialtyColumns = `sp.id, sp.name, sp.description`
+	reviewColumns    = `r.id, r.specialist_id, r.url, r.rating, r.comment`
+)
+
+/*
+scanSpecialist maps a row starting with specialistColumns to a Specialist
+The nullable columns are read as empty strings (0 for a missing specialty), the overridden fields fill in the field sources,
+extra receives the columns selected after them
+The function returns the scan error, e.g. sql.ErrNoRows for an empty *sql.Row
+*/
+func scanSpecialist(row rowScanner, extra ...any) (*types.Specialist, error) {
+	var s types.Specialist
+	var specialtyID sql.NullInt64
+	var overridden pq.StringArray
+
+	nullable := []*string{&s.Location, &s.Address, &s.Url, &s.Telephone, &s.Email, &s.Monday, &s.Tuesday, &s.Wednesday, &s.Thursday, &s.Friday, &s.Saturday, &s.Sunday, &s.Staff}
+	values := make([]sql.NullString, len(nullable))
+
+	dest := []any{&s.ID, &s.Name, &specialtyID}
+	for i := range values {
+		dest = append(dest, &values[i])
+	}
+	dest = append(dest, &s.Version, &overridden)
+
+	if err := row.Scan(append(dest, extra...)...); err != nil {
+		return nil, err
+	}
+
+	s.SpecialtyID = int(specialtyID.Int64)
+	for i, value := range values {
+		*nullable[i] = value.String
+	}
+	s.FieldSources = types.NewFieldSources(overridden)
+
+	return &s, nil
+}
+
+// scanSpecialty maps a row of specialtyColumns to a Specialty
+func scanSpecialty(row rowScanner) (*types.Specialty, error) {
+	var s types.Specialty
+	if err := row.Scan(&s.ID, &s.Name, &s.Description); err != nil {
+		return nil, err
+	}
+
+	return &s, nil
+}
+
+// scanReview maps a row of reviewColumns to a Review, a missing specialist and comment are read as 0 and an empty string
+func scanReview(row rowScanner) (*types.Review, error) {
+	var r types.Review
+	var specialistID sql.NullInt64
+	var comment sql.NullString
+
+	if err := row.Scan(&r.ID, &specialistID, &r.Url, &r.Rating, &comment); err != nil {
+		return nil, err
+	}
+
+	r.SpecialistId = int(specialistID.Int64)
+	r.Comment = comment.String
+
+	return &r, nil
+}
+
+/*
+scanAll maps every row with scan and closes the rows
+The function returns the first scan error or the error of the iteration, no partial result is returned
+*/
+func scanAll[T any](rows *sql.Rows, scan func(rowScanner) (*T, error)) ([]*T, error) {
+	defer rows.Close()
+
+	var items []*T
+
+	for rows.Next() {
+		item, err := scan(rows)
+		if err != nil {
+			return nil, err
+		}
+		items = append(items, item)
+	}
+
+	if err := rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return items, nil
+}
+
+// changedRow returns the error of a statement changing a single row, ErrNotFound when the row does not exist
+func changedRow(res sql.Result, err error) error {
+	if err != nil {
+		return err
+	}
+
+	changed, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if changed == 0 {
+		return ErrNotFound
+	}
+
+	return nil
+}
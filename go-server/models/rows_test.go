@@ -0,0 +1,142 @@
+package models
+
+import (
+	"context"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+)
+
+var specialistColumnNames = []string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"}
+
+func TestGetAllSpecialists_NullColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows(specialistColumnNames).
+		AddRow(1, "Ambulancia", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 1, nil).
+		AddRow(2, "Kardio Košice", 3, "POINT(21.25 48.72)", "Hlavná 1", nil, "055/123", nil, "7:00 - 12:00", nil, nil, nil, nil, nil, nil, "MUDr. Ján Novák", 4, nil)
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s`).WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetAllSpecialists(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Specialist{
+		{ID: 1, Name: "Ambulancia", Version: 1, FieldSources: types.NewFieldSources(nil)},
+		{ID: 2, Name: "Kardio Košice", SpecialtyID: 3, Location: "POINT(21.25 48.72)", Address: "Hlavná 1", Telephone: "055/123", Monday: "7:00 - 12:00", Staff: "MUDr. Ján Novák", Version: 4, FieldSources: types.NewFieldSources(nil)},
+	}, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllSpecialists_ScanError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows(specialistColumnNames).
+		AddRow(1, "Ambulancia", 1, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 1, nil).
+		AddRow("two", "Ambulancia", 1, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 1, nil)
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s`).WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetAllSpecialists(context.Background())
+
+	assert.ErrorContains(t, err, "converting driver.Value type string (\"two\") to a int")
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistProfile_NullColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	columns := append(append([]string{}, specialistColumnNames...), "insurers", "specialty_name", "updated_at", "count", "average_rating")
+	rows := sqlmock.NewRows(columns).
+		AddRow(1, "Ambulancia", nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, nil, 2, nil, "{}", "", memoryUpdatedAt, 0, 0.0)
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s (.+) WHERE s.id=`).WithArgs(1).WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialistProfile(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Equal(t, &types.Specialist{ID: 1, Name: "Ambulancia", Insurers: []string{}, Version: 2, FieldSources: types.NewFieldSources(nil)}, res.Specialist)
+	assert.Equal(t, memoryUpdatedAt, res.UpdatedAt)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllSpecialties_ScanError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "kardiológia", nil)
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp`).WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetAllSpecialties(context.Background())
+
+	assert.ErrorContains(t, err, "converting NULL to string is unsupported")
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAllReviews_NullColumns(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "specialist_id", "url", "rating", "comment"}).
+		AddRow(1, nil, "https://example.com", 4.5, nil).
+		AddRow(2, 3, "https://example.com/2", 2.0, "dlho sa čaká")
+
+	mock.ExpectQuery(`SELECT (.+) FROM review r`).WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.AllReviews(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Review{
+		{ID: 1, Url: "https://example.com", Rating: 4.5},
+		{ID: 2, SpecialistId: 3, Url: "https://example.com/2", Rating: 2, Comment: "dlho sa čaká"},
+	}, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestAllReviews_ScanError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "specialist_id", "url", "rating", "comment"}).
+		AddRow(1, 1, "https://example.com", "excellent", nil)
+
+	mock.ExpectQuery(`SELECT (.+) FROM review r`).WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.AllReviews(context.Background())
+
+	assert.ErrorContains(t, err, "converting driver.Value type string (\"excellent\") to a float64")
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,37 +1,36 @@
 package models
 
-import "github.com/acornak/healthcare-poc/types"
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/lib/pq"
+)
 
 /*
 GetAllSpecialists returns all specialists from the database
 The function returns a slice of pointers to Specialist structs
 The function returns an error if there was an issue with the database
 */
-func (m *DBModel) GetAllSpecialists() ([]*types.Specialist, error) {
+func (m *DBModel) GetAllSpecialists(ctx context.Context) ([]*types.Specialist, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
-	SELECT id, name, specialty_id, location, address, url, telephone, email, monday, tuesday, wednesday, thursday, friday, saturday, sunday
-	FROM specialist
+	SELECT ` + specialistColumns + `
+	FROM specialist s
 	`
 
-	rows, err := m.DB.Query(stmt)
+	rows, err := m.DB.QueryContext(ctx, stmt)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
-
-	var specialists []*types.Specialist
-
-	for rows.Next() {
-		var s types.Specialist
-		rows.Scan(&s.ID, &s.Name, &s.SpecialtyID, &s.Location, &s.Address, &s.Url, &s.Telephone, &s.Email, &s.Monday, &s.Tuesday, &s.Wednesday, &s.Thursday, &s.Friday, &s.Saturday, &s.Sunday)
-		specialists = append(specialists, &s)
-	}
-
-	if err = rows.Err(); err != nil {
-		return nil, err
-	}
 
-	return specialists, nil
+	return scanAll(rows, func(row rowScanner) (*types.Specialist, error) {
+		return scanSpecialist(row)
+	})
 }
 
 /*
@@ -40,86 +39,125 @@ The specialtyID is the id of the specialty
 The function returns a slice of pointers to Specialist structs
 The function returns an error if there was an issue with the database
 */
-func (m *DBModel) GetSpecialistBySpecialty(specialtyID int) ([]*types.Specialist, error) {
+func (m *DBModel) GetSpecialistBySpecialty(ctx context.Context, specialtyID int) ([]*types.Specialist, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
-	SELECT id, name, specialty_id, location, address, url, telephone, email, monday, tuesday, wednesday, thursday, friday, saturday, sunday
-	FROM specialist
-	WHERE specialty_id=$1
+	SELECT ` + specialistColumns + `
+	FROM specialist s
+	WHERE s.specialty_id=$1
 	`
 
-	rows, err := m.DB.Query(stmt, specialtyID)
+	rows, err := m.DB.QueryContext(ctx, stmt, specialtyID)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var specialists []*types.Specialist
+	return scanAll(rows, func(row rowScanner) (*types.Specialist, error) {
+		return scanSpecialist(row)
+	})
+}
 
-	for rows.Next() {
-		var s types.Specialist
-		rows.Scan(&s.ID, &s.Name, &s.SpecialtyID, &s.Location, &s.Address, &s.Url, &s.Telephone, &s.Email, &s.Monday, &s.Tuesday, &s.Wednesday, &s.Thursday, &s.Friday, &s.Saturday, &s.Sunday)
-		specialists = append(specialists, &s)
-	}
+/*
+GetSpecialistByID returns a specialist from the database with a specific id
+The id is the id of the specialist
+The function returns a pointer to a Specialist struct
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetSpecialistByID(ctx context.Context, id int) (*types.Specialist, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
 
-	if err = rows.Err(); err != nil {
+	stmt := `
+	SELECT ` + specialistColumns + `
+	FROM specialist s
+	WHERE s.id=$1
+	`
+
+	row := m.DB.QueryRowContext(ctx, stmt, id)
+
+	s, err := scanSpecialist(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
 		return nil, err
 	}
 
-	return specialists, nil
+	return s, nil
 }
 
 /*
-GetSpecialistByID returns a specialist from the database with a specific id
+GetSpecialistProfile returns the full profile of a specialist with a specific id
 The id is the id of the specialist
-The function returns a pointer to a Specialist struct
+The profile contains the specialist with its insurers, the specialty name, the review summary and the last update time
+The location is returned in the WKT format, opening hours and navigation links are left for the caller to derive
+The function returns a pointer to a SpecialistProfile struct, nil if the specialist does not exist
 The function returns an error if there was an issue with the database
 */
-func (m *DBModel) GetSpecialistByID(id int) (*types.Specialist, error) {
+func (m *DBModel) GetSpecialistProfile(ctx context.Context, id int) (*types.SpecialistProfile, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
-	SELECT id, name, specialty_id, location, address, url, telephone, email, monday, tuesday, wednesday, thursday, friday, saturday, sunday
-	FROM specialist
-	WHERE id=$1
+	SELECT ` + specialistColumns + `, s.insurers, coalesce(sp.name, ''), s.updated_at, r.count, r.average_rating
+	FROM specialist s
+	LEFT JOIN specialty sp ON sp.id = s.specialty_id
+	CROSS JOIN LATERAL (
+		SELECT count(*) AS count, coalesce(avg(rating), 0) AS average_rating
+		FROM review
+		WHERE specialist_id = s.id
+	) r
+	WHERE s.id=$1
 	`
 
-	row := m.DB.QueryRow(stmt, id)
+	row := m.DB.QueryRowContext(ctx, stmt, id)
 
-	var s types.Specialist
-	err := row.Scan(&s.ID, &s.Name, &s.SpecialtyID, &s.Location, &s.Address, &s.Url, &s.Telephone, &s.Email, &s.Monday, &s.Tuesday, &s.Wednesday, &s.Thursday, &s.Friday, &s.Saturday, &s.Sunday)
+	var p types.SpecialistProfile
+	var insurers pq.StringArray
+	s, err := scanSpecialist(row, &insurers, &p.SpecialtyName, &p.UpdatedAt, &p.Reviews.Count, &p.Reviews.AverageRating)
 	if err != nil {
-		if err.Error() == "sql: no rows in result set" {
+		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
 	}
 
-	return &s, nil
+	s.Insurers = []string(insurers)
+	p.Specialist = s
+
+	return &p, nil
 }
 
 /*
 GetSpecialistByName returns a specialist from the database with a specific name
-The name is the name of the specialist
+The name is the name of the specialist as published by the source, an overridden name is matched by its source value
 The function returns a pointer to a Specialist struct
 The function returns an error if there was an issue with the database
 */
-func (m *DBModel) GetSpecialistByName(name string) (*types.Specialist, error) {
+func (m *DBModel) GetSpecialistByName(ctx context.Context, name string) (*types.Specialist, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
-	SELECT id, name, specialty_id, location, address, url, telephone, email, monday, tuesday, wednesday, thursday, friday, saturday, sunday
-	FROM specialist
-	WHERE name=$1
+	SELECT ` + specialistColumns + `
+	FROM specialist s
+	LEFT JOIN specialist_override o ON o.specialist_id = s.id AND o.field = 'name'
+	WHERE coalesce(o.source_value, s.name)=$1
 	`
 
-	row := m.DB.QueryRow(stmt, name)
+	row := m.DB.QueryRowContext(ctx, stmt, name)
 
-	var s types.Specialist
-	err := row.Scan(&s.ID, &s.Name, &s.SpecialtyID, &s.Location, &s.Address, &s.Url, &s.Telephone, &s.Email, &s.Monday, &s.Tuesday, &s.Wednesday, &s.Thursday, &s.Friday, &s.Saturday, &s.Sunday)
+	s, err := scanSpecialist(row)
 	if err != nil {
-		if err.Error() == "sql: no rows in result set" {
+		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
 	}
 
-	return &s, nil
+	return s, nil
 }
 
 /*
@@ -130,80 +168,284 @@ The userLocation is the location in WKT format
 The function returns a slice of pointers to Specialist structs
 The function returns an error if there was an issue with the database
 */
-func (m *DBModel) GetSpecialistBySpecialtyAndLocation(specialtyID, radius int, userLocation string) ([]*types.Specialist, error) {
+func (m *DBModel) GetSpecialistBySpecialtyAndLocation(ctx context.Context, specialtyID, radius int, userLocation string) ([]*types.Specialist, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
-	SELECT id, name, specialty_id, location, address, url, telephone, email, monday, tuesday, wednesday, thursday, friday, saturday, sunday
-    FROM specialist 
-    WHERE specialty_id=$1 AND ST_DWithin(location, ST_GeogFromText($2), $3)
-    `
+	SELECT ` + specialistColumns + `
+	FROM specialist s
+	WHERE s.specialty_id=$1 AND ST_DWithin(s.location, ST_GeogFromText($2), $3)
+	`
 
-	rows, err := m.DB.Query(stmt, specialtyID, userLocation, radius)
+	rows, err := m.DB.QueryContext(ctx, stmt, specialtyID, userLocation, radius)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var specialists []*types.Specialist
+	return scanAll(rows, func(row rowScanner) (*types.Specialist, error) {
+		return scanSpecialist(row)
+	})
+}
 
-	for rows.Next() {
-		var s types.Specialist
-		rows.Scan(&s.ID, &s.Name, &s.SpecialtyID, &s.Location, &s.Address, &s.Url, &s.Telephone, &s.Email, &s.Monday, &s.Tuesday, &s.Wednesday, &s.Thursday, &s.Friday, &s.Saturday, &s.Sunday)
-		specialists = append(specialists, &s)
+/*
+InsertSpecialist inserts a new specialist into the database
+The s parameter is a Specialist struct
+The function returns the id of the new specialist
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) InsertSpecialist(ctx context.Context, s types.Specialist) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	INSERT INTO specialist (name, specialty_id, location, address, url, telephone, email, monday, tuesday, wednesday, thursday, friday, saturday, sunday, staff, insurers, kpzs)
+	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14, $15, $16, $17)
+	RETURNING id
+	`
+
+	// a nil slice would be stored as NULL
+	insurers := s.Insurers
+	if insurers == nil {
+		insurers = []string{}
 	}
 
-	if err = rows.Err(); err != nil {
-		return nil, err
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := setActor(ctx, tx); err != nil {
+		return 0, err
 	}
 
-	return specialists, nil
+	var id int
+	err = tx.QueryRowContext(ctx, stmt, s.Name, s.SpecialtyID, s.Location, s.Address, s.Url, s.Telephone, s.Email, s.Monday, s.Tuesday, s.Wednesday, s.Thursday, s.Friday, s.Saturday, s.Sunday, s.Staff, pq.Array(insurers), s.KPZS).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	if err := insertAudit(ctx, tx, id); err != nil {
+		return 0, err
+	}
+
+	return id, tx.Commit()
 }
 
 /*
-InsertSpecialist inserts a new specialist into the database
-The s parameter is a Specialist struct
-The function returns an error if there was an issue with the database
+DeleteSpecialist deletes a specialist with a specific id and version
+The version is the version of the specialist the caller read
+The source name of the specialist and the names merged into it are kept as tombstones, so the scraper does not insert it again
+The function returns ErrNotFound if the specialist does not exist and ErrStaleVersion if it was changed since
+The function returns an error if there was an issue with the database, e.g. the specialist still has reviews
 */
-func (m *DBModel) InsertSpecialist(s types.Specialist) error {
-	stmt := `
-	INSERT INTO specialist (name, specialty_id, location, address, url, telephone, email, monday, tuesday, wednesday, thursday, friday, saturday, sunday)
-	VALUES ($1, $2, $3, $4, $5, $6, $7, $8, $9, $10, $11, $12, $13, $14)
+func (m *DBModel) DeleteSpecialist(ctx context.Context, id, version int) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	tombstoneStmt := `
+	INSERT INTO specialist_tombstone (name, specialist_id, actor)
+	SELECT n.name, $1, $3
+	FROM (
+		SELECT coalesce(o.source_value, s.name) AS name
+		FROM specialist s
+		LEFT JOIN specialist_override o ON o.specialist_id = s.id AND o.field = 'name'
+		WHERE s.id=$1 AND s.version=$2
+		UNION
+		SELECT a.name
+		FROM specialist_alias a
+		JOIN specialist s ON s.id = a.specialist_id
+		WHERE s.id=$1 AND s.version=$2
+	) n
+	ON CONFLICT (name) DO UPDATE SET specialist_id=EXCLUDED.specialist_id, actor=EXCLUDED.actor, deleted_at=now()
 	`
 
-	_, err := m.DB.Exec(stmt, s.Name, s.SpecialtyID, s.Location, s.Address, s.Url, s.Telephone, s.Email, s.Monday, s.Tuesday, s.Wednesday, s.Thursday, s.Friday, s.Saturday, s.Sunday)
+	stmt := `DELETE FROM specialist WHERE id=$1 AND version=$2`
+
+	tx, err := m.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	return nil
-}
+	if err := setActor(ctx, tx); err != nil {
+		return err
+	}
 
-func (m *DBModel) DeleteSpecialist(id int) error {
-	stmt := `DELETE FROM specialist WHERE id=$1`
+	if _, err := tx.ExecContext(ctx, tombstoneStmt, id, version, ActorFromContext(ctx)); err != nil {
+		return err
+	}
 
-	_, err := m.DB.Exec(stmt, id)
+	res, err := tx.ExecContext(ctx, stmt, id, version)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return specialistVersionError(ctx, tx, id)
+	}
+
+	if err := insertAudit(ctx, tx, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 /*
-UpdateSpecialist updates a specialist in the database
-The s parameter is a Specialist struct
+UpdateSpecialist updates a specialist in the database and bumps its version
+The s parameter is a Specialist struct, its Version is the version of the specialist the caller read
+The KPZS code comes from the source and is kept
+The function returns the new version of the specialist
+The function returns ErrNotFound if the specialist does not exist and ErrStaleVersion if it was changed since
 The function returns an error if there was an issue with the database
 */
-func (m *DBModel) UpdateSpecialist(s types.Specialist) error {
+func (m *DBModel) UpdateSpecialist(ctx context.Context, s types.Specialist) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
 	UPDATE specialist
-	SET name=$1, specialty_id=$2, location=$3, address=$4, url=$5, telephone=$6, email=$7, monday=$8, tuesday=$9, wednesday=$10, thursday=$11, friday=$12, saturday=$13, sunday=$14
-	WHERE id=$8
+	SET name=$1, specialty_id=$2, location=$3, address=$4, url=$5, telephone=$6, email=$7, monday=$8, tuesday=$9, wednesday=$10,
+		thursday=$11, friday=$12, saturday=$13, sunday=$14, staff=$15, insurers=$16, version=version+1, updated_at=now()
+	WHERE id=$17 AND version=$18
+	RETURNING version
 	`
 
-	_, err := m.DB.Exec(stmt, s.Name, s.SpecialtyID, s.Location, s.Address, s.Url, s.Telephone, s.Email, s.ID, s.Monday, s.Tuesday, s.Wednesday, s.Thursday, s.Friday, s.Saturday, s.Sunday)
+	// a nil slice would be stored as NULL
+	insurers := s.Insurers
+	if insurers == nil {
+		insurers = []string{}
+	}
+
+	tx, err := m.DB.BeginTx(ctx, nil)
+	if err != nil {
+		return 0, err
+	}
+	defer tx.Rollback()
+
+	if err := setActor(ctx, tx); err != nil {
+		return 0, err
+	}
+
+	var version int
+	err = tx.QueryRowContext(ctx, stmt, s.Name, s.SpecialtyID, s.Location, s.Address, s.Url, s.Telephone, s.Email, s.Monday, s.Tuesday, s.Wednesday, s.Thursday, s.Friday, s.Saturday, s.Sunday, s.Staff, pq.Array(insurers), s.ID, s.Version).Scan(&version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, specialistVersionError(ctx, tx, s.ID)
+		}
+		return 0, err
+	}
+
+	if err := insertAudit(ctx, tx, s.ID); err != nil {
+		return 0, err
+	}
+
+	return version, tx.Commit()
+}
+
+// specialistVersionError tells apart a missing specialist from a stale version once an update or delete matched no row
+func specialistVersionError(ctx context.Context, tx *sql.Tx, id int) error {
+	var exists bool
+	err := tx.QueryRowContext(ctx, `SELECT EXISTS (SELECT 1 FROM specialist WHERE id=$1)`, id).Scan(&exists)
 	if err != nil {
 		return err
 	}
 
-	return nil
+	if !exists {
+		return ErrNotFound
+	}
+
+	return ErrStaleVersion
+}
+
+/*
+SearchSpecialists returns specialists matching the search terms ordered by relevance
+The terms are folded (lower case, without diacritics) search words
+Every term is compared by trigram word similarity against the unaccented clinic name, staff, address and specialty
+The rank of a specialist is the average similarity of all terms, specialists below minRank are skipped
+The search scans all specialists without a trigram index: an index only serves the %> and <% operators, not word_similarity
+in an average, and the rows searched are the specialists of one region, a few thousand at most, so the scan stays cheap
+The limit is the maximum number of results
+The function returns a slice of pointers to SpecialistSearchResult structs
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) SearchSpecialists(ctx context.Context, terms []string, minRank float64, limit int) ([]*types.SpecialistSearchResult, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT ` + specialistColumns + `, sp.name, r.rank
+	FROM specialist s
+	JOIN specialty sp ON sp.id = s.specialty_id
+	CROSS JOIN LATERAL (
+		SELECT avg(word_similarity(t, unaccent(lower(coalesce(s.name, '') || ' ' || coalesce(s.staff, '') || ' ' || coalesce(s.address, '') || ' ' || sp.name)))) AS rank
+		FROM unnest($1::text[]) AS t
+	) r
+	WHERE r.rank >= $2
+	ORDER BY r.rank DESC, s.id
+	LIMIT $3
+	`
+
+	rows, err := m.DB.QueryContext(ctx, stmt, pq.Array(terms), minRank, limit)
+	if err != nil {
+		return nil, err
+	}
+
+	return scanAll(rows, func(row rowScanner) (*types.SpecialistSearchResult, error) {
+		var r types.SpecialistSearchResult
+		s, err := scanSpecialist(row, &r.SpecialtyName, &r.Rank)
+		if err != nil {
+			return nil, err
+		}
+		r.Specialist = s
+
+		return &r, nil
+	})
+}
+
+/*
+GetSpecialistPlaces returns the addresses and coordinates of all specialists with a known location
+It is used to build the offline gazetteer
+The function returns a slice of pointers to Place structs
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetSpecialistPlaces(ctx context.Context) ([]*types.Place, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT address, ST_Y(location::geometry), ST_X(location::geometry)
+	FROM specialist
+	WHERE location IS NOT NULL AND coalesce(address, '') <> ''
+	`
+
+	rows, err := m.DB.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var places []*types.Place
+
+	for rows.Next() {
+		p := types.Place{Type: types.PlaceTypeHouse}
+		err := rows.Scan(&p.DisplayName, &p.Lat, &p.Lon)
+		if err != nil {
+			return nil, err
+		}
+		places = append(places, &p)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return places, nil
 }
@@ -1,11 +1,14 @@
 package models
 
 import (
+	"context"
 	"errors"
 	"testing"
+	"time"
 
 	sqlmock "github.com/DATA-DOG/go-sqlmock"
 	"github.com/acornak/healthcare-poc/types"
+	"github.com/lib/pq"
 	"github.com/stretchr/testify/assert"
 )
 
@@ -19,7 +22,7 @@ func TestGetAllSpecialists_SqlError(t *testing.T) {
 	mock.ExpectQuery("SELECT (.+) FROM specialist").WithoutArgs().WillReturnError(errors.New("mocked error"))
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetAllSpecialists()
+	res, err := modelsDB.DB.GetAllSpecialists(context.Background())
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -34,14 +37,14 @@ func TestGetAllSpecialists_RowsScanError(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}).
-		AddRow(1, "John Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "")
+	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"}).
+		AddRow(1, "John Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "", "", 1, nil)
 	rows.RowError(0, errors.New("rows scan error"))
 
 	mock.ExpectQuery("SELECT (.+) FROM specialist").WithoutArgs().WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetAllSpecialists()
+	res, err := modelsDB.DB.GetAllSpecialists(context.Background())
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "rows scan error")
@@ -56,31 +59,33 @@ func TestGetAllSpecialists_Success(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}).
-		AddRow(1, "John Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "")
+	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"}).
+		AddRow(1, "John Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "", "", 1, nil)
 
 	mock.ExpectQuery("SELECT (.+) FROM specialist").WithoutArgs().WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetAllSpecialists()
+	res, err := modelsDB.DB.GetAllSpecialists(context.Background())
 
 	expected := []*types.Specialist{
 		{
-			ID:          1,
-			Name:        "John Doe",
-			SpecialtyID: 1,
-			Location:    "New York",
-			Address:     "123 Main St",
-			Url:         "https://example.com",
-			Telephone:   "123-456-7890",
-			Email:       "me@example.com",
-			Monday:      "7:00 - 12:00, 13:00 - 15:00",
-			Tuesday:     "7:00 - 12:00, 13:00 - 15:00",
-			Wednesday:   "7:00 - 12:00, 13:00 - 15:00",
-			Thursday:    "7:00 - 12:00, 13:00 - 15:00",
-			Friday:      "7:00 - 12:00, 13:00 - 15:00",
-			Saturday:    "",
-			Sunday:      "",
+			ID:           1,
+			Name:         "John Doe",
+			SpecialtyID:  1,
+			Location:     "New York",
+			Address:      "123 Main St",
+			Url:          "https://example.com",
+			Telephone:    "123-456-7890",
+			Email:        "me@example.com",
+			Monday:       "7:00 - 12:00, 13:00 - 15:00",
+			Tuesday:      "7:00 - 12:00, 13:00 - 15:00",
+			Wednesday:    "7:00 - 12:00, 13:00 - 15:00",
+			Thursday:     "7:00 - 12:00, 13:00 - 15:00",
+			Friday:       "7:00 - 12:00, 13:00 - 15:00",
+			Saturday:     "",
+			Sunday:       "",
+			Version:      1,
+			FieldSources: types.NewFieldSources(nil),
 		},
 	}
 
@@ -96,10 +101,10 @@ func TestGetSpecialistBySpecialty_SqlError(t *testing.T) {
 	}
 	defer db.Close()
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialist WHERE specialty_id=`).WithArgs(1).WillReturnError(errors.New("mocked error"))
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.specialty_id=`).WithArgs(1).WillReturnError(errors.New("mocked error"))
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialistBySpecialty(1)
+	res, err := modelsDB.DB.GetSpecialistBySpecialty(context.Background(), 1)
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -114,16 +119,16 @@ func TestGetSpecialistBySpecialty_RowsScanError(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}).
-		AddRow(1, "John Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "").
-		AddRow(2, "Jane Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "")
+	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"}).
+		AddRow(1, "John Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "", "", 1, nil).
+		AddRow(2, "Jane Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "", "", 1, nil)
 
 	rows.RowError(0, errors.New("rows scan error"))
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialist WHERE specialty_id=`).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.specialty_id=`).WithArgs(1).WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialistBySpecialty(1)
+	res, err := modelsDB.DB.GetSpecialistBySpecialty(context.Background(), 1)
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "rows scan error")
@@ -138,49 +143,53 @@ func TestGetSpecialistBySpecialty_Success(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}).
-		AddRow(1, "John Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "").
-		AddRow(2, "Jane Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "jane@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "")
+	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"}).
+		AddRow(1, "John Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "", "", 1, nil).
+		AddRow(2, "Jane Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "jane@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "", "", 1, nil)
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialist WHERE specialty_id=`).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.specialty_id=`).WithArgs(1).WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialistBySpecialty(1)
+	res, err := modelsDB.DB.GetSpecialistBySpecialty(context.Background(), 1)
 
 	expected := []*types.Specialist{
 		{
-			ID:          1,
-			Name:        "John Doe",
-			SpecialtyID: 1,
-			Location:    "New York",
-			Address:     "123 Main St",
-			Url:         "https://example.com",
-			Telephone:   "123-456-7890",
-			Email:       "me@example.com",
-			Monday:      "7:00 - 12:00, 13:00 - 15:00",
-			Tuesday:     "7:00 - 12:00, 13:00 - 15:00",
-			Wednesday:   "7:00 - 12:00, 13:00 - 15:00",
-			Thursday:    "7:00 - 12:00, 13:00 - 15:00",
-			Friday:      "7:00 - 12:00, 13:00 - 15:00",
-			Saturday:    "",
-			Sunday:      "",
+			ID:           1,
+			Name:         "John Doe",
+			SpecialtyID:  1,
+			Location:     "New York",
+			Address:      "123 Main St",
+			Url:          "https://example.com",
+			Telephone:    "123-456-7890",
+			Email:        "me@example.com",
+			Monday:       "7:00 - 12:00, 13:00 - 15:00",
+			Tuesday:      "7:00 - 12:00, 13:00 - 15:00",
+			Wednesday:    "7:00 - 12:00, 13:00 - 15:00",
+			Thursday:     "7:00 - 12:00, 13:00 - 15:00",
+			Friday:       "7:00 - 12:00, 13:00 - 15:00",
+			Saturday:     "",
+			Sunday:       "",
+			Version:      1,
+			FieldSources: types.NewFieldSources(nil),
 		},
 		{
-			ID:          2,
-			Name:        "Jane Doe",
-			SpecialtyID: 1,
-			Location:    "New York",
-			Address:     "123 Main St",
-			Url:         "https://example.com",
-			Telephone:   "123-456-7890",
-			Email:       "jane@example.com",
-			Monday:      "7:00 - 12:00, 13:00 - 15:00",
-			Tuesday:     "7:00 - 12:00, 13:00 - 15:00",
-			Wednesday:   "7:00 - 12:00, 13:00 - 15:00",
-			Thursday:    "7:00 - 12:00, 13:00 - 15:00",
-			Friday:      "7:00 - 12:00, 13:00 - 15:00",
-			Saturday:    "",
-			Sunday:      "",
+			ID:           2,
+			Name:         "Jane Doe",
+			SpecialtyID:  1,
+			Location:     "New York",
+			Address:      "123 Main St",
+			Url:          "https://example.com",
+			Telephone:    "123-456-7890",
+			Email:        "jane@example.com",
+			Monday:       "7:00 - 12:00, 13:00 - 15:00",
+			Tuesday:      "7:00 - 12:00, 13:00 - 15:00",
+			Wednesday:    "7:00 - 12:00, 13:00 - 15:00",
+			Thursday:     "7:00 - 12:00, 13:00 - 15:00",
+			Friday:       "7:00 - 12:00, 13:00 - 15:00",
+			Saturday:     "",
+			Sunday:       "",
+			Version:      1,
+			FieldSources: types.NewFieldSources(nil),
 		},
 	}
 
@@ -196,10 +205,10 @@ func TestGetSpecialistByID_SqlError(t *testing.T) {
 	}
 	defer db.Close()
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialist WHERE id=`).WithArgs(1).WillReturnError(errors.New("mocked error"))
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=`).WithArgs(1).WillReturnError(errors.New("mocked error"))
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialistByID(1)
+	res, err := modelsDB.DB.GetSpecialistByID(context.Background(), 1)
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -214,12 +223,12 @@ func TestGetSpecialistByID_NoRows(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"})
+	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"})
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialist WHERE id=`).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=`).WithArgs(1).WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialistByID(1)
+	res, err := modelsDB.DB.GetSpecialistByID(context.Background(), 1)
 
 	assert.NoError(t, err)
 	assert.Nil(t, res)
@@ -233,30 +242,107 @@ func TestGetSpecialistByID_Success(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}).
-		AddRow(1, "John Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "")
+	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"}).
+		AddRow(1, "John Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "", "", 1, nil)
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialist WHERE id=`).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=`).WithArgs(1).WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialistByID(1)
+	res, err := modelsDB.DB.GetSpecialistByID(context.Background(), 1)
 
 	expected := &types.Specialist{
-		ID:          1,
-		Name:        "John Doe",
-		SpecialtyID: 1,
-		Location:    "New York",
-		Address:     "123 Main St",
-		Url:         "https://example.com",
-		Telephone:   "123-456-7890",
-		Email:       "me@example.com",
-		Monday:      "7:00 - 12:00, 13:00 - 15:00",
-		Tuesday:     "7:00 - 12:00, 13:00 - 15:00",
-		Wednesday:   "7:00 - 12:00, 13:00 - 15:00",
-		Thursday:    "7:00 - 12:00, 13:00 - 15:00",
-		Friday:      "7:00 - 12:00, 13:00 - 15:00",
-		Saturday:    "",
-		Sunday:      "",
+		ID:           1,
+		Name:         "John Doe",
+		SpecialtyID:  1,
+		Location:     "New York",
+		Address:      "123 Main St",
+		Url:          "https://example.com",
+		Telephone:    "123-456-7890",
+		Email:        "me@example.com",
+		Monday:       "7:00 - 12:00, 13:00 - 15:00",
+		Tuesday:      "7:00 - 12:00, 13:00 - 15:00",
+		Wednesday:    "7:00 - 12:00, 13:00 - 15:00",
+		Thursday:     "7:00 - 12:00, 13:00 - 15:00",
+		Friday:       "7:00 - 12:00, 13:00 - 15:00",
+		Saturday:     "",
+		Sunday:       "",
+		Version:      1,
+		FieldSources: types.NewFieldSources(nil),
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistProfile_SqlError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s (.+) WHERE s.id=`).WithArgs(1).WillReturnError(errors.New("mocked error"))
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialistProfile(context.Background(), 1)
+
+	assert.EqualError(t, err, "mocked error")
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistProfile_NoRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s (.+) WHERE s.id=`).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"id"}))
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialistProfile(context.Background(), 1)
+
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistProfile_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	updatedAt := time.Date(2024, 1, 2, 3, 4, 5, 0, time.UTC)
+
+	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden", "insurers", "specialty_name", "updated_at", "count", "average_rating"}).
+		AddRow(1, "John Doe", 2, "POINT(21.25 48.72)", "123 Main St", "", "123-456-7890", "", "7:00 - 12:00", "", "", "", "", "", "", "MUDr. John Doe", 1, nil, "{VšZP,Union}", "ortopéd", updatedAt, 3, 4.5)
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s (.+) WHERE s.id=`).WithArgs(1).WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialistProfile(context.Background(), 1)
+
+	expected := &types.SpecialistProfile{
+		Specialist: &types.Specialist{
+			ID:           1,
+			Name:         "John Doe",
+			SpecialtyID:  2,
+			Location:     "POINT(21.25 48.72)",
+			Address:      "123 Main St",
+			Telephone:    "123-456-7890",
+			Monday:       "7:00 - 12:00",
+			Staff:        "MUDr. John Doe",
+			Insurers:     []string{"VšZP", "Union"},
+			Version:      1,
+			FieldSources: types.NewFieldSources(nil),
+		},
+		SpecialtyName: "ortopéd",
+		Reviews:       types.ReviewSummary{Count: 3, AverageRating: 4.5},
+		UpdatedAt:     updatedAt,
 	}
 
 	assert.NoError(t, err)
@@ -271,10 +357,10 @@ func TestGetSpecialistByName_SqlError(t *testing.T) {
 	}
 	defer db.Close()
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialist WHERE name=`).WithArgs("test").WillReturnError(errors.New("mocked error"))
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s LEFT JOIN specialist_override o (.+) WHERE coalesce\(o.source_value, s.name\)=`).WithArgs("test").WillReturnError(errors.New("mocked error"))
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialistByName("test")
+	res, err := modelsDB.DB.GetSpecialistByName(context.Background(), "test")
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -289,12 +375,12 @@ func TestGetSpecialistByName_NoRows(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"})
+	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"})
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialist WHERE name=`).WithArgs("test").WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s LEFT JOIN specialist_override o (.+) WHERE coalesce\(o.source_value, s.name\)=`).WithArgs("test").WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialistByName("test")
+	res, err := modelsDB.DB.GetSpecialistByName(context.Background(), "test")
 
 	assert.NoError(t, err)
 	assert.Nil(t, res)
@@ -308,30 +394,32 @@ func TestGetSpecialistByName_Success(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}).
-		AddRow(1, "John Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "")
+	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"}).
+		AddRow(1, "John Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "", "", 1, "{email,name}")
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialist WHERE name=`).WithArgs("test").WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s LEFT JOIN specialist_override o (.+) WHERE coalesce\(o.source_value, s.name\)=`).WithArgs("test").WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialistByName("test")
+	res, err := modelsDB.DB.GetSpecialistByName(context.Background(), "test")
 
 	expected := &types.Specialist{
-		ID:          1,
-		Name:        "John Doe",
-		SpecialtyID: 1,
-		Location:    "New York",
-		Address:     "123 Main St",
-		Url:         "https://example.com",
-		Telephone:   "123-456-7890",
-		Email:       "me@example.com",
-		Monday:      "7:00 - 12:00, 13:00 - 15:00",
-		Tuesday:     "7:00 - 12:00, 13:00 - 15:00",
-		Wednesday:   "7:00 - 12:00, 13:00 - 15:00",
-		Thursday:    "7:00 - 12:00, 13:00 - 15:00",
-		Friday:      "7:00 - 12:00, 13:00 - 15:00",
-		Saturday:    "",
-		Sunday:      "",
+		ID:           1,
+		Name:         "John Doe",
+		SpecialtyID:  1,
+		Location:     "New York",
+		Address:      "123 Main St",
+		Url:          "https://example.com",
+		Telephone:    "123-456-7890",
+		Email:        "me@example.com",
+		Monday:       "7:00 - 12:00, 13:00 - 15:00",
+		Tuesday:      "7:00 - 12:00, 13:00 - 15:00",
+		Wednesday:    "7:00 - 12:00, 13:00 - 15:00",
+		Thursday:     "7:00 - 12:00, 13:00 - 15:00",
+		Friday:       "7:00 - 12:00, 13:00 - 15:00",
+		Saturday:     "",
+		Sunday:       "",
+		Version:      1,
+		FieldSources: types.NewFieldSources([]string{"email", "name"}),
 	}
 
 	assert.NoError(t, err)
@@ -363,12 +451,15 @@ func TestInsertSpecialist_SqlError(t *testing.T) {
 		Sunday:      "",
 	}
 
-	mock.ExpectExec(`INSERT INTO specialist`).
-		WithArgs(s.Name, s.SpecialtyID, s.Location, s.Address, s.Url, s.Telephone, s.Email, s.Monday, s.Tuesday, s.Wednesday, s.Thursday, s.Friday, s.Saturday, s.Sunday).
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`INSERT INTO specialist (.+) RETURNING id`).
+		WithArgs(s.Name, s.SpecialtyID, s.Location, s.Address, s.Url, s.Telephone, s.Email, s.Monday, s.Tuesday, s.Wednesday, s.Thursday, s.Friday, s.Saturday, s.Sunday, s.Staff, "{}", s.KPZS).
 		WillReturnError(errors.New("mocked error"))
+	mock.ExpectRollback()
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.InsertSpecialist(s)
+	_, err = modelsDB.DB.InsertSpecialist(context.Background(), s)
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -399,14 +490,18 @@ func TestInsertSpecialist_Success(t *testing.T) {
 		Sunday:      "",
 	}
 
-	mock.ExpectExec(`INSERT INTO specialist`).
-		WithArgs(s.Name, s.SpecialtyID, s.Location, s.Address, s.Url, s.Telephone, s.Email, s.Monday, s.Tuesday, s.Wednesday, s.Thursday, s.Friday, s.Saturday, s.Sunday).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`INSERT INTO specialist (.+) RETURNING id`).
+		WithArgs(s.Name, s.SpecialtyID, s.Location, s.Address, s.Url, s.Telephone, s.Email, s.Monday, s.Tuesday, s.Wednesday, s.Thursday, s.Friday, s.Saturday, s.Sunday, s.Staff, "{}", s.KPZS).
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+	mock.ExpectCommit()
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.InsertSpecialist(s)
+	id, err := modelsDB.DB.InsertSpecialist(context.Background(), s)
 
 	assert.NoError(t, err)
+	assert.Equal(t, 7, id)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -417,10 +512,14 @@ func TestDeleteSpecialist_SqlError(t *testing.T) {
 	}
 	defer db.Close()
 
-	mock.ExpectExec(`DELETE FROM specialist WHERE id=\$1`).WithArgs(1).WillReturnError(errors.New("mocked error"))
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO specialist_tombstone`).WithArgs(1, 3, "system").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM specialist WHERE id=\$1 AND version=\$2`).WithArgs(1, 3).WillReturnError(errors.New("mocked error"))
+	mock.ExpectRollback()
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.DeleteSpecialist(1)
+	err = modelsDB.DB.DeleteSpecialist(context.Background(), 1, 3)
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -434,15 +533,75 @@ func TestDeleteSpecialist_Success(t *testing.T) {
 	}
 	defer db.Close()
 
-	mock.ExpectExec(`DELETE FROM specialist WHERE id=\$1`).WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`INSERT INTO specialist_tombstone`).WithArgs(1, 3, "system").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM specialist WHERE id=\$1 AND version=\$2`).WithArgs(1, 3).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.DeleteSpecialist(1)
+	err = modelsDB.DB.DeleteSpecialist(context.Background(), 1, 3)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestDeleteSpecialist_VersionErrors(t *testing.T) {
+	tests := []struct {
+		exists   bool
+		expected error
+	}{
+		{true, ErrStaleVersion},
+		{false, ErrNotFound},
+	}
+
+	for _, test := range tests {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to open sqlmock database: %s", err)
+		}
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectExec(`INSERT INTO specialist_tombstone`).WithArgs(1, 3, "system").WillReturnResult(sqlmock.NewResult(0, 1))
+		mock.ExpectExec(`DELETE FROM specialist WHERE id=\$1 AND version=\$2`).WithArgs(1, 3).WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM specialist WHERE id=\$1\)`).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(test.exists))
+		mock.ExpectRollback()
+
+		modelsDB := NewModels(db)
+		err = modelsDB.DB.DeleteSpecialist(context.Background(), 1, 3)
+
+		assert.ErrorIs(t, err, test.expected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	}
+}
+
+// updateSpecialistQuery matches the optimistically locked update, the id and the version are the last arguments
+const updateSpecialistQuery = `UPDATE specialist SET name=\$1, specialty_id=\$2, location=\$3, address=\$4, url=\$5, telephone=\$6, email=\$7, monday=\$8, tuesday=\$9, wednesday=\$10, thursday=\$11, friday=\$12, saturday=\$13, sunday=\$14, staff=\$15, insurers=\$16, version=version\+1, updated_at=now\(\) WHERE id=\$17 AND version=\$18 RETURNING version`
+
+func testUpdatedSpecialist() types.Specialist {
+	return types.Specialist{
+		ID:           1,
+		Name:         "John Doe",
+		SpecialtyID:  1,
+		Location:     "New York",
+		Address:      "123 Main St",
+		Url:          "https://example.com",
+		Telephone:    "123-456-7890",
+		Email:        "me@example.com",
+		Monday:       "7:00 - 12:00, 13:00 - 15:00",
+		Tuesday:      "7:00 - 12:00, 13:00 - 15:00",
+		Wednesday:    "7:00 - 12:00, 13:00 - 15:00",
+		Thursday:     "7:00 - 12:00, 13:00 - 15:00",
+		Friday:       "7:00 - 12:00, 13:00 - 15:00",
+		Staff:        "MUDr. John Doe",
+		Insurers:     []string{"VšZP"},
+		Version:      3,
+		FieldSources: types.NewFieldSources(nil),
+	}
+}
+
 func TestUpdateSpecialist_SqlError(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -450,33 +609,20 @@ func TestUpdateSpecialist_SqlError(t *testing.T) {
 	}
 	defer db.Close()
 
-	s := types.Specialist{
-		ID:          1,
-		Name:        "John Doe",
-		SpecialtyID: 1,
-		Location:    "New York",
-		Address:     "123 Main St",
-		Url:         "https://example.com",
-		Telephone:   "123-456-7890",
-		Email:       "me@example.com",
-		Monday:      "7:00 - 12:00, 13:00 - 15:00",
-		Tuesday:     "7:00 - 12:00, 13:00 - 15:00",
-		Wednesday:   "7:00 - 12:00, 13:00 - 15:00",
-		Thursday:    "7:00 - 12:00, 13:00 - 15:00",
-		Friday:      "7:00 - 12:00, 13:00 - 15:00",
-		Saturday:    "",
-		Sunday:      "",
-	}
+	s := testUpdatedSpecialist()
 
-	mock.ExpectExec(`UPDATE specialist SET name=\$1, specialty_id=\$2, location=\$3, address=\$4, url=\$5, telephone=\$6, email=\$7, monday=\$8, tuesday=\$9, wednesday=\$10, thursday=\$11, friday=\$12, saturday=\$13, sunday=\$14 WHERE id=\$8`).
-		WithArgs(s.Name, s.SpecialtyID, s.Location, s.Address, s.Url, s.Telephone, s.Email, s.ID, s.Monday, s.Tuesday, s.Wednesday, s.Thursday, s.Friday, s.Saturday, s.Sunday).
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(updateSpecialistQuery).
+		WithArgs(s.Name, s.SpecialtyID, s.Location, s.Address, s.Url, s.Telephone, s.Email, s.Monday, s.Tuesday, s.Wednesday, s.Thursday, s.Friday, s.Saturday, s.Sunday, s.Staff, pq.Array(s.Insurers), s.ID, s.Version).
 		WillReturnError(errors.New("mocked error"))
+	mock.ExpectRollback()
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.UpdateSpecialist(s)
+	version, err := modelsDB.DB.UpdateSpecialist(context.Background(), s)
 
-	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
+	assert.Zero(t, version)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -487,35 +633,55 @@ func TestUpdateSpecialist_Success(t *testing.T) {
 	}
 	defer db.Close()
 
-	s := types.Specialist{
-		ID:          1,
-		Name:        "John Doe",
-		SpecialtyID: 1,
-		Location:    "New York",
-		Address:     "123 Main St",
-		Url:         "https://example.com",
-		Telephone:   "123-456-7890",
-		Email:       "me@example.com",
-		Monday:      "7:00 - 12:00, 13:00 - 15:00",
-		Tuesday:     "7:00 - 12:00, 13:00 - 15:00",
-		Wednesday:   "7:00 - 12:00, 13:00 - 15:00",
-		Thursday:    "7:00 - 12:00, 13:00 - 15:00",
-		Friday:      "7:00 - 12:00, 13:00 - 15:00",
-		Saturday:    "",
-		Sunday:      "",
-	}
+	s := testUpdatedSpecialist()
 
-	mock.ExpectExec(`UPDATE specialist SET name=\$1, specialty_id=\$2, location=\$3, address=\$4, url=\$5, telephone=\$6, email=\$7, monday=\$8, tuesday=\$9, wednesday=\$10, thursday=\$11, friday=\$12, saturday=\$13, sunday=\$14 WHERE id=\$8`).
-		WithArgs(s.Name, s.SpecialtyID, s.Location, s.Address, s.Url, s.Telephone, s.Email, s.ID, s.Monday, s.Tuesday, s.Wednesday, s.Thursday, s.Friday, s.Saturday, s.Sunday).
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("jana").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(updateSpecialistQuery).
+		WithArgs(s.Name, s.SpecialtyID, s.Location, s.Address, s.Url, s.Telephone, s.Email, s.Monday, s.Tuesday, s.Wednesday, s.Thursday, s.Friday, s.Saturday, s.Sunday, s.Staff, pq.Array(s.Insurers), s.ID, s.Version).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+	mock.ExpectCommit()
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.UpdateSpecialist(s)
+	version, err := modelsDB.DB.UpdateSpecialist(WithActor(context.Background(), "jana"), s)
 
 	assert.NoError(t, err)
+	assert.Equal(t, 4, version)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUpdateSpecialist_VersionErrors(t *testing.T) {
+	tests := []struct {
+		exists   bool
+		expected error
+	}{
+		{true, ErrStaleVersion},
+		{false, ErrNotFound},
+	}
+
+	for _, test := range tests {
+		db, mock, err := sqlmock.New()
+		if err != nil {
+			t.Fatalf("failed to open sqlmock database: %s", err)
+		}
+
+		s := testUpdatedSpecialist()
+
+		mock.ExpectBegin()
+		mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+		mock.ExpectQuery(updateSpecialistQuery).WillReturnRows(sqlmock.NewRows([]string{"version"}))
+		mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM specialist WHERE id=\$1\)`).WithArgs(1).WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(test.exists))
+		mock.ExpectRollback()
+
+		modelsDB := NewModels(db)
+		_, err = modelsDB.DB.UpdateSpecialist(context.Background(), s)
+
+		assert.ErrorIs(t, err, test.expected)
+		assert.NoError(t, mock.ExpectationsWereMet())
+		db.Close()
+	}
+}
+
 func TestGetSpecialistBySpecialtyAndLocation_SqlError(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -526,7 +692,7 @@ func TestGetSpecialistBySpecialtyAndLocation_SqlError(t *testing.T) {
 	mock.ExpectQuery("SELECT (.+) FROM specialist").WithArgs(1, "123 Main St", 10000).WillReturnError(errors.New("mocked error"))
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialistBySpecialtyAndLocation(1, 10000, "123 Main St")
+	res, err := modelsDB.DB.GetSpecialistBySpecialtyAndLocation(context.Background(), 1, 10000, "123 Main St")
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -548,7 +714,7 @@ func TestGetSpecialistBySpecialtyAndLocation_RowsScanError(t *testing.T) {
 	mock.ExpectQuery("SELECT (.+) FROM specialist").WithArgs(1, "123 Main St", 10000).WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialistBySpecialtyAndLocation(1, 10000, "123 Main St")
+	res, err := modelsDB.DB.GetSpecialistBySpecialtyAndLocation(context.Background(), 1, 10000, "123 Main St")
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "rows scan error")
@@ -563,31 +729,114 @@ func TestGetSpecialistBySpecialtyAndLocation_Success(t *testing.T) {
 	}
 	defer db.Close()
 
-	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}).
-		AddRow(1, "John Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "")
+	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"}).
+		AddRow(1, "John Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "", "", 1, nil)
 
 	mock.ExpectQuery("SELECT (.+) FROM specialist").WithArgs(1, "123 Main St", 10000).WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialistBySpecialtyAndLocation(1, 10000, "123 Main St")
+	res, err := modelsDB.DB.GetSpecialistBySpecialtyAndLocation(context.Background(), 1, 10000, "123 Main St")
 
 	expected := []*types.Specialist{
 		{
-			ID:          1,
-			Name:        "John Doe",
-			SpecialtyID: 1,
-			Location:    "New York",
-			Address:     "123 Main St",
-			Url:         "https://example.com",
-			Telephone:   "123-456-7890",
-			Email:       "me@example.com",
-			Monday:      "7:00 - 12:00, 13:00 - 15:00",
-			Tuesday:     "7:00 - 12:00, 13:00 - 15:00",
-			Wednesday:   "7:00 - 12:00, 13:00 - 15:00",
-			Thursday:    "7:00 - 12:00, 13:00 - 15:00",
-			Friday:      "7:00 - 12:00, 13:00 - 15:00",
-			Saturday:    "",
-			Sunday:      "",
+			ID:           1,
+			Name:         "John Doe",
+			SpecialtyID:  1,
+			Location:     "New York",
+			Address:      "123 Main St",
+			Url:          "https://example.com",
+			Telephone:    "123-456-7890",
+			Email:        "me@example.com",
+			Monday:       "7:00 - 12:00, 13:00 - 15:00",
+			Tuesday:      "7:00 - 12:00, 13:00 - 15:00",
+			Wednesday:    "7:00 - 12:00, 13:00 - 15:00",
+			Thursday:     "7:00 - 12:00, 13:00 - 15:00",
+			Friday:       "7:00 - 12:00, 13:00 - 15:00",
+			Saturday:     "",
+			Sunday:       "",
+			Version:      1,
+			FieldSources: types.NewFieldSources(nil),
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchSpecialists_SqlError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM specialist s JOIN specialty sp").WithArgs(pq.Array([]string{"ocny", "lekar"}), 0.3, 10).WillReturnError(errors.New("mocked error"))
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.SearchSpecialists(context.Background(), []string{"ocny", "lekar"}, 0.3, 10)
+
+	assert.Error(t, err)
+	assert.EqualError(t, err, "mocked error")
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchSpecialists_ScanError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "name"}).AddRow(1, "John Doe")
+
+	mock.ExpectQuery("SELECT (.+) FROM specialist s JOIN specialty sp").WithArgs(pq.Array([]string{"ocny", "lekar"}), 0.3, 10).WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.SearchSpecialists(context.Background(), []string{"ocny", "lekar"}, 0.3, 10)
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSearchSpecialists_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden", "name", "rank"}).
+		AddRow(1, "Očná ambulancia", 2, "POINT(21.9 48.7)", "Nám. osloboditeľov 1, Michalovce", "", "123-456-7890", "me@example.com", "7:00 - 15:00", "7:00 - 15:00", "7:00 - 15:00", "7:00 - 15:00", "7:00 - 15:00", "", "", "MUDr. Ján Novák", 1, nil, "oftalmológia", 0.8)
+
+	mock.ExpectQuery("SELECT (.+) FROM specialist s JOIN specialty sp").WithArgs(pq.Array([]string{"ocny", "lekar"}), 0.3, 10).WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.SearchSpecialists(context.Background(), []string{"ocny", "lekar"}, 0.3, 10)
+
+	expected := []*types.SpecialistSearchResult{
+		{
+			Specialist: &types.Specialist{
+				ID:           1,
+				Name:         "Očná ambulancia",
+				SpecialtyID:  2,
+				Location:     "POINT(21.9 48.7)",
+				Address:      "Nám. osloboditeľov 1, Michalovce",
+				Telephone:    "123-456-7890",
+				Email:        "me@example.com",
+				Monday:       "7:00 - 15:00",
+				Tuesday:      "7:00 - 15:00",
+				Wednesday:    "7:00 - 15:00",
+				Thursday:     "7:00 - 15:00",
+				Friday:       "7:00 - 15:00",
+				Staff:        "MUDr. Ján Novák",
+				Version:      1,
+				FieldSources: types.NewFieldSources(nil),
+			},
+			SpecialtyName: "oftalmológia",
+			Rank:          0.8,
 		},
 	}
 
@@ -595,3 +844,40 @@ func TestGetSpecialistBySpecialtyAndLocation_Success(t *testing.T) {
 	assert.Equal(t, expected, res)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestGetSpecialistPlaces_SqlError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT address, (.+) FROM specialist WHERE location IS NOT NULL`).WillReturnError(errors.New("mocked error"))
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialistPlaces(context.Background())
+
+	assert.EqualError(t, err, "mocked error")
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialistPlaces_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"address", "lat", "lon"}).
+		AddRow("Hlavná 1, 04001 Košice, Slovenská republika", 48.72, 21.258)
+
+	mock.ExpectQuery(`SELECT address, (.+) FROM specialist WHERE location IS NOT NULL`).WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialistPlaces(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, []*types.Place{{Lat: 48.72, Lon: 21.258, DisplayName: "Hlavná 1, 04001 Košice, Slovenská republika", Type: types.PlaceTypeHouse}}, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -1,110 +1,151 @@
 package models
 
-import "github.com/acornak/healthcare-poc/types"
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/acornak/healthcare-poc/textutil"
+	"github.com/acornak/healthcare-poc/types"
+)
 
 /*
 GetAllSpecialties returns all specialties from the database
 The function returns a slice of pointers to Specialty structs
 The function returns an error if there was an issue with the database
 */
-func (m *DBModel) GetAllSpecialties() ([]*types.Specialty, error) {
+func (m *DBModel) GetAllSpecialties(ctx context.Context) ([]*types.Specialty, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
-	SELECT id, name, description
-	FROM specialty
+	SELECT ` + specialtyColumns + `
+	FROM specialty sp
 	`
 
-	rows, err := m.DB.Query(stmt)
+	rows, err := m.DB.QueryContext(ctx, stmt)
 	if err != nil {
 		return nil, err
 	}
-	defer rows.Close()
 
-	var specialties []*types.Specialty
+	return scanAll(rows, scanSpecialty)
+}
 
-	for rows.Next() {
-		var s types.Specialty
-		rows.Scan(&s.ID, &s.Name, &s.Description)
-		specialties = append(specialties, &s)
-	}
+/*
+GetSpecialtyByID returns a specialty from the database with a specific id
+The id is the id of the specialty
+The function returns a pointer to a Specialty struct
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetSpecialtyByID(ctx context.Context, id int) (*types.Specialty, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
 
-	if err = rows.Err(); err != nil {
+	stmt := `
+	SELECT ` + specialtyColumns + `
+	FROM specialty sp
+	WHERE sp.id=$1
+	`
+
+	row := m.DB.QueryRowContext(ctx, stmt, id)
+
+	s, err := scanSpecialty(row)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return nil, nil
+		}
 		return nil, err
 	}
 
-	return specialties, nil
+	return s, nil
 }
 
 /*
-GetSpecialtyByID returns a specialty from the database with a specific id
-The id is the id of the specialty
+GetSpecialtyByName returns a specialty from the database with a specific name
+The name is the name of the specialty
 The function returns a pointer to a Specialty struct
 The function returns an error if there was an issue with the database
 */
-func (m *DBModel) GetSpecialtyByID(id int) (*types.Specialty, error) {
+func (m *DBModel) GetSpecialtyByName(ctx context.Context, name string) (*types.Specialty, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
-	SELECT id, name, description
-	FROM specialty
-	WHERE id=$1
+	SELECT ` + specialtyColumns + `
+	FROM specialty sp
+	WHERE sp.name=$1
 	`
 
-	row := m.DB.QueryRow(stmt, id)
+	row := m.DB.QueryRowContext(ctx, stmt, name)
 
-	var s types.Specialty
-	err := row.Scan(&s.ID, &s.Name, &s.Description)
+	s, err := scanSpecialty(row)
 	if err != nil {
-		if err.Error() == "sql: no rows in result set" {
+		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
 	}
 
-	return &s, nil
+	return s, nil
 }
 
 /*
-GetSpecialtyByName returns a specialty from the database with a specific name
-The name is the name of the specialty
+GetSpecialtyByNormalizedName returns a specialty from the database with a specific normalized name
+The name is normalized before the lookup, aliases left behind by merged duplicates are resolved to the surviving specialty
 The function returns a pointer to a Specialty struct
 The function returns an error if there was an issue with the database
 */
-func (m *DBModel) GetSpecialtyByName(name string) (*types.Specialty, error) {
+func (m *DBModel) GetSpecialtyByNormalizedName(ctx context.Context, name string) (*types.Specialty, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
-	SELECT id, name, description
-	FROM specialty
-	WHERE name=$1
+	SELECT ` + specialtyColumns + `
+	FROM specialty sp
+	WHERE sp.normalized_name=$1
+	UNION ALL
+	SELECT ` + specialtyColumns + `
+	FROM specialty_alias a
+	JOIN specialty sp ON sp.id = a.specialty_id
+	WHERE a.normalized_name=$1
+	LIMIT 1
 	`
 
-	row := m.DB.QueryRow(stmt, name)
+	row := m.DB.QueryRowContext(ctx, stmt, textutil.Normalize(name))
 
-	var s types.Specialty
-	err := row.Scan(&s.ID, &s.Name, &s.Description)
+	s, err := scanSpecialty(row)
 	if err != nil {
-		if err.Error() == "sql: no rows in result set" {
+		if errors.Is(err, sql.ErrNoRows) {
 			return nil, nil
 		}
 		return nil, err
 	}
 
-	return &s, nil
+	return s, nil
 }
 
 /*
 InsertSpecialty inserts a specialty into the database
 The s parameter is a Specialty struct
+The function returns the id of the new specialty
 The function returns an error if there was an issue with the database
 */
-func (m *DBModel) InsertSpecialty(s types.Specialty) error {
+func (m *DBModel) InsertSpecialty(ctx context.Context, s types.Specialty) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
-	INSERT INTO specialty (name, description)
-	VALUES ($1, $2)
+	INSERT INTO specialty (name, normalized_name, description)
+	VALUES ($1, $2, $3)
+	RETURNING id
 	`
 
-	_, err := m.DB.Exec(stmt, s.Name, s.Description)
-	if err != nil {
-		return err
-	}
+	return m.audited(ctx, func(db dbExecutor) (int, error) {
+		var id int
+		err := db.QueryRowContext(ctx, stmt, s.Name, textutil.Normalize(s.Name), s.Description).Scan(&id)
 
-	return nil
+		return id, err
+	})
 }
 
 /*
@@ -112,14 +153,17 @@ InsertMultipleSpecialties inserts multiple specialties into the database
 The s parameter is a slice of Specialty structs
 The function returns an error if there was an issue with the database
 */
-func (m *DBModel) InsertMultipleSpecialties(s []types.Specialty) error {
+func (m *DBModel) InsertMultipleSpecialties(ctx context.Context, s []types.Specialty) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
-	INSERT INTO specialty (name, description)
-	VALUES ($1, $2)
+	INSERT INTO specialty (name, normalized_name, description)
+	VALUES ($1, $2, $3)
 	`
 
 	for _, specialty := range s {
-		_, err := m.DB.Exec(stmt, specialty.Name, specialty.Description)
+		_, err := m.DB.ExecContext(ctx, stmt, specialty.Name, textutil.Normalize(specialty.Name), specialty.Description)
 		if err != nil {
 			return err
 		}
@@ -130,39 +174,141 @@ func (m *DBModel) InsertMultipleSpecialties(s []types.Specialty) error {
 
 /*
 DeleteSpecialty deletes a specialty from the database with a specific id
-The id is the id of the specialty
-The function returns an error if there was an issue with the database
+Its translations, aliases and symptom mappings are deleted with it, a specialty still assigned to specialists is kept
+Everything runs in a single transaction
+The function returns ErrNotFound if the specialty does not exist
+The function returns an error if there was an issue with the database, e.g. the specialty still has specialists
 */
-func (m *DBModel) DeleteSpecialty(id int) error {
-	stmt := `
-	DELETE FROM specialties
-	WHERE id = $1
-	`
+func (m *DBModel) DeleteSpecialty(ctx context.Context, id int) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
 
-	_, err := m.DB.Exec(stmt, id)
+	tx, err := m.DB.BeginTx(ctx, nil)
 	if err != nil {
 		return err
 	}
+	defer tx.Rollback()
 
-	return nil
+	for _, stmt := range []string{
+		`DELETE FROM specialty_translation WHERE specialty_id=$1`,
+		`DELETE FROM specialty_alias WHERE specialty_id=$1`,
+		`DELETE FROM symptom_mapping WHERE specialty_id=$1`,
+	} {
+		if _, err := tx.ExecContext(ctx, stmt, id); err != nil {
+			return err
+		}
+	}
+
+	res, err := tx.ExecContext(ctx, `DELETE FROM specialty WHERE id=$1`, id)
+	if err != nil {
+		return err
+	}
+
+	deleted, err := res.RowsAffected()
+	if err != nil {
+		return err
+	}
+
+	if deleted == 0 {
+		return ErrNotFound
+	}
+
+	if err := insertAudit(ctx, tx, id); err != nil {
+		return err
+	}
+
+	return tx.Commit()
 }
 
 /*
-UpdateSpecialty updates a specialty in the database
+UpdateSpecialty updates the name and description of a specialty in the database
 The s parameter is a Specialty struct
-The function returns an error if there was an issue with the database
+The function returns ErrNotFound if the specialty does not exist
+The function returns an error if there was an issue with the database, e.g. the new name is taken
 */
-func (m *DBModel) UpdateSpecialty(s types.Specialty) error {
+func (m *DBModel) UpdateSpecialty(ctx context.Context, s types.Specialty) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
 	stmt := `
 	UPDATE specialty
-	SET name=$1, description=$2
-	WHERE id=$3
+	SET name=$1, normalized_name=$2, description=$3
+	WHERE id=$4
 	`
 
-	_, err := m.DB.Exec(stmt, s.Name, s.Description, s.ID)
+	_, err := m.audited(ctx, func(db dbExecutor) (int, error) {
+		res, err := db.ExecContext(ctx, stmt, s.Name, textutil.Normalize(s.Name), s.Description, s.ID)
+
+		return s.ID, changedRow(res, err)
+	})
+
+	return err
+}
+
+/*
+MergeSpecialties merges a duplicate specialty into the canonical one
+Specialists, symptom mappings, translations and aliases of the duplicate are moved to the canonical specialty,
+the normalized name of the duplicate is kept as an alias and the duplicate is deleted
+Everything runs in a single transaction
+The function returns the number of specialists moved to the canonical specialty
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) MergeSpecialties(ctx context.Context, duplicateID, canonicalID int) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	tx, err := m.DB.BeginTx(ctx, nil)
 	if err != nil {
-		return err
+		return 0, err
 	}
+	defer tx.Rollback()
 
-	return nil
+	if err := setActor(ctx, tx); err != nil {
+		return 0, err
+	}
+
+	res, err := tx.ExecContext(ctx, `UPDATE specialist SET specialty_id=$1, version=version+1, updated_at=now() WHERE specialty_id=$2`, canonicalID, duplicateID)
+	if err != nil {
+		return 0, err
+	}
+
+	moved, err := res.RowsAffected()
+	if err != nil {
+		return 0, err
+	}
+
+	stmts := []struct {
+		query string
+		args  []any
+	}{
+		{`INSERT INTO symptom_mapping (keyword, specialty_id, weight, enabled)
+		SELECT keyword, $1, weight, enabled FROM symptom_mapping WHERE specialty_id=$2
+		ON CONFLICT (keyword, specialty_id) DO NOTHING`, []any{canonicalID, duplicateID}},
+		{`DELETE FROM symptom_mapping WHERE specialty_id=$1`, []any{duplicateID}},
+		{`INSERT INTO specialty_translation (specialty_id, language, name, description, synonyms)
+		SELECT $1, language, name, description, synonyms FROM specialty_translation WHERE specialty_id=$2
+		ON CONFLICT (specialty_id, language) DO NOTHING`, []any{canonicalID, duplicateID}},
+		{`DELETE FROM specialty_translation WHERE specialty_id=$1`, []any{duplicateID}},
+		{`UPDATE specialty_alias SET specialty_id=$1 WHERE specialty_id=$2`, []any{canonicalID, duplicateID}},
+		{`INSERT INTO specialty_alias (normalized_name, specialty_id)
+		SELECT normalized_name, $1 FROM specialty WHERE id=$2
+		ON CONFLICT (normalized_name) DO UPDATE SET specialty_id=EXCLUDED.specialty_id`, []any{canonicalID, duplicateID}},
+		{`DELETE FROM specialty WHERE id=$1`, []any{duplicateID}},
+	}
+
+	for _, stmt := range stmts {
+		if _, err := tx.ExecContext(ctx, stmt.query, stmt.args...); err != nil {
+			return 0, err
+		}
+	}
+
+	if err := insertAudit(ctx, tx, canonicalID); err != nil {
+		return 0, err
+	}
+
+	if err := tx.Commit(); err != nil {
+		return 0, err
+	}
+
+	return int(moved), nil
 }
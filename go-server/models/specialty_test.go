@@ -1,6 +1,7 @@
 package models
 
 import (
+	"context"
 	"errors"
 	"testing"
 
@@ -19,7 +20,7 @@ func TestGetAllSpecialties_SqlError(t *testing.T) {
 	mock.ExpectQuery(`SELECT (.+) FROM specialty`).WithoutArgs().WillReturnError(errors.New("mocked error"))
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetAllSpecialties()
+	res, err := modelsDB.DB.GetAllSpecialties(context.Background())
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -40,7 +41,7 @@ func TestGetAllSpecialties_RowsScanError(t *testing.T) {
 	mock.ExpectQuery("SELECT (.+) FROM specialty").WithoutArgs().WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetAllSpecialties()
+	res, err := modelsDB.DB.GetAllSpecialties(context.Background())
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "rows scan error")
@@ -60,7 +61,7 @@ func TestGetAllSpecialties_Success(t *testing.T) {
 	mock.ExpectQuery("SELECT (.+) FROM specialty").WithoutArgs().WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetAllSpecialties()
+	res, err := modelsDB.DB.GetAllSpecialties(context.Background())
 
 	expected := []*types.Specialty{
 		{
@@ -82,10 +83,10 @@ func TestGetSpecialtyByID_SqlError(t *testing.T) {
 	}
 	defer db.Close()
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE id=\$1`).WithArgs(1).WillReturnError(errors.New("mocked error"))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.id=\$1`).WithArgs(1).WillReturnError(errors.New("mocked error"))
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialtyByID(1)
+	res, err := modelsDB.DB.GetSpecialtyByID(context.Background(), 1)
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -101,10 +102,10 @@ func TestGetSpecialtyByID_NoRows(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id", "name", "description"})
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE id=\$1`).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.id=\$1`).WithArgs(1).WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialtyByID(1)
+	res, err := modelsDB.DB.GetSpecialtyByID(context.Background(), 1)
 
 	assert.NoError(t, err)
 	assert.Nil(t, res)
@@ -120,10 +121,10 @@ func TestGetSpecialtyByID_Success(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "test", "test")
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE id=\$1`).WithArgs(1).WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.id=\$1`).WithArgs(1).WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialtyByID(1)
+	res, err := modelsDB.DB.GetSpecialtyByID(context.Background(), 1)
 
 	expected := &types.Specialty{
 		ID:          1,
@@ -143,10 +144,10 @@ func TestGetSpecialtyByName_SqlError(t *testing.T) {
 	}
 	defer db.Close()
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE name=\$1`).WithArgs("test").WillReturnError(errors.New("mocked error"))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.name=\$1`).WithArgs("test").WillReturnError(errors.New("mocked error"))
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialtyByName("test")
+	res, err := modelsDB.DB.GetSpecialtyByName(context.Background(), "test")
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -163,10 +164,10 @@ func TestGetSpecialtyByName_NoRows(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id", "name", "description"})
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE name=\$1`).WithArgs("test").WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.name=\$1`).WithArgs("test").WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialtyByName("test")
+	res, err := modelsDB.DB.GetSpecialtyByName(context.Background(), "test")
 
 	assert.NoError(t, err)
 	assert.Nil(t, res)
@@ -182,10 +183,10 @@ func TestGetSpecialtyByName_Success(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "test", "test")
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE name=\$1`).WithArgs("test").WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.name=\$1`).WithArgs("test").WillReturnRows(rows)
 
 	modelsDB := NewModels(db)
-	res, err := modelsDB.DB.GetSpecialtyByName("test")
+	res, err := modelsDB.DB.GetSpecialtyByName(context.Background(), "test")
 
 	expected := &types.Specialty{
 		ID:          1,
@@ -198,6 +199,50 @@ func TestGetSpecialtyByName_Success(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestGetSpecialtyByNormalizedName_NoRows(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "description"})
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ocne lekarstvo").WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialtyByNormalizedName(context.Background(), "Očné  Lekárstvo")
+
+	assert.NoError(t, err)
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSpecialtyByNormalizedName_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "očné lekárstvo", "test")
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1 UNION ALL (.+) FROM specialty_alias`).WithArgs("ocne lekarstvo").WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetSpecialtyByNormalizedName(context.Background(), "OČNÉ LEKÁRSTVO")
+
+	expected := &types.Specialty{
+		ID:          1,
+		Name:        "očné lekárstvo",
+		Description: "test",
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestInsertSpecialty_SqlError(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -210,10 +255,10 @@ func TestInsertSpecialty_SqlError(t *testing.T) {
 		Description: "test",
 	}
 
-	mock.ExpectExec("INSERT INTO specialty").WithArgs(s.Name, s.Description).WillReturnError(errors.New("mocked error"))
+	mock.ExpectQuery("INSERT INTO specialty (.+) RETURNING id").WithArgs(s.Name, s.Name, s.Description).WillReturnError(errors.New("mocked error"))
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.InsertSpecialty(s)
+	_, err = modelsDB.DB.InsertSpecialty(context.Background(), s)
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -232,12 +277,13 @@ func TestInsertSpecialty_Success(t *testing.T) {
 		Description: "test",
 	}
 
-	mock.ExpectExec("INSERT INTO specialty").WithArgs(s.Name, s.Description).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery("INSERT INTO specialty (.+) RETURNING id").WithArgs(s.Name, s.Name, s.Description).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(5))
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.InsertSpecialty(s)
+	id, err := modelsDB.DB.InsertSpecialty(context.Background(), s)
 
 	assert.NoError(t, err)
+	assert.Equal(t, 5, id)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
@@ -256,10 +302,10 @@ func TestInsertMultipleSpecialties_SqlError(t *testing.T) {
 		Description: "test2",
 	}}
 
-	mock.ExpectExec("INSERT INTO specialty").WithArgs(s[0].Name, s[0].Description).WillReturnError(errors.New("mocked error"))
+	mock.ExpectExec("INSERT INTO specialty").WithArgs(s[0].Name, s[0].Name, s[0].Description).WillReturnError(errors.New("mocked error"))
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.InsertMultipleSpecialties(s)
+	err = modelsDB.DB.InsertMultipleSpecialties(context.Background(), s)
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
@@ -281,11 +327,11 @@ func TestInsertMultipleSpecialties_Success(t *testing.T) {
 		Description: "test2",
 	}}
 
-	mock.ExpectExec("INSERT INTO specialty").WithArgs(s[0].Name, s[0].Description).WillReturnResult(sqlmock.NewResult(1, 1))
-	mock.ExpectExec("INSERT INTO specialty").WithArgs(s[1].Name, s[1].Description).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO specialty").WithArgs(s[0].Name, s[0].Name, s[0].Description).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO specialty").WithArgs(s[1].Name, s[1].Name, s[1].Description).WillReturnResult(sqlmock.NewResult(1, 1))
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.InsertMultipleSpecialties(s)
+	err = modelsDB.DB.InsertMultipleSpecialties(context.Background(), s)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -298,16 +344,42 @@ func TestDeleteSpecialty_SqlError(t *testing.T) {
 	}
 	defer db.Close()
 
-	mock.ExpectExec("DELETE FROM specialties").WithArgs(1).WillReturnError(errors.New("mocked error"))
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM specialty_translation").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM specialty_alias").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM symptom_mapping").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM specialty WHERE id=\$1`).WithArgs(1).WillReturnError(errors.New("mocked error"))
+	mock.ExpectRollback()
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.DeleteSpecialty(1)
+	err = modelsDB.DB.DeleteSpecialty(context.Background(), 1)
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestDeleteSpecialty_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM specialty_translation").WithArgs(42).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM specialty_alias").WithArgs(42).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM symptom_mapping").WithArgs(42).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`DELETE FROM specialty WHERE id=\$1`).WithArgs(42).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectRollback()
+
+	modelsDB := NewModels(db)
+	err = modelsDB.DB.DeleteSpecialty(context.Background(), 42)
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestDeleteSpecialty_Success(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -315,10 +387,15 @@ func TestDeleteSpecialty_Success(t *testing.T) {
 	}
 	defer db.Close()
 
-	mock.ExpectExec("DELETE FROM specialties").WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectBegin()
+	mock.ExpectExec("DELETE FROM specialty_translation").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 2))
+	mock.ExpectExec("DELETE FROM specialty_alias").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM symptom_mapping").WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`DELETE FROM specialty WHERE id=\$1`).WithArgs(1).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.DeleteSpecialty(1)
+	err = modelsDB.DB.DeleteSpecialty(context.Background(), 1)
 
 	assert.NoError(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -333,20 +410,36 @@ func TestUpdateSpecialty_SqlError(t *testing.T) {
 
 	s := types.Specialty{
 		ID:          1,
-		Name:        "test",
+		Name:        "Očné lekárstvo",
 		Description: "test",
 	}
 
-	mock.ExpectExec(`UPDATE specialty SET name=\$1, description=\$2 WHERE id=\$3`).WithArgs(s.Name, s.Description, s.ID).WillReturnError(errors.New("mocked error"))
+	mock.ExpectExec(`UPDATE specialty SET name=\$1, normalized_name=\$2, description=\$3 WHERE id=\$4`).WithArgs(s.Name, "ocne lekarstvo", s.Description, s.ID).WillReturnError(errors.New("mocked error"))
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.UpdateSpecialty(s)
+	err = modelsDB.DB.UpdateSpecialty(context.Background(), s)
 
 	assert.Error(t, err)
 	assert.EqualError(t, err, "mocked error")
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestUpdateSpecialty_NotFound(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`UPDATE specialty SET name=\$1, normalized_name=\$2, description=\$3 WHERE id=\$4`).WithArgs("test", "test", "test", 42).WillReturnResult(sqlmock.NewResult(0, 0))
+
+	modelsDB := NewModels(db)
+	err = modelsDB.DB.UpdateSpecialty(context.Background(), types.Specialty{ID: 42, Name: "test", Description: "test"})
+
+	assert.ErrorIs(t, err, ErrNotFound)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestUpdateSpecialty_Success(t *testing.T) {
 	db, mock, err := sqlmock.New()
 	if err != nil {
@@ -356,15 +449,80 @@ func TestUpdateSpecialty_Success(t *testing.T) {
 
 	s := types.Specialty{
 		ID:          1,
-		Name:        "test",
+		Name:        "Očné lekárstvo",
 		Description: "test",
 	}
 
-	mock.ExpectExec(`UPDATE specialty SET name=\$1, description=\$2 WHERE id=\$3`).WithArgs(s.Name, s.Description, s.ID).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE specialty SET name=\$1, normalized_name=\$2, description=\$3 WHERE id=\$4`).WithArgs(s.Name, "ocne lekarstvo", s.Description, s.ID).WillReturnResult(sqlmock.NewResult(0, 1))
+
+	modelsDB := NewModels(db)
+	err = modelsDB.DB.UpdateSpecialty(context.Background(), s)
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMergeSpecialties_BeginError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin().WillReturnError(errors.New("mocked error"))
+
+	modelsDB := NewModels(db)
+	moved, err := modelsDB.DB.MergeSpecialties(context.Background(), 2, 1)
+
+	assert.EqualError(t, err, "mocked error")
+	assert.Equal(t, 0, moved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMergeSpecialties_SqlError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE specialist SET specialty_id").WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("INSERT INTO symptom_mapping").WithArgs(1, 2).WillReturnError(errors.New("mocked error"))
+	mock.ExpectRollback()
+
+	modelsDB := NewModels(db)
+	moved, err := modelsDB.DB.MergeSpecialties(context.Background(), 2, 1)
+
+	assert.EqualError(t, err, "mocked error")
+	assert.Equal(t, 0, moved)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestMergeSpecialties_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("system").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE specialist SET specialty_id").WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 3))
+	mock.ExpectExec("INSERT INTO symptom_mapping").WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM symptom_mapping").WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("INSERT INTO specialty_translation").WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("DELETE FROM specialty_translation").WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("UPDATE specialty_alias").WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec("INSERT INTO specialty_alias").WithArgs(1, 2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec("DELETE FROM specialty WHERE id").WithArgs(2).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
 
 	modelsDB := NewModels(db)
-	err = modelsDB.DB.UpdateSpecialty(s)
+	moved, err := modelsDB.DB.MergeSpecialties(context.Background(), 2, 1)
 
 	assert.NoError(t, err)
+	assert.Equal(t, 3, moved)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
@@ -0,0 +1,173 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+
+	"github.com/acornak/healthcare-poc/types"
+)
+
+/*
+GetAllSymptomMappings returns all symptom mappings from the database together with the specialty name
+The function returns a slice of pointers to SymptomMapping structs
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetAllSymptomMappings(ctx context.Context) ([]*types.SymptomMapping, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT sm.id, sm.keyword, sm.specialty_id, sp.name, sm.weight, sm.enabled
+	FROM symptom_mapping sm
+	JOIN specialty sp ON sp.id = sm.specialty_id
+	ORDER BY sm.id
+	`
+
+	rows, err := m.DB.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var mappings []*types.SymptomMapping
+
+	for rows.Next() {
+		var sm types.SymptomMapping
+		err := rows.Scan(&sm.ID, &sm.Keyword, &sm.SpecialtyID, &sm.SpecialtyName, &sm.Weight, &sm.Enabled)
+		if err != nil {
+			return nil, err
+		}
+		mappings = append(mappings, &sm)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return mappings, nil
+}
+
+/*
+GetSymptomMappingByID returns a symptom mapping from the database with a specific id together with the specialty name
+The function returns a pointer to a SymptomMapping struct, nil if there is no mapping with the id
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetSymptomMappingByID(ctx context.Context, id int) (*types.SymptomMapping, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT sm.id, sm.keyword, sm.specialty_id, sp.name, sm.weight, sm.enabled
+	FROM symptom_mapping sm
+	JOIN specialty sp ON sp.id = sm.specialty_id
+	WHERE sm.id=$1
+	`
+
+	var sm types.SymptomMapping
+	err := m.DB.QueryRowContext(ctx, stmt, id).Scan(&sm.ID, &sm.Keyword, &sm.SpecialtyID, &sm.SpecialtyName, &sm.Weight, &sm.Enabled)
+	if err != nil {
+		if err == sql.ErrNoRows {
+			return nil, nil
+		}
+		return nil, err
+	}
+
+	return &sm, nil
+}
+
+/*
+InsertSymptomMapping inserts a symptom mapping into the database
+Existing keyword and specialty pairs are left untouched, so curated edits survive re-seeding
+The sm parameter is a SymptomMapping struct
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) InsertSymptomMapping(ctx context.Context, sm types.SymptomMapping) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	INSERT INTO symptom_mapping (keyword, specialty_id, weight, enabled)
+	VALUES ($1, $2, $3, $4)
+	ON CONFLICT (keyword, specialty_id) DO NOTHING
+	`
+
+	_, err := m.DB.ExecContext(ctx, stmt, sm.Keyword, sm.SpecialtyID, sm.Weight, sm.Enabled)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/*
+CreateSymptomMapping inserts a symptom mapping added by an admin into the database
+Unlike InsertSymptomMapping a mapping of the same keyword and specialty is not skipped, it is a unique violation
+The function returns the id of the new symptom mapping
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) CreateSymptomMapping(ctx context.Context, sm types.SymptomMapping) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	INSERT INTO symptom_mapping (keyword, specialty_id, weight, enabled)
+	VALUES ($1, $2, $3, $4)
+	RETURNING id
+	`
+
+	return m.audited(ctx, func(db dbExecutor) (int, error) {
+		var id int
+		err := db.QueryRowContext(ctx, stmt, sm.Keyword, sm.SpecialtyID, sm.Weight, sm.Enabled).Scan(&id)
+
+		return id, err
+	})
+}
+
+/*
+UpdateSymptomMapping updates a symptom mapping in the database
+The sm parameter is a SymptomMapping struct
+The function returns ErrNotFound if the symptom mapping does not exist
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) UpdateSymptomMapping(ctx context.Context, sm types.SymptomMapping) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	UPDATE symptom_mapping
+	SET keyword=$1, specialty_id=$2, weight=$3, enabled=$4
+	WHERE id=$5
+	`
+
+	_, err := m.audited(ctx, func(db dbExecutor) (int, error) {
+		res, err := db.ExecContext(ctx, stmt, sm.Keyword, sm.SpecialtyID, sm.Weight, sm.Enabled, sm.ID)
+
+		return sm.ID, changedRow(res, err)
+	})
+
+	return err
+}
+
+/*
+DeleteSymptomMapping deletes a symptom mapping from the database with a specific id
+The id is the id of the symptom mapping
+The function returns ErrNotFound if the symptom mapping does not exist
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) DeleteSymptomMapping(ctx context.Context, id int) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	DELETE FROM symptom_mapping
+	WHERE id = $1
+	`
+
+	_, err := m.audited(ctx, func(db dbExecutor) (int, error) {
+		res, err := db.ExecContext(ctx, stmt, id)
+
+		return id, changedRow(res, err)
+	})
+
+	return err
+}
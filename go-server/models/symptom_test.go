@@ -0,0 +1,175 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAllSymptomMappings_SqlError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM symptom_mapping").WithoutArgs().WillReturnError(errors.New("mocked error"))
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetAllSymptomMappings(context.Background())
+
+	assert.EqualError(t, err, "mocked error")
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllSymptomMappings_ScanError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "keyword"}).AddRow(1, "zub")
+
+	mock.ExpectQuery("SELECT (.+) FROM symptom_mapping").WithoutArgs().WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetAllSymptomMappings(context.Background())
+
+	assert.Error(t, err)
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllSymptomMappings_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "keyword", "specialty_id", "name", "weight", "enabled"}).
+		AddRow(1, "zub", 2, "ambulancia zubného lekárstva", 0.95, true).
+		AddRow(2, "oko", 3, "ambulancia oftalmológie", 0.9, false)
+
+	mock.ExpectQuery("SELECT (.+) FROM symptom_mapping").WithoutArgs().WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetAllSymptomMappings(context.Background())
+
+	expected := []*types.SymptomMapping{
+		{ID: 1, Keyword: "zub", SpecialtyID: 2, SpecialtyName: "ambulancia zubného lekárstva", Weight: 0.95, Enabled: true},
+		{ID: 2, Keyword: "oko", SpecialtyID: 3, SpecialtyName: "ambulancia oftalmológie", Weight: 0.9, Enabled: false},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestInsertSymptomMapping(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	sm := types.SymptomMapping{Keyword: "zub", SpecialtyID: 2, Weight: 0.95, Enabled: true}
+
+	mock.ExpectExec(`INSERT INTO symptom_mapping (.+) ON CONFLICT`).WithArgs("zub", 2, 0.95, true).WillReturnError(errors.New("mocked error"))
+	mock.ExpectExec(`INSERT INTO symptom_mapping (.+) ON CONFLICT`).WithArgs("zub", 2, 0.95, true).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	modelsDB := NewModels(db)
+
+	assert.EqualError(t, modelsDB.DB.InsertSymptomMapping(context.Background(), sm), "mocked error")
+	assert.NoError(t, modelsDB.DB.InsertSymptomMapping(context.Background(), sm))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestCreateSymptomMapping(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	sm := types.SymptomMapping{Keyword: "zub", SpecialtyID: 2, Weight: 0.95, Enabled: true}
+
+	mock.ExpectQuery(`INSERT INTO symptom_mapping (.+) RETURNING id`).WithArgs("zub", 2, 0.95, true).WillReturnError(errors.New("mocked error"))
+	mock.ExpectQuery(`INSERT INTO symptom_mapping (.+) RETURNING id`).WithArgs("zub", 2, 0.95, true).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+	modelsDB := NewModels(db)
+
+	_, err = modelsDB.DB.CreateSymptomMapping(context.Background(), sm)
+	assert.EqualError(t, err, "mocked error")
+
+	id, err := modelsDB.DB.CreateSymptomMapping(context.Background(), sm)
+	assert.NoError(t, err)
+	assert.Equal(t, 7, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpdateSymptomMapping(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	sm := types.SymptomMapping{ID: 1, Keyword: "zub", SpecialtyID: 2, Weight: 0.95, Enabled: false}
+
+	mock.ExpectExec(`UPDATE symptom_mapping SET keyword=\$1, specialty_id=\$2, weight=\$3, enabled=\$4 WHERE id=\$5`).WithArgs("zub", 2, 0.95, false, 1).WillReturnError(errors.New("mocked error"))
+	mock.ExpectExec(`UPDATE symptom_mapping SET keyword=\$1, specialty_id=\$2, weight=\$3, enabled=\$4 WHERE id=\$5`).WithArgs("zub", 2, 0.95, false, 1).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	modelsDB := NewModels(db)
+
+	assert.EqualError(t, modelsDB.DB.UpdateSymptomMapping(context.Background(), sm), "mocked error")
+	assert.NoError(t, modelsDB.DB.UpdateSymptomMapping(context.Background(), sm))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestDeleteSymptomMapping(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec(`DELETE FROM symptom_mapping WHERE id = \$1`).WithArgs(1).WillReturnError(errors.New("mocked error"))
+	mock.ExpectExec(`DELETE FROM symptom_mapping WHERE id = \$1`).WithArgs(1).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	modelsDB := NewModels(db)
+
+	assert.EqualError(t, modelsDB.DB.DeleteSymptomMapping(context.Background(), 1), "mocked error")
+	assert.NoError(t, modelsDB.DB.DeleteSymptomMapping(context.Background(), 1))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetSymptomMappingByID(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM symptom_mapping sm JOIN specialty sp ON sp.id = sm.specialty_id WHERE sm.id=\$1`).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "keyword", "specialty_id", "name", "weight", "enabled"}).AddRow(1, "zub", 2, "stomatológia", 0.95, true))
+	mock.ExpectQuery(`SELECT (.+) FROM symptom_mapping`).WithArgs(2).WillReturnError(sql.ErrNoRows)
+
+	modelsDB := NewModels(db)
+
+	sm, err := modelsDB.DB.GetSymptomMappingByID(context.Background(), 1)
+	assert.NoError(t, err)
+	assert.Equal(t, &types.SymptomMapping{ID: 1, Keyword: "zub", SpecialtyID: 2, SpecialtyName: "stomatológia", Weight: 0.95, Enabled: true}, sm)
+
+	sm, err = modelsDB.DB.GetSymptomMappingByID(context.Background(), 2)
+	assert.NoError(t, err)
+	assert.Nil(t, sm)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,250 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/lib/pq"
+)
+
+/*
+GetAllSpecialtiesLocalized returns all specialties with their category in a specific language
+The language is an ISO 639-1 code, missing translations fall back to the original Slovak data
+The function returns a slice of pointers to Specialty structs
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetAllSpecialtiesLocalized(ctx context.Context, language string) ([]*types.Specialty, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT s.id, coalesce(t.name, s.name), coalesce(t.description, s.description), coalesce(t.synonyms, '{}'),
+		c.id, c.code, coalesce(ct.name, c.code), pc.code
+	FROM specialty s
+	LEFT JOIN specialty_translation t ON t.specialty_id = s.id AND t.language = $1
+	LEFT JOIN specialty_category c ON c.id = s.category_id
+	LEFT JOIN specialty_category_translation ct ON ct.category_id = c.id AND ct.language = $1
+	LEFT JOIN specialty_category pc ON pc.id = c.parent_id
+	ORDER BY s.id
+	`
+
+	rows, err := m.DB.QueryContext(ctx, stmt, language)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	var specialties []*types.Specialty
+
+	for rows.Next() {
+		var s types.Specialty
+		var synonyms pq.StringArray
+		var categoryID sql.NullInt64
+		var categoryCode, categoryName, parentCode sql.NullString
+
+		err := rows.Scan(&s.ID, &s.Name, &s.Description, &synonyms, &categoryID, &categoryCode, &categoryName, &parentCode)
+		if err != nil {
+			return nil, err
+		}
+
+		s.Synonyms = synonyms
+		if categoryID.Valid {
+			s.Category = &types.SpecialtyCategory{
+				ID:         int(categoryID.Int64),
+				Code:       categoryCode.String,
+				Name:       categoryName.String,
+				ParentCode: parentCode.String,
+			}
+		}
+		specialties = append(specialties, &s)
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return specialties, nil
+}
+
+/*
+GetTranslatedSpecialtyIDs returns the ids of all specialties that have at least one translation
+The function returns a set of specialty ids
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetTranslatedSpecialtyIDs(ctx context.Context) (map[int]bool, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT DISTINCT specialty_id
+	FROM specialty_translation
+	`
+
+	rows, err := m.DB.QueryContext(ctx, stmt)
+	if err != nil {
+		return nil, err
+	}
+	defer rows.Close()
+
+	ids := make(map[int]bool)
+
+	for rows.Next() {
+		var id int
+		if err := rows.Scan(&id); err != nil {
+			return nil, err
+		}
+		ids[id] = true
+	}
+
+	if err = rows.Err(); err != nil {
+		return nil, err
+	}
+
+	return ids, nil
+}
+
+/*
+UpsertSpecialtyCategory inserts a specialty category or updates the parent of an existing one
+The category is identified by its code, the parent by the code of the parent category
+The function returns the id of the category
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) UpsertSpecialtyCategory(ctx context.Context, code, parentCode string) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	INSERT INTO specialty_category (code, parent_id)
+	VALUES ($1, (SELECT id FROM specialty_category WHERE code=$2))
+	ON CONFLICT (code) DO UPDATE SET parent_id=EXCLUDED.parent_id
+	RETURNING id
+	`
+
+	var id int
+	err := m.DB.QueryRowContext(ctx, stmt, code, parentCode).Scan(&id)
+	if err != nil {
+		return 0, err
+	}
+
+	return id, nil
+}
+
+/*
+UpsertSpecialtyCategoryTranslation inserts or updates the name of a specialty category in one language
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) UpsertSpecialtyCategoryTranslation(ctx context.Context, categoryID int, language, name string) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	INSERT INTO specialty_category_translation (category_id, language, name)
+	VALUES ($1, $2, $3)
+	ON CONFLICT (category_id, language) DO UPDATE SET name=EXCLUDED.name
+	`
+
+	_, err := m.DB.ExecContext(ctx, stmt, categoryID, language, name)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/*
+UpsertSpecialtyTranslation inserts or updates the translation of a specialty in one language
+The t parameter is a SpecialtyTranslation struct
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) UpsertSpecialtyTranslation(ctx context.Context, t types.SpecialtyTranslation) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	INSERT INTO specialty_translation (specialty_id, language, name, description, synonyms)
+	VALUES ($1, $2, $3, $4, $5)
+	ON CONFLICT (specialty_id, language) DO UPDATE SET name=EXCLUDED.name, description=EXCLUDED.description, synonyms=EXCLUDED.synonyms
+	`
+
+	_, err := m.DB.ExecContext(ctx, stmt, t.SpecialtyID, t.Language, t.Name, t.Description, pq.Array(t.Synonyms))
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/*
+UpdateSpecialtyTaxonomy sets the description and category of a specialty
+The id is the id of the specialty
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) UpdateSpecialtyTaxonomy(ctx context.Context, id int, description string, categoryID int) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	UPDATE specialty
+	SET description=$1, category_id=$2
+	WHERE id=$3
+	`
+
+	_, err := m.DB.ExecContext(ctx, stmt, description, categoryID, id)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
+
+/*
+GetSeedVersion returns the version of a seed file last applied to the database
+The name is the name of the seed file
+The function returns 0 if the seed file was never applied
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) GetSeedVersion(ctx context.Context, name string) (int, error) {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	SELECT version
+	FROM seed_version
+	WHERE name=$1
+	`
+
+	var version int
+	err := m.DB.QueryRowContext(ctx, stmt, name).Scan(&version)
+	if err != nil {
+		if errors.Is(err, sql.ErrNoRows) {
+			return 0, nil
+		}
+		return 0, err
+	}
+
+	return version, nil
+}
+
+/*
+SetSeedVersion records the version of a seed file applied to the database
+The function returns an error if there was an issue with the database
+*/
+func (m *DBModel) SetSeedVersion(ctx context.Context, name string, version int) error {
+	ctx, cancel := m.withTimeout(ctx)
+	defer cancel()
+
+	stmt := `
+	INSERT INTO seed_version (name, version, applied_at)
+	VALUES ($1, $2, now())
+	ON CONFLICT (name) DO UPDATE SET version=EXCLUDED.version, applied_at=EXCLUDED.applied_at
+	`
+
+	_, err := m.DB.ExecContext(ctx, stmt, name, version)
+	if err != nil {
+		return err
+	}
+
+	return nil
+}
@@ -0,0 +1,152 @@
+package models
+
+import (
+	"context"
+	"database/sql"
+	"errors"
+	"testing"
+
+	sqlmock "github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/lib/pq"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestGetAllSpecialtiesLocalized_SqlError(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM specialty s").WithArgs("en").WillReturnError(errors.New("mocked error"))
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetAllSpecialtiesLocalized(context.Background(), "en")
+
+	assert.EqualError(t, err, "mocked error")
+	assert.Nil(t, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetAllSpecialtiesLocalized_Success(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "description", "synonyms", "category_id", "category_code", "category_name", "parent_code"}).
+		AddRow(1, "Orthodontics", "Braces", "{orthodontist,braces}", 2, "orthodontics", "Orthodontics", "dentistry").
+		AddRow(2, "ambulancia pediatrie", "", "{}", nil, nil, nil, nil)
+
+	mock.ExpectQuery("SELECT (.+) FROM specialty s").WithArgs("en").WillReturnRows(rows)
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetAllSpecialtiesLocalized(context.Background(), "en")
+
+	expected := []*types.Specialty{
+		{
+			ID:          1,
+			Name:        "Orthodontics",
+			Description: "Braces",
+			Synonyms:    []string{"orthodontist", "braces"},
+			Category:    &types.SpecialtyCategory{ID: 2, Code: "orthodontics", Name: "Orthodontics", ParentCode: "dentistry"},
+		},
+		{
+			ID:       2,
+			Name:     "ambulancia pediatrie",
+			Synonyms: []string{},
+		},
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestGetTranslatedSpecialtyIDs(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT DISTINCT specialty_id FROM specialty_translation").WillReturnRows(sqlmock.NewRows([]string{"specialty_id"}).AddRow(1).AddRow(3))
+
+	modelsDB := NewModels(db)
+	res, err := modelsDB.DB.GetTranslatedSpecialtyIDs(context.Background())
+
+	assert.NoError(t, err)
+	assert.Equal(t, map[int]bool{1: true, 3: true}, res)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertSpecialtyCategory(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("INSERT INTO specialty_category (.+) ON CONFLICT").WithArgs("orthodontics", "dentistry").WillReturnError(errors.New("mocked error"))
+	mock.ExpectQuery("INSERT INTO specialty_category (.+) ON CONFLICT").WithArgs("orthodontics", "dentistry").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(7))
+
+	modelsDB := NewModels(db)
+
+	_, err = modelsDB.DB.UpsertSpecialtyCategory(context.Background(), "orthodontics", "dentistry")
+	assert.EqualError(t, err, "mocked error")
+
+	id, err := modelsDB.DB.UpsertSpecialtyCategory(context.Background(), "orthodontics", "dentistry")
+	assert.NoError(t, err)
+	assert.Equal(t, 7, id)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestUpsertTranslations(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectExec("INSERT INTO specialty_category_translation").WithArgs(7, "en", "Orthodontics").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO specialty_translation").WithArgs(1, "en", "Orthodontics", "Braces", pq.Array([]string{"braces"})).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec(`UPDATE specialty SET description=\$1, category_id=\$2 WHERE id=\$3`).WithArgs("Strojčeky", 7, 1).WillReturnError(errors.New("mocked error"))
+
+	modelsDB := NewModels(db)
+
+	assert.NoError(t, modelsDB.DB.UpsertSpecialtyCategoryTranslation(context.Background(), 7, "en", "Orthodontics"))
+	assert.NoError(t, modelsDB.DB.UpsertSpecialtyTranslation(context.Background(), types.SpecialtyTranslation{SpecialtyID: 1, Language: "en", Name: "Orthodontics", Description: "Braces", Synonyms: []string{"braces"}}))
+	assert.EqualError(t, modelsDB.DB.UpdateSpecialtyTaxonomy(context.Background(), 1, "Strojčeky", 7), "mocked error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeedVersion(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT version FROM seed_version").WithArgs("specialties").WillReturnError(sql.ErrNoRows)
+	mock.ExpectQuery("SELECT version FROM seed_version").WithArgs("specialties").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(2))
+	mock.ExpectQuery("SELECT version FROM seed_version").WithArgs("specialties").WillReturnError(errors.New("mocked error"))
+	mock.ExpectExec("INSERT INTO seed_version").WithArgs("specialties", 3).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	modelsDB := NewModels(db)
+
+	version, err := modelsDB.DB.GetSeedVersion(context.Background(), "specialties")
+	assert.NoError(t, err)
+	assert.Equal(t, 0, version)
+
+	version, err = modelsDB.DB.GetSeedVersion(context.Background(), "specialties")
+	assert.NoError(t, err)
+	assert.Equal(t, 2, version)
+
+	_, err = modelsDB.DB.GetSeedVersion(context.Background(), "specialties")
+	assert.EqualError(t, err, "mocked error")
+
+	assert.NoError(t, modelsDB.DB.SetSeedVersion(context.Background(), "specialties", 3))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
@@ -0,0 +1,282 @@
+package scrapers
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+
+	"github.com/acornak/healthcare-poc/geocoding"
+	"github.com/acornak/healthcare-poc/models"
+	"github.com/acornak/healthcare-poc/textutil"
+	"github.com/acornak/healthcare-poc/types"
+	"go.uber.org/zap"
+)
+
+const (
+	// every address is verified again after this interval
+	addressCheckInterval = 30 * 24 * time.Hour
+	// the number of specialists verified per run, keeps a run well within the provider rate limits
+	addressCheckBatchSize   = 50
+	addressCheckConcurrency = 2
+	// recorded in the history of specialists for the addresses filled in by the check
+	addressCheckActor = "address-check"
+)
+
+// a Slovak postal code, written as "04001" or "040 01", followed by the municipality
+var postalCodeRegexp = regexp.MustCompile(`^(\d{3})\s?(\d{2})\s+(.+)$`)
+
+// a postal code written with a space, "040 01"
+var spacedPostalCodeRegexp = regexp.MustCompile(`\b(\d{3})\s(\d{2})\b`)
+
+/*
+VerifyAddresses reverse geocodes the coordinates of specialists and compares the result with their addresses
+Empty addresses are filled in with the geocoded address, addresses in another municipality or street than the coordinates are flagged as a mismatch
+The stated address is also geocoded to record its distance from the coordinates
+Only the specialists not verified within the last 30 days are checked, at most 50 per run
+The function does nothing when the scraper has no geocoder
+The function returns an error if there was an issue with the database or the geocoder
+*/
+func (s *Scraper) VerifyAddresses(ctx context.Context) error {
+	if s.Geocoder == nil {
+		return nil
+	}
+
+	ctx = models.WithActor(ctx, addressCheckActor)
+
+	specialists, err := s.Models.Addresses.GetSpecialistsForAddressCheck(ctx, addressCheckInterval, addressCheckBatchSize)
+	if err != nil {
+		return err
+	}
+
+	checks := make([]*types.AddressCheck, len(specialists))
+	errs := geocoding.RunBatch(ctx, len(specialists), geocoding.BatchOptions{Concurrency: addressCheckConcurrency}, func(i int) error {
+		check, err := s.checkAddress(ctx, specialists[i])
+		if err != nil {
+			return err
+		}
+		checks[i] = check
+		return s.Models.Addresses.SaveAddressCheck(ctx, *check)
+	})
+
+	counts := map[string]int{}
+	for i, check := range checks {
+		if errs[i] != nil {
+			errs[i] = fmt.Errorf("address check of specialist %d failed: %w", specialists[i].ID, errs[i])
+			continue
+		}
+		counts[check.Status]++
+		if check.Status == types.AddressCheckMismatch {
+			s.Logger.Warn("specialist address does not match its location",
+				zap.Int("specialist_id", check.SpecialistID),
+				zap.String("address", check.StatedAddress),
+				zap.String("geocoded_address", check.GeocodedAddress),
+			)
+		}
+	}
+
+	if len(specialists) > 0 {
+		s.Logger.Info("specialist addresses verified",
+			zap.Int(types.AddressCheckVerified, counts[types.AddressCheckVerified]),
+			zap.Int(types.AddressCheckMismatch, counts[types.AddressCheckMismatch]),
+			zap.Int(types.AddressCheckFilled, counts[types.AddressCheckFilled]),
+			zap.Int(types.AddressCheckUnresolved, counts[types.AddressCheckUnresolved]),
+		)
+	}
+
+	return errors.Join(errs...)
+}
+
+// checkAddress reverse geocodes the location of the specialist and decides the status of its address
+func (s *Scraper) checkAddress(ctx context.Context, specialist *types.Specialist) (*types.AddressCheck, error) {
+	check := &types.AddressCheck{
+		SpecialistID:  specialist.ID,
+		Status:        types.AddressCheckUnresolved,
+		StatedAddress: specialist.Address,
+	}
+
+	location, err := types.ParseLocation(specialist.Location)
+	if err != nil {
+		return check, nil
+	}
+
+	place, err := s.Geocoder.Reverse(ctx, location.Lat, location.Lon)
+	if errors.Is(err, geocoding.ErrNotFound) {
+		return check, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+
+	check.GeocodedAddress = place.DisplayName
+	if place.Address != nil && place.Address.String() != "" {
+		check.GeocodedAddress = place.Address.String()
+	}
+
+	switch {
+	case types.IsEmptyAddress(specialist.Address):
+		// only a street address is good enough to be shown as the address of the specialist
+		if place.Address != nil && place.Address.Street != "" && place.Address.Municipality != "" {
+			check.Status = types.AddressCheckFilled
+			check.DistanceMeters = geocoding.Distance(location.Lat, location.Lon, place.Lat, place.Lon)
+		}
+		return check, nil
+	case addressMatches(specialist.Address, place):
+		check.Status = types.AddressCheckVerified
+	default:
+		check.Status = types.AddressCheckMismatch
+	}
+
+	// how far the stated address is from the coordinates, unknown when the geocoder cannot find it
+	stated, err := s.Geocoder.Search(ctx, specialist.Address)
+	if errors.Is(err, geocoding.ErrNotFound) || (err == nil && len(stated) == 0) {
+		return check, nil
+	}
+	if err != nil {
+		return nil, err
+	}
+	check.DistanceMeters = geocoding.Distance(location.Lat, location.Lon, stated[0].Lat, stated[0].Lon)
+
+	return check, nil
+}
+
+/*
+addressMatches reports whether the stated address lies in the same municipality and street as the geocoded place
+Street names match when every word of the shorter one starts the same as a word of the other,
+so abbreviations such as "Nám. SNP" for "Námestie SNP" are accepted, house numbers are not compared
+*/
+func addressMatches(stated string, place *types.Place) bool {
+	return municipalityMatches(stated, place) && streetMatches(stated, place)
+}
+
+// municipalityMatches reports whether the stated address lies in the municipality, or has the postal code, of the geocoded place
+func municipalityMatches(stated string, place *types.Place) bool {
+	statedWords := wordSet(joinPostalCodes(stated))
+
+	if place.Address != nil {
+		if postalCode := place.Address.PostalCode; postalCode != "" && statedWords[postalCode] {
+			return true
+		}
+		if municipality := place.Address.Municipality; municipality != "" {
+			return containsWords(statedWords, textutil.Words(municipality))
+		}
+	}
+
+	municipality := statedMunicipality(stated)
+	if municipality == "" {
+		// nothing to compare with
+		return true
+	}
+
+	return containsWords(wordSet(joinPostalCodes(place.DisplayName)), textutil.Words(municipality))
+}
+
+// streetMatches reports whether the street of the stated address is the street of the geocoded place
+func streetMatches(stated string, place *types.Place) bool {
+	street := statedStreet(stated)
+	if len(street) == 0 {
+		// nothing to compare with
+		return true
+	}
+
+	// villages without street names number the houses after the village, "Ruskov 12, 04419 Ruskov"
+	municipality := statedMunicipality(stated)
+	if place.Address != nil && place.Address.Municipality != "" {
+		municipality += " " + place.Address.Municipality
+	}
+	if containsWords(wordSet(municipality), street) {
+		return true
+	}
+
+	if place.Address == nil {
+		return prefixWordsMatch(street, textutil.Words(place.DisplayName))
+	}
+
+	if place.Address.Street == "" {
+		return true
+	}
+
+	placeStreet := textutil.Words(place.Address.Street)
+	if len(placeStreet) < len(street) {
+		return prefixWordsMatch(placeStreet, street)
+	}
+
+	return prefixWordsMatch(street, placeStreet)
+}
+
+// statedStreet returns the folded words of the street of an address without the house number, empty if there is none
+func statedStreet(address string) []string {
+	line := strings.TrimSpace(strings.Split(address, ",")[0])
+	if postalCodeRegexp.MatchString(line) {
+		return nil
+	}
+
+	street := []string{}
+	for _, word := range textutil.Words(line) {
+		if strings.IndexFunc(word, unicode.IsDigit) < 0 {
+			street = append(street, word)
+		}
+	}
+
+	return street
+}
+
+// prefixWordsMatch reports whether every word starts the same as one of the other words, one being a prefix of the other
+func prefixWordsMatch(words, others []string) bool {
+	for _, word := range words {
+		found := false
+		for _, other := range others {
+			if strings.HasPrefix(word, other) || strings.HasPrefix(other, word) {
+				found = true
+				break
+			}
+		}
+		if !found {
+			return false
+		}
+	}
+
+	return true
+}
+
+// statedMunicipality returns the municipality following the postal code of an address, empty if there is none
+func statedMunicipality(address string) string {
+	for _, part := range strings.Split(address, ",") {
+		if matches := postalCodeRegexp.FindStringSubmatch(strings.TrimSpace(part)); matches != nil {
+			return matches[3]
+		}
+	}
+
+	return ""
+}
+
+// joinPostalCodes removes the space inside postal codes written as "040 01"
+func joinPostalCodes(text string) string {
+	return spacedPostalCodeRegexp.ReplaceAllString(text, "$1$2")
+}
+
+func wordSet(text string) map[string]bool {
+	words := make(map[string]bool)
+	for _, word := range textutil.Words(text) {
+		words[word] = true
+	}
+
+	return words
+}
+
+func containsWords(set map[string]bool, words []string) bool {
+	if len(words) == 0 {
+		return false
+	}
+
+	for _, word := range words {
+		if !set[word] {
+			return false
+		}
+	}
+
+	return true
+}
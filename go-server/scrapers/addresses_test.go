@@ -0,0 +1,155 @@
+package scrapers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/geocoding"
+	"github.com/acornak/healthcare-poc/models"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+type stubReverseGeocoder struct {
+	place *types.Place
+	err   error
+	// the places found for a stated address, none when nil
+	found []*types.Place
+}
+
+func (s *stubReverseGeocoder) Name() string {
+	return "stub"
+}
+
+func (s *stubReverseGeocoder) Search(ctx context.Context, query string) ([]*types.Place, error) {
+	if s.found == nil {
+		return nil, geocoding.ErrNotFound
+	}
+	return s.found, nil
+}
+
+func (s *stubReverseGeocoder) Reverse(ctx context.Context, lat, lon float64) (*types.Place, error) {
+	return s.place, s.err
+}
+
+var hlavna = &types.Place{
+	Lat:         48.7203,
+	Lon:         21.2578,
+	DisplayName: "1, Hlavná, Staré Mesto, Košice, okres Košice I, Košický kraj, 040 01, Slovensko",
+	Address:     &types.PlaceAddress{Street: "Hlavná", HouseNumber: "1", PostalCode: "04001", Municipality: "Košice"},
+}
+
+func TestCheckAddress(t *testing.T) {
+	tests := []struct {
+		name     string
+		address  string
+		location string
+		place    *types.Place
+		err      error
+		status   string
+		geocoded string
+	}{
+		{"same address", "Hlavná 1, 04001 Košice, Slovenská republika", "POINT(21.2578 48.7203)", hlavna, nil, types.AddressCheckVerified, "Hlavná 1, 04001 Košice, Slovenská republika"},
+		{"other house number", "Hlavná 25, 04001 Košice", "POINT(21.2578 48.7203)", hlavna, nil, types.AddressCheckVerified, "Hlavná 1, 04001 Košice, Slovenská republika"},
+		{"other street in the same town", "Mlynská 5, Košice", "POINT(21.2578 48.7203)", hlavna, nil, types.AddressCheckMismatch, "Hlavná 1, 04001 Košice, Slovenská republika"},
+		{"abbreviated street", "Nám. SNP 3, 07101 Michalovce", "POINT(21.9195 48.7553)", &types.Place{DisplayName: "Námestie SNP, Michalovce", Address: &types.PlaceAddress{Street: "Námestie slobody SNP", PostalCode: "07101", Municipality: "Michalovce"}}, nil, types.AddressCheckVerified, "Námestie slobody SNP, 07101 Michalovce, Slovenská republika"},
+		{"village without streets", "Ruskov 12, 04419 Ruskov", "POINT(21.4367 48.6611)", &types.Place{DisplayName: "Hlavná, Ruskov", Address: &types.PlaceAddress{Street: "Hlavná", PostalCode: "04419", Municipality: "Ruskov"}}, nil, types.AddressCheckVerified, "Hlavná, 04419 Ruskov, Slovenská republika"},
+		{"same postal code", "Hlavná 1, 040 01 Kosice-Stare Mesto", "POINT(21.2578 48.7203)", hlavna, nil, types.AddressCheckVerified, "Hlavná 1, 04001 Košice, Slovenská republika"},
+		{"other town", "Ulica 9, 07101 Michalovce, Slovenská republika", "POINT(21.2578 48.7203)", hlavna, nil, types.AddressCheckMismatch, "Hlavná 1, 04001 Košice, Slovenská republika"},
+		{"empty address", "", "POINT(21.2578 48.7203)", hlavna, nil, types.AddressCheckFilled, "Hlavná 1, 04001 Košice, Slovenská republika"},
+		{"legacy empty address", " ,  , Slovenská republika", "POINT(21.2578 48.7203)", hlavna, nil, types.AddressCheckFilled, "Hlavná 1, 04001 Košice, Slovenská republika"},
+		{"empty address without a street", "", "POINT(21.2578 48.7203)", &types.Place{DisplayName: "Košice, Slovensko", Type: types.PlaceTypeCity}, nil, types.AddressCheckUnresolved, "Košice, Slovensko"},
+		{"no structured address", "Hlavná 1, 04001 Košice", "POINT(21.2578 48.7203)", &types.Place{DisplayName: "Hlavná 1, Košice, Slovensko"}, nil, types.AddressCheckVerified, "Hlavná 1, Košice, Slovensko"},
+		{"no structured address in other street", "Mlynská 5, 04001 Košice", "POINT(21.2578 48.7203)", &types.Place{DisplayName: "Hlavná 1, Košice, Slovensko"}, nil, types.AddressCheckMismatch, "Hlavná 1, Košice, Slovensko"},
+		{"no structured address in other town", "Hlavná 1, 04001 Košice", "POINT(21.2578 48.7203)", &types.Place{DisplayName: "Michalovce, Slovensko"}, nil, types.AddressCheckMismatch, "Michalovce, Slovensko"},
+		{"nothing found", "Hlavná 1, 04001 Košice", "POINT(21.2578 48.7203)", nil, geocoding.ErrNotFound, types.AddressCheckUnresolved, ""},
+		{"invalid location", "Hlavná 1, 04001 Košice", "0101000020E6100000", nil, nil, types.AddressCheckUnresolved, ""},
+	}
+
+	for _, test := range tests {
+		scraper := &Scraper{Logger: zap.NewNop(), Geocoder: &stubReverseGeocoder{place: test.place, err: test.err}}
+
+		check, err := scraper.checkAddress(context.Background(), &types.Specialist{ID: 7, Location: test.location, Address: test.address})
+
+		assert.NoError(t, err, test.name)
+		assert.Equal(t, 7, check.SpecialistID, test.name)
+		assert.Equal(t, test.address, check.StatedAddress, test.name)
+		assert.Equal(t, test.status, check.Status, test.name)
+		assert.Equal(t, test.geocoded, check.GeocodedAddress, test.name)
+	}
+}
+
+func TestCheckAddress_Distance(t *testing.T) {
+	// the stated address is geocoded 1.1 km north of the coordinates of the specialist
+	geocoder := &stubReverseGeocoder{place: hlavna, found: []*types.Place{{Lat: 48.7303, Lon: 21.2578}}}
+	scraper := &Scraper{Logger: zap.NewNop(), Geocoder: geocoder}
+
+	check, err := scraper.checkAddress(context.Background(), &types.Specialist{ID: 7, Location: "POINT(21.2578 48.7203)", Address: "Hlavná 1, 04001 Košice"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, types.AddressCheckVerified, check.Status)
+	assert.InDelta(t, 1112, check.DistanceMeters, 5)
+
+	// an address the geocoder cannot find has no distance
+	geocoder.found = nil
+	check, err = scraper.checkAddress(context.Background(), &types.Specialist{ID: 7, Location: "POINT(21.2578 48.7203)", Address: "Hlavná 1, 04001 Košice"})
+
+	assert.NoError(t, err)
+	assert.Equal(t, 0.0, check.DistanceMeters)
+}
+
+func TestCheckAddress_GeocoderError(t *testing.T) {
+	scraper := &Scraper{Logger: zap.NewNop(), Geocoder: &stubReverseGeocoder{err: &geocoding.RateLimitError{Provider: "stub", RetryAfter: 3600e9}}}
+
+	check, err := scraper.checkAddress(context.Background(), &types.Specialist{ID: 7, Location: "POINT(21.2578 48.7203)"})
+
+	assert.EqualError(t, err, "stub returned status 429")
+	assert.Nil(t, check)
+}
+
+func TestVerifyAddresses(t *testing.T) {
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s LEFT JOIN specialist_address_check`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "location", "address"}).AddRow(8, "Poliklinika", "POINT(21.2578 48.7203)", ""))
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("address-check").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectExec(`UPDATE specialist SET address`).WithArgs("Hlavná 1, 04001 Košice, Slovenská republika", 8, "").WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectExec(`INSERT INTO specialist_address_check`).WithArgs(8, "filled", "", "Hlavná 1, 04001 Košice, Slovenská republika", sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(0, 1))
+	mock.ExpectCommit()
+
+	scraper := &Scraper{Logger: zap.NewNop(), Models: models.NewModels(db), Geocoder: &stubReverseGeocoder{place: hlavna}}
+
+	err = scraper.VerifyAddresses(context.Background())
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestVerifyAddresses_Errors(t *testing.T) {
+	// without a geocoder nothing is checked
+	assert.NoError(t, (&Scraper{Logger: zap.NewNop()}).VerifyAddresses(context.Background()))
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s`).
+		WillReturnRows(sqlmock.NewRows([]string{"id", "name", "location", "address"}).AddRow(8, "Poliklinika", "POINT(21.2578 48.7203)", ""))
+
+	scraper := &Scraper{Logger: zap.NewNop(), Models: models.NewModels(db), Geocoder: &stubReverseGeocoder{err: errors.New("connection refused")}}
+
+	err = scraper.VerifyAddresses(context.Background())
+
+	assert.EqualError(t, err, "address check of specialist 8 failed: connection refused")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
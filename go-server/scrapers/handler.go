@@ -1,36 +1,44 @@
 package scrapers
 
 import (
+	"context"
 	"errors"
 
+	"github.com/acornak/healthcare-poc/models"
+	"github.com/acornak/healthcare-poc/textutil"
 	"github.com/acornak/healthcare-poc/types"
 	"go.uber.org/zap"
 )
 
-func (s *Scraper) insertSpecialties(specialists []struct {
+func (s *Scraper) insertSpecialties(ctx context.Context, specialists []struct {
 	Properties types.GeoportalSpecialist
 }) error {
-	specialtiesMap := make(map[string]bool)
+	// variants differing only in case, spacing or diacritics share the normalized name
+	specialtiesMap := make(map[string]string)
 
 	for _, specialist := range specialists {
-		specialtiesMap[specialist.Properties.Specialization] = true
+		name := specialist.Properties.SpecialtyName()
+		normalized := textutil.Normalize(name)
+		if _, ok := specialtiesMap[normalized]; !ok {
+			specialtiesMap[normalized] = name
+		}
 	}
 
-	for specialty := range specialtiesMap {
+	for _, specialty := range specialtiesMap {
 		castedSpecialty := types.Specialty{Name: specialty}
 
-		found, err := s.Models.DB.GetSpecialtyByName(castedSpecialty.Name)
+		found, err := s.Models.Specialties.GetSpecialtyByNormalizedName(ctx, castedSpecialty.Name)
 		if err != nil {
 			return err
 		}
 
 		if found == nil {
-			err = s.Models.DB.InsertSpecialty(castedSpecialty)
+			id, err := s.Models.Specialties.InsertSpecialty(ctx, castedSpecialty)
 			if err != nil {
 				return err
 			}
 
-			s.Logger.Info("specialty inserted", zap.String("name", castedSpecialty.Name))
+			s.Logger.Info("specialty inserted", zap.Int("id", id), zap.String("name", castedSpecialty.Name))
 		}
 
 	}
@@ -38,7 +46,12 @@ func (s *Scraper) insertSpecialties(specialists []struct {
 	return nil
 }
 
-func (s *Scraper) ScrapeHandler() error {
+// scraperActor is recorded in the history of specialists for the changes made by the sync from the geoportal
+const scraperActor = "scraper"
+
+func (s *Scraper) ScrapeHandler(ctx context.Context) error {
+	ctx = models.WithActor(ctx, scraperActor)
+
 	// scrape data from geoportal API
 	specialists, err := s.GetSpecialists()
 	if err != nil {
@@ -46,35 +59,73 @@ func (s *Scraper) ScrapeHandler() error {
 	}
 
 	// get all existing specialties
-	err = s.insertSpecialties(specialists.Features)
+	err = s.insertSpecialties(ctx, specialists.Features)
 	if err != nil {
 		return err
 	}
 
 	for _, specialist := range specialists.Features {
-		// check if specialist already exists
-		found, err := s.Models.DB.GetSpecialistByName(specialist.Properties.Name)
+		// get specialty by normalized name, following aliases of merged specialties
+		specialty, err := s.Models.Specialties.GetSpecialtyByNormalizedName(ctx, specialist.Properties.SpecialtyName())
+		if err != nil {
+			return err
+		}
+
+		if specialty == nil {
+			return errors.New("specialty not found")
+		}
+
+		castedSpecialist := specialist.Properties.CastToDbType(specialty.ID)
+
+		// check if specialist already exists, an overridden name is matched by its source value
+		found, err := s.Models.Specialists.GetSpecialistByName(ctx, specialist.Properties.Name)
 		if err != nil {
 			return err
 		}
 
 		if found != nil {
+			// update the specialist with the source data, fields overridden by an admin are kept
+			changed, err := s.Models.Specialists.SyncSpecialist(ctx, found.ID, castedSpecialist)
+			if errors.Is(err, models.ErrStaleVersion) || errors.Is(err, models.ErrNotFound) {
+				// changed or deleted by an admin in the meantime, the next scrape picks it up
+				s.Logger.Warn("specialist not synced", zap.Int("id", found.ID), zap.Error(err))
+				continue
+			}
+			if err != nil {
+				return err
+			}
+
+			if changed {
+				s.Logger.Info("specialist updated", zap.Int("id", found.ID), zap.String("name", castedSpecialist.Name))
+			}
+
 			continue
 		}
 
-		// get specialty by name
-		specialty, err := s.Models.DB.GetSpecialtyByName(specialist.Properties.Specialization)
+		// a duplicate merged into another specialist by an admin is not inserted again
+		survivorID, err := s.Models.Specialists.GetSpecialistAlias(ctx, specialist.Properties.Name)
 		if err != nil {
 			return err
 		}
 
-		if specialty == nil {
-			return errors.New("specialty not found")
+		if survivorID != 0 {
+			s.Logger.Debug("specialist merged, skipped", zap.Int("survivor_id", survivorID), zap.String("name", castedSpecialist.Name))
+			continue
+		}
+
+		// a specialist deleted by an admin is not inserted again either
+		deleted, err := s.Models.Specialists.IsSpecialistDeleted(ctx, specialist.Properties.Name)
+		if err != nil {
+			return err
+		}
+
+		if deleted {
+			s.Logger.Debug("specialist deleted, skipped", zap.String("name", castedSpecialist.Name))
+			continue
 		}
 
 		// insert specialist
-		castedSpecialist := specialist.Properties.CastToDbType(specialty.ID)
-		err = s.Models.DB.InsertSpecialist(castedSpecialist)
+		_, err = s.Models.Specialists.InsertSpecialist(ctx, castedSpecialist)
 		if err != nil {
 			return err
 		}
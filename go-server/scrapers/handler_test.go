@@ -1,6 +1,7 @@
 package scrapers
 
 import (
+	"context"
 	"errors"
 	"io"
 	"net/http"
@@ -27,14 +28,14 @@ func TestInsertSpecialties_GetSpecialtyError(t *testing.T) {
 	}
 	defer db.Close()
 
-	mock.ExpectQuery("SELECT (.+) FROM specialty WHERE name").WithArgs("ortoped").WillReturnError(errors.New("mocked error"))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnError(errors.New("mocked error"))
 
 	scraper := &Scraper{
 		Logger: logger,
 		Models: models.NewModels(db),
 	}
 
-	err = scraper.insertSpecialties([]struct {
+	err = scraper.insertSpecialties(context.Background(), []struct {
 		Properties types.GeoportalSpecialist
 	}{{Properties: types.GeoportalSpecialist{Specialization: "ortoped"}}})
 
@@ -56,14 +57,14 @@ func TestInsertSpecialties_SpecialtyFound(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "ortoped", "test")
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE name=\$1`).WithArgs("ortoped").WillReturnRows(rows)
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rows)
 
 	scraper := &Scraper{
 		Logger: logger,
 		Models: models.NewModels(db),
 	}
 
-	err = scraper.insertSpecialties([]struct {
+	err = scraper.insertSpecialties(context.Background(), []struct {
 		Properties types.GeoportalSpecialist
 	}{{Properties: types.GeoportalSpecialist{Specialization: "ortoped"}}})
 
@@ -85,15 +86,15 @@ func TestInsertSpecialties_InsertError(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id", "name", "description"})
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE name=\$1`).WithArgs("ortoped").WillReturnRows(rows)
-	mock.ExpectExec("INSERT INTO specialty").WithArgs("ortoped", "").WillReturnError(errors.New("mocked error"))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rows)
+	mock.ExpectQuery("INSERT INTO specialty").WithArgs("ortoped", "ortoped", "").WillReturnError(errors.New("mocked error"))
 
 	scraper := &Scraper{
 		Logger: logger,
 		Models: models.NewModels(db),
 	}
 
-	err = scraper.insertSpecialties([]struct {
+	err = scraper.insertSpecialties(context.Background(), []struct {
 		Properties types.GeoportalSpecialist
 	}{{Properties: types.GeoportalSpecialist{Specialization: "ortoped"}}})
 
@@ -115,15 +116,15 @@ func TestInsertSpecialties_Success(t *testing.T) {
 
 	rows := sqlmock.NewRows([]string{"id", "name", "description"})
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE name=\$1`).WithArgs("ortoped").WillReturnRows(rows)
-	mock.ExpectExec("INSERT INTO specialty").WithArgs("ortoped", "").WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rows)
+	mock.ExpectQuery("INSERT INTO specialty").WithArgs("ortoped", "ortoped", "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
 
 	scraper := &Scraper{
 		Logger: logger,
 		Models: models.NewModels(db),
 	}
 
-	err = scraper.insertSpecialties([]struct {
+	err = scraper.insertSpecialties(context.Background(), []struct {
 		Properties types.GeoportalSpecialist
 	}{{Properties: types.GeoportalSpecialist{Specialization: "ortoped"}}})
 
@@ -131,6 +132,40 @@ func TestInsertSpecialties_Success(t *testing.T) {
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestInsertSpecialties_MergesVariants(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rows := sqlmock.NewRows([]string{"id", "name", "description"})
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ocne lekarstvo").WillReturnRows(rows)
+	mock.ExpectQuery("INSERT INTO specialty").WithArgs("očné lekárstvo", "ocne lekarstvo", "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+
+	scraper := &Scraper{
+		Logger: logger,
+		Models: models.NewModels(db),
+	}
+
+	err = scraper.insertSpecialties(context.Background(), []struct {
+		Properties types.GeoportalSpecialist
+	}{
+		{Properties: types.GeoportalSpecialist{Specialization: "očné lekárstvo"}},
+		{Properties: types.GeoportalSpecialist{Specialization: "Očné  lekárstvo "}},
+		{Properties: types.GeoportalSpecialist{Specialization: "OCNE LEKARSTVO"}},
+	})
+
+	assert.Nil(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestScraperHandler_GetSpecialistsError(t *testing.T) {
 	os.Setenv("SCRAPER_SPECIALISTS_URL", "http://example.com")
 
@@ -144,7 +179,7 @@ func TestScraperHandler_GetSpecialistsError(t *testing.T) {
 		Get:    func(url string) (*http.Response, error) { return nil, errors.New("http get error") },
 	}
 
-	err = scraper.ScrapeHandler()
+	err = scraper.ScrapeHandler(context.Background())
 	assert.Equal(t, "http get error", err.Error())
 }
 
@@ -162,7 +197,7 @@ func TestScraperHandler_InsertSpecialtiesError(t *testing.T) {
 	}
 	defer db.Close()
 
-	mock.ExpectQuery("SELECT (.+) FROM specialty WHERE name").WithArgs("ortoped").WillReturnError(errors.New("mocked error"))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnError(errors.New("mocked error"))
 
 	resp := `{"features":[{"properties":{"id":1, "druh_zariadenia": "ortoped"}}]}`
 
@@ -177,7 +212,7 @@ func TestScraperHandler_InsertSpecialtiesError(t *testing.T) {
 		Models: models.NewModels(db),
 	}
 
-	err = scraper.ScrapeHandler()
+	err = scraper.ScrapeHandler(context.Background())
 
 	assert.Equal(t, "mocked error", err.Error())
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -198,10 +233,12 @@ func TestScraperHandler_GetSpecialistByNameError(t *testing.T) {
 	defer db.Close()
 
 	rows := sqlmock.NewRows([]string{"id", "name", "description"})
+	rowsSpecialtyByName := sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "ortoped", "")
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE name=\$1`).WithArgs("ortoped").WillReturnRows(rows)
-	mock.ExpectExec("INSERT INTO specialty").WithArgs("ortoped", "").WillReturnResult(sqlmock.NewResult(1, 1))
-	mock.ExpectQuery(`SELECT (.+) FROM specialist WHERE name=`).WithArgs("John Doe, Md.").WillReturnError(errors.New("mocked error"))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rows)
+	mock.ExpectQuery("INSERT INTO specialty").WithArgs("ortoped", "ortoped", "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialtyByName)
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s (.+) WHERE coalesce\(o.source_value, s.name\)=\$1`).WithArgs("John Doe, Md.").WillReturnError(errors.New("mocked error"))
 
 	resp := `{"features":[{"properties":{"id":1, "nazov_zariadenia": "John Doe, Md.", "druh_zariadenia": "ortoped"}}]}`
 
@@ -216,7 +253,7 @@ func TestScraperHandler_GetSpecialistByNameError(t *testing.T) {
 		Models: models.NewModels(db),
 	}
 
-	err = scraper.ScrapeHandler()
+	err = scraper.ScrapeHandler(context.Background())
 
 	assert.Equal(t, "mocked error", err.Error())
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -237,12 +274,83 @@ func TestScraperHandler_SpecialistFound(t *testing.T) {
 	defer db.Close()
 
 	rowsSpecialty := sqlmock.NewRows([]string{"id", "name", "description"})
-	rowsSpecialist := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"}).
-		AddRow(1, "John Doe", 1, "New York", "123 Main St", "https://example.com", "123-456-7890", "me@example.com", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "7:00 - 12:00, 13:00 - 15:00", "", "")
+	rowsSpecialtyByName := sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "ortoped", "")
+	rowsSpecialist := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"}).
+		AddRow(1, "John Doe", 1, "POINT(0 0)", "Hlavná 1", nil, ", ", nil, nil, nil, nil, nil, nil, nil, nil, nil, 3, "{name}")
+	rowsLocked := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden", "insurers", "kpzs"}).
+		AddRow(1, "John Doe", 1, "POINT(0 0)", "Hlavná 1", nil, ", ", nil, nil, nil, nil, nil, nil, nil, nil, nil, 3, "{name}", "{}", nil)
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialty)
+	mock.ExpectQuery("INSERT INTO specialty").WithArgs("ortoped", "ortoped", "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialtyByName)
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s (.+) WHERE coalesce\(o.source_value, s.name\)=\$1`).WithArgs("John Doe, Md.").WillReturnRows(rowsSpecialist)
+
+	// the overridden name is kept, the address is updated from the source
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("scraper").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=\$1 FOR UPDATE OF s`).WithArgs(1).WillReturnRows(rowsLocked)
+	mock.ExpectQuery(`SELECT field, source_value FROM specialist_override WHERE specialist_id=\$1`).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"field", "source_value"}).AddRow("name", "John Doe, Md."))
+	mock.ExpectQuery(`UPDATE specialist SET address=NULLIF\(\$1, ''\), version=version\+1, updated_at=now\(\) WHERE id=\$2 AND version=\$3 RETURNING version`).
+		WithArgs("", 1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(4))
+	mock.ExpectCommit()
+
+	resp := `{"features":[{"properties":{"id":1, "nazov_zariadenia": "John Doe, Md.", "druh_zariadenia": "ortoped"}}]}`
+
+	scraper := &Scraper{
+		Logger: logger,
+		Get: func(url string) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(resp)),
+			}, nil
+		},
+		Models: models.NewModels(db),
+	}
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialty)
-	mock.ExpectExec("INSERT INTO specialty").WithArgs("ortoped", "").WillReturnResult(sqlmock.NewResult(1, 1))
-	mock.ExpectQuery(`SELECT (.+) FROM specialist WHERE name=`).WithArgs("John Doe, Md.").WillReturnRows(rowsSpecialist)
+	err = scraper.ScrapeHandler(context.Background())
+
+	assert.Nil(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScraperHandler_SpecialistSyncStale(t *testing.T) {
+	os.Setenv("SCRAPER_SPECIALISTS_URL", "http://example.com")
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rowsSpecialty := sqlmock.NewRows([]string{"id", "name", "description"})
+	rowsSpecialtyByName := sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "ortoped", "")
+	rowsSpecialist := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"}).
+		AddRow(1, "John Doe", 1, "POINT(0 0)", "Hlavná 1", nil, ", ", nil, nil, nil, nil, nil, nil, nil, nil, nil, 3, "{name}")
+	rowsLocked := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden", "insurers", "kpzs"}).
+		AddRow(1, "John Doe", 1, "POINT(0 0)", "Hlavná 1", nil, ", ", nil, nil, nil, nil, nil, nil, nil, nil, nil, 3, "{name}", "{}", nil)
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialty)
+	mock.ExpectQuery("INSERT INTO specialty").WithArgs("ortoped", "ortoped", "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialtyByName)
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s (.+) WHERE coalesce\(o.source_value, s.name\)=\$1`).WithArgs("John Doe, Md.").WillReturnRows(rowsSpecialist)
+
+	// the specialist was changed by an admin during the sync, it is skipped until the next scrape
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("scraper").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s WHERE s.id=\$1 FOR UPDATE OF s`).WithArgs(1).WillReturnRows(rowsLocked)
+	mock.ExpectQuery(`SELECT field, source_value FROM specialist_override WHERE specialist_id=\$1`).WithArgs(1).
+		WillReturnRows(sqlmock.NewRows([]string{"field", "source_value"}).AddRow("name", "John Doe, Md."))
+	mock.ExpectQuery(`UPDATE specialist SET address=NULLIF\(\$1, ''\), version=version\+1, updated_at=now\(\) WHERE id=\$2 AND version=\$3 RETURNING version`).
+		WithArgs("", 1, 3).
+		WillReturnRows(sqlmock.NewRows([]string{"version"}))
+	mock.ExpectRollback()
 
 	resp := `{"features":[{"properties":{"id":1, "nazov_zariadenia": "John Doe, Md.", "druh_zariadenia": "ortoped"}}]}`
 
@@ -257,7 +365,7 @@ func TestScraperHandler_SpecialistFound(t *testing.T) {
 		Models: models.NewModels(db),
 	}
 
-	err = scraper.ScrapeHandler()
+	err = scraper.ScrapeHandler(context.Background())
 
 	assert.Nil(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -278,12 +386,10 @@ func TestScraperHandler_GetSpecialtyByNameError(t *testing.T) {
 	defer db.Close()
 
 	rowsSpecialty := sqlmock.NewRows([]string{"id", "name", "description"})
-	rowsSpecialist := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"})
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialty)
-	mock.ExpectExec("INSERT INTO specialty").WithArgs("ortoped", "").WillReturnResult(sqlmock.NewResult(1, 1))
-	mock.ExpectQuery(`SELECT (.+) FROM specialist WHERE name=`).WithArgs("John Doe, Md.").WillReturnRows(rowsSpecialist)
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE name=\$1`).WithArgs("ortoped").WillReturnError(errors.New("mocked error"))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialty)
+	mock.ExpectQuery("INSERT INTO specialty").WithArgs("ortoped", "ortoped", "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnError(errors.New("mocked error"))
 
 	resp := `{"features":[{"properties":{"id":1, "nazov_zariadenia": "John Doe, Md.", "druh_zariadenia": "ortoped"}}]}`
 
@@ -298,7 +404,7 @@ func TestScraperHandler_GetSpecialtyByNameError(t *testing.T) {
 		Models: models.NewModels(db),
 	}
 
-	err = scraper.ScrapeHandler()
+	err = scraper.ScrapeHandler(context.Background())
 
 	assert.Equal(t, "mocked error", err.Error())
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -319,12 +425,10 @@ func TestScraperHandler_SpecialtyNotFound(t *testing.T) {
 	defer db.Close()
 
 	rowsSpecialty := sqlmock.NewRows([]string{"id", "name", "description"})
-	rowsSpecialist := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"})
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialty)
-	mock.ExpectExec("INSERT INTO specialty").WithArgs("ortoped", "").WillReturnResult(sqlmock.NewResult(1, 1))
-	mock.ExpectQuery(`SELECT (.+) FROM specialist WHERE name=`).WithArgs("John Doe, Md.").WillReturnRows(rowsSpecialist)
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE name=\$1`).WithArgs("ortoped").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialty)
+	mock.ExpectQuery("INSERT INTO specialty").WithArgs("ortoped", "ortoped", "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}))
 
 	resp := `{"features":[{"properties":{"id":1, "nazov_zariadenia": "John Doe, Md.", "druh_zariadenia": "ortoped"}}]}`
 
@@ -339,7 +443,7 @@ func TestScraperHandler_SpecialtyNotFound(t *testing.T) {
 		Models: models.NewModels(db),
 	}
 
-	err = scraper.ScrapeHandler()
+	err = scraper.ScrapeHandler(context.Background())
 
 	assert.Equal(t, "specialty not found", err.Error())
 	assert.NoError(t, mock.ExpectationsWereMet())
@@ -360,17 +464,22 @@ func TestScraperHandler_InsertSpecialistError(t *testing.T) {
 	defer db.Close()
 
 	rowsSpecialty := sqlmock.NewRows([]string{"id", "name", "description"})
-	rowsSpecialist := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"})
+	rowsSpecialist := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"})
 	rowsSpecialtyByName := sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "ortoped", "")
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialty)
-	mock.ExpectExec("INSERT INTO specialty").WithArgs("ortoped", "").WillReturnResult(sqlmock.NewResult(1, 1))
-	mock.ExpectQuery(`SELECT (.+) FROM specialist WHERE name=`).WithArgs("John Doe, Md.").WillReturnRows(rowsSpecialist)
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialtyByName)
-
-	mock.ExpectExec(`INSERT INTO specialist`).
-		WithArgs("John Doe, Md.", 1, "POINT(0 0)", " ,  , Slovenská republika", "", ", ", "", "", "", "", "", "", "", "").
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialty)
+	mock.ExpectQuery("INSERT INTO specialty").WithArgs("ortoped", "ortoped", "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialtyByName)
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s (.+) WHERE coalesce\(o.source_value, s.name\)=\$1`).WithArgs("John Doe, Md.").WillReturnRows(rowsSpecialist)
+	mock.ExpectQuery(`SELECT specialist_id FROM specialist_alias WHERE name=\$1`).WithArgs("John Doe, Md.").WillReturnRows(sqlmock.NewRows([]string{"specialist_id"}))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM specialist_tombstone WHERE name=\$1\)`).WithArgs("John Doe, Md.").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("scraper").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`INSERT INTO specialist`).
+		WithArgs("John Doe, Md.", 1, "POINT(0 0)", "", "", ", ", "", "", "", "", "", "", "", "", "", "{}", "").
 		WillReturnError(errors.New("mocked error"))
+	mock.ExpectRollback()
 
 	resp := `{"features":[{"properties":{"id":1, "nazov_zariadenia": "John Doe, Md.", "druh_zariadenia": "ortoped"}}]}`
 
@@ -385,12 +494,101 @@ func TestScraperHandler_InsertSpecialistError(t *testing.T) {
 		Models: models.NewModels(db),
 	}
 
-	err = scraper.ScrapeHandler()
+	err = scraper.ScrapeHandler(context.Background())
 
 	assert.Equal(t, "mocked error", err.Error())
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
 
+func TestScraperHandler_SpecialistMerged(t *testing.T) {
+	os.Setenv("SCRAPER_SPECIALISTS_URL", "http://example.com")
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rowsSpecialty := sqlmock.NewRows([]string{"id", "name", "description"})
+	rowsSpecialist := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"})
+	rowsSpecialtyByName := sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "ortoped", "")
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialty)
+	mock.ExpectQuery("INSERT INTO specialty").WithArgs("ortoped", "ortoped", "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialtyByName)
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s (.+) WHERE coalesce\(o.source_value, s.name\)=\$1`).WithArgs("John Doe, Md.").WillReturnRows(rowsSpecialist)
+	// merged into specialist 2 by an admin, it is not inserted again
+	mock.ExpectQuery(`SELECT specialist_id FROM specialist_alias WHERE name=\$1`).WithArgs("John Doe, Md.").WillReturnRows(sqlmock.NewRows([]string{"specialist_id"}).AddRow(2))
+
+	resp := `{"features":[{"properties":{"id":1, "nazov_zariadenia": "John Doe, Md.", "druh_zariadenia": "ortoped"}}]}`
+
+	scraper := &Scraper{
+		Logger: logger,
+		Get: func(url string) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(resp)),
+			}, nil
+		},
+		Models: models.NewModels(db),
+	}
+
+	err = scraper.ScrapeHandler(context.Background())
+
+	assert.Nil(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestScraperHandler_SpecialistDeleted(t *testing.T) {
+	os.Setenv("SCRAPER_SPECIALISTS_URL", "http://example.com")
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	rowsSpecialty := sqlmock.NewRows([]string{"id", "name", "description"})
+	rowsSpecialist := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"})
+	rowsSpecialtyByName := sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "ortoped", "")
+
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialty)
+	mock.ExpectQuery("INSERT INTO specialty").WithArgs("ortoped", "ortoped", "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialtyByName)
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s (.+) WHERE coalesce\(o.source_value, s.name\)=\$1`).WithArgs("John Doe, Md.").WillReturnRows(rowsSpecialist)
+	mock.ExpectQuery(`SELECT specialist_id FROM specialist_alias WHERE name=\$1`).WithArgs("John Doe, Md.").WillReturnRows(sqlmock.NewRows([]string{"specialist_id"}))
+	// deleted by an admin, it is not inserted again
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM specialist_tombstone WHERE name=\$1\)`).WithArgs("John Doe, Md.").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(true))
+
+	resp := `{"features":[{"properties":{"id":1, "nazov_zariadenia": "John Doe, Md.", "druh_zariadenia": "ortoped"}}]}`
+
+	scraper := &Scraper{
+		Logger: logger,
+		Get: func(url string) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(resp)),
+			}, nil
+		},
+		Models: models.NewModels(db),
+	}
+
+	err = scraper.ScrapeHandler(context.Background())
+
+	assert.Nil(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
 func TestScraperHandler_Success(t *testing.T) {
 	os.Setenv("SCRAPER_SPECIALISTS_URL", "http://example.com")
 
@@ -406,16 +604,21 @@ func TestScraperHandler_Success(t *testing.T) {
 	defer db.Close()
 
 	rowsSpecialty := sqlmock.NewRows([]string{"id", "name", "description"})
-	rowsSpecialist := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday"})
+	rowsSpecialist := sqlmock.NewRows([]string{"id", "name", "specialty_id", "location", "address", "url", "telephone", "email", "monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff", "version", "overridden"})
 	rowsSpecialtyByName := sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(1, "ortoped", "")
 
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialty)
-	mock.ExpectExec("INSERT INTO specialty").WithArgs("ortoped", "").WillReturnResult(sqlmock.NewResult(1, 1))
-	mock.ExpectQuery(`SELECT (.+) FROM specialist WHERE name=`).WithArgs("John Doe, Md.").WillReturnRows(rowsSpecialist)
-	mock.ExpectQuery(`SELECT (.+) FROM specialty WHERE name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialtyByName)
-	mock.ExpectExec(`INSERT INTO specialist`).
-		WithArgs("John Doe, Md.", 1, "POINT(0 0)", " ,  , Slovenská republika", "", ", ", "", "", "", "", "", "", "", "").
-		WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialty)
+	mock.ExpectQuery("INSERT INTO specialty").WithArgs("ortoped", "ortoped", "").WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectQuery(`SELECT (.+) FROM specialty sp WHERE sp.normalized_name=\$1`).WithArgs("ortoped").WillReturnRows(rowsSpecialtyByName)
+	mock.ExpectQuery(`SELECT (.+) FROM specialist s (.+) WHERE coalesce\(o.source_value, s.name\)=\$1`).WithArgs("John Doe, Md.").WillReturnRows(rowsSpecialist)
+	mock.ExpectQuery(`SELECT specialist_id FROM specialist_alias WHERE name=\$1`).WithArgs("John Doe, Md.").WillReturnRows(sqlmock.NewRows([]string{"specialist_id"}))
+	mock.ExpectQuery(`SELECT EXISTS \(SELECT 1 FROM specialist_tombstone WHERE name=\$1\)`).WithArgs("John Doe, Md.").WillReturnRows(sqlmock.NewRows([]string{"exists"}).AddRow(false))
+	mock.ExpectBegin()
+	mock.ExpectExec(`SELECT set_config\('app.actor', \$1, true\)`).WithArgs("scraper").WillReturnResult(sqlmock.NewResult(0, 0))
+	mock.ExpectQuery(`INSERT INTO specialist`).
+		WithArgs("John Doe, Md.", 1, "POINT(0 0)", "", "", ", ", "", "", "", "", "", "", "", "", "", "{}", "").
+		WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(1))
+	mock.ExpectCommit()
 
 	resp := `{"features":[{"properties":{"id":1, "nazov_zariadenia": "John Doe, Md.", "druh_zariadenia": "ortoped"}}]}`
 
@@ -430,8 +633,68 @@ func TestScraperHandler_Success(t *testing.T) {
 		Models: models.NewModels(db),
 	}
 
-	err = scraper.ScrapeHandler()
+	err = scraper.ScrapeHandler(context.Background())
 
 	assert.Nil(t, err)
 	assert.NoError(t, mock.ExpectationsWereMet())
 }
+
+func TestScraperHandler_Memory(t *testing.T) {
+	os.Setenv("SCRAPER_SPECIALISTS_URL", "http://example.com")
+
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	resp := `{"features":[
+		{"properties":{"id":1, "nazov_zariadenia": "John Doe, Md.", "druh_zariadenia": "ortoped"}},
+		{"properties":{"id":2, "nazov_zariadenia": "Jane Doe, Md.", "druh_zariadenia": "Ortopéd"}}
+	]}`
+
+	memory := models.NewMemoryModel()
+	scraper := &Scraper{
+		Logger: logger,
+		Get: func(url string) (*http.Response, error) {
+			return &http.Response{
+				StatusCode: http.StatusOK,
+				Body:       io.NopCloser(strings.NewReader(resp)),
+			}, nil
+		},
+		Models: models.NewMemoryModels(memory),
+	}
+
+	// scraping twice does not duplicate anything
+	for i := 0; i < 2; i++ {
+		err = scraper.ScrapeHandler(context.Background())
+		assert.Nil(t, err)
+	}
+
+	specialties, err := memory.GetAllSpecialties(context.Background())
+	assert.NoError(t, err)
+	assert.Len(t, specialties, 1)
+
+	specialists, err := memory.GetSpecialistBySpecialty(context.Background(), specialties[0].ID)
+	assert.NoError(t, err)
+	assert.Len(t, specialists, 2)
+
+	// the merged duplicate is not inserted again
+	_, _, err = memory.MergeSpecialists(context.Background(), specialists[1].ID, specialists[1].Version, specialists[0].ID, specialists[0].Version)
+	assert.NoError(t, err)
+
+	err = scraper.ScrapeHandler(context.Background())
+	assert.Nil(t, err)
+
+	specialists, _ = memory.GetSpecialistBySpecialty(context.Background(), specialties[0].ID)
+	assert.Len(t, specialists, 1)
+
+	// neither is the specialist deleted by an admin, nor the duplicate merged into it
+	err = memory.DeleteSpecialist(context.Background(), specialists[0].ID, specialists[0].Version)
+	assert.NoError(t, err)
+
+	err = scraper.ScrapeHandler(context.Background())
+	assert.Nil(t, err)
+
+	specialists, _ = memory.GetSpecialistBySpecialty(context.Background(), specialties[0].ID)
+	assert.Empty(t, specialists)
+}
@@ -5,14 +5,16 @@ import (
 	"net/http"
 	"time"
 
+	"github.com/acornak/healthcare-poc/geocoding"
 	"github.com/acornak/healthcare-poc/models"
 	"go.uber.org/zap"
 )
 
 type Scraper struct {
-	Logger *zap.Logger
-	Models models.Models
-	Get    func(url string) (resp *http.Response, err error)
+	Logger   *zap.Logger
+	Models   models.Models
+	Get      func(url string) (resp *http.Response, err error)
+	Geocoder geocoding.Geocoder
 }
 
 func NewScraper(logger *zap.Logger, models models.Models) *Scraper {
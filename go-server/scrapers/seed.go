@@ -0,0 +1,202 @@
+package scrapers
+
+import (
+	"context"
+	"sort"
+	"strconv"
+
+	"github.com/acornak/healthcare-poc/seeds"
+	"github.com/acornak/healthcare-poc/textutil"
+	"github.com/acornak/healthcare-poc/types"
+	"go.uber.org/zap"
+)
+
+/*
+SeedSymptomMappings inserts the curated symptom mappings shipped in the seeds package
+Mappings are resolved to specialties by name, mappings for specialties that were not scraped yet are skipped
+The mappings of a specialty are applied once per seed file version, its applied version is kept as the symptoms:<specialty id> seed,
+so mappings a curator edited or deleted stay as they are until the seed file version changes
+Keyword and specialty pairs already present in the database are never touched
+The function returns an error if there was an issue with the database or the seed file
+*/
+func (s *Scraper) SeedSymptomMappings(ctx context.Context) error {
+	seed, err := seeds.Symptoms()
+	if err != nil {
+		return err
+	}
+
+	specialties, err := s.Models.Specialties.GetAllSpecialties(ctx)
+	if err != nil {
+		return err
+	}
+
+	specialtyIDs := make(map[string]int)
+	for _, specialty := range specialties {
+		specialtyIDs[textutil.Fold(specialty.Name)] = specialty.ID
+	}
+
+	// the seeded groups of every specialty whose applied version is older than the seed file
+	pending := make(map[int][]int)
+	var order []int
+	for i, group := range seed.Mappings {
+		specialtyID, ok := specialtyIDs[textutil.Fold(group.Specialty)]
+		if !ok {
+			s.Logger.Debug("specialty for symptom mapping not found", zap.String("specialty", group.Specialty))
+			continue
+		}
+
+		if _, ok := pending[specialtyID]; !ok {
+			applied, err := s.Models.Seeds.GetSeedVersion(ctx, symptomSeedName(specialtyID))
+			if err != nil {
+				return err
+			}
+			if applied >= seed.Version {
+				continue
+			}
+			order = append(order, specialtyID)
+		}
+		pending[specialtyID] = append(pending[specialtyID], i)
+	}
+
+	for _, specialtyID := range order {
+		for _, i := range pending[specialtyID] {
+			group := seed.Mappings[i]
+			for _, keyword := range group.Keywords {
+				err = s.Models.Symptoms.InsertSymptomMapping(ctx, types.SymptomMapping{
+					Keyword:     keyword,
+					SpecialtyID: specialtyID,
+					Weight:      group.Weight,
+					Enabled:     true,
+				})
+				if err != nil {
+					return err
+				}
+			}
+		}
+
+		if err := s.Models.Seeds.SetSeedVersion(ctx, symptomSeedName(specialtyID), seed.Version); err != nil {
+			return err
+		}
+	}
+
+	if len(order) > 0 {
+		s.Logger.Info("symptom mappings seeded", zap.Int("version", seed.Version), zap.Int("specialties", len(order)))
+	}
+
+	return nil
+}
+
+// symptomSeedName is the name of the seed version of the symptom mappings of a specialty
+func symptomSeedName(specialtyID int) string {
+	return "symptoms:" + strconv.Itoa(specialtyID)
+}
+
+const specialtySeedName = "specialties"
+
+/*
+SeedSpecialtyTaxonomy applies the specialty taxonomy shipped in the seeds package
+Categories, descriptions and translations are written when the seed file version is newer than the applied one,
+and for specialties that have no translations yet, e.g. because the scraper only just discovered them
+Specialties are resolved by name, taxonomy entries for specialties that were not scraped yet are skipped
+The function returns an error if there was an issue with the database or the seed file
+*/
+func (s *Scraper) SeedSpecialtyTaxonomy(ctx context.Context) error {
+	seed, err := seeds.Specialties()
+	if err != nil {
+		return err
+	}
+
+	applied, err := s.Models.Seeds.GetSeedVersion(ctx, specialtySeedName)
+	if err != nil {
+		return err
+	}
+	outdated := applied < seed.Version
+
+	specialties, err := s.Models.Specialties.GetAllSpecialties(ctx)
+	if err != nil {
+		return err
+	}
+
+	specialtyIDs := make(map[string]int)
+	for _, specialty := range specialties {
+		specialtyIDs[textutil.Fold(specialty.Name)] = specialty.ID
+	}
+
+	translated, err := s.Models.Specialties.GetTranslatedSpecialtyIDs(ctx)
+	if err != nil {
+		return err
+	}
+
+	pending := make(map[int]int)
+	for i, entry := range seed.Specialties {
+		id, ok := specialtyIDs[textutil.Fold(entry.Name)]
+		if ok && (outdated || !translated[id]) {
+			pending[i] = id
+		}
+	}
+
+	if !outdated && len(pending) == 0 {
+		return nil
+	}
+
+	categoryIDs := make(map[string]int)
+	for _, category := range seed.Categories {
+		id, err := s.Models.Specialties.UpsertSpecialtyCategory(ctx, category.Code, category.Parent)
+		if err != nil {
+			return err
+		}
+		categoryIDs[category.Code] = id
+
+		for _, language := range sortedKeys(category.Names) {
+			if err := s.Models.Specialties.UpsertSpecialtyCategoryTranslation(ctx, id, language, category.Names[language]); err != nil {
+				return err
+			}
+		}
+	}
+
+	for i, entry := range seed.Specialties {
+		id, ok := pending[i]
+		if !ok {
+			continue
+		}
+
+		err := s.Models.Specialties.UpdateSpecialtyTaxonomy(ctx, id, entry.Translations["sk"].Description, categoryIDs[entry.Category])
+		if err != nil {
+			return err
+		}
+
+		for _, language := range sortedKeys(entry.Translations) {
+			translation := entry.Translations[language]
+			err := s.Models.Specialties.UpsertSpecialtyTranslation(ctx, types.SpecialtyTranslation{
+				SpecialtyID: id,
+				Language:    language,
+				Name:        translation.Name,
+				Description: translation.Description,
+				Synonyms:    translation.Synonyms,
+			})
+			if err != nil {
+				return err
+			}
+		}
+	}
+
+	if outdated {
+		if err := s.Models.Seeds.SetSeedVersion(ctx, specialtySeedName, seed.Version); err != nil {
+			return err
+		}
+	}
+
+	s.Logger.Info("specialty taxonomy seeded", zap.Int("version", seed.Version), zap.Int("specialties", len(pending)))
+
+	return nil
+}
+
+func sortedKeys[V any](m map[string]V) []string {
+	keys := make([]string, 0, len(m))
+	for key := range m {
+		keys = append(keys, key)
+	}
+	sort.Strings(keys)
+
+	return keys
+}
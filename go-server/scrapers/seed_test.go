@@ -0,0 +1,211 @@
+package scrapers
+
+import (
+	"context"
+	"errors"
+	"testing"
+
+	"github.com/DATA-DOG/go-sqlmock"
+	"github.com/acornak/healthcare-poc/models"
+	"github.com/acornak/healthcare-poc/seeds"
+	"github.com/stretchr/testify/assert"
+	"go.uber.org/zap"
+)
+
+func TestSeedSymptomMappings_GetSpecialtiesError(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM specialty").WillReturnError(errors.New("mocked error"))
+
+	scraper := &Scraper{
+		Logger: logger,
+		Models: models.NewModels(db),
+	}
+
+	err = scraper.SeedSymptomMappings(context.Background())
+
+	assert.EqualError(t, err, "mocked error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeedSymptomMappings_InsertError(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	mock.ExpectQuery("SELECT (.+) FROM specialty").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(2, "ambulancia zubného lekárstva", ""))
+	mock.ExpectQuery("SELECT version FROM seed_version").WithArgs("symptoms:2").WillReturnRows(sqlmock.NewRows([]string{"version"}))
+	mock.ExpectExec("INSERT INTO symptom_mapping").WithArgs("zub", 2, 0.95, true).WillReturnError(errors.New("mocked error"))
+
+	scraper := &Scraper{
+		Logger: logger,
+		Models: models.NewModels(db),
+	}
+
+	err = scraper.SeedSymptomMappings(context.Background())
+
+	assert.EqualError(t, err, "mocked error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeedSymptomMappings_Success(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	seed, err := seeds.Symptoms()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery("SELECT (.+) FROM specialty").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(2, "Ambulancia zubného lekárstva", ""))
+	mock.ExpectQuery("SELECT version FROM seed_version").WithArgs("symptoms:2").WillReturnRows(sqlmock.NewRows([]string{"version"}))
+
+	for _, keyword := range []string{"zub", "zuba", "zubom", "zuby", "zubov", "ďasno", "ďasná", "zubár", "plomba", "korunka"} {
+		mock.ExpectExec("INSERT INTO symptom_mapping").WithArgs(keyword, 2, 0.95, true).WillReturnResult(sqlmock.NewResult(1, 1))
+	}
+	mock.ExpectExec("INSERT INTO seed_version").WithArgs("symptoms:2", seed.Version).WillReturnResult(sqlmock.NewResult(1, 1))
+
+	scraper := &Scraper{
+		Logger: logger,
+		Models: models.NewModels(db),
+	}
+
+	err = scraper.SeedSymptomMappings(context.Background())
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+// mappings deleted by a curator are not seeded again while the seed file version is unchanged
+func TestSeedSymptomMappings_AlreadyApplied(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	seed, err := seeds.Symptoms()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery("SELECT (.+) FROM specialty").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}).AddRow(2, "Ambulancia zubného lekárstva", ""))
+	mock.ExpectQuery("SELECT version FROM seed_version").WithArgs("symptoms:2").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(seed.Version))
+
+	scraper := &Scraper{
+		Logger: logger,
+		Models: models.NewModels(db),
+	}
+
+	err = scraper.SeedSymptomMappings(context.Background())
+
+	assert.NoError(t, err)
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeedSpecialtyTaxonomy_UpToDate(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	seed, err := seeds.Specialties()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery("SELECT version FROM seed_version").WithArgs("specialties").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(seed.Version))
+	mock.ExpectQuery("SELECT (.+) FROM specialty").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}).
+		AddRow(1, "ambulancia ortopédie", "").
+		AddRow(2, "ambulancia pediatrie", ""))
+	mock.ExpectQuery("SELECT DISTINCT specialty_id FROM specialty_translation").WillReturnRows(sqlmock.NewRows([]string{"specialty_id"}).AddRow(1))
+
+	scraper := &Scraper{
+		Logger: logger,
+		Models: models.NewModels(db),
+	}
+
+	assert.NoError(t, scraper.SeedSpecialtyTaxonomy(context.Background()))
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
+
+func TestSeedSpecialtyTaxonomy_NewSpecialty(t *testing.T) {
+	logger, err := zap.NewProduction()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	db, mock, err := sqlmock.New()
+	if err != nil {
+		t.Fatalf("failed to open sqlmock database: %s", err)
+	}
+	defer db.Close()
+
+	seed, err := seeds.Specialties()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	mock.ExpectQuery("SELECT version FROM seed_version").WithArgs("specialties").WillReturnRows(sqlmock.NewRows([]string{"version"}).AddRow(seed.Version))
+	mock.ExpectQuery("SELECT (.+) FROM specialty").WillReturnRows(sqlmock.NewRows([]string{"id", "name", "description"}).
+		AddRow(1, "ambulancia ortopédie", "").
+		AddRow(2, "Ambulancia čeľustnej ortopédie", ""))
+	mock.ExpectQuery("SELECT DISTINCT specialty_id FROM specialty_translation").WillReturnRows(sqlmock.NewRows([]string{"specialty_id"}).AddRow(1))
+
+	categoryIDs := make(map[string]int)
+	for i, category := range seed.Categories {
+		categoryIDs[category.Code] = i + 10
+		mock.ExpectQuery("INSERT INTO specialty_category ").WithArgs(category.Code, category.Parent).WillReturnRows(sqlmock.NewRows([]string{"id"}).AddRow(i + 10))
+		for _, language := range []string{"en", "hu", "sk"} {
+			mock.ExpectExec("INSERT INTO specialty_category_translation").WithArgs(i+10, language, category.Names[language]).WillReturnResult(sqlmock.NewResult(1, 1))
+		}
+	}
+
+	mock.ExpectExec("UPDATE specialty SET description").WithArgs("Rovnanie zubov a korekcia postavenia čeľustí, strojčeky.", categoryIDs["orthodontics"], 2).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO specialty_translation").WithArgs(2, "en", "Orthodontics", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO specialty_translation").WithArgs(2, "hu", "Fogszabályozás", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnResult(sqlmock.NewResult(1, 1))
+	mock.ExpectExec("INSERT INTO specialty_translation").WithArgs(2, "sk", "Čeľustná ortopédia", sqlmock.AnyArg(), sqlmock.AnyArg()).WillReturnError(errors.New("mocked error"))
+
+	scraper := &Scraper{
+		Logger: logger,
+		Models: models.NewModels(db),
+	}
+
+	assert.EqualError(t, scraper.SeedSpecialtyTaxonomy(context.Background()), "mocked error")
+	assert.NoError(t, mock.ExpectationsWereMet())
+}
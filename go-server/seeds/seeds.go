@@ -0,0 +1,141 @@
+package seeds
+
+import (
+	_ "embed"
+	"encoding/json"
+)
+
+//go:embed symptoms.json
+var symptomsFile []byte
+
+//go:embed specialties.json
+var specialtiesFile []byte
+
+//go:embed municipalities.json
+var municipalitiesFile []byte
+
+//go:embed timezones.json
+var timezonesFile []byte
+
+/*
+SymptomSeed represents the curated symptom to specialty mapping shipped with the server
+The struct contains the following fields:
+- Version: the version of the seed file, bump it on every change
+- Mappings: keywords grouped by the name of the specialty they point to
+*/
+type SymptomSeed struct {
+	Version  int `json:"version"`
+	Mappings []struct {
+		Specialty string   `json:"specialty"`
+		Weight    float64  `json:"weight"`
+		Keywords  []string `json:"keywords"`
+	} `json:"mappings"`
+}
+
+/*
+Symptoms returns the embedded symptom to specialty mapping
+The function returns an error if the embedded file is not valid JSON
+*/
+func Symptoms() (SymptomSeed, error) {
+	var seed SymptomSeed
+	if err := json.Unmarshal(symptomsFile, &seed); err != nil {
+		return SymptomSeed{}, err
+	}
+
+	return seed, nil
+}
+
+/*
+SpecialtySeed represents the specialty taxonomy shipped with the server
+The struct contains the following fields:
+- Version: the version of the seed file, bump it on every change
+- Categories: the specialty categories, parents have to be listed before their children
+- Specialties: descriptions, categories and translations keyed by the geoportal specialty name
+*/
+type SpecialtySeed struct {
+	Version    int `json:"version"`
+	Categories []struct {
+		Code   string            `json:"code"`
+		Parent string            `json:"parent"`
+		Names  map[string]string `json:"names"`
+	} `json:"categories"`
+	Specialties []struct {
+		Name         string `json:"name"`
+		Category     string `json:"category"`
+		Translations map[string]struct {
+			Name        string   `json:"name"`
+			Description string   `json:"description"`
+			Synonyms    []string `json:"synonyms"`
+		} `json:"translations"`
+	} `json:"specialties"`
+}
+
+/*
+Specialties returns the embedded specialty taxonomy
+The function returns an error if the embedded file is not valid JSON
+*/
+func Specialties() (SpecialtySeed, error) {
+	var seed SpecialtySeed
+	if err := json.Unmarshal(specialtiesFile, &seed); err != nil {
+		return SpecialtySeed{}, err
+	}
+
+	return seed, nil
+}
+
+/*
+MunicipalitySeed represents the Slovak municipalities used by the offline gazetteer
+The struct contains the following fields:
+- Version: the version of the seed file, bump it on every change
+- Municipalities: the name, the district (okres) and the approximate centre of every municipality
+*/
+type MunicipalitySeed struct {
+	Version        int `json:"version"`
+	Municipalities []struct {
+		Name     string  `json:"name"`
+		District string  `json:"district"`
+		Lat      float64 `json:"lat"`
+		Lon      float64 `json:"lon"`
+	} `json:"municipalities"`
+}
+
+/*
+Municipalities returns the embedded list of municipalities
+The function returns an error if the embedded file is not valid JSON
+*/
+func Municipalities() (MunicipalitySeed, error) {
+	var seed MunicipalitySeed
+	if err := json.Unmarshal(municipalitiesFile, &seed); err != nil {
+		return MunicipalitySeed{}, err
+	}
+
+	return seed, nil
+}
+
+/*
+TimezoneSeed represents the timezone boundaries used to resolve the timezone of a location offline
+The boundaries are simplified and cover Slovakia and its neighbours only
+The struct contains the following fields:
+- Version: the version of the seed file, bump it on every change
+- Zones: the IANA name of every timezone and its polygons, closed rings of [longitude, latitude] points
+*/
+type TimezoneSeed struct {
+	Version int `json:"version"`
+	Zones   []struct {
+		ID       string         `json:"id"`
+		Polygons [][][2]float64 `json:"polygons"`
+	} `json:"zones"`
+}
+
+/*
+Timezones returns the embedded timezone boundaries
+The function returns an error if the embedded file is not valid JSON
+*/
+func Timezones() (TimezoneSeed, error) {
+	var seed TimezoneSeed
+	if err := json.Unmarshal(timezonesFile, &seed); err != nil {
+		return TimezoneSeed{}, err
+	}
+
+	return seed, nil
+}
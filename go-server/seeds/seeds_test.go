@@ -0,0 +1,85 @@
+package seeds
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestSymptoms(t *testing.T) {
+	seed, err := Symptoms()
+
+	assert.NoError(t, err)
+	assert.Greater(t, seed.Version, 0)
+	assert.NotEmpty(t, seed.Mappings)
+
+	for _, mapping := range seed.Mappings {
+		assert.NotEmpty(t, mapping.Specialty)
+		assert.NotEmpty(t, mapping.Keywords, mapping.Specialty)
+		assert.True(t, mapping.Weight > 0 && mapping.Weight <= 1, mapping.Specialty)
+	}
+}
+
+func TestSpecialties(t *testing.T) {
+	seed, err := Specialties()
+
+	assert.NoError(t, err)
+	assert.Greater(t, seed.Version, 0)
+
+	categories := make(map[string]bool)
+	for _, category := range seed.Categories {
+		if category.Parent != "" {
+			assert.True(t, categories[category.Parent], "parent of %s has to be listed first", category.Code)
+		}
+		for _, language := range []string{"sk", "en", "hu"} {
+			assert.NotEmpty(t, category.Names[language], "%s: missing %s name", category.Code, language)
+		}
+		categories[category.Code] = true
+	}
+
+	for _, specialty := range seed.Specialties {
+		assert.True(t, categories[specialty.Category], "%s: unknown category %s", specialty.Name, specialty.Category)
+		for _, language := range []string{"sk", "en", "hu"} {
+			translation := specialty.Translations[language]
+			assert.NotEmpty(t, translation.Name, "%s: missing %s name", specialty.Name, language)
+			assert.NotEmpty(t, translation.Description, "%s: missing %s description", specialty.Name, language)
+			assert.LessOrEqual(t, len(translation.Description), 255, specialty.Name)
+		}
+	}
+}
+
+func TestMunicipalities(t *testing.T) {
+	seed, err := Municipalities()
+
+	assert.NoError(t, err)
+	assert.Greater(t, seed.Version, 0)
+	assert.NotEmpty(t, seed.Municipalities)
+
+	names := make(map[string]bool)
+	for _, municipality := range seed.Municipalities {
+		assert.False(t, names[municipality.Name], "duplicate municipality %s", municipality.Name)
+		names[municipality.Name] = true
+		assert.NotEmpty(t, municipality.District, municipality.Name)
+
+		// bounding box of Slovakia
+		assert.True(t, municipality.Lat > 47.7 && municipality.Lat < 49.7, municipality.Name)
+		assert.True(t, municipality.Lon > 16.8 && municipality.Lon < 22.6, municipality.Name)
+	}
+}
+
+func TestTimezones(t *testing.T) {
+	seed, err := Timezones()
+
+	assert.NoError(t, err)
+	assert.Greater(t, seed.Version, 0)
+	assert.NotEmpty(t, seed.Zones)
+
+	for _, zone := range seed.Zones {
+		assert.NotEmpty(t, zone.ID)
+		assert.NotEmpty(t, zone.Polygons, zone.ID)
+		for _, ring := range zone.Polygons {
+			assert.GreaterOrEqual(t, len(ring), 4, zone.ID)
+			assert.Equal(t, ring[0], ring[len(ring)-1], zone.ID)
+		}
+	}
+}
@@ -0,0 +1,78 @@
+package textutil
+
+import (
+	"strings"
+	"unicode"
+
+	"golang.org/x/text/unicode/norm"
+)
+
+/*
+Fold returns s in lower case with all diacritics removed
+It is used to compare user input typed without diacritics ("ocny lekar")
+with the Slovak data coming from the geoportal ("očný lekár")
+Folding works rune by rune, so the folded string has the same number of runes as s
+*/
+func Fold(s string) string {
+	var b strings.Builder
+	b.Grow(len(s))
+
+	for _, r := range s {
+		b.WriteRune(foldRune(r))
+	}
+
+	return b.String()
+}
+
+func foldRune(r rune) rune {
+	r = unicode.ToLower(r)
+	if r < unicode.MaxASCII {
+		return r
+	}
+
+	decomposed := []rune(norm.NFD.String(string(r)))
+	if len(decomposed) == 0 {
+		return r
+	}
+
+	return decomposed[0]
+}
+
+/*
+Words splits text into folded words in their original order
+Punctuation is treated as a separator
+*/
+func Words(text string) []string {
+	return strings.FieldsFunc(Fold(text), func(r rune) bool {
+		return !unicode.IsLetter(r) && !unicode.IsDigit(r)
+	})
+}
+
+/*
+Terms splits a free text query into folded search terms
+Punctuation is treated as a separator and duplicate terms are dropped
+*/
+func Terms(query string) []string {
+	words := Words(query)
+
+	seen := make(map[string]bool)
+	terms := []string{}
+
+	for _, word := range words {
+		if seen[word] {
+			continue
+		}
+		seen[word] = true
+		terms = append(terms, word)
+	}
+
+	return terms
+}
+
+/*
+Normalize returns the folded words of text joined by single spaces
+Two strings that differ only in case, diacritics, punctuation or spacing normalize to the same key
+*/
+func Normalize(text string) string {
+	return strings.Join(Words(text), " ")
+}
@@ -0,0 +1,31 @@
+package textutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFold(t *testing.T) {
+	assert.Equal(t, "ocny lekar michalovce", Fold("Očný lekár Michalovce"))
+	assert.Equal(t, "zlta ruza dlha", Fold("Žltá ruža dĺha"))
+	assert.Equal(t, "", Fold(""))
+	assert.Equal(t, len([]rune("ťŤäÄôÔ")), len([]rune(Fold("ťŤäÄôÔ"))))
+}
+
+func TestWords(t *testing.T) {
+	assert.Equal(t, []string{"boli", "ma", "zub", "a", "zub"}, Words("Bolí ma zub, a zub!"))
+	assert.Empty(t, Words(" ,. "))
+}
+
+func TestTerms(t *testing.T) {
+	assert.Equal(t, []string{"ocny", "lekar", "michalovce"}, Terms("  Očný lekár, Michalovce "))
+	assert.Equal(t, []string{"mudr", "novak"}, Terms("MUDr. Novák mudr"))
+	assert.Equal(t, []string{}, Terms(" ,. "))
+}
+
+func TestNormalize(t *testing.T) {
+	assert.Equal(t, "ambulancia vnutorneho lekarstva", Normalize("  Ambulancia  vnútorného lekárstva "))
+	assert.Equal(t, "ambulancia vnutorneho lekarstva", Normalize("ambulancia vnútorného-lekárstva."))
+	assert.Equal(t, "", Normalize(" - "))
+}
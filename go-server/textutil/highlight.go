@@ -0,0 +1,96 @@
+package textutil
+
+import (
+	"html"
+	"strings"
+	"unicode"
+)
+
+const (
+	HighlightOpen  = "<mark>"
+	HighlightClose = "</mark>"
+)
+
+/*
+Highlight wraps every word of text that matches one of the folded terms in <mark> tags
+A word matches a term when its folded form contains the term or when both share
+a prefix of at least two thirds of the term, so "ocneho" is highlighted for "ocny"
+The original spelling of text is preserved, but it is HTML escaped, so the result is safe to render as HTML
+The function returns the highlighted text and whether anything was highlighted
+*/
+func Highlight(text string, terms []string) (string, bool) {
+	if len(terms) == 0 || text == "" {
+		return html.EscapeString(text), false
+	}
+
+	runes := []rune(text)
+	var b strings.Builder
+	matched := false
+
+	for i := 0; i < len(runes); {
+		if !isWordRune(runes[i]) {
+			b.WriteString(html.EscapeString(string(runes[i])))
+			i++
+			continue
+		}
+
+		j := i
+		for j < len(runes) && isWordRune(runes[j]) {
+			j++
+		}
+
+		word := string(runes[i:j])
+		if matchesAny(Fold(word), terms) {
+			b.WriteString(HighlightOpen + html.EscapeString(word) + HighlightClose)
+			matched = true
+		} else {
+			b.WriteString(html.EscapeString(word))
+		}
+
+		i = j
+	}
+
+	return b.String(), matched
+}
+
+func isWordRune(r rune) bool {
+	return unicode.IsLetter(r) || unicode.IsDigit(r)
+}
+
+func matchesAny(word string, terms []string) bool {
+	for _, term := range terms {
+		if term == "" {
+			continue
+		}
+
+		if strings.Contains(word, term) {
+			return true
+		}
+
+		termLen := len([]rune(term))
+		required := termLen * 2 / 3
+		if required < 3 {
+			required = 3
+		}
+		if required > termLen {
+			required = termLen
+		}
+
+		if commonPrefix(word, term) >= required {
+			return true
+		}
+	}
+
+	return false
+}
+
+func commonPrefix(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	n := 0
+
+	for n < len(ar) && n < len(br) && ar[n] == br[n] {
+		n++
+	}
+
+	return n
+}
@@ -0,0 +1,42 @@
+package textutil
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestHighlight_Match(t *testing.T) {
+	actual, matched := Highlight("Ambulancia očného lekárstva, Michalovce", []string{"ocny", "lekar", "michalovce"})
+
+	assert.True(t, matched)
+	assert.Equal(t, "Ambulancia <mark>očného</mark> <mark>lekárstva</mark>, <mark>Michalovce</mark>", actual)
+}
+
+func TestHighlight_NoMatch(t *testing.T) {
+	actual, matched := Highlight("Ambulancia vnútorného lekárstva", []string{"kosice"})
+
+	assert.False(t, matched)
+	assert.Equal(t, "Ambulancia vnútorného lekárstva", actual)
+}
+
+func TestHighlight_Empty(t *testing.T) {
+	actual, matched := Highlight("", []string{"kosice"})
+	assert.False(t, matched)
+	assert.Equal(t, "", actual)
+
+	actual, matched = Highlight("Košice", nil)
+	assert.False(t, matched)
+	assert.Equal(t, "Košice", actual)
+}
+
+func TestHighlight_Escape(t *testing.T) {
+	actual, matched := Highlight(`Kardio <script>alert("Košice")</script> & spol.`, []string{"kosice"})
+
+	assert.True(t, matched)
+	assert.Equal(t, `Kardio &lt;script&gt;alert(&#34;<mark>Košice</mark>&#34;)&lt;/script&gt; &amp; spol.`, actual)
+
+	actual, matched = Highlight("<b>Košice</b>", nil)
+	assert.False(t, matched)
+	assert.Equal(t, "&lt;b&gt;Košice&lt;/b&gt;", actual)
+}
@@ -0,0 +1,135 @@
+package timezone
+
+import (
+	"fmt"
+	"math"
+	"time"
+	// the IANA database is embedded so the lookup works in containers without tzdata
+	_ "time/tzdata"
+
+	"github.com/acornak/healthcare-poc/seeds"
+	"github.com/acornak/healthcare-poc/types"
+)
+
+type zone struct {
+	id       string
+	bounds   types.BoundingBox
+	polygons [][]types.Location
+}
+
+/*
+Finder resolves the timezone of a location offline from timezone boundaries
+Locations outside all boundaries get the nautical timezone of their longitude, e.g. Etc/GMT-2 east of 22.5°,
+nautical timezones have no daylight saving time, so the timezone is reported as approximate
+*/
+type Finder struct {
+	zones []zone
+}
+
+/*
+New returns a finder for the timezone boundaries in the seed
+The function returns an error if a timezone is unknown to the IANA database or a polygon is not a closed ring
+*/
+func New(seed seeds.TimezoneSeed) (*Finder, error) {
+	f := &Finder{}
+
+	for _, z := range seed.Zones {
+		if _, err := time.LoadLocation(z.ID); err != nil {
+			return nil, fmt.Errorf("timezone %q: %w", z.ID, err)
+		}
+		if len(z.Polygons) == 0 {
+			return nil, fmt.Errorf("timezone %q has no polygons", z.ID)
+		}
+
+		entry := zone{id: z.ID, bounds: types.BoundingBox{MinLat: 90, MinLon: 180, MaxLat: -90, MaxLon: -180}}
+		for _, ring := range z.Polygons {
+			if len(ring) < 4 || ring[0] != ring[len(ring)-1] {
+				return nil, fmt.Errorf("timezone %q has a polygon that is not a closed ring", z.ID)
+			}
+
+			polygon := make([]types.Location, len(ring))
+			for i, point := range ring {
+				polygon[i] = types.Location{Lat: point[1], Lon: point[0]}
+				entry.bounds.MinLat = math.Min(entry.bounds.MinLat, point[1])
+				entry.bounds.MinLon = math.Min(entry.bounds.MinLon, point[0])
+				entry.bounds.MaxLat = math.Max(entry.bounds.MaxLat, point[1])
+				entry.bounds.MaxLon = math.Max(entry.bounds.MaxLon, point[0])
+			}
+			entry.polygons = append(entry.polygons, polygon)
+		}
+
+		f.zones = append(f.zones, entry)
+	}
+
+	return f, nil
+}
+
+/*
+NewDefault returns a finder for the embedded timezone boundaries, see seeds.Timezones
+*/
+func NewDefault() (*Finder, error) {
+	seed, err := seeds.Timezones()
+	if err != nil {
+		return nil, err
+	}
+
+	return New(seed)
+}
+
+/*
+Name returns the IANA name of the timezone at the location
+approximate is true for a location outside all boundaries, the name is then the nautical timezone of its longitude,
+which may be hours off the local time on land and ignores daylight saving time
+*/
+func (f *Finder) Name(l types.Location) (name string, approximate bool) {
+	for _, z := range f.zones {
+		if !z.bounds.Contains(l) {
+			continue
+		}
+		for _, polygon := range z.polygons {
+			if contains(polygon, l) {
+				return z.id, false
+			}
+		}
+	}
+
+	return nautical(l.Lon), true
+}
+
+/*
+Location returns the timezone at the location, approximate as described in Name
+The function returns an error if the timezone is missing from the IANA database
+*/
+func (f *Finder) Location(l types.Location) (loc *time.Location, approximate bool, err error) {
+	name, approximate := f.Name(l)
+
+	loc, err = time.LoadLocation(name)
+	if err != nil {
+		return nil, false, fmt.Errorf("failed to load timezone %s: %w", name, err)
+	}
+
+	return loc, approximate, nil
+}
+
+// contains reports whether the closed ring contains the location, using ray casting
+func contains(ring []types.Location, l types.Location) bool {
+	inside := false
+	for i, j := 0, len(ring)-1; i < len(ring); j, i = i, i+1 {
+		a, b := ring[i], ring[j]
+		if (a.Lat > l.Lat) != (b.Lat > l.Lat) && l.Lon < (b.Lon-a.Lon)*(l.Lat-a.Lat)/(b.Lat-a.Lat)+a.Lon {
+			inside = !inside
+		}
+	}
+
+	return inside
+}
+
+// nautical returns the Etc zone of the longitude, the sign of Etc zones is inverted, Etc/GMT-1 is UTC+1
+func nautical(lon float64) string {
+	offset := int(math.Round(lon / 15))
+	if offset == 0 {
+		return "Etc/GMT"
+	}
+
+	return fmt.Sprintf("Etc/GMT%+d", -offset)
+}
@@ -0,0 +1,74 @@
+package timezone
+
+import (
+	"testing"
+
+	"github.com/acornak/healthcare-poc/seeds"
+	"github.com/acornak/healthcare-poc/types"
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewDefault(t *testing.T) {
+	finder, err := NewDefault()
+
+	assert.NoError(t, err)
+	assert.NotEmpty(t, finder.zones)
+}
+
+func TestFinder_Name(t *testing.T) {
+	finder, err := NewDefault()
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	tests := map[string]struct {
+		location    types.Location
+		expected    string
+		approximate bool
+	}{
+		"Košice":     {types.Location{Lat: 48.7172272, Lon: 21.2496774}, "Europe/Bratislava", false},
+		"Bratislava": {types.Location{Lat: 48.1486, Lon: 17.1077}, "Europe/Bratislava", false},
+		"Sobrance":   {types.Location{Lat: 48.7446, Lon: 22.1815}, "Europe/Bratislava", false},
+		"Komárno":    {types.Location{Lat: 47.7633, Lon: 18.1283}, "Europe/Bratislava", false},
+		"Štúrovo":    {types.Location{Lat: 47.7987, Lon: 18.7237}, "Europe/Bratislava", false},
+		"Komárom":    {types.Location{Lat: 47.7383, Lon: 18.1224}, "Europe/Budapest", false},
+		"Esztergom":  {types.Location{Lat: 47.7856, Lon: 18.7403}, "Europe/Budapest", false},
+		"Uzhhorod":   {types.Location{Lat: 48.6208, Lon: 22.2879}, "Europe/Kyiv", false},
+		"Vienna":     {types.Location{Lat: 48.2082, Lon: 16.3738}, "Europe/Vienna", false},
+		"Miskolc":    {types.Location{Lat: 48.1035, Lon: 20.7784}, "Europe/Budapest", false},
+		"Kraków":     {types.Location{Lat: 50.0647, Lon: 19.945}, "Europe/Warsaw", false},
+		"Ostrava":    {types.Location{Lat: 49.8209, Lon: 18.2625}, "Europe/Prague", false},
+		"Shanghai":   {types.Location{Lat: 31.2304, Lon: 121.4737}, "Etc/GMT-8", true},
+		"New York":   {types.Location{Lat: 40.7128, Lon: -74.006}, "Etc/GMT+5", true},
+		"London":     {types.Location{Lat: 51.5074, Lon: -0.1278}, "Etc/GMT", true},
+		"Paris":      {types.Location{Lat: 48.8566, Lon: 2.3522}, "Etc/GMT", true},
+	}
+
+	for name, test := range tests {
+		zone, approximate := finder.Name(test.location)
+		assert.Equal(t, test.expected, zone, name)
+		assert.Equal(t, test.approximate, approximate, name)
+
+		loc, approximate, err := finder.Location(test.location)
+		assert.NoError(t, err, name)
+		assert.Equal(t, test.expected, loc.String(), name)
+		assert.Equal(t, test.approximate, approximate, name)
+	}
+}
+
+func TestNew_InvalidSeed(t *testing.T) {
+	seed := seeds.TimezoneSeed{Version: 1}
+	seed.Zones = append(seed.Zones, struct {
+		ID       string         `json:"id"`
+		Polygons [][][2]float64 `json:"polygons"`
+	}{ID: "Shanghai/China", Polygons: [][][2]float64{{{0, 0}, {1, 0}, {1, 1}, {0, 0}}}})
+
+	_, err := New(seed)
+	assert.ErrorContains(t, err, `timezone "Shanghai/China"`)
+
+	seed.Zones[0].ID = "Europe/Bratislava"
+	seed.Zones[0].Polygons = [][][2]float64{{{0, 0}, {1, 0}, {1, 1}}}
+
+	_, err = New(seed)
+	assert.EqualError(t, err, `timezone "Europe/Bratislava" has a polygon that is not a closed ring`)
+}
@@ -0,0 +1,129 @@
+package types
+
+import (
+	"regexp"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// the country suffix of addresses from the geoportal
+const addressCountry = "Slovenská republika"
+
+// a Slovak postal code, written as "04001" or "040 01", followed by the municipality
+var addressTownRegexp = regexp.MustCompile(`^\d{3}\s?\d{2}\s+(.+)$`)
+
+// results of the address verification of a specialist
+const (
+	AddressCheckVerified   = "verified"
+	AddressCheckMismatch   = "mismatch"
+	AddressCheckFilled     = "filled"
+	AddressCheckUnresolved = "unresolved"
+)
+
+/*
+PlaceAddress represents the parts of a postal address of a geocoded place
+The struct contains the following fields:
+- Street: the street, or the municipality part for house numbers without a street
+- HouseNumber: the house number
+- PostalCode: the postal code without spaces
+- Municipality: the town or village
+*/
+type PlaceAddress struct {
+	Street       string `json:"street,omitempty"`
+	HouseNumber  string `json:"house_number,omitempty"`
+	PostalCode   string `json:"postal_code,omitempty"`
+	Municipality string `json:"municipality,omitempty"`
+}
+
+/*
+String returns the address in the format of the geoportal, e.g. "Hlavná 1, 04001 Košice, Slovenská republika"
+Missing parts are left out, the function returns an empty string when both street and municipality are missing
+*/
+func (a PlaceAddress) String() string {
+	return FormatAddress(a.Street, a.HouseNumber, a.PostalCode, a.Municipality)
+}
+
+/*
+FormatAddress returns an address in the format of the geoportal, e.g. "Hlavná 1, 04001 Košice, Slovenská republika"
+Missing parts are left out, the function returns an empty string when both street and municipality are missing
+*/
+func FormatAddress(street, houseNumber, postalCode, municipality string) string {
+	if strings.TrimSpace(street) == "" && strings.TrimSpace(municipality) == "" {
+		return ""
+	}
+
+	line := strings.Join(strings.Fields(street+" "+houseNumber), " ")
+	town := strings.Join(strings.Fields(strings.ReplaceAll(postalCode, " ", "")+" "+municipality), " ")
+
+	parts := []string{}
+	for _, part := range []string{line, town, addressCountry} {
+		if part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	return strings.Join(parts, ", ")
+}
+
+/*
+IsEmptyAddress reports whether the address holds nothing but punctuation and the country
+Older scrapes stored addresses like " ,  , Slovenská republika" for specialists without any address
+*/
+func IsEmptyAddress(address string) bool {
+	rest := strings.ReplaceAll(address, addressCountry, "")
+
+	return strings.IndexFunc(rest, func(r rune) bool {
+		return unicode.IsLetter(r) || unicode.IsDigit(r)
+	}) < 0
+}
+
+/*
+AddressCheck represents the verification of the address of a specialist against its coordinates
+The struct contains the following fields:
+- SpecialistID: the id of the specialist
+- SpecialistName: the name of the specialist
+- Status: verified, mismatch, filled (the empty address was filled in) or unresolved (nothing found at the coordinates)
+- StatedAddress: the address of the specialist when it was checked
+- GeocodedAddress: the address found at the coordinates of the specialist
+- DistanceMeters: the distance between the coordinates and the geocoded stated address (the filled in place for an empty address), 0 when unknown
+- CheckedAt: when the check ran
+*/
+type AddressCheck struct {
+	SpecialistID    int       `json:"specialist_id"`
+	SpecialistName  string    `json:"specialist_name,omitempty"`
+	Status          string    `json:"status"`
+	StatedAddress   string    `json:"stated_address"`
+	GeocodedAddress string    `json:"geocoded_address"`
+	DistanceMeters  float64   `json:"distance_meters"`
+	CheckedAt       time.Time `json:"checked_at"`
+}
+
+/*
+AddressMunicipality returns the municipality of an address in the format of the geoportal, e.g. Košice for "Hlavná 1, 04001 Košice, Slovenská republika"
+It is the part following the postal code, or without one the last part after the street, an address of a single part is taken for the municipality
+The function returns an empty string when there is no municipality in the address
+*/
+func AddressMunicipality(address string) string {
+	parts := []string{}
+	for _, part := range strings.Split(strings.ReplaceAll(address, addressCountry, ""), ",") {
+		if part = strings.TrimSpace(part); part != "" {
+			parts = append(parts, part)
+		}
+	}
+
+	for _, part := range parts {
+		if matches := addressTownRegexp.FindStringSubmatch(part); matches != nil {
+			return matches[1]
+		}
+	}
+
+	switch {
+	case len(parts) > 1:
+		return parts[len(parts)-1]
+	case len(parts) == 1 && strings.IndexFunc(parts[0], unicode.IsDigit) < 0:
+		return parts[0]
+	}
+
+	return ""
+}
@@ -0,0 +1,32 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestFormatAddress(t *testing.T) {
+	assert.Equal(t, "Hlavná 1, 04001 Košice, Slovenská republika", FormatAddress("Hlavná", "1", "040 01", "Košice"))
+	assert.Equal(t, "Hlavná 1, Košice, Slovenská republika", FormatAddress(" Hlavná ", "1", "", "Košice"))
+	assert.Equal(t, "04001 Košice, Slovenská republika", FormatAddress("", "", "04001", "Košice"))
+	assert.Equal(t, "Hlavná, Slovenská republika", FormatAddress("Hlavná", "", "", ""))
+	assert.Equal(t, "", FormatAddress("", "12", "04001", ""))
+	assert.Equal(t, "Hlavná 1, 04001 Košice, Slovenská republika", PlaceAddress{Street: "Hlavná", HouseNumber: "1", PostalCode: "04001", Municipality: "Košice"}.String())
+}
+
+func TestIsEmptyAddress(t *testing.T) {
+	assert.True(t, IsEmptyAddress(""))
+	assert.True(t, IsEmptyAddress(" ,  , Slovenská republika"))
+	assert.False(t, IsEmptyAddress("Košice, Slovenská republika"))
+	assert.False(t, IsEmptyAddress("12, Slovenská republika"))
+}
+
+func TestAddressMunicipality(t *testing.T) {
+	assert.Equal(t, "Košice", AddressMunicipality("Hlavná 1, 04001 Košice, Slovenská republika"))
+	assert.Equal(t, "Moldava nad Bodvou", AddressMunicipality("Školská 3, 045 01 Moldava nad Bodvou"))
+	assert.Equal(t, "Košice-Západ", AddressMunicipality("Trieda SNP 1, Košice-Západ"))
+	assert.Equal(t, "Michalovce", AddressMunicipality("Michalovce"))
+	assert.Equal(t, "", AddressMunicipality("Hlavná 1"))
+	assert.Equal(t, "", AddressMunicipality(" ,  , Slovenská republika"))
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+// actions recorded in the admin audit log
+const (
+	AuditActionCreate = "create"
+	AuditActionUpdate = "update"
+	AuditActionDelete = "delete"
+)
+
+// entities changed through the admin API
+const (
+	AuditEntitySpecialist       = "specialist"
+	AuditEntitySpecialty        = "specialty"
+	AuditEntityReview           = "review"
+	AuditEntitySymptomMapping   = "symptom_mapping"
+	AuditEntityHolidayOverride  = "holiday_override"
+	AuditEntityEmergencyService = "emergency_service"
+)
+
+// AuditEntities lists the entities recorded in the admin audit log
+var AuditEntities = []string{
+	AuditEntitySpecialist,
+	AuditEntitySpecialty,
+	AuditEntityReview,
+	AuditEntitySymptomMapping,
+	AuditEntityHolidayOverride,
+	AuditEntityEmergencyService,
+}
+
+/*
+AuditEntry represents one change made through the admin API
+The struct contains the following fields:
+- ID: the id of the entry
+- Actor: who made the change, as sent in the X-Admin-User header
+- RequestID: the id of the request that made the change
+- Action: create, update or delete
+- Entity: one of AuditEntities
+- EntityID: the id of the changed record, 0 for a holiday override, which is identified by the date and region in Before and After
+- Before: the record before the change as JSON, empty for a create
+- After: the record after the change as JSON, empty for a delete
+- CreatedAt: when the change was made
+*/
+type AuditEntry struct {
+	ID        int             `json:"id"`
+	Actor     string          `json:"actor"`
+	RequestID string          `json:"request_id,omitempty"`
+	Action    string          `json:"action"`
+	Entity    string          `json:"entity"`
+	EntityID  int             `json:"entity_id"`
+	Before    json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After     json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	CreatedAt time.Time       `json:"created_at"`
+}
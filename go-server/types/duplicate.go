@@ -0,0 +1,35 @@
+package types
+
+/*
+DuplicateSignals represents how alike two specialists are in each compared field, from 0 to 1
+The struct contains the following fields:
+- Name: the trigram similarity of the names
+- Address: 1 for the same address, the trigram similarity of the addresses otherwise
+- Location: 1 within 25 meters, falling to 0 at 500 meters, 0 when a location is unknown
+- Telephone: 1 when the specialists share a phone number
+- KPZS: 1 when the specialists have the same code of the provider
+*/
+type DuplicateSignals struct {
+	Name      float64 `json:"name"`
+	Address   float64 `json:"address"`
+	Location  float64 `json:"location"`
+	Telephone float64 `json:"telephone"`
+	KPZS      float64 `json:"kpzs"`
+}
+
+/*
+DuplicateCandidate represents two specialists that may be the same clinic
+The struct contains the following fields:
+- Specialist: the specialist with the lower id, with its KPZS code
+- Duplicate: the other specialist, with its KPZS code
+- Score: the weighted sum of the signals from 0 to 1
+- Signals: the similarity of the compared fields
+- DistanceMeters: the distance of the locations in meters, nil when a location is unknown
+*/
+type DuplicateCandidate struct {
+	Specialist     *Specialist      `json:"specialist"`
+	Duplicate      *Specialist      `json:"duplicate"`
+	Score          float64          `json:"score"`
+	Signals        DuplicateSignals `json:"signals"`
+	DistanceMeters *float64         `json:"distance_meters,omitempty"`
+}
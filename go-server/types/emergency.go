@@ -0,0 +1,203 @@
+package types
+
+import (
+	"errors"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// emergency service kinds
+const (
+	// ambulantná pohotovostná služba, the out-of-hours general practice
+	EmergencyKindAPS = "aps"
+	// hospital emergency department, urgentný príjem
+	EmergencyKindER = "er"
+	// pharmacy on duty, lekáreň s pohotovostnou službou
+	EmergencyKindPharmacy = "pharmacy"
+)
+
+// EmergencyKinds lists the valid emergency service kinds
+var EmergencyKinds = []string{EmergencyKindAPS, EmergencyKindER, EmergencyKindPharmacy}
+
+// HolidayWeekday is the weekday of the shifts that replace the Sunday shifts on holidays
+const HolidayWeekday = 7
+
+/*
+EmergencyShift represents one entry of the rota of an emergency service
+A shift either repeats every week on Weekday or is a one-off duty on Date
+The struct contains the following fields:
+- Weekday: 0 for Monday to 6 for Sunday, 7 for holidays, nil for a one-off duty
+- Date: the day of a one-off duty in the YYYY-MM-DD format, empty for a weekly shift
+- Opens: the start of the shift in the HH:MM format
+- Closes: the end of the shift in the HH:MM format, a shift closing at or before its opening ends the next day, 00:00 - 00:00 lasts 24 hours
+*/
+type EmergencyShift struct {
+	Weekday *int   `json:"weekday,omitempty"`
+	Date    string `json:"date,omitempty"`
+	Opens   string `json:"opens"`
+	Closes  string `json:"closes"`
+}
+
+/*
+EmergencyService represents an emergency or on-call service
+The struct contains the following fields:
+- ID: the id of the service
+- Name: the name of the service
+- Kind: aps, er or pharmacy
+- Location: the location in the WKT format
+- Address: the address of the service
+- Telephone: the telephone number
+- Url: the website
+- Note: free text for the patients, e.g. "vchod z dvora"
+- Shifts: the rota of the service
+- Navigation: links to navigate to the service
+*/
+type EmergencyService struct {
+	ID         int              `json:"id"`
+	Name       string           `json:"name"`
+	Kind       string           `json:"kind"`
+	Location   string           `json:"location"`
+	Address    string           `json:"address,omitempty"`
+	Telephone  string           `json:"telephone,omitempty"`
+	Url        string           `json:"url,omitempty"`
+	Note       string           `json:"note,omitempty"`
+	Shifts     []EmergencyShift `json:"shifts"`
+	Navigation *NavigationLinks `json:"navigation,omitempty"`
+}
+
+/*
+NearbyEmergencyService represents an emergency service found near a location
+The struct contains the following fields:
+- Service: the emergency service
+- DistanceMeters: the distance from the location
+- OpenUntil: the end of the current shift, shifts following without a break included
+*/
+type NearbyEmergencyService struct {
+	Service        *EmergencyService `json:"service"`
+	DistanceMeters float64           `json:"distance_meters"`
+	OpenUntil      time.Time         `json:"open_until"`
+}
+
+/*
+Validate checks that the shift is either a weekly shift or a one-off duty and its times are valid
+*/
+func (s EmergencyShift) Validate() error {
+	if (s.Weekday == nil) == (s.Date == "") {
+		return errors.New("a shift needs either a weekday or a date")
+	}
+
+	if s.Weekday != nil && (*s.Weekday < 0 || *s.Weekday > HolidayWeekday) {
+		return fmt.Errorf("weekday %d is out of range [0, 7]", *s.Weekday)
+	}
+
+	if s.Date != "" {
+		if _, err := time.Parse("2006-01-02", s.Date); err != nil {
+			return fmt.Errorf("date %q must be in the YYYY-MM-DD format", s.Date)
+		}
+	}
+
+	for _, clock := range []string{s.Opens, s.Closes} {
+		if _, ok := parseClock(clock); !ok {
+			return fmt.Errorf("time %q must be in the HH:MM format", clock)
+		}
+	}
+
+	return nil
+}
+
+/*
+OpenAt reports whether the service is open at t and until when, in the local time of the service
+isHoliday tells whether a day is a holiday, the holiday shifts apply then, or the Sunday shifts when the service has none
+*/
+func (s *EmergencyService) OpenAt(t time.Time, isHoliday func(day time.Time) bool) (bool, time.Time) {
+	type interval struct{ start, end time.Time }
+
+	// shifts started yesterday may still run, shifts of the following week may extend the current one
+	intervals := []interval{}
+	today := time.Date(t.Year(), t.Month(), t.Day(), 0, 0, 0, 0, t.Location())
+	for offset := -1; offset <= 7; offset++ {
+		day := today.AddDate(0, 0, offset)
+		for _, shift := range s.shiftsOn(day, isHoliday(day)) {
+			opens, _ := parseClock(shift.Opens)
+			closes, _ := parseClock(shift.Closes)
+
+			start := atClock(day, opens)
+			end := atClock(day, closes)
+			if closes <= opens {
+				end = atClock(day.AddDate(0, 0, 1), closes)
+			}
+			intervals = append(intervals, interval{start, end})
+		}
+	}
+
+	var until time.Time
+	for _, i := range intervals {
+		if !i.start.After(t) && i.end.After(t) && i.end.After(until) {
+			until = i.end
+		}
+	}
+	if until.IsZero() {
+		return false, time.Time{}
+	}
+
+	// merge the shifts following without a break
+	for extended := true; extended; {
+		extended = false
+		for _, i := range intervals {
+			if !i.start.After(until) && i.end.After(until) {
+				until = i.end
+				extended = true
+			}
+		}
+	}
+
+	return true, until
+}
+
+// shiftsOn returns the shifts of the day, the one-off duties and the weekly shifts of its weekday
+func (s *EmergencyService) shiftsOn(day time.Time, holiday bool) []EmergencyShift {
+	weekday := (int(day.Weekday()) + 6) % 7
+	if holiday {
+		weekday = 6
+		for _, shift := range s.Shifts {
+			if shift.Weekday != nil && *shift.Weekday == HolidayWeekday {
+				weekday = HolidayWeekday
+				break
+			}
+		}
+	}
+
+	date := day.Format("2006-01-02")
+	shifts := []EmergencyShift{}
+	for _, shift := range s.Shifts {
+		if shift.Date == date || (shift.Weekday != nil && *shift.Weekday == weekday) {
+			shifts = append(shifts, shift)
+		}
+	}
+
+	return shifts
+}
+
+// parseClock parses a HH:MM time of day into minutes since midnight, 24:00 included
+func parseClock(clock string) (int, bool) {
+	hours, minutes, ok := strings.Cut(strings.TrimSpace(clock), ":")
+	if !ok || len(minutes) != 2 {
+		return 0, false
+	}
+
+	if _, ok := formatClock(hours, minutes); !ok {
+		return 0, false
+	}
+
+	h, _ := strconv.Atoi(hours)
+	m, _ := strconv.Atoi(minutes)
+
+	return h*60 + m, true
+}
+
+// atClock returns the wall clock time on the day, so shifts keep their hours on the days the clocks change
+func atClock(day time.Time, minutes int) time.Time {
+	return time.Date(day.Year(), day.Month(), day.Day(), 0, minutes, 0, 0, day.Location())
+}
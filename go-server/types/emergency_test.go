@@ -0,0 +1,111 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func weekday(day int) *int {
+	return &day
+}
+
+func TestEmergencyShift_Validate(t *testing.T) {
+	tests := []struct {
+		shift    EmergencyShift
+		expected string
+	}{
+		{EmergencyShift{Weekday: weekday(0), Opens: "15:30", Closes: "07:00"}, ""},
+		{EmergencyShift{Weekday: weekday(7), Opens: "00:00", Closes: "24:00"}, ""},
+		{EmergencyShift{Date: "2024-03-04", Opens: "08:00", Closes: "20:00"}, ""},
+		{EmergencyShift{Opens: "08:00", Closes: "20:00"}, "a shift needs either a weekday or a date"},
+		{EmergencyShift{Weekday: weekday(1), Date: "2024-03-04", Opens: "08:00", Closes: "20:00"}, "a shift needs either a weekday or a date"},
+		{EmergencyShift{Weekday: weekday(8), Opens: "08:00", Closes: "20:00"}, "weekday 8 is out of range [0, 7]"},
+		{EmergencyShift{Date: "4.3.2024", Opens: "08:00", Closes: "20:00"}, `date "4.3.2024" must be in the YYYY-MM-DD format`},
+		{EmergencyShift{Weekday: weekday(1), Opens: "8", Closes: "20:00"}, `time "8" must be in the HH:MM format`},
+		{EmergencyShift{Weekday: weekday(1), Opens: "08:00", Closes: "24:30"}, `time "24:30" must be in the HH:MM format`},
+	}
+
+	for _, test := range tests {
+		err := test.shift.Validate()
+		if test.expected == "" {
+			assert.NoError(t, err)
+		} else {
+			assert.EqualError(t, err, test.expected)
+		}
+	}
+}
+
+func TestEmergencyService_OpenAt(t *testing.T) {
+	bratislava, err := time.LoadLocation("Europe/Bratislava")
+	if err != nil {
+		t.Fatal(err)
+	}
+
+	// out-of-hours service: weekdays overnight, weekends and holidays around the clock
+	aps := &EmergencyService{Shifts: []EmergencyShift{
+		{Weekday: weekday(0), Opens: "15:30", Closes: "07:00"},
+		{Weekday: weekday(1), Opens: "15:30", Closes: "07:00"},
+		{Weekday: weekday(2), Opens: "15:30", Closes: "07:00"},
+		{Weekday: weekday(3), Opens: "15:30", Closes: "07:00"},
+		{Weekday: weekday(4), Opens: "15:30", Closes: "00:00"},
+		{Weekday: weekday(5), Opens: "00:00", Closes: "24:00"},
+		{Weekday: weekday(6), Opens: "00:00", Closes: "24:00"},
+		{Date: "2024-03-05", Opens: "07:00", Closes: "15:30"},
+	}}
+
+	noHolidays := func(time.Time) bool { return false }
+	at := func(value string) time.Time {
+		parsed, _ := time.ParseInLocation("2006-01-02 15:04", value, bratislava)
+		return parsed
+	}
+
+	tests := []struct {
+		time  string
+		open  bool
+		until string
+	}{
+		// Monday
+		{"2024-03-04 10:00", false, ""},
+		{"2024-03-04 15:30", true, "2024-03-06 07:00"},
+		{"2024-03-05 06:59", true, "2024-03-06 07:00"},
+		// Tuesday, the one-off duty joins the overnight shifts
+		{"2024-03-05 10:00", true, "2024-03-06 07:00"},
+		// Wednesday morning, the shift started on Tuesday
+		{"2024-03-06 06:00", true, "2024-03-06 07:00"},
+		{"2024-03-06 07:00", false, ""},
+		// Friday evening runs through the weekend
+		{"2024-03-08 20:00", true, "2024-03-11 00:00"},
+	}
+
+	for _, test := range tests {
+		open, until := aps.OpenAt(at(test.time), noHolidays)
+		assert.Equal(t, test.open, open, test.time)
+		if test.open {
+			assert.True(t, at(test.until).Equal(until), "%s: open until %s", test.time, until)
+		}
+	}
+
+	// on a holiday Monday the Sunday shift applies
+	holiday := func(day time.Time) bool { return day.Format("2006-01-02") == "2024-04-01" }
+	open, until := aps.OpenAt(at("2024-04-01 10:00"), holiday)
+	assert.True(t, open)
+	assert.True(t, at("2024-04-02 00:00").Equal(until), until)
+
+	// the holiday shifts replace the Sunday shifts when there are any
+	pharmacy := &EmergencyService{Shifts: []EmergencyShift{
+		{Weekday: weekday(6), Opens: "08:00", Closes: "20:00"},
+		{Weekday: weekday(HolidayWeekday), Opens: "09:00", Closes: "12:00"},
+	}}
+	open, _ = pharmacy.OpenAt(at("2024-04-01 13:00"), holiday)
+	assert.False(t, open)
+	open, until = pharmacy.OpenAt(at("2024-04-01 10:00"), holiday)
+	assert.True(t, open)
+	assert.True(t, at("2024-04-01 12:00").Equal(until))
+
+	// clocks go forward on 2024-03-31, the shift keeps its wall clock hours
+	open, until = pharmacy.OpenAt(at("2024-03-31 19:00"), noHolidays)
+	assert.True(t, open)
+	assert.Equal(t, "2024-03-31T20:00:00+02:00", until.Format(time.RFC3339))
+}
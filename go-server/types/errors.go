@@ -0,0 +1,116 @@
+package types
+
+import (
+	"fmt"
+	"strings"
+)
+
+/*
+ErrorCode is the machine-readable kind of an error returned by the API
+Clients (e.g. the chatbot) react to the code, the message is meant for people
+*/
+type ErrorCode string
+
+const (
+	// ErrorCodeInvalidPayload is a request the client has to fix, e.g. a missing or malformed field
+	ErrorCodeInvalidPayload ErrorCode = "invalid_payload"
+	// ErrorCodeUnauthorized is a request without valid credentials
+	ErrorCodeUnauthorized ErrorCode = "unauthorized"
+	// ErrorCodeNotFound is a request for something that does not exist
+	ErrorCodeNotFound ErrorCode = "not_found"
+	// ErrorCodeConflict is a change clashing with the stored data, e.g. a duplicate
+	ErrorCodeConflict ErrorCode = "conflict"
+	// ErrorCodeRateLimited is a request refused by a rate limited external provider
+	ErrorCodeRateLimited ErrorCode = "rate_limited"
+	// ErrorCodeUpstream is a failure of an external provider, e.g. a geocoder
+	ErrorCodeUpstream ErrorCode = "upstream_error"
+	// ErrorCodeUnavailable is a request that cannot be served right now, e.g. it was cancelled or the feature is disabled
+	ErrorCodeUnavailable ErrorCode = "unavailable"
+	// ErrorCodeTimeout is a request that did not finish in time
+	ErrorCodeTimeout ErrorCode = "timeout"
+	// ErrorCodeInternal is any other failure, its details are logged and never returned
+	ErrorCodeInternal ErrorCode = "internal_error"
+)
+
+/*
+FieldError describes a single invalid field of a request
+The struct contains the following fields:
+- Field: the name of the field as sent by the client
+- Message: what is wrong with the field
+*/
+type FieldError struct {
+	Field   string `json:"field"`
+	Message string `json:"message"`
+}
+
+/*
+Error is a domain error with a message safe to return to clients
+The struct contains the following fields:
+- Code: the kind of the error, it decides the HTTP status code
+- Message: the description of the error shown to clients
+- Fields: the invalid fields of a rejected request
+- Err: the underlying cause, it is logged but never returned to clients
+*/
+type Error struct {
+	Code    ErrorCode
+	Message string
+	Fields  []FieldError
+	Err     error
+}
+
+func (e *Error) Error() string {
+	if e.Err != nil {
+		return fmt.Sprintf("%s: %s", e.Message, e.Err)
+	}
+
+	return e.Message
+}
+
+func (e *Error) Unwrap() error {
+	return e.Err
+}
+
+// NewError returns an error with the code and message
+func NewError(code ErrorCode, message string) *Error {
+	return &Error{Code: code, Message: message}
+}
+
+// NewValidationError returns an invalid payload error, fields lists the invalid fields if they are known
+func NewValidationError(message string, fields ...FieldError) *Error {
+	return &Error{Code: ErrorCodeInvalidPayload, Message: message, Fields: fields}
+}
+
+// NewMissingFieldError returns an invalid payload error for a required field left out
+func NewMissingFieldError(field string) *Error {
+	return NewValidationError(fmt.Sprintf("Invalid payload: missing %s field", field), FieldError{Field: field, Message: "is required"})
+}
+
+// NewMissingFieldsError returns an invalid payload error for several required fields left out
+func NewMissingFieldsError(fields []string) *Error {
+	fieldErrors := make([]FieldError, 0, len(fields))
+	for _, field := range fields {
+		fieldErrors = append(fieldErrors, FieldError{Field: field, Message: "is required"})
+	}
+
+	return NewValidationError("Invalid payload: missing "+strings.Join(fields, ", "), fieldErrors...)
+}
+
+// NewInvalidFieldError returns an invalid payload error for a field with a wrong value, e.g. ("limit", "must be between 1 and 10")
+func NewInvalidFieldError(field, message string) *Error {
+	return NewValidationError(fmt.Sprintf("Invalid payload: %s %s", field, message), FieldError{Field: field, Message: message})
+}
+
+// NewNotFoundError returns a not found error
+func NewNotFoundError(message string) *Error {
+	return NewError(ErrorCodeNotFound, message)
+}
+
+// NewConflictError returns a conflict error caused by err
+func NewConflictError(message string, err error) *Error {
+	return &Error{Code: ErrorCodeConflict, Message: message, Err: err}
+}
+
+// NewUpstreamError returns an external provider failure caused by err
+func NewUpstreamError(message string, err error) *Error {
+	return &Error{Code: ErrorCodeUpstream, Message: message, Err: err}
+}
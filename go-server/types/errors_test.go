@@ -0,0 +1,38 @@
+package types
+
+import (
+	"errors"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestError(t *testing.T) {
+	cause := errors.New("pq: duplicate key value violates unique constraint")
+
+	err := NewConflictError("The change conflicts with the stored data", cause)
+
+	assert.Equal(t, ErrorCodeConflict, err.Code)
+	assert.EqualError(t, err, "The change conflicts with the stored data: pq: duplicate key value violates unique constraint")
+	assert.ErrorIs(t, err, cause)
+
+	assert.EqualError(t, NewNotFoundError("Specialist not found"), "Specialist not found")
+	assert.Equal(t, ErrorCodeUpstream, NewUpstreamError("Failed to get geocode location", cause).Code)
+}
+
+func TestValidationErrors(t *testing.T) {
+	missing := NewMissingFieldError("query")
+	assert.Equal(t, ErrorCodeInvalidPayload, missing.Code)
+	assert.Equal(t, "Invalid payload: missing query field", missing.Message)
+	assert.Equal(t, []FieldError{{Field: "query", Message: "is required"}}, missing.Fields)
+
+	missingMany := NewMissingFieldsError([]string{"name", "location"})
+	assert.Equal(t, "Invalid payload: missing name, location", missingMany.Message)
+	assert.Equal(t, []FieldError{{Field: "name", Message: "is required"}, {Field: "location", Message: "is required"}}, missingMany.Fields)
+
+	invalid := NewInvalidFieldError("limit", "must be between 1 and 10")
+	assert.Equal(t, "Invalid payload: limit must be between 1 and 10", invalid.Message)
+	assert.Equal(t, []FieldError{{Field: "limit", Message: "must be between 1 and 10"}}, invalid.Fields)
+
+	assert.Nil(t, NewValidationError("Invalid JSON payload").Fields)
+}
@@ -39,15 +39,24 @@ type GeoportalSpecialist struct {
 	Bbox           []float64 `json:"bbox"`
 }
 
+/*
+SpecialtyName returns the specialization in lower case with collapsed whitespace
+The geoportal is not consistent in capitalization and spacing of druh_zariadenia
+*/
+func (g *GeoportalSpecialist) SpecialtyName() string {
+	return strings.ToLower(strings.Join(strings.Fields(g.Specialization), " "))
+}
+
 func (g *GeoportalSpecialist) getWKTLocation() string {
 	lat := strconv.FormatFloat(g.Latitude, 'f', -1, 64)
 	lon := strconv.FormatFloat(g.Longitude, 'f', -1, 64)
 	return "POINT(" + lon + " " + lat + ")"
 }
 
+// getAddress returns the address line, or the address built from its parts, empty when the geoportal has neither
 func (g *GeoportalSpecialist) getAddress() string {
-	if g.Address == "" {
-		return g.StreetName + " " + g.BuildingNumber + ", " + g.PostalCode + " " + g.Municipality + ", Slovenská republika"
+	if IsEmptyAddress(g.Address) {
+		return FormatAddress(g.StreetName, g.BuildingNumber, g.PostalCode, g.Municipality)
 	}
 	return g.Address
 }
@@ -101,6 +110,21 @@ func (g *GeoportalSpecialist) getDovera() bool {
 	}
 }
 
+func (g *GeoportalSpecialist) getInsurers() []string {
+	insurers := []string{}
+	if g.getVszp() {
+		insurers = append(insurers, "VšZP")
+	}
+	if g.getDovera() {
+		insurers = append(insurers, "Dôvera")
+	}
+	if g.getUnion() {
+		insurers = append(insurers, "Union")
+	}
+
+	return insurers
+}
+
 func (g *GeoportalSpecialist) CastToDbType(specialtyID int) Specialist {
 	return Specialist{
 		Name:        g.Name,
@@ -116,5 +140,8 @@ func (g *GeoportalSpecialist) CastToDbType(specialtyID int) Specialist {
 		Friday:      g.FridayHours,
 		Saturday:    g.SaturdayHours,
 		Sunday:      g.SundayHours,
+		Staff:       g.getSpecialistNames(),
+		Insurers:    g.getInsurers(),
+		KPZS:        g.KPZS,
 	}
 }
@@ -45,6 +45,14 @@ func setupTestCase() GeoportalSpecialist {
 	return testCaseCopy
 }
 
+func TestSpecialtyName(t *testing.T) {
+	testCase := setupTestCase()
+	assert.Equal(t, "ambulancia vnútorného lekárstva", testCase.SpecialtyName())
+
+	testCase.Specialization = "  Ambulancia  Vnútorného lekárstva "
+	assert.Equal(t, "ambulancia vnútorného lekárstva", testCase.SpecialtyName())
+}
+
 func TestGetWKTLocation(t *testing.T) {
 	testCase := setupTestCase()
 
@@ -65,7 +73,11 @@ func TestGetAddress(t *testing.T) {
 	actual = testCase.getAddress()
 	assert.Equal(t, expected, actual)
 
-	testCase.Address = expected
+	testCase = GeoportalSpecialist{Municipality: "Michalovce", PostalCode: "071 01"}
+	assert.Equal(t, "07101 Michalovce, Slovenská republika", testCase.getAddress())
+
+	testCase = GeoportalSpecialist{}
+	assert.Equal(t, "", testCase.getAddress())
 }
 
 func TestGetSpecialistNames(t *testing.T) {
@@ -123,6 +135,15 @@ func TestGetDovera(t *testing.T) {
 	assert.Equal(t, expected, actual)
 }
 
+func TestGetInsurers(t *testing.T) {
+	testCase := setupTestCase()
+	assert.Equal(t, []string{"VšZP", "Union"}, testCase.getInsurers())
+
+	testCase.Union = "nie"
+	testCase.Vszp = "nie"
+	assert.Equal(t, []string{}, testCase.getInsurers())
+}
+
 func TestCastToDbType(t *testing.T) {
 	testCase := setupTestCase()
 
@@ -140,6 +161,9 @@ func TestCastToDbType(t *testing.T) {
 		Friday:      "7:00 - 13:00, 13:30 - 15:00",
 		Saturday:    "",
 		Sunday:      "",
+		Staff:       "MUDr. Milena Zidanova, Zita Triuma, Jozef Kralik, MUDr. Jana Kralikova",
+		Insurers:    []string{"VšZP", "Union"},
+		KPZS:        "P27489001201",
 	}
 	actual := testCase.CastToDbType(1)
 	assert.Equal(t, expected, actual)
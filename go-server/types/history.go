@@ -0,0 +1,40 @@
+package types
+
+import (
+	"encoding/json"
+	"time"
+)
+
+/*
+SpecialistChange represents one change of a specialist, made by the scraper, the address check or the admin API
+The struct contains the following fields:
+- ID: the id of the change
+- SpecialistID: the id of the changed specialist
+- Action: create, update or delete, as in the admin audit log
+- Actor: who made the change, the member of staff for the admin API, e.g. scraper for the sync from the geoportal, empty in the public history
+- Before: the changed fields before the change as JSON, empty for a create
+- After: the changed fields after the change as JSON, empty for a delete
+- ChangedAt: when the change was made
+*/
+type SpecialistChange struct {
+	ID           int             `json:"id"`
+	SpecialistID int             `json:"specialist_id"`
+	Action       string          `json:"action"`
+	Actor        string          `json:"actor,omitempty"`
+	Before       json.RawMessage `json:"before,omitempty" swaggertype:"object"`
+	After        json.RawMessage `json:"after,omitempty" swaggertype:"object"`
+	ChangedAt    time.Time       `json:"changed_at"`
+}
+
+/*
+SpecialistSnapshot represents a specialist as it was at a point in time
+The struct contains the following fields:
+- Specialist: the specialist after the latest change before the point in time
+- Actor: who made that change, empty in the public history
+- ChangedAt: when that change was made, the snapshot is valid from then on
+*/
+type SpecialistSnapshot struct {
+	Specialist *Specialist `json:"specialist"`
+	Actor      string      `json:"actor,omitempty"`
+	ChangedAt  time.Time   `json:"changed_at"`
+}
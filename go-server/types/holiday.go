@@ -0,0 +1,24 @@
+package types
+
+// holiday sources
+const (
+	HolidaySourcePublic   = "public"
+	HolidaySourceOverride = "override"
+)
+
+/*
+Holiday represents a day the clinics keep Sunday hours, or an override of such a day
+The struct contains the following fields:
+- Date: the day in the YYYY-MM-DD format
+- Name: the Slovak name of the holiday or the reason of the closure
+- Region: the municipality or district the holiday applies to, empty for the whole country
+- Closed: false only for overrides cancelling a public holiday, the clinics then keep their usual hours
+- Source: public for the statutory holidays, override for the entries edited by an admin
+*/
+type Holiday struct {
+	Date   string `json:"date"`
+	Name   string `json:"name"`
+	Region string `json:"region,omitempty"`
+	Closed bool   `json:"closed"`
+	Source string `json:"source"`
+}
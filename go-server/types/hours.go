@@ -0,0 +1,132 @@
+package types
+
+import (
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+	"time"
+)
+
+var timeRangeRegexp = regexp.MustCompile(`^(\d{1,2}):(\d{2})\s*-\s*(\d{1,2}):(\d{2})$`)
+
+/*
+TimeRange represents a single opening interval within a day
+The struct contains the following fields:
+- Open: the opening time in the HH:MM format
+- Close: the closing time in the HH:MM format
+*/
+type TimeRange struct {
+	Open  string `json:"open"`
+	Close string `json:"close"`
+}
+
+/*
+DayHours represents the opening hours of a specialist on one weekday
+The struct contains the following fields:
+- Day: the lower case English name of the weekday
+- Ranges: the opening intervals, empty when the specialist is closed
+- Note: the parts of the source text that are not time ranges, e.g. "po dohode"
+*/
+type DayHours struct {
+	Day    string      `json:"day"`
+	Ranges []TimeRange `json:"ranges"`
+	Note   string      `json:"note,omitempty"`
+}
+
+/*
+ParseOpeningHours parses opening hours as published by the geoportal, e.g. "7:00 - 13:00, 13:30 - 15:00"
+The ranges are returned in the HH:MM format in the order of the source text
+The parts that are not valid time ranges are returned joined as a note
+*/
+func ParseOpeningHours(raw string) ([]TimeRange, string) {
+	ranges := []TimeRange{}
+	notes := []string{}
+
+	for _, part := range strings.Split(raw, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+
+		matches := timeRangeRegexp.FindStringSubmatch(part)
+		if matches == nil {
+			notes = append(notes, part)
+			continue
+		}
+
+		open, okOpen := formatClock(matches[1], matches[2])
+		closing, okClose := formatClock(matches[3], matches[4])
+		if !okOpen || !okClose || closing <= open {
+			notes = append(notes, part)
+			continue
+		}
+
+		ranges = append(ranges, TimeRange{Open: open, Close: closing})
+	}
+
+	return ranges, strings.Join(notes, ", ")
+}
+
+func formatClock(hours, minutes string) (string, bool) {
+	h, err := strconv.Atoi(hours)
+	if err != nil {
+		return "", false
+	}
+	m, err := strconv.Atoi(minutes)
+	if err != nil {
+		return "", false
+	}
+
+	if h > 24 || m > 59 || (h == 24 && m != 0) {
+		return "", false
+	}
+
+	return fmt.Sprintf("%02d:%02d", h, m), true
+}
+
+/*
+OpeningHours returns the parsed opening hours of the specialist for every weekday starting with Monday
+*/
+func (s *Specialist) OpeningHours() []DayHours {
+	days := []struct {
+		name string
+		raw  string
+	}{
+		{"monday", s.Monday},
+		{"tuesday", s.Tuesday},
+		{"wednesday", s.Wednesday},
+		{"thursday", s.Thursday},
+		{"friday", s.Friday},
+		{"saturday", s.Saturday},
+		{"sunday", s.Sunday},
+	}
+
+	hours := make([]DayHours, 0, len(days))
+	for _, day := range days {
+		ranges, note := ParseOpeningHours(day.raw)
+		hours = append(hours, DayHours{Day: day.name, Ranges: ranges, Note: note})
+	}
+
+	return hours
+}
+
+/*
+OpenAt reports whether the specialist is open at t, in the local time of the specialist
+On a holiday the specialist keeps its Sunday hours, whatever the weekday
+*/
+func (s *Specialist) OpenAt(t time.Time, holiday bool) bool {
+	day := (int(t.Weekday()) + 6) % 7
+	if holiday {
+		day = 6
+	}
+
+	clock := t.Format("15:04")
+	for _, r := range s.OpeningHours()[day].Ranges {
+		if clock >= r.Open && clock < r.Close {
+			return true
+		}
+	}
+
+	return false
+}
@@ -0,0 +1,64 @@
+package types
+
+import (
+	"testing"
+	"time"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseOpeningHours(t *testing.T) {
+	tests := []struct {
+		raw    string
+		ranges []TimeRange
+		note   string
+	}{
+		{"", []TimeRange{}, ""},
+		{"7:00 - 13:00, 13:30 - 15:00", []TimeRange{{"07:00", "13:00"}, {"13:30", "15:00"}}, ""},
+		{"07:30-11:00", []TimeRange{{"07:30", "11:00"}}, ""},
+		{"8:00 - 12:00, po dohode", []TimeRange{{"08:00", "12:00"}}, "po dohode"},
+		{"12:00 - 8:00", []TimeRange{}, "12:00 - 8:00"},
+		{"25:00 - 26:00", []TimeRange{}, "25:00 - 26:00"},
+		{"20:00 - 24:00", []TimeRange{{"20:00", "24:00"}}, ""},
+	}
+
+	for _, test := range tests {
+		ranges, note := ParseOpeningHours(test.raw)
+		assert.Equal(t, test.ranges, ranges, test.raw)
+		assert.Equal(t, test.note, note, test.raw)
+	}
+}
+
+func TestOpeningHours(t *testing.T) {
+	testCase := setupTestCase()
+	specialist := testCase.CastToDbType(1)
+
+	hours := specialist.OpeningHours()
+
+	assert.Len(t, hours, 7)
+	assert.Equal(t, DayHours{Day: "monday", Ranges: []TimeRange{{"07:00", "13:00"}, {"13:30", "15:00"}}}, hours[0])
+	assert.Equal(t, DayHours{Day: "sunday", Ranges: []TimeRange{}}, hours[6])
+}
+
+func TestOpenAt(t *testing.T) {
+	specialist := &Specialist{Monday: "7:00 - 13:00, 13:30 - 15:00", Sunday: "20:00 - 24:00"}
+
+	monday := func(clock string) time.Time {
+		parsed, _ := time.Parse("2006-01-02 15:04", "2024-03-04 "+clock)
+		return parsed
+	}
+
+	assert.True(t, specialist.OpenAt(monday("07:00"), false))
+	assert.True(t, specialist.OpenAt(monday("12:59"), false))
+	assert.False(t, specialist.OpenAt(monday("13:00"), false))
+	assert.True(t, specialist.OpenAt(monday("14:00"), false))
+	assert.False(t, specialist.OpenAt(monday("15:00"), false))
+	assert.False(t, specialist.OpenAt(monday("21:00"), false))
+
+	// a holiday on a Monday keeps the Sunday hours
+	assert.False(t, specialist.OpenAt(monday("08:00"), true))
+	assert.True(t, specialist.OpenAt(monday("23:59"), true))
+
+	sunday := monday("21:00").AddDate(0, 0, 6)
+	assert.True(t, specialist.OpenAt(sunday, false))
+}
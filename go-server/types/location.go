@@ -0,0 +1,315 @@
+package types
+
+import (
+	"encoding/json"
+	"errors"
+	"fmt"
+	"math"
+	"strconv"
+	"strings"
+)
+
+// ErrAxisOrder is returned for coordinates that are valid only with latitude and longitude swapped
+var ErrAxisOrder = errors.New("the coordinates look swapped: WKT and GeoJSON list the longitude first, e.g. POINT(21.2496774 48.7172272)")
+
+/*
+Location represents a point on the WGS 84 ellipsoid
+The struct contains the following fields:
+- Lat: the latitude between -90 and 90
+- Lon: the longitude between -180 and 180
+*/
+type Location struct {
+	Lat float64 `json:"lat"`
+	Lon float64 `json:"lon"`
+}
+
+/*
+BoundingBox represents an area between two parallels and two meridians
+The struct contains the following fields:
+- MinLat: the southern edge
+- MinLon: the western edge
+- MaxLat: the northern edge
+- MaxLon: the eastern edge
+*/
+type BoundingBox struct {
+	MinLat float64 `json:"min_lat"`
+	MinLon float64 `json:"min_lon"`
+	MaxLat float64 `json:"max_lat"`
+	MaxLon float64 `json:"max_lon"`
+}
+
+// ServiceArea is the area the specialists are scraped from, Slovakia with a small margin
+var ServiceArea = BoundingBox{MinLat: 47.7, MinLon: 16.8, MaxLat: 49.65, MaxLon: 22.6}
+
+// the WKT geometry types other than a point, longer names first so MULTIPOINT is not reported as POINT
+var wktGeometryTypes = []string{"MULTIPOINT", "MULTILINESTRING", "MULTIPOLYGON", "GEOMETRYCOLLECTION", "LINESTRING", "POLYGON"}
+
+/*
+Contains reports whether the location lies inside the bounding box, edges included
+*/
+func (b BoundingBox) Contains(l Location) bool {
+	return l.Lat >= b.MinLat && l.Lat <= b.MaxLat && l.Lon >= b.MinLon && l.Lon <= b.MaxLon
+}
+
+/*
+LocationInput represents a location as sent by a client, before it is parsed
+A JSON string is kept as is (WKT or EWKT), a JSON object is kept as its raw JSON text (GeoJSON Point or {lat, lon})
+The input is parsed by the Location method, so a malformed location gets a precise error instead of "Invalid JSON payload"
+*/
+type LocationInput string
+
+func (in *LocationInput) UnmarshalJSON(data []byte) error {
+	var text string
+	if err := json.Unmarshal(data, &text); err == nil {
+		*in = LocationInput(text)
+		return nil
+	}
+
+	trimmed := strings.TrimSpace(string(data))
+	if trimmed == "null" {
+		*in = ""
+		return nil
+	}
+	if !strings.HasPrefix(trimmed, "{") {
+		return errors.New("location must be a WKT string or a JSON object")
+	}
+
+	*in = LocationInput(trimmed)
+	return nil
+}
+
+/*
+Location parses the input, see ParseLocation
+*/
+func (in LocationInput) Location() (Location, error) {
+	return ParseLocation(string(in))
+}
+
+/*
+ParseLocation parses a location from any of the supported formats:
+- WKT, e.g. "POINT(21.2496774 48.7172272)" or "POINT (2.1e1 4.8e1)"
+- EWKT, e.g. "SRID=4326;POINT(21.2496774 48.7172272)", only SRID 4326 is accepted
+- GeoJSON Point, e.g. {"type": "Point", "coordinates": [21.2496774, 48.7172272]}
+- an object with latitude and longitude, e.g. {"lat": 48.7172272, "lon": 21.2496774}, "latitude", "lng" and "longitude" are accepted too
+The function returns an error describing what is wrong with the input, coordinates out of range included
+*/
+func ParseLocation(raw string) (Location, error) {
+	raw = strings.TrimSpace(raw)
+	if raw == "" {
+		return Location{}, errors.New("location is empty")
+	}
+
+	var l Location
+	var err error
+	if strings.HasPrefix(raw, "{") {
+		l, err = parseJSONLocation(raw)
+	} else {
+		l, err = parseWKTLocation(raw)
+	}
+	if err != nil {
+		return Location{}, err
+	}
+
+	if err := l.Validate(); err != nil {
+		return Location{}, err
+	}
+
+	return l, nil
+}
+
+/*
+Validate checks that the coordinates are within their ranges
+A latitude out of range that would be valid as a longitude, while the longitude would be valid as a latitude, is reported as ErrAxisOrder
+*/
+func (l Location) Validate() error {
+	if l.Lat < -90 || l.Lat > 90 {
+		if l.Lat >= -180 && l.Lat <= 180 && l.Lon >= -90 && l.Lon <= 90 {
+			return fmt.Errorf("latitude %s is out of range [-90, 90], %w", formatFloat(l.Lat), ErrAxisOrder)
+		}
+		return fmt.Errorf("latitude %s is out of range [-90, 90]", formatFloat(l.Lat))
+	}
+
+	if l.Lon < -180 || l.Lon > 180 {
+		return fmt.Errorf("longitude %s is out of range [-180, 180]", formatFloat(l.Lon))
+	}
+
+	return nil
+}
+
+/*
+CheckAxisOrder returns ErrAxisOrder when the location lies outside the area but would lie inside it with latitude and longitude swapped
+Both orders are valid coordinates in that case, so the mistake can only be caught knowing where the locations are expected
+*/
+func (l Location) CheckAxisOrder(area BoundingBox) error {
+	swapped := Location{Lat: l.Lon, Lon: l.Lat}
+	if !area.Contains(l) && area.Contains(swapped) {
+		return fmt.Errorf("%s lies outside the service area but %s lies inside it, %w", l.WKT(), swapped.WKT(), ErrAxisOrder)
+	}
+
+	return nil
+}
+
+/*
+WKT returns the location as a WKT point, longitude first
+*/
+func (l Location) WKT() string {
+	return "POINT(" + formatFloat(l.Lon) + " " + formatFloat(l.Lat) + ")"
+}
+
+func parseWKTLocation(raw string) (Location, error) {
+	text := raw
+
+	if prefix, rest, ok := strings.Cut(text, ";"); ok {
+		srid, found := strings.CutPrefix(strings.ToUpper(strings.TrimSpace(prefix)), "SRID=")
+		if !found {
+			return Location{}, fmt.Errorf("invalid EWKT prefix %q, expected SRID=4326", prefix)
+		}
+		if srid != "4326" {
+			return Location{}, fmt.Errorf("unsupported SRID %s, expected 4326", srid)
+		}
+		text = strings.TrimSpace(rest)
+	}
+
+	geometry, body, _ := strings.Cut(text, "(")
+	geometry = strings.ToUpper(strings.TrimSpace(geometry))
+	if geometry == "POINT EMPTY" {
+		return Location{}, errors.New("WKT point is empty")
+	}
+	if !strings.HasPrefix(geometry, "POINT") {
+		for _, name := range wktGeometryTypes {
+			if strings.HasPrefix(geometry, name) {
+				return Location{}, fmt.Errorf("unsupported geometry type %s, expected POINT", name)
+			}
+		}
+		return Location{}, fmt.Errorf("invalid location %q, expected a WKT point, e.g. POINT(21.2496774 48.7172272)", raw)
+	}
+
+	// POINT Z, POINT M and POINT ZM carry extra ordinates after the longitude and latitude
+	dimensions := strings.TrimSpace(strings.TrimPrefix(geometry, "POINT"))
+	expected := 2 + len(dimensions)
+	if dimensions != "" && dimensions != "Z" && dimensions != "M" && dimensions != "ZM" {
+		return Location{}, fmt.Errorf("invalid location %q, expected a WKT point, e.g. POINT(21.2496774 48.7172272)", raw)
+	}
+
+	body, ok := strings.CutSuffix(strings.TrimSpace(body), ")")
+	if !ok {
+		return Location{}, fmt.Errorf("invalid location %q, the WKT point is missing its parentheses", raw)
+	}
+
+	fields := strings.Fields(body)
+	if len(fields) != expected {
+		return Location{}, fmt.Errorf("WKT point must have %d coordinates, got %d", expected, len(fields))
+	}
+
+	lon, err := parseCoordinate("longitude", fields[0])
+	if err != nil {
+		return Location{}, err
+	}
+	lat, err := parseCoordinate("latitude", fields[1])
+	if err != nil {
+		return Location{}, err
+	}
+
+	return Location{Lat: lat, Lon: lon}, nil
+}
+
+func parseJSONLocation(raw string) (Location, error) {
+	var object struct {
+		Type        *string          `json:"type"`
+		Coordinates json.RawMessage  `json:"coordinates"`
+		Lat         *json.RawMessage `json:"lat"`
+		Latitude    *json.RawMessage `json:"latitude"`
+		Lon         *json.RawMessage `json:"lon"`
+		Lng         *json.RawMessage `json:"lng"`
+		Longitude   *json.RawMessage `json:"longitude"`
+		CRS         *json.RawMessage `json:"crs"`
+	}
+
+	if err := json.Unmarshal([]byte(raw), &object); err != nil {
+		return Location{}, fmt.Errorf("invalid location object: %v", err)
+	}
+
+	if object.Type != nil {
+		if !strings.EqualFold(*object.Type, "Point") {
+			return Location{}, fmt.Errorf("unsupported GeoJSON geometry type %s, expected Point", *object.Type)
+		}
+		if object.CRS != nil {
+			return Location{}, errors.New("GeoJSON crs member is not supported, coordinates must be WGS 84")
+		}
+
+		var coordinates []json.RawMessage
+		if err := json.Unmarshal(object.Coordinates, &coordinates); err != nil {
+			return Location{}, errors.New("GeoJSON Point coordinates must be an array of numbers")
+		}
+		if len(coordinates) < 2 || len(coordinates) > 3 {
+			return Location{}, fmt.Errorf("GeoJSON Point must have 2 or 3 coordinates, got %d", len(coordinates))
+		}
+
+		lon, err := parseJSONCoordinate("longitude", coordinates[0])
+		if err != nil {
+			return Location{}, err
+		}
+		lat, err := parseJSONCoordinate("latitude", coordinates[1])
+		if err != nil {
+			return Location{}, err
+		}
+
+		return Location{Lat: lat, Lon: lon}, nil
+	}
+
+	lat, err := pickCoordinate("latitude", object.Lat, object.Latitude)
+	if err != nil {
+		return Location{}, err
+	}
+	lon, err := pickCoordinate("longitude", object.Lon, object.Lng, object.Longitude)
+	if err != nil {
+		return Location{}, err
+	}
+
+	return Location{Lat: lat, Lon: lon}, nil
+}
+
+// pickCoordinate returns the only one of the alias fields that is set
+func pickCoordinate(name string, aliases ...*json.RawMessage) (float64, error) {
+	var value *json.RawMessage
+	for _, alias := range aliases {
+		if alias == nil {
+			continue
+		}
+		if value != nil {
+			return 0, fmt.Errorf("%s is given more than once", name)
+		}
+		value = alias
+	}
+
+	if value == nil {
+		return 0, fmt.Errorf("missing %s", name)
+	}
+
+	return parseJSONCoordinate(name, *value)
+}
+
+// parseJSONCoordinate parses a JSON number, numbers sent as strings are accepted too
+func parseJSONCoordinate(name string, raw json.RawMessage) (float64, error) {
+	text := string(raw)
+
+	var quoted string
+	if err := json.Unmarshal(raw, &quoted); err == nil {
+		text = quoted
+	}
+
+	return parseCoordinate(name, text)
+}
+
+func parseCoordinate(name, text string) (float64, error) {
+	value, err := strconv.ParseFloat(text, 64)
+	if err != nil || math.IsNaN(value) || math.IsInf(value, 0) {
+		return 0, fmt.Errorf("%s %q is not a number", name, text)
+	}
+
+	return value, nil
+}
+
+func formatFloat(value float64) string {
+	return strconv.FormatFloat(value, 'f', -1, 64)
+}
@@ -0,0 +1,120 @@
+package types
+
+import (
+	"encoding/json"
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestParseLocation(t *testing.T) {
+	kosice := Location{Lat: 48.7172272, Lon: 21.2496774}
+
+	tests := []string{
+		"POINT(21.2496774 48.7172272)",
+		"POINT (21.2496774 48.7172272)",
+		"  point( 21.2496774   48.7172272 ) ",
+		"POINT Z (21.2496774 48.7172272 210)",
+		"SRID=4326;POINT(21.2496774 48.7172272)",
+		"srid=4326; POINT(21.2496774 48.7172272)",
+		"POINT(2.12496774e1 4.87172272E+1)",
+		`{"type": "Point", "coordinates": [21.2496774, 48.7172272]}`,
+		`{"type": "Point", "coordinates": [21.2496774, 48.7172272, 210]}`,
+		`{"lat": 48.7172272, "lon": 21.2496774}`,
+		`{"latitude": 48.7172272, "longitude": 21.2496774}`,
+		`{"lat": "48.7172272", "lng": 21.2496774}`,
+	}
+
+	for _, raw := range tests {
+		location, err := ParseLocation(raw)
+		assert.NoError(t, err, raw)
+		assert.Equal(t, kosice, location, raw)
+	}
+}
+
+func TestParseLocation_Errors(t *testing.T) {
+	tests := []struct {
+		raw      string
+		expected string
+	}{
+		{" ", "location is empty"},
+		{"Košice", `invalid location "Košice", expected a WKT point, e.g. POINT(21.2496774 48.7172272)`},
+		{"POINT EMPTY", "WKT point is empty"},
+		{"POINT(21.2496774)", "WKT point must have 2 coordinates, got 1"},
+		{"POINT(21.2496774, 48.7172272)", `longitude "21.2496774," is not a number`},
+		{"POINT(21.2496774 48.7172272", `invalid location "POINT(21.2496774 48.7172272", the WKT point is missing its parentheses`},
+		{"POINT(abc 48.7)", `longitude "abc" is not a number`},
+		{"POINT(21.2 NaN)", `latitude "NaN" is not a number`},
+		{"POLYGON((0 0, 1 1, 1 0, 0 0))", "unsupported geometry type POLYGON, expected POINT"},
+		{"MULTIPOINT((0 0))", "unsupported geometry type MULTIPOINT, expected POINT"},
+		{"SRID=3857;POINT(2365478 6274125)", "unsupported SRID 3857, expected 4326"},
+		{"4326;POINT(21.2 48.7)", `invalid EWKT prefix "4326", expected SRID=4326`},
+		{"POINT(21.2 148.7)", "latitude 148.7 is out of range [-90, 90], " + ErrAxisOrder.Error()},
+		{"POINT(121.2 148.7)", "latitude 148.7 is out of range [-90, 90]"},
+		{"POINT(190 48.7)", "longitude 190 is out of range [-180, 180]"},
+		{`{"type": "LineString", "coordinates": [[0, 0], [1, 1]]}`, "unsupported GeoJSON geometry type LineString, expected Point"},
+		{`{"type": "Point", "coordinates": [21.2]}`, "GeoJSON Point must have 2 or 3 coordinates, got 1"},
+		{`{"type": "Point", "coordinates": [21.2, 48.7], "crs": {}}`, "GeoJSON crs member is not supported, coordinates must be WGS 84"},
+		{`{"lat": 48.7}`, "missing longitude"},
+		{`{"lon": 21.2}`, "missing latitude"},
+		{`{"lat": 48.7, "latitude": 48.7, "lon": 21.2}`, "latitude is given more than once"},
+		{`{"lat": 48.7, "lon": "east"}`, `longitude "east" is not a number`},
+		{`{"lat": null, "lon": 21.2}`, "missing latitude"},
+		{`{"type": "Point", "coordinates": "21.2 48.7"}`, "GeoJSON Point coordinates must be an array of numbers"},
+		{`{"lat": 48.7`, "invalid location object: unexpected end of JSON input"},
+	}
+
+	for _, test := range tests {
+		_, err := ParseLocation(test.raw)
+		assert.EqualError(t, err, test.expected, test.raw)
+	}
+}
+
+func TestLocation_CheckAxisOrder(t *testing.T) {
+	assert.NoError(t, Location{Lat: 48.7172272, Lon: 21.2496774}.CheckAxisOrder(ServiceArea))
+	// outside the service area in both orders, e.g. London
+	assert.NoError(t, Location{Lat: 51.5, Lon: -0.1}.CheckAxisOrder(ServiceArea))
+
+	err := Location{Lat: 21.2496774, Lon: 48.7172272}.CheckAxisOrder(ServiceArea)
+	assert.ErrorIs(t, err, ErrAxisOrder)
+	assert.EqualError(t, err, "POINT(48.7172272 21.2496774) lies outside the service area but POINT(21.2496774 48.7172272) lies inside it, "+ErrAxisOrder.Error())
+}
+
+func TestLocation_WKT(t *testing.T) {
+	assert.Equal(t, "POINT(21.2496774 48.7172272)", Location{Lat: 48.7172272, Lon: 21.2496774}.WKT())
+	assert.Equal(t, "POINT(-71.060316 48.432044)", Location{Lat: 48.432044, Lon: -71.060316}.WKT())
+}
+
+func TestLocationInput_UnmarshalJSON(t *testing.T) {
+	var payload struct {
+		Location LocationInput `json:"location"`
+	}
+
+	tests := map[string]LocationInput{
+		`{"location": "POINT(21.2 48.7)"}`:                         "POINT(21.2 48.7)",
+		`{"location": {"lat": 48.7, "lon": 21.2}}`:                 `{"lat": 48.7, "lon": 21.2}`,
+		`{"location": {"type":"Point","coordinates":[21.2,48.7]}}`: `{"type":"Point","coordinates":[21.2,48.7]}`,
+		`{"location": null}`:                                       "",
+	}
+
+	for body, expected := range tests {
+		payload.Location = "previous"
+		assert.NoError(t, json.Unmarshal([]byte(body), &payload), body)
+		assert.Equal(t, expected, payload.Location, body)
+	}
+
+	assert.EqualError(t, json.Unmarshal([]byte(`{"location": [21.2, 48.7]}`), &payload), "location must be a WKT string or a JSON object")
+
+	location, err := LocationInput(`{"lat": 48.7, "lon": 21.2}`).Location()
+	assert.NoError(t, err)
+	assert.Equal(t, Location{Lat: 48.7, Lon: 21.2}, location)
+}
+
+func TestBoundingBox_Contains(t *testing.T) {
+	box := BoundingBox{MinLat: 48, MinLon: 21, MaxLat: 49, MaxLon: 22}
+
+	assert.True(t, box.Contains(Location{Lat: 48.5, Lon: 21.5}))
+	assert.True(t, box.Contains(Location{Lat: 48, Lon: 22}))
+	assert.False(t, box.Contains(Location{Lat: 47.9, Lon: 21.5}))
+	assert.False(t, box.Contains(Location{Lat: 48.5, Lon: 22.1}))
+}
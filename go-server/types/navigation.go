@@ -0,0 +1,84 @@
+package types
+
+import (
+	"net/url"
+)
+
+/*
+NavigationLinks represents links opening a location in map applications
+The struct contains the following fields:
+- GoogleMaps: the Google Maps place link
+- GoogleMapsDirections: the Google Maps directions link, starting at the origin when one is known
+- OpenStreetMap: the OpenStreetMap link with a marker
+- OpenStreetMapDirections: the OpenStreetMap directions link, starting at the origin when one is known
+- Waze: the Waze place link
+- WazeDirections: the Waze link starting navigation, Waze always starts at the current position of the device
+*/
+type NavigationLinks struct {
+	GoogleMaps              string `json:"google_maps"`
+	GoogleMapsDirections    string `json:"google_maps_directions"`
+	OpenStreetMap           string `json:"openstreetmap"`
+	OpenStreetMapDirections string `json:"openstreetmap_directions"`
+	Waze                    string `json:"waze"`
+	WazeDirections          string `json:"waze_directions"`
+}
+
+/*
+NewNavigationLinks returns navigation links to a destination location, e.g. "POINT(21.2496774 48.7172272)"
+The origin is an optional WKT point the directions start at, an empty origin leaves the start to the map application
+The function returns an error if the destination or a non-empty origin is not a valid WKT point
+*/
+func NewNavigationLinks(destination, origin string) (*NavigationLinks, error) {
+	to, err := navigationPoint(destination)
+	if err != nil {
+		return nil, err
+	}
+
+	var from []string
+	if origin != "" {
+		from, err = navigationPoint(origin)
+		if err != nil {
+			return nil, err
+		}
+	}
+
+	lat, lon := to[0], to[1]
+	coords := lat + "," + lon
+
+	googleDirections := url.Values{"api": {"1"}, "destination": {coords}}
+	if from != nil {
+		googleDirections.Set("origin", from[0]+","+from[1])
+	}
+
+	osmRoute := ";" + coords
+	if from != nil {
+		osmRoute = from[0] + "," + from[1] + osmRoute
+	}
+
+	return &NavigationLinks{
+		GoogleMaps:              "https://www.google.com/maps/search/?" + url.Values{"api": {"1"}, "query": {coords}}.Encode(),
+		GoogleMapsDirections:    "https://www.google.com/maps/dir/?" + googleDirections.Encode(),
+		OpenStreetMap:           "https://www.openstreetmap.org/?mlat=" + lat + "&mlon=" + lon + "#map=17/" + lat + "/" + lon,
+		OpenStreetMapDirections: "https://www.openstreetmap.org/directions?" + url.Values{"route": {osmRoute}}.Encode(),
+		Waze:                    "https://waze.com/ul?" + url.Values{"ll": {coords}}.Encode(),
+		WazeDirections:          "https://waze.com/ul?" + url.Values{"ll": {coords}, "navigate": {"yes"}}.Encode(),
+	}, nil
+}
+
+/*
+IsWKTPoint reports whether the text is a location accepted by NewNavigationLinks, see ParseLocation
+*/
+func IsWKTPoint(wkt string) bool {
+	_, err := navigationPoint(wkt)
+	return err == nil
+}
+
+// navigationPoint returns the latitude and longitude of a location formatted for URLs
+func navigationPoint(location string) ([]string, error) {
+	l, err := ParseLocation(location)
+	if err != nil {
+		return nil, err
+	}
+
+	return []string{formatFloat(l.Lat), formatFloat(l.Lon)}, nil
+}
@@ -0,0 +1,58 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewNavigationLinks(t *testing.T) {
+	links, err := NewNavigationLinks("POINT(21.2496774 48.7172272)", "")
+
+	expected := &NavigationLinks{
+		GoogleMaps:              "https://www.google.com/maps/search/?api=1&query=48.7172272%2C21.2496774",
+		GoogleMapsDirections:    "https://www.google.com/maps/dir/?api=1&destination=48.7172272%2C21.2496774",
+		OpenStreetMap:           "https://www.openstreetmap.org/?mlat=48.7172272&mlon=21.2496774#map=17/48.7172272/21.2496774",
+		OpenStreetMapDirections: "https://www.openstreetmap.org/directions?route=%3B48.7172272%2C21.2496774",
+		Waze:                    "https://waze.com/ul?ll=48.7172272%2C21.2496774",
+		WazeDirections:          "https://waze.com/ul?ll=48.7172272%2C21.2496774&navigate=yes",
+	}
+
+	assert.NoError(t, err)
+	assert.Equal(t, expected, links)
+}
+
+func TestNewNavigationLinks_WithOrigin(t *testing.T) {
+	links, err := NewNavigationLinks("POINT(21.2496774 48.7172272)", "POINT(21.9 48.75)")
+
+	assert.NoError(t, err)
+	assert.Equal(t, "https://www.google.com/maps/dir/?api=1&destination=48.7172272%2C21.2496774&origin=48.75%2C21.9", links.GoogleMapsDirections)
+	assert.Equal(t, "https://www.openstreetmap.org/directions?route=48.75%2C21.9%3B48.7172272%2C21.2496774", links.OpenStreetMapDirections)
+	assert.Equal(t, "https://waze.com/ul?ll=48.7172272%2C21.2496774&navigate=yes", links.WazeDirections)
+}
+
+func TestNewNavigationLinks_InvalidWKT(t *testing.T) {
+	tests := map[string]string{
+		"":                     "location is empty",
+		"POINT(21.2)":          "WKT point must have 2 coordinates, got 1",
+		"LINESTRING(0 0, 1 1)": "unsupported geometry type LINESTRING, expected POINT",
+		"0101000020E6100000":   `invalid location "0101000020E6100000", expected a WKT point, e.g. POINT(21.2496774 48.7172272)`,
+	}
+	for wkt, expected := range tests {
+		links, err := NewNavigationLinks(wkt, "")
+		assert.EqualError(t, err, expected, wkt)
+		assert.Nil(t, links)
+	}
+
+	links, err := NewNavigationLinks("POINT(21.2496774 48.7172272)", "Košice")
+	assert.EqualError(t, err, `invalid location "Košice", expected a WKT point, e.g. POINT(21.2496774 48.7172272)`)
+	assert.Nil(t, links)
+}
+
+func TestIsWKTPoint(t *testing.T) {
+	assert.True(t, IsWKTPoint("POINT(21.2496774 48.7172272)"))
+	assert.True(t, IsWKTPoint("POINT (-0.1 51.5)"))
+	assert.True(t, IsWKTPoint("SRID=4326;POINT(21.2496774 48.7172272)"))
+	assert.False(t, IsWKTPoint("POINT(21.2496774)"))
+	assert.False(t, IsWKTPoint("Košice"))
+}
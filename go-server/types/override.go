@@ -0,0 +1,87 @@
+package types
+
+import "time"
+
+// sources of the fields of a specialist
+const (
+	// the value comes from the source of the specialist, the geoportal or the admin API that created it
+	FieldSourceSource = "source"
+	// the value was corrected by an admin and is kept by the sync from the geoportal
+	FieldSourceOverride = "override"
+)
+
+// SpecialistOverrideFields lists the fields of a specialist an admin can override, in the order of the Specialist struct
+var SpecialistOverrideFields = []string{
+	"name", "location", "address", "url", "telephone", "email",
+	"monday", "tuesday", "wednesday", "thursday", "friday", "saturday", "sunday", "staff",
+}
+
+/*
+SpecialistOverride represents a field of a specialist overridden by an admin
+The struct contains the following fields:
+- Field: the overridden field, one of SpecialistOverrideFields
+- Value: the value of the override, the one presented by the API
+- SourceValue: the latest value of the field in the source, restored when the override is removed
+- UpdatedAt: when the override was last set
+*/
+type SpecialistOverride struct {
+	Field       string    `json:"field"`
+	Value       string    `json:"value"`
+	SourceValue string    `json:"source_value"`
+	UpdatedAt   time.Time `json:"updated_at"`
+}
+
+/*
+OverridableField returns a pointer to the field of the specialist with the name, one of SpecialistOverrideFields
+The function returns nil for any other name
+*/
+func (s *Specialist) OverridableField(field string) *string {
+	switch field {
+	case "name":
+		return &s.Name
+	case "location":
+		return &s.Location
+	case "address":
+		return &s.Address
+	case "url":
+		return &s.Url
+	case "telephone":
+		return &s.Telephone
+	case "email":
+		return &s.Email
+	case "monday":
+		return &s.Monday
+	case "tuesday":
+		return &s.Tuesday
+	case "wednesday":
+		return &s.Wednesday
+	case "thursday":
+		return &s.Thursday
+	case "friday":
+		return &s.Friday
+	case "saturday":
+		return &s.Saturday
+	case "sunday":
+		return &s.Sunday
+	case "staff":
+		return &s.Staff
+	}
+
+	return nil
+}
+
+// NewFieldSources returns the source of every overridable field, the overridden fields come from an override
+func NewFieldSources(overridden []string) map[string]string {
+	sources := make(map[string]string, len(SpecialistOverrideFields))
+	for _, field := range SpecialistOverrideFields {
+		sources[field] = FieldSourceSource
+	}
+
+	for _, field := range overridden {
+		if _, ok := sources[field]; ok {
+			sources[field] = FieldSourceOverride
+		}
+	}
+
+	return sources
+}
@@ -0,0 +1,30 @@
+package types
+
+import (
+	"testing"
+
+	"github.com/stretchr/testify/assert"
+)
+
+func TestNewFieldSources(t *testing.T) {
+	sources := NewFieldSources([]string{"name", "insurers"})
+
+	assert.Len(t, sources, len(SpecialistOverrideFields))
+	assert.Equal(t, FieldSourceOverride, sources["name"])
+	assert.Equal(t, FieldSourceSource, sources["telephone"])
+	assert.NotContains(t, sources, "insurers")
+}
+
+func TestSpecialist_OverridableField(t *testing.T) {
+	s := Specialist{Name: "Kardio Košice", Staff: "MUDr. Ján Novák", Insurers: []string{"VšZP"}}
+
+	for _, field := range SpecialistOverrideFields {
+		assert.NotNil(t, s.OverridableField(field), field)
+	}
+
+	*s.OverridableField("telephone") = "055/123"
+	assert.Equal(t, "055/123", s.Telephone)
+	assert.Equal(t, "MUDr. Ján Novák", *s.OverridableField("staff"))
+	assert.Nil(t, s.OverridableField("insurers"))
+	assert.Nil(t, s.OverridableField("specialty_id"))
+}
@@ -1,5 +1,7 @@
 package types
 
+import "time"
+
 /*
 Review represents a review of a specialist
 The struct contains the following fields:
@@ -35,23 +37,82 @@ The struct contains the following fields:
 - Friday: the opening hours of the specialist on Friday
 - Saturday: the opening hours of the specialist on Saturday
 - Sunday: the opening hours of the specialist on Sunday
+- Staff: the names of the doctors and nurses working at the specialist
+- Insurers: the health insurance companies the specialist has a contract with
+- KPZS: the code of the provider in the geoportal, set by the scraper
+- Navigation: links opening the location of the specialist in map applications, filled in by the API
+- Version: bumped by every update, an update or delete must name the version it was based on
+- FieldSources: whether each overridable field comes from the source or an admin override
 */
 type Specialist struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	SpecialtyID int    `json:"specialty_id"`
-	Location    string `json:"location,omitempty"`
-	Address     string `json:"address,omitempty"`
-	Url         string `json:"url,omitempty"`
-	Telephone   string `json:"telephone,omitempty"`
-	Email       string `json:"email,omitempty"`
-	Monday      string `json:"monday,omitempty"`
-	Tuesday     string `json:"tuesday,omitempty"`
-	Wednesday   string `json:"wednesday,omitempty"`
-	Thursday    string `json:"thursday,omitempty"`
-	Friday      string `json:"friday,omitempty"`
-	Saturday    string `json:"saturday,omitempty"`
-	Sunday      string `json:"sunday,omitempty"`
+	ID           int               `json:"id"`
+	Name         string            `json:"name"`
+	SpecialtyID  int               `json:"specialty_id"`
+	Location     string            `json:"location,omitempty"`
+	Address      string            `json:"address,omitempty"`
+	Url          string            `json:"url,omitempty"`
+	Telephone    string            `json:"telephone,omitempty"`
+	Email        string            `json:"email,omitempty"`
+	Monday       string            `json:"monday,omitempty"`
+	Tuesday      string            `json:"tuesday,omitempty"`
+	Wednesday    string            `json:"wednesday,omitempty"`
+	Thursday     string            `json:"thursday,omitempty"`
+	Friday       string            `json:"friday,omitempty"`
+	Saturday     string            `json:"saturday,omitempty"`
+	Sunday       string            `json:"sunday,omitempty"`
+	Staff        string            `json:"staff,omitempty"`
+	Insurers     []string          `json:"insurers,omitempty"`
+	KPZS         string            `json:"kpzs,omitempty"`
+	Navigation   *NavigationLinks  `json:"navigation,omitempty"`
+	Version      int               `json:"version,omitempty"`
+	FieldSources map[string]string `json:"field_sources,omitempty"`
+}
+
+/*
+SpecialistProfile represents the full detail of a single specialist
+The struct contains the following fields:
+- Specialist: the specialist
+- SpecialtyName: the name of the specialty of the specialist
+- OpeningHours: the opening hours parsed into time ranges, one entry per weekday starting with Monday
+- OpenNow: whether the specialist is open at the time of the request, Sunday hours apply on holidays
+- Holiday: the holiday or closure of the day, nil on a regular day
+- Reviews: the number of reviews and their average rating
+- UpdatedAt: when the specialist was last updated
+*/
+type SpecialistProfile struct {
+	Specialist    *Specialist   `json:"specialist"`
+	SpecialtyName string        `json:"specialty_name"`
+	OpeningHours  []DayHours    `json:"opening_hours"`
+	OpenNow       bool          `json:"open_now"`
+	Holiday       *Holiday      `json:"holiday,omitempty"`
+	Reviews       ReviewSummary `json:"reviews"`
+	UpdatedAt     time.Time     `json:"updated_at"`
+}
+
+/*
+ReviewSummary represents the aggregated reviews of a specialist
+The struct contains the following fields:
+- Count: the number of reviews
+- AverageRating: the average rating, 0 when there are no reviews
+*/
+type ReviewSummary struct {
+	Count         int     `json:"count"`
+	AverageRating float64 `json:"average_rating"`
+}
+
+/*
+SpecialistSearchResult represents a single hit of the specialist full-text search
+The struct contains the following fields:
+- Specialist: the matched specialist
+- SpecialtyName: the name of the specialty of the specialist
+- Rank: the relevance of the hit between 0 and 1, higher is better
+- Highlights: the matched fields as escaped HTML with the matching words wrapped in <mark> tags
+*/
+type SpecialistSearchResult struct {
+	Specialist    *Specialist       `json:"specialist"`
+	SpecialtyName string            `json:"specialty_name"`
+	Rank          float64           `json:"rank"`
+	Highlights    map[string]string `json:"highlights,omitempty"`
 }
 
 /*
@@ -60,9 +121,109 @@ The struct contains the following fields:
 - ID: the id of the specialty
 - Name: the name of the specialty
 - Description: the description of the specialty
+- Synonyms: alternative names of the specialty in the requested language
+- Category: the category the specialty belongs to
 */
 type Specialty struct {
-	ID          int    `json:"id"`
-	Name        string `json:"name"`
-	Description string `json:"description"`
+	ID          int                `json:"id"`
+	Name        string             `json:"name"`
+	Description string             `json:"description"`
+	Synonyms    []string           `json:"synonyms,omitempty"`
+	Category    *SpecialtyCategory `json:"category,omitempty"`
+}
+
+/*
+SpecialtyCategory represents a group of related specialties, e.g. dentistry
+The struct contains the following fields:
+- ID: the id of the category
+- Code: the stable identifier of the category from the seed file
+- Name: the name of the category in the requested language
+- ParentCode: the code of the parent category, empty for top level categories
+*/
+type SpecialtyCategory struct {
+	ID         int    `json:"id"`
+	Code       string `json:"code"`
+	Name       string `json:"name"`
+	ParentCode string `json:"parent_code,omitempty"`
+}
+
+/*
+SpecialtyTranslation represents the name and description of a specialty in one language
+The struct contains the following fields:
+- SpecialtyID: the id of the specialty
+- Language: the ISO 639-1 language code
+- Name: the translated name
+- Description: the translated description
+- Synonyms: alternative names in the language
+*/
+type SpecialtyTranslation struct {
+	SpecialtyID int      `json:"specialty_id"`
+	Language    string   `json:"language"`
+	Name        string   `json:"name"`
+	Description string   `json:"description"`
+	Synonyms    []string `json:"synonyms"`
+}
+
+/*
+SymptomMapping represents a curated keyword pointing to a specialty
+The struct contains the following fields:
+- ID: the id of the mapping
+- Keyword: the symptom or keyword, matched as a word prefix without diacritics, words shorter than 4 letters only as whole words
+- SpecialtyID: the id of the specialty
+- SpecialtyName: the name of the specialty
+- Weight: how strongly the keyword indicates the specialty, between 0 and 1
+- Enabled: disabled mappings are kept for the audit trail but ignored by triage
+*/
+type SymptomMapping struct {
+	ID            int     `json:"id"`
+	Keyword       string  `json:"keyword"`
+	SpecialtyID   int     `json:"specialty_id"`
+	SpecialtyName string  `json:"specialty_name,omitempty"`
+	Weight        float64 `json:"weight"`
+	Enabled       bool    `json:"enabled"`
+}
+
+/*
+TriageCandidate represents a specialty suggested for a free text complaint
+The struct contains the following fields:
+- SpecialtyID: the id of the specialty
+- SpecialtyName: the name of the specialty
+- Confidence: the combined weight of all matched keywords, between 0 and 1
+- MatchedKeywords: the keywords that matched the complaint
+*/
+type TriageCandidate struct {
+	SpecialtyID     int      `json:"specialty_id"`
+	SpecialtyName   string   `json:"specialty_name"`
+	Confidence      float64  `json:"confidence"`
+	MatchedKeywords []string `json:"matched_keywords"`
+}
+
+// the kinds of geocoded places
+const (
+	PlaceTypeRegion = "region"
+	PlaceTypeCity   = "city"
+	PlaceTypeStreet = "street"
+	PlaceTypeHouse  = "house"
+	PlaceTypeOther  = "other"
+)
+
+/*
+Place represents a geocoded location
+The struct contains the following fields:
+- Lat: the latitude
+- Lon: the longitude
+- DisplayName: the human readable address or name of the place
+- Type: the kind of the place, one of region, city, street, house or other, empty when the provider does not tell
+- Importance: the relevance reported by the provider between 0 and 1, 0 when the provider does not tell
+- BoundingBox: the area covered by the place, nil when the provider does not tell
+- Address: the parts of the postal address of the place, nil when the provider does not tell
+*/
+type Place struct {
+	Lat         float64       `json:"lat"`
+	Lon         float64       `json:"lon"`
+	DisplayName string        `json:"display_name"`
+	Type        string        `json:"type,omitempty"`
+	Importance  float64       `json:"importance,omitempty"`
+	BoundingBox *BoundingBox  `json:"bounding_box,omitempty"`
+	Address     *PlaceAddress `json:"address,omitempty"`
 }